@@ -0,0 +1,36 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package controllers implements the AntreaConfig provider of the generic config controller.
+package controllers
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/tanzu-framework/addons/controllers/genericconfig"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+	cniv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/addonconfigs/cni/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=addons.tanzu.vmware.com,resources=antreaconfigs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=addons.tanzu.vmware.com,resources=antreaconfigs/status,verbs=get;update;patch
+
+// NewAntreaConfigProvider returns the provider of the AntreaConfig CRD for the generic config controller
+func NewAntreaConfigProvider() (*genericconfig.Provider, error) {
+	// the defaulting and validating webhooks of AntreaConfig are implemented by its API type
+	return &genericconfig.Provider{
+		Kind:      constants.AntreaConfigKind,
+		AddonName: constants.AntreaAddonName,
+		NewConfig: func() client.Object {
+			return &cniv1alpha1.AntreaConfig{}
+		},
+		NewConfigList: func() client.ObjectList {
+			return &cniv1alpha1.AntreaConfigList{}
+		},
+		OwnerClusterRequired: true,
+		DataValues:           mapAntreaConfigToDataValues,
+		SetSecretRef: func(config client.Object, secretName string) {
+			config.(*cniv1alpha1.AntreaConfig).Status.SecretRef = secretName
+		},
+	}, nil
+}
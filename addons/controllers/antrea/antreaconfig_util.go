@@ -5,16 +5,12 @@ package controllers
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/pkg/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	yaml "gopkg.in/yaml.v3"
 	clusterv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
-	clusterapiutil "sigs.k8s.io/cluster-api/util"
-	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
 	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/util"
 	cniv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/addonconfigs/cni/v1alpha1"
 )
@@ -110,54 +106,15 @@ type antreaFeatureGates struct {
 	TrafficControl     bool `yaml:"TrafficControl"`
 }
 
-// ClusterToAntreaConfig returns a list of Requests with AntreaConfig ObjectKey
-func (r *AntreaConfigReconciler) ClusterToAntreaConfig(o client.Object) []ctrl.Request {
-	cluster, ok := o.(*clusterv1beta1.Cluster)
-	if !ok {
-		r.Log.Error(errors.New("invalid type"),
-			"Expected to receive Cluster resource",
-			"actualType", fmt.Sprintf("%T", o))
-		return nil
-	}
-
-	r.Log.V(4).Info("Mapping cluster to AntreaConfig")
+// mapAntreaConfigToDataValues maps AntreaConfig CR to data values
+func mapAntreaConfigToDataValues(_ context.Context, _ client.Client, config client.Object,
+	cluster *clusterv1beta1.Cluster) (interface{}, error) {
 
-	configs := &cniv1alpha1.AntreaConfigList{}
-
-	if err := r.Client.List(context.Background(), configs); err != nil {
-		r.Log.Error(err, "Error listing AntreaConfig")
-		return nil
-	}
-
-	var requests []ctrl.Request
-	for i := range configs.Items {
-		config := &configs.Items[i]
-		if config.Namespace == cluster.Namespace {
-			// avoid enqueuing reconcile requests for template AntreaConfig CRs in event handler of Cluster CR
-			if _, ok := config.Annotations[constants.TKGAnnotationTemplateConfig]; ok && config.Namespace == r.Config.SystemNamespace {
-				continue
-			}
-
-			// corresponding AntreaConfig should have following ownerRef
-			ownerReference := metav1.OwnerReference{
-				APIVersion: clusterv1beta1.GroupVersion.String(),
-				Kind:       cluster.Kind,
-				Name:       cluster.Name,
-				UID:        cluster.UID,
-			}
-
-			if clusterapiutil.HasOwnerRef(config.OwnerReferences, ownerReference) {
-				r.Log.V(4).Info("Adding AntreaConfig for reconciliation",
-					constants.NamespaceLogKey, config.Namespace, constants.NameLogKey, config.Name)
-
-				requests = append(requests, ctrl.Request{
-					NamespacedName: clusterapiutil.ObjectKey(config),
-				})
-			}
-		}
+	antreaConfigYaml, err := mapAntreaConfigSpec(cluster, config.(*cniv1alpha1.AntreaConfig))
+	if err != nil {
+		return nil, err
 	}
-
-	return requests
+	return yaml.Marshal(antreaConfigYaml)
 }
 
 func mapAntreaConfigSpec(cluster *clusterv1beta1.Cluster, config *cniv1alpha1.AntreaConfig) (*antreaConfigSpec, error) {
@@ -0,0 +1,62 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package controllers implements the AwsEbsCSIConfig provider of the generic config controller.
+package controllers
+
+import (
+	_ "embed" // for the data values template
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/tanzu-framework/addons/controllers/genericconfig"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+	csiv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/addonconfigs/csi/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=csi.tanzu.vmware.com,resources=awsebscsiconfigs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=csi.tanzu.vmware.com,resources=awsebscsiconfigs/status,verbs=get;update;patch
+//+kubebuilder:webhook:verbs=create;update,path=/validate-csi-tanzu-vmware-com-v1alpha1-awsebscsiconfig,mutating=false,failurePolicy=fail,groups=csi.tanzu.vmware.com,resources=awsebscsiconfigs,versions=v1alpha1,name=awsebscsiconfig.validating.vmware.com,admissionReviewVersions=v1,sideEffects=None
+
+// dataValuesTemplate is the ytt template mapping the AwsEbsCSIConfig CR to the data values of the aws-ebs-csi package
+//
+//go:embed awsebscsiconfig_datavalues.yaml
+var dataValuesTemplate string
+
+// specSchema is the schema which the spec of the AwsEbsCSIConfig CR is validated against on admission
+var specSchema = &apiextensionsv1.JSONSchemaProps{
+	Type:     "object",
+	Required: []string{"awsEBSCSIDriver"},
+	Properties: map[string]apiextensionsv1.JSONSchemaProps{
+		"awsEBSCSIDriver": {
+			Type: "object",
+			Properties: map[string]apiextensionsv1.JSONSchemaProps{
+				// an empty namespace selects the default namespace kube-system
+				"namespace":          {Type: "string", MaxLength: pointer.Int64(63), Pattern: "^([a-z0-9]([-a-z0-9]*[a-z0-9])?)?$"},
+				"deploymentReplicas": {Type: "integer", Minimum: pointer.Float64(1)},
+			},
+		},
+	},
+}
+
+// NewAwsEbsCSIConfigProvider returns the provider of the AwsEbsCSIConfig CRD for the generic config controller
+func NewAwsEbsCSIConfigProvider() (*genericconfig.Provider, error) {
+	dataValues, err := genericconfig.YttDataValues(map[string]string{"awsebscsiconfig_datavalues.yaml": dataValuesTemplate})
+	if err != nil {
+		return nil, err
+	}
+	return &genericconfig.Provider{
+		Kind:      constants.AwsEbsCSIConfigKind,
+		AddonName: constants.AwsEbsCSIAddonName,
+		NewConfig: func() client.Object {
+			return &csiv1alpha1.AwsEbsCSIConfig{}
+		},
+		DataValues: dataValues,
+		SpecSchema: specSchema,
+		SetSecretRef: func(config client.Object, secretName string) {
+			config.(*csiv1alpha1.AwsEbsCSIConfig).Status.SecretRef = &secretName
+		},
+	}, nil
+}
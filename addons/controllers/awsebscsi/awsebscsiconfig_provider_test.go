@@ -0,0 +1,82 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	"github.com/vmware-tanzu/tanzu-framework/addons/controllers/genericconfig"
+	csiv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/addonconfigs/csi/v1alpha1"
+)
+
+var _ = Describe("AwsEbsCSIConfig provider", func() {
+	var (
+		provider *genericconfig.Provider
+		config   *csiv1alpha1.AwsEbsCSIConfig
+		cluster  *clusterapiv1beta1.Cluster
+	)
+
+	BeforeEach(func() {
+		var err error
+		provider, err = NewAwsEbsCSIConfigProvider()
+		Expect(err).NotTo(HaveOccurred())
+		config = &csiv1alpha1.AwsEbsCSIConfig{ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: "default"}}
+		cluster = &clusterapiv1beta1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: "default"}}
+	})
+
+	It("should map the config to the default data values", func() {
+		dvs, err := provider.DataValues(context.Background(), nil, config, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(dvs.([]byte))).To(Equal(`awsEBSCSIDriver:
+  namespace: kube-system
+  http_proxy: ""
+  https_proxy: ""
+  no_proxy: ""
+  deployment_replicas: 3
+`))
+	})
+
+	It("should map the config to the data values", func() {
+		config.Spec.AwsEbsCSI = csiv1alpha1.AwsEbsCSI{
+			Namespace:          "csi",
+			HTTPProxy:          "http://proxy:3128",
+			HTTPSProxy:         "https://proxy:3128",
+			NoProxy:            "10.0.0.0/8",
+			DeploymentReplicas: pointer.Int32(1),
+		}
+		dvs, err := provider.DataValues(context.Background(), nil, config, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(dvs.([]byte))).To(Equal(`awsEBSCSIDriver:
+  namespace: csi
+  http_proxy: http://proxy:3128
+  https_proxy: https://proxy:3128
+  no_proxy: 10.0.0.0/8
+  deployment_replicas: 1
+`))
+	})
+
+	It("should validate the spec of the config", func() {
+		Expect(provider.HasWebhook()).To(BeTrue())
+		wh := &genericconfig.Webhook{Provider: provider}
+		Expect(wh.ValidateCreate(context.Background(), config)).To(Succeed())
+
+		config.Spec.AwsEbsCSI.Namespace = "Kube_System"
+		err := wh.ValidateCreate(context.Background(), config)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.awsEBSCSIDriver.namespace"))
+
+		config.Spec.AwsEbsCSI.Namespace = "kube-system"
+		config.Spec.AwsEbsCSI.DeploymentReplicas = pointer.Int32(0)
+		err = wh.ValidateCreate(context.Background(), config)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.awsEBSCSIDriver.deploymentReplicas"))
+	})
+})
@@ -0,0 +1,31 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package controllers implements the AzureDiskCSIConfig provider of the generic config controller.
+package controllers
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/tanzu-framework/addons/controllers/genericconfig"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+	csiv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/addonconfigs/csi/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=csi.tanzu.vmware.com,resources=azurediskcsiconfigs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=csi.tanzu.vmware.com,resources=azurediskcsiconfigs/status,verbs=get;update;patch
+
+// NewAzureDiskCSIConfigProvider returns the provider of the AzureDiskCSIConfig CRD for the generic config controller
+func NewAzureDiskCSIConfigProvider() (*genericconfig.Provider, error) {
+	return &genericconfig.Provider{
+		Kind:      constants.AzureDiskCSIConfigKind,
+		AddonName: constants.AzureDiskCSIAddonName,
+		NewConfig: func() client.Object {
+			return &csiv1alpha1.AzureDiskCSIConfig{}
+		},
+		DataValues: mapAzureDiskCSIConfigToDataValues,
+		SetSecretRef: func(config client.Object, secretName string) {
+			config.(*csiv1alpha1.AzureDiskCSIConfig).Status.SecretRef = &secretName
+		},
+	}, nil
+}
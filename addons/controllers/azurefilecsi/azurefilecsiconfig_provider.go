@@ -0,0 +1,31 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package controllers implements the AzureFileCSIConfig provider of the generic config controller.
+package controllers
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/tanzu-framework/addons/controllers/genericconfig"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+	csiv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/addonconfigs/csi/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=csi.tanzu.vmware.com,resources=azurefilecsiconfigs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=csi.tanzu.vmware.com,resources=azurefilecsiconfigs/status,verbs=get;update;patch
+
+// NewAzureFileCSIConfigProvider returns the provider of the AzureFileCSIConfig CRD for the generic config controller
+func NewAzureFileCSIConfigProvider() (*genericconfig.Provider, error) {
+	return &genericconfig.Provider{
+		Kind:      constants.AzureFileCSIConfigKind,
+		AddonName: constants.AzureFileCSIAddonName,
+		NewConfig: func() client.Object {
+			return &csiv1alpha1.AzureFileCSIConfig{}
+		},
+		DataValues: mapAzureFileCSIConfigToDataValues,
+		SetSecretRef: func(config client.Object, secretName string) {
+			config.(*csiv1alpha1.AzureFileCSIConfig).Status.SecretRef = &secretName
+		},
+	}, nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package controllers implements the CalicoConfig provider of the generic config controller.
+package controllers
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/tanzu-framework/addons/controllers/genericconfig"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+	cniv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/addonconfigs/cni/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=cni.tanzu.vmware.com,resources=calicoconfigs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cni.tanzu.vmware.com,resources=calicoconfigs/status,verbs=get;update;patch
+
+// NewCalicoConfigProvider returns the provider of the CalicoConfig CRD for the generic config controller
+func NewCalicoConfigProvider() (*genericconfig.Provider, error) {
+	// the defaulting and validating webhooks of CalicoConfig are implemented by its API type
+	return &genericconfig.Provider{
+		Kind:      constants.CalicoConfigKind,
+		AddonName: constants.CalicoAddonName,
+		NewConfig: func() client.Object {
+			return &cniv1alpha1.CalicoConfig{}
+		},
+		NewConfigList: func() client.ObjectList {
+			return &cniv1alpha1.CalicoConfigList{}
+		},
+		OwnerClusterRequired: true,
+		DataValues:           mapCalicoConfigToDataValues,
+		SetSecretRef: func(config client.Object, secretName string) {
+			config.(*cniv1alpha1.CalicoConfig).Status.SecretRef = secretName
+		},
+	}, nil
+}
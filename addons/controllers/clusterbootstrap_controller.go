@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -22,9 +23,12 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/remote"
 	clusterapiutil "sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	clusterapipatchutil "sigs.k8s.io/cluster-api/util/patch"
 	clusterApiPredicates "sigs.k8s.io/cluster-api/util/predicates"
 	secretutil "sigs.k8s.io/cluster-api/util/secret"
@@ -67,15 +71,23 @@ type ClusterBootstrapReconciler struct {
 	aggregatedAPIResourcesClient client.Client
 	// helper for looking up api-resources and getting preferred versions
 	gvrHelper util.GVRHelper
+	// tracker is used for watching the addon resources on the remote clusters, their drift is not detected without it
+	tracker  *remote.ClusterCacheTracker
+	recorder record.EventRecorder
+	// drift of the addon resources found by the ongoing reconciliations, keyed by cluster
+	driftLock    sync.Mutex
+	driftReports map[client.ObjectKey]*remoteDriftReport
 }
 
 // NewClusterBootstrapReconciler returns a reconciler for ClusterBootstrap
-func NewClusterBootstrapReconciler(c client.Client, log logr.Logger, scheme *runtime.Scheme, config *addonconfig.ClusterBootstrapControllerConfig) *ClusterBootstrapReconciler {
+func NewClusterBootstrapReconciler(c client.Client, log logr.Logger, scheme *runtime.Scheme, config *addonconfig.ClusterBootstrapControllerConfig,
+	tracker *remote.ClusterCacheTracker) *ClusterBootstrapReconciler {
 	return &ClusterBootstrapReconciler{
-		Client: c,
-		Log:    log,
-		Scheme: scheme,
-		Config: config,
+		Client:  c,
+		Log:     log,
+		Scheme:  scheme,
+		Config:  config,
+		tracker: tracker,
 	}
 }
 
@@ -116,6 +128,8 @@ func (r *ClusterBootstrapReconciler) SetupWithManager(ctx context.Context, mgr c
 	}
 	r.dynamicClient = dynClient
 	r.providerWatches = make(map[string]client.Object)
+	r.recorder = mgr.GetEventRecorderFor("ClusterBootstrapController")
+	r.driftReports = make(map[client.ObjectKey]*remoteDriftReport)
 
 	clientset := kubernetes.NewForConfigOrDie(mgr.GetConfig())
 	r.gvrHelper = util.NewGVRHelper(ctx, clientset.DiscoveryClient)
@@ -205,12 +219,21 @@ func (r *ClusterBootstrapReconciler) reconcileNormal(cluster *clusterapiv1beta1.
 		return ctrl.Result{}, nil
 	}
 
+	// The changed packages of a cluster selected by a PackageRolloutPolicy are only upgraded once the rollout starts
+	// the upgrade of the cluster, the packages are not reconciled on the cluster until then
+	held, err := r.isPackageUpgradeHeld(cluster, clusterBootstrap, log)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Create a PackageInstall CR under the cluster namespace for deploying the kapp-controller on the remote cluster.
 	// We need kapp-controller to be deployed prior to CNI, CPI, CSI. This will be a no-op if the cluster object is mgmt
 	// cluster.
-	if err := r.createOrPatchKappPackageInstall(clusterBootstrap, cluster); err != nil {
-		// Return error if kapp-controller fails to be deployed, let reconciler try again
-		return ctrl.Result{}, err
+	if !held {
+		if err := r.createOrPatchKappPackageInstall(clusterBootstrap, cluster); err != nil {
+			// Return error if kapp-controller fails to be deployed, let reconciler try again
+			return ctrl.Result{}, err
+		}
 	}
 
 	remoteClient, err := util.GetClusterClient(r.context, r.Client, r.Scheme, clusterapiutil.ObjectKey(cluster))
@@ -218,25 +241,58 @@ func (r *ClusterBootstrapReconciler) reconcileNormal(cluster *clusterapiv1beta1.
 		return ctrl.Result{RequeueAfter: constants.RequeueAfterDuration}, fmt.Errorf("failed to get remote cluster client: %w", err)
 	}
 
+	// Out-of-band changes to the addon resources on the remote cluster trigger a reconciliation, and are reverted or
+	// reported according to the drift policy of the ClusterBootstrap
+	if err := r.watchRemoteAddonResources(cluster, log); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.startRemoteDriftReport(cluster, clusterBootstrap)
+
 	if err := r.prepareRemoteCluster(cluster, remoteClient); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	_, err = r.createOrPatchResourcesForCorePackages(cluster, clusterBootstrap, remoteClient, log)
+	// The PackageInstalls of the packages depending on packages which are not reconciled successfully yet are not
+	// created until the next reconciliation
+	blocked, err := r.getBlockedPackages(cluster, clusterBootstrap, remoteClient)
 	if err != nil {
 		return ctrl.Result{RequeueAfter: constants.RequeueAfterDuration}, err
 	}
+	if err := r.reconcileBlockedPackagesCondition(clusterBootstrap, blocked); err != nil {
+		return ctrl.Result{}, err
+	}
+	blockedPackages := blocked.blockers
 
-	_, err = r.createOrPatchResourcesForAdditionalPackages(cluster, clusterBootstrap, remoteClient, log)
-	if err != nil {
-		return ctrl.Result{RequeueAfter: constants.RequeueAfterDuration}, err
+	if !held {
+		_, err = r.createOrPatchResourcesForCorePackages(cluster, clusterBootstrap, blockedPackages, remoteClient, log)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: constants.RequeueAfterDuration}, err
+		}
+
+		_, err = r.createOrPatchResourcesForAdditionalPackages(cluster, clusterBootstrap, blockedPackages, remoteClient, log)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: constants.RequeueAfterDuration}, err
+		}
+
+		if err := r.reconcilePackagesRevision(clusterBootstrap); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.reconcileRemoteDriftCondition(cluster, clusterBootstrap, held || len(blockedPackages) != 0); err != nil {
+		return ctrl.Result{}, err
 	}
 
+	if len(blockedPackages) != 0 {
+		log.Info(fmt.Sprintf("waiting for the dependencies of %d packages to be reconciled", len(blockedPackages)))
+		return ctrl.Result{RequeueAfter: constants.RequeueAfterDuration}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
 func (r *ClusterBootstrapReconciler) createOrPatchResourcesForCorePackages(cluster *clusterapiv1beta1.Cluster,
 	clusterBootstrap *runtanzuv1alpha3.ClusterBootstrap,
+	blockedPackages map[string][]string,
 	remoteClient client.Client,
 	log logr.Logger) (ctrl.Result, error) {
 
@@ -256,7 +312,7 @@ func (r *ClusterBootstrapReconciler) createOrPatchResourcesForCorePackages(clust
 		// to handle packages in sequence order. I.e., Create all resources for CNI first, and then CPI, CSI. It is also
 		// possible to create all resources in a different order or in parallel. We will consider to use goroutines to create
 		// all resources in parallel on remote cluster if there is performance issue from sequential ordering.
-		if err := r.createOrPatchAddonResourcesOnRemote(cluster, corePackage, remoteClient); err != nil {
+		if err := r.createOrPatchAddonResourcesOnRemote(cluster, corePackage, blockedPackages[corePackage.RefName], remoteClient); err != nil {
 			// For core packages, we require all their creation or patching to succeed, so if error happens against any of the
 			// packages, we return error and let the reconciler retry again.
 			log.Error(err, fmt.Sprintf("unable to create or patch all the required resources for %s on cluster: %s/%s",
@@ -280,11 +336,12 @@ func (r *ClusterBootstrapReconciler) createOrPatchResourcesForCorePackages(clust
 
 func (r *ClusterBootstrapReconciler) createOrPatchResourcesForAdditionalPackages(cluster *clusterapiv1beta1.Cluster,
 	clusterBootstrap *runtanzuv1alpha3.ClusterBootstrap,
+	blockedPackages map[string][]string,
 	remoteClient client.Client,
 	log logr.Logger) (ctrl.Result, error) {
 
 	for _, additionalPkg := range clusterBootstrap.Spec.AdditionalPackages {
-		if err := r.createOrPatchAddonResourcesOnRemote(cluster, additionalPkg, remoteClient); err != nil {
+		if err := r.createOrPatchAddonResourcesOnRemote(cluster, additionalPkg, blockedPackages[additionalPkg.RefName], remoteClient); err != nil {
 			// Logging has been handled in createOrPatchAddonResourcesOnRemote()
 			return ctrl.Result{}, err
 		}
@@ -420,6 +477,18 @@ func (r *ClusterBootstrapReconciler) createOrPatchClusterBootstrapFromTemplate(c
 	}
 	// Handle ClusterBootstrap update when TKR version of the cluster is upgraded
 	if tkrName != clusterBootstrap.Status.ResolvedTKR {
+		// The packages of a cluster selected by a PackageRolloutPolicy are only upgraded once the rollout starts the
+		// upgrade of the cluster, the cluster stays paused until then
+		if r.Config.FeatureGatePackageRolloutPolicy && clusterBootstrap.Annotations[constants.RolloutApprovedTKRAnnotation] != tkrName {
+			policy, err := clusterSelectedByPackageRolloutPolicy(r.context, r.Client, cluster)
+			if err != nil {
+				return nil, err
+			}
+			if policy != nil {
+				log.Info(fmt.Sprintf("Waiting for PackageRolloutPolicy %s to upgrade ClusterBootstrap to TKR %s", policy.Name, tkrName))
+				return clusterBootstrap, nil
+			}
+		}
 		log.Info(fmt.Sprintf("Upgrading ClusterBootstrap from TKR %s to TKR %s", clusterBootstrap.Status.ResolvedTKR, tkrName))
 		return r.patchClusterBootstrapFromTemplate(cluster, clusterBootstrap, clusterBootstrapTemplate, clusterBootstrapHelper, tkrName, log)
 	}
@@ -458,6 +527,11 @@ func (r *ClusterBootstrapReconciler) patchClusterBootstrapFromTemplate(
 	}
 
 	// No need to update ClusterBootstrap ownerRef
+	// The approval of the TKR by a PackageRolloutPolicy approves the packages of its ClusterBootstrapTemplate
+	if r.Config.FeatureGatePackageRolloutPolicy && updatedClusterBootstrap.Annotations[constants.RolloutApprovedTKRAnnotation] == tkrName {
+		updatedClusterBootstrap.Annotations[constants.RolloutApprovedPackagesAnnotation] = util.GetPackagesRevision(updatedClusterBootstrap.Spec)
+	}
+
 	// Patch ClusterBootstrap's Spec
 	if err := patchHelper.Patch(r.context, updatedClusterBootstrap); err != nil {
 		log.Error(err, "failed to update clusterBootstrap spec")
@@ -499,6 +573,7 @@ func (r *ClusterBootstrapReconciler) mergeClusterBootstrapPackagesWithTemplate(
 	//    3. The Group and Kind for default core package providers will not change across different TKR versions
 	//    4. All packages, including additional packages, can't be deleted (meaning the package refName can't be changed, only allow version bump)
 	//    5. We will keep users' customization on valuesFrom of each package, users are responsible for the correctness of the content they put in will work with the next version.
+	//    6. We will keep users' customization on dependsOn and wave of each package, the packages without any inherit them from the new ClusterBootstrapTemplate.
 	packages := make([]*runtanzuv1alpha3.ClusterBootstrapPackage, 0)
 	if updatedClusterBootstrap.Spec.CNI == nil {
 		log.Info("no CNI package specified in ClusterBootstrap, should not happen. Continue with CNI in ClusterBootstrapTemplate of new TKR")
@@ -513,6 +588,7 @@ func (r *ClusterBootstrapReconciler) mergeClusterBootstrapPackagesWithTemplate(
 			return nil, errors.Wrap(err, errorMsg)
 		}
 		updatedClusterBootstrap.Spec.CNI.RefName = updatedCNI
+		util.InheritPackageOrder(updatedClusterBootstrap.Spec.CNI, clusterBootstrapTemplate.Spec.CNI)
 	}
 
 	if updatedClusterBootstrap.Spec.Kapp == nil {
@@ -530,6 +606,7 @@ func (r *ClusterBootstrapReconciler) mergeClusterBootstrapPackagesWithTemplate(
 		packages = append(packages, newCSIPkg)
 	} else {
 		updatedClusterBootstrap.Spec.CSI.RefName = clusterBootstrapTemplate.Spec.CSI.RefName
+		util.InheritPackageOrder(updatedClusterBootstrap.Spec.CSI, clusterBootstrapTemplate.Spec.CSI)
 	}
 
 	if updatedClusterBootstrap.Spec.CPI == nil {
@@ -538,6 +615,7 @@ func (r *ClusterBootstrapReconciler) mergeClusterBootstrapPackagesWithTemplate(
 		packages = append(packages, newCPIPkg)
 	} else {
 		updatedClusterBootstrap.Spec.CPI.RefName = clusterBootstrapTemplate.Spec.CPI.RefName
+		util.InheritPackageOrder(updatedClusterBootstrap.Spec.CPI, clusterBootstrapTemplate.Spec.CPI)
 	}
 
 	// Since we don't allow users to delete additional packages in our webhook
@@ -568,6 +646,7 @@ func (r *ClusterBootstrapReconciler) mergeClusterBootstrapPackagesWithTemplate(
 		// Find the one to one match for additional package in new ClusterBootstrapTemplate and old ClusterBootstrap and update
 		if pkg, ok := additionalPackageMap[packageRefName]; ok {
 			pkg.RefName = templatePkg.RefName
+			util.InheritPackageOrder(pkg, templatePkg)
 		} else {
 			// If new additional package is added in ClusterBootstrapTemplate, just add it to updated ClusterBootstrap
 			newPkg := templatePkg.DeepCopy()
@@ -753,7 +832,7 @@ func (r *ClusterBootstrapReconciler) createOrPatchPackageInstallOnRemote(cluster
 		},
 	}
 
-	_, err = controllerutil.CreateOrPatch(r.context, clusterClient, remotePkgi, func() error {
+	_, err = r.createOrPatchOnRemote(cluster, clusterClient, remotePkgi, func() error {
 		remotePkgi.Spec.ServiceAccountName = r.Config.PkgiServiceAccount
 		remotePkgi.Spec.SyncPeriod = &metav1.Duration{Duration: r.Config.PkgiSyncPeriod}
 		// remotePackageRefName and remotePackageVersion are fetched from the Package CR on remote cluster.
@@ -785,6 +864,191 @@ func (r *ClusterBootstrapReconciler) createOrPatchPackageInstallOnRemote(cluster
 	return remotePkgi, nil
 }
 
+// blockedPackages are the packages whose PackageInstall is not created on the remote cluster yet, as they wait for
+// other packages
+type blockedPackages struct {
+	// blockers are the short names of the packages each blocked package waits for, by refName of the blocked package
+	blockers map[string][]string
+	// failed are the short names of the packages waited for whose PackageInstall failed to reconcile
+	failed []string
+	// notCreated are the short names of the packages waited for whose PackageInstall is not created, although they do
+	// not wait for other packages
+	notCreated []string
+}
+
+// getBlockedPackages returns the packages waiting for other packages, for the packages whose PackageInstall is not
+// created on the remote cluster yet. A package waits for the packages it depends on and for the packages of the lower
+// waves until their PackageInstalls are reconciled successfully. The PackageInstalls which exist already are patched
+// regardless of the state of their dependencies.
+func (r *ClusterBootstrapReconciler) getBlockedPackages(cluster *clusterapiv1beta1.Cluster,
+	clusterBootstrap *runtanzuv1alpha3.ClusterBootstrap, clusterClient client.Client) (*blockedPackages, error) {
+
+	pkgs := util.GetOrderedRolloutPackages(clusterBootstrap.Spec)
+	installed := make(map[string]bool, len(pkgs))
+	reconciled := make(map[string]bool, len(pkgs))
+	failed := make(map[string]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		pkgi := &kapppkgiv1alpha1.PackageInstall{}
+		key := client.ObjectKey{Namespace: r.Config.SystemNamespace, Name: util.GeneratePackageInstallName(cluster.Name, pkg.RefName)}
+		if err := clusterClient.Get(r.context, key, pkgi); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "unable to get PackageInstall %s/%s on cluster %s/%s", key.Namespace, key.Name, cluster.Namespace, cluster.Name)
+		}
+		installed[pkg.RefName] = true
+		pkgiCondition := util.SummarizeAppConditions(pkgi.Status.Conditions)
+		reconciled[pkg.RefName] = pkgi.Status.ObservedGeneration == pkgi.Generation &&
+			pkgiCondition != nil && pkgiCondition.Type == kappctrlv1alpha1.ReconcileSucceeded
+		failed[pkg.RefName] = pkgiCondition != nil && pkgiCondition.Type == kappctrlv1alpha1.ReconcileFailed
+	}
+
+	blocked := &blockedPackages{blockers: make(map[string][]string)}
+	for _, pkg := range pkgs {
+		if installed[pkg.RefName] {
+			continue
+		}
+		for _, blocker := range util.GetPackageBlockers(pkg, pkgs) {
+			if !reconciled[blocker.RefName] {
+				blocked.blockers[pkg.RefName] = append(blocked.blockers[pkg.RefName], shortPackageName(blocker.RefName))
+			}
+		}
+	}
+	for _, pkg := range pkgs {
+		switch {
+		case failed[pkg.RefName]:
+			blocked.failed = append(blocked.failed, shortPackageName(pkg.RefName))
+		case !installed[pkg.RefName] && len(blocked.blockers[pkg.RefName]) == 0:
+			blocked.notCreated = append(blocked.notCreated, shortPackageName(pkg.RefName))
+		}
+	}
+	return blocked, nil
+}
+
+// shortPackageName returns the short name of the package of the refName, e.g. antrea
+func shortPackageName(refName string) string {
+	return strings.Split(refName, ".")[0]
+}
+
+// reconcileBlockedPackagesCondition sets the PackagesBlocked condition of the ClusterBootstrap listing the packages
+// waiting for their dependencies, or removes it if no package is blocked. The packages are reported as failed when a
+// package they wait for failed to reconcile, or when its PackageInstall is not created for too long although it does
+// not wait for other packages, e.g. because its data values are never generated.
+func (r *ClusterBootstrapReconciler) reconcileBlockedPackagesCondition(clusterBootstrap *runtanzuv1alpha3.ClusterBootstrap,
+	blocked *blockedPackages) error {
+
+	existing := conditions.Get(clusterBootstrap, runtanzuv1alpha3.ConditionPackagesBlocked)
+	var condition *clusterapiv1beta1.Condition
+	if len(blocked.blockers) != 0 {
+		condition = blockedPackagesCondition(clusterBootstrap, blocked, existing)
+	}
+	if condition == nil && existing == nil {
+		return nil
+	}
+	if condition != nil && existing != nil && existing.Status == condition.Status && existing.Reason == condition.Reason &&
+		existing.Severity == condition.Severity && existing.Message == condition.Message {
+		return nil
+	}
+
+	patchHelper, err := clusterapipatchutil.NewHelper(clusterBootstrap, r.Client)
+	if err != nil {
+		return err
+	}
+	if condition == nil {
+		conditions.Delete(clusterBootstrap, runtanzuv1alpha3.ConditionPackagesBlocked)
+	} else {
+		conditions.Set(clusterBootstrap, condition)
+	}
+	if err := patchHelper.Patch(r.context, clusterBootstrap); err != nil {
+		return errors.Wrapf(err, "unable to patch the status of ClusterBootstrap %s/%s", clusterBootstrap.Namespace, clusterBootstrap.Name)
+	}
+	return nil
+}
+
+// blockedPackagesCondition returns the PackagesBlocked condition of the blocked packages. The existing condition tells
+// since when packages are blocked.
+func blockedPackagesCondition(clusterBootstrap *runtanzuv1alpha3.ClusterBootstrap, blocked *blockedPackages,
+	existing *clusterapiv1beta1.Condition) *clusterapiv1beta1.Condition {
+
+	notCreatedTooLong := existing != nil && time.Since(existing.LastTransitionTime.Time) > constants.PackageDependencyCreationTimeout
+	reason, severity := runtanzuv1alpha3.ReasonDependenciesNotReconciled, clusterapiv1beta1.ConditionSeverityInfo
+	var messages []string
+	for _, pkg := range util.GetOrderedRolloutPackages(clusterBootstrap.Spec) {
+		blockers, ok := blocked.blockers[pkg.RefName]
+		if !ok {
+			continue
+		}
+		message := fmt.Sprintf("%s is waiting for %s", shortPackageName(pkg.RefName), strings.Join(blockers, ", "))
+		if failed := intersectStrings(blockers, blocked.failed); len(failed) != 0 {
+			reason, severity = runtanzuv1alpha3.ReasonDependenciesFailed, clusterapiv1beta1.ConditionSeverityError
+			message += fmt.Sprintf(" (%s failed to reconcile)", strings.Join(failed, ", "))
+		} else if notCreated := intersectStrings(blockers, blocked.notCreated); len(notCreated) != 0 && notCreatedTooLong {
+			if reason != runtanzuv1alpha3.ReasonDependenciesFailed {
+				reason, severity = runtanzuv1alpha3.ReasonDependenciesNotCreated, clusterapiv1beta1.ConditionSeverityError
+			}
+			message += fmt.Sprintf(" (the PackageInstall of %s is not created)", strings.Join(notCreated, ", "))
+		}
+		messages = append(messages, message)
+	}
+	return &clusterapiv1beta1.Condition{
+		Type:     runtanzuv1alpha3.ConditionPackagesBlocked,
+		Status:   corev1.ConditionTrue,
+		Reason:   reason,
+		Severity: severity,
+		Message:  strings.Join(messages, "; "),
+	}
+}
+
+// intersectStrings returns the values which are in both slices, in the order of the first slice
+func intersectStrings(values, others []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, other := range others {
+			if value == other {
+				result = append(result, value)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// isPackageUpgradeHeld returns whether the packages of the ClusterBootstrap changed since they were rolled out to the
+// cluster, e.g. as the version of a package was bumped, and wait for the PackageRolloutPolicy selecting the cluster to
+// approve their upgrade
+func (r *ClusterBootstrapReconciler) isPackageUpgradeHeld(cluster *clusterapiv1beta1.Cluster,
+	clusterBootstrap *runtanzuv1alpha3.ClusterBootstrap, log logr.Logger) (bool, error) {
+
+	if !r.Config.FeatureGatePackageRolloutPolicy || !util.IsPackageUpgradePending(clusterBootstrap) ||
+		clusterBootstrap.Annotations[constants.RolloutApprovedPackagesAnnotation] == util.GetPackagesRevision(clusterBootstrap.Spec) {
+		return false, nil
+	}
+	policy, err := clusterSelectedByPackageRolloutPolicy(r.context, r.Client, cluster)
+	if err != nil || policy == nil {
+		return false, err
+	}
+	log.Info(fmt.Sprintf("Waiting for PackageRolloutPolicy %s to upgrade the changed packages of ClusterBootstrap", policy.Name))
+	return true, nil
+}
+
+// reconcilePackagesRevision records the revision of the packages rolled out to the cluster in the ClusterBootstrap
+// status
+func (r *ClusterBootstrapReconciler) reconcilePackagesRevision(clusterBootstrap *runtanzuv1alpha3.ClusterBootstrap) error {
+	revision := util.GetPackagesRevision(clusterBootstrap.Spec)
+	if clusterBootstrap.Status.PackagesRevision == revision {
+		return nil
+	}
+	patchHelper, err := clusterapipatchutil.NewHelper(clusterBootstrap, r.Client)
+	if err != nil {
+		return err
+	}
+	clusterBootstrap.Status.PackagesRevision = revision
+	if err := patchHelper.Patch(r.context, clusterBootstrap); err != nil {
+		return errors.Wrapf(err, "unable to patch the status of ClusterBootstrap %s/%s", clusterBootstrap.Namespace, clusterBootstrap.Name)
+	}
+	return nil
+}
+
 // reconcileSystemNamespace creates system namespace on remote workload cluster. This is because the system namespace
 // might not have been created yet when this controller reconciles remote cluster.
 func (r *ClusterBootstrapReconciler) reconcileSystemNamespace(clusterClient client.Client) error {
@@ -845,7 +1109,7 @@ func (r *ClusterBootstrapReconciler) createOrPatchAddonServiceAccountOnRemote(cl
 	r.Log.Info(fmt.Sprintf("creating or patching ServiceAccount %s/%s on cluster %s/%s",
 		serviceAccount.Namespace, serviceAccount.Name, cluster.Namespace, cluster.Name))
 
-	_, err := controllerutil.CreateOrPatch(r.context, clusterClient, serviceAccount, nil)
+	_, err := r.createOrPatchOnRemote(cluster, clusterClient, serviceAccount, nil)
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			// If the error is IsAlreadyExists, we ignore and return nil
@@ -869,7 +1133,7 @@ func (r *ClusterBootstrapReconciler) createOrPatchAddonRBACOnRemote(cluster *clu
 		},
 	}
 
-	if _, err := controllerutil.CreateOrPatch(r.context, clusterClient, addonRole, func() error {
+	if _, err := r.createOrPatchOnRemote(cluster, clusterClient, addonRole, func() error {
 		addonRole.Rules = []rbacv1.PolicyRule{
 			{
 				APIGroups: []string{"*"},
@@ -896,7 +1160,7 @@ func (r *ClusterBootstrapReconciler) createOrPatchAddonRBACOnRemote(cluster *clu
 			Name: r.Config.PkgiClusterRoleBinding,
 		},
 	}
-	if _, err := controllerutil.CreateOrPatch(r.context, clusterClient, addonRoleBinding, func() error {
+	if _, err := r.createOrPatchOnRemote(cluster, clusterClient, addonRoleBinding, func() error {
 		addonRoleBinding.Subjects = []rbacv1.Subject{
 			{
 				Kind:      "ServiceAccount",
@@ -924,9 +1188,10 @@ func (r *ClusterBootstrapReconciler) createOrPatchAddonRBACOnRemote(cluster *clu
 }
 
 // createOrPatchAddonResourcesOnRemote creates or patches the resources for a cluster bootstrap package on remote workload
-// cluster. The resources are [Package CR, Secret for PackageInstall, PackageInstall CR].
+// cluster. The resources are [Package CR, Secret for PackageInstall, PackageInstall CR]. The PackageInstall CR is not
+// created while the package is blocked by the packages it waits for.
 func (r *ClusterBootstrapReconciler) createOrPatchAddonResourcesOnRemote(cluster *clusterapiv1beta1.Cluster,
-	cbPkg *runtanzuv1alpha3.ClusterBootstrapPackage, clusterClient client.Client) error {
+	cbPkg *runtanzuv1alpha3.ClusterBootstrapPackage, blockers []string, clusterClient client.Client) error {
 
 	remotePackage, err := r.createOrPatchPackageOnRemote(cluster, cbPkg, clusterClient)
 	if err != nil {
@@ -957,6 +1222,12 @@ func (r *ClusterBootstrapReconciler) createOrPatchAddonResourcesOnRemote(cluster
 		return nil
 	}
 
+	if len(blockers) != 0 {
+		r.Log.Info(fmt.Sprintf("skip creating the packageInstall for the package %s on cluster %s/%s since it is waiting for %s",
+			remotePackage.Name, cluster.Namespace, cluster.Name, strings.Join(blockers, ", ")))
+		return nil
+	}
+
 	pkgi, err := r.createOrPatchPackageInstallOnRemote(cluster, cbPkg, remoteSecret, clusterClient)
 	if err != nil {
 		return err
@@ -1114,7 +1385,7 @@ func (r *ClusterBootstrapReconciler) createOrPatchPackageInstallSecret(cluster *
 		return nil
 	}
 
-	_, err = controllerutil.CreateOrPatch(r.context, clusterClient, remoteSecret, dataValuesSecretMutateFn)
+	_, err = r.createOrPatchOnRemote(cluster, clusterClient, remoteSecret, dataValuesSecretMutateFn)
 	if err != nil {
 		r.Log.Error(err, "error creating or patching addon data values secret")
 		return nil, err
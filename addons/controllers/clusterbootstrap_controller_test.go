@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	clusterapiutil "sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/secret"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -1247,6 +1249,73 @@ var _ = Describe("ClusterBootstrap Reconciler", func() {
 		})
 	})
 
+	When("the addon resources on the cluster drift", func() {
+		BeforeEach(func() {
+			clusterName = "test-cluster-10"
+			clusterNamespace = "cluster-namespace-10"
+			clusterResourceFilePath = "testdata/test-cluster-bootstrap-10.yaml"
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: clusterNamespace,
+				},
+			}
+			Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		})
+
+		Context("with the default drift policy", func() {
+			It("should revert the modified and deleted resources and report them in the RemoteResourcesDrifted condition", func() {
+				cluster := &clusterapiv1beta1.Cluster{}
+				Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: clusterNamespace, Name: clusterName}, cluster)).To(Succeed())
+				cluster.Status.Phase = string(clusterapiv1beta1.ClusterPhaseProvisioned)
+				Expect(k8sClient.Status().Update(ctx, cluster)).To(Succeed())
+
+				remoteClient, err := util.GetClusterClient(ctx, k8sClient, scheme, clusterapiutil.ObjectKey(cluster))
+				Expect(err).NotTo(HaveOccurred())
+
+				By("waiting for the ClusterRole to be applied on the cluster")
+				clusterBootstrap := &runtanzuv1alpha3.ClusterBootstrap{}
+				clusterRoleKey := "ClusterRole " + constants.PackageInstallClusterRole
+				Eventually(func() bool {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), clusterBootstrap); err != nil {
+						return false
+					}
+					for _, resource := range clusterBootstrap.Status.AppliedResources {
+						if resource == clusterRoleKey {
+							return true
+						}
+					}
+					return false
+				}, waitTimeout, pollingInterval).Should(BeTrue())
+
+				By("modifying the ClusterRole out of band")
+				clusterRole := &rbacv1.ClusterRole{}
+				Expect(remoteClient.Get(ctx, client.ObjectKey{Name: constants.PackageInstallClusterRole}, clusterRole)).To(Succeed())
+				appliedRules := clusterRole.Rules
+				clusterRole.Rules = append(clusterRole.Rules, rbacv1.PolicyRule{APIGroups: []string{""}, Verbs: []string{"get"}, Resources: []string{"pods"}})
+				Expect(remoteClient.Update(ctx, clusterRole)).To(Succeed())
+
+				Eventually(func() bool {
+					if err := remoteClient.Get(ctx, client.ObjectKey{Name: constants.PackageInstallClusterRole}, clusterRole); err != nil {
+						return false
+					}
+					return reflect.DeepEqual(clusterRole.Rules, appliedRules)
+				}, waitTimeout, pollingInterval).Should(BeTrue())
+				assertRemoteDriftReverted(clusterBootstrap, clusterRoleKey+" was modified")
+
+				By("deleting the ClusterRole out of band")
+				Expect(remoteClient.Delete(ctx, clusterRole)).To(Succeed())
+
+				Eventually(func() bool {
+					if err := remoteClient.Get(ctx, client.ObjectKey{Name: constants.PackageInstallClusterRole}, clusterRole); err != nil {
+						return false
+					}
+					return reflect.DeepEqual(clusterRole.Rules, appliedRules)
+				}, waitTimeout, pollingInterval).Should(BeTrue())
+				assertRemoteDriftReverted(clusterBootstrap, clusterRoleKey+" was deleted")
+			})
+		})
+	})
+
 	// This test case is for ensuring that controller will skip deleting additional packages that's inside annotation run.tanzu.vmware.com/skip-packageinstall-deletion
 	When("Cluster with ako", func() {
 		BeforeEach(func() {
@@ -1564,3 +1633,77 @@ func assertOwnerReferencesExist(ctx context.Context, k8sClient client.Client, na
 		return true
 	}, waitTimeout, pollingInterval).Should(BeTrue())
 }
+
+func assertRemoteDriftReverted(clusterBootstrap *runtanzuv1alpha3.ClusterBootstrap, drift string) {
+	Eventually(func() bool {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterBootstrap), clusterBootstrap); err != nil {
+			return false
+		}
+		condition := conditions.Get(clusterBootstrap, runtanzuv1alpha3.ConditionRemoteResourcesDrifted)
+		return condition != nil && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == runtanzuv1alpha3.ReasonDriftReverted && strings.Contains(condition.Message, drift)
+	}, waitTimeout, pollingInterval).Should(BeTrue())
+}
+
+var _ = Describe("PackagesBlocked condition", func() {
+	clusterBootstrap := &runtanzuv1alpha3.ClusterBootstrap{
+		Spec: &runtanzuv1alpha3.ClusterBootstrapTemplateSpec{
+			CNI: &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: "antrea.tanzu.vmware.com.1.5.3--vmware.1-tkg.1"},
+			CSI: &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: "vsphere-csi.tanzu.vmware.com.2.5.2--vmware.1-tkg.1", DependsOn: []string{"antrea"}},
+		},
+	}
+	blocked := func() *blockedPackages {
+		return &blockedPackages{blockers: map[string][]string{"vsphere-csi.tanzu.vmware.com.2.5.2--vmware.1-tkg.1": {"antrea"}}}
+	}
+
+	It("should report the packages waiting for their dependencies", func() {
+		condition := blockedPackagesCondition(clusterBootstrap, blocked(), nil)
+		Expect(condition.Reason).To(Equal(runtanzuv1alpha3.ReasonDependenciesNotReconciled))
+		Expect(condition.Severity).To(Equal(clusterapiv1beta1.ConditionSeverityInfo))
+		Expect(condition.Message).To(Equal("vsphere-csi is waiting for antrea"))
+	})
+
+	It("should report the packages waiting for a package which failed to reconcile", func() {
+		b := blocked()
+		b.failed = []string{"antrea"}
+		condition := blockedPackagesCondition(clusterBootstrap, b, nil)
+		Expect(condition.Reason).To(Equal(runtanzuv1alpha3.ReasonDependenciesFailed))
+		Expect(condition.Severity).To(Equal(clusterapiv1beta1.ConditionSeverityError))
+		Expect(condition.Message).To(Equal("vsphere-csi is waiting for antrea (antrea failed to reconcile)"))
+	})
+
+	It("should report the packages waiting too long for a package which is not created", func() {
+		b := blocked()
+		b.notCreated = []string{"antrea"}
+		condition := blockedPackagesCondition(clusterBootstrap, b, &clusterapiv1beta1.Condition{LastTransitionTime: metav1.Now()})
+		Expect(condition.Reason).To(Equal(runtanzuv1alpha3.ReasonDependenciesNotReconciled))
+
+		since := metav1.NewTime(time.Now().Add(-constants.PackageDependencyCreationTimeout - time.Minute))
+		condition = blockedPackagesCondition(clusterBootstrap, b, &clusterapiv1beta1.Condition{LastTransitionTime: since})
+		Expect(condition.Reason).To(Equal(runtanzuv1alpha3.ReasonDependenciesNotCreated))
+		Expect(condition.Severity).To(Equal(clusterapiv1beta1.ConditionSeverityError))
+		Expect(condition.Message).To(Equal("vsphere-csi is waiting for antrea (the PackageInstall of antrea is not created)"))
+	})
+})
+
+var _ = Describe("Remote addon resource updates", func() {
+	applied := func(resourceVersion string, generation int64, hash string) client.Object {
+		obj := &metav1.PartialObjectMetadata{}
+		obj.SetResourceVersion(resourceVersion)
+		obj.SetGeneration(generation)
+		obj.SetAnnotations(map[string]string{constants.AppliedHashAnnotation: hash})
+		return obj
+	}
+
+	It("should report the out-of-band updates of the applied resources", func() {
+		Expect(isRemoteContentUpdate(applied("1", 0, "a"), applied("2", 0, "a"))).To(BeTrue())
+		Expect(isRemoteContentUpdate(applied("1", 1, "a"), applied("2", 2, "a"))).To(BeTrue())
+	})
+
+	It("should ignore the resyncs, the status updates and the updates applied by the controller", func() {
+		Expect(isRemoteContentUpdate(applied("1", 0, "a"), applied("1", 0, "a"))).To(BeFalse())
+		Expect(isRemoteContentUpdate(applied("1", 1, "a"), applied("2", 1, "a"))).To(BeFalse())
+		Expect(isRemoteContentUpdate(applied("1", 0, "a"), applied("2", 0, "b"))).To(BeFalse())
+		Expect(isRemoteContentUpdate(&metav1.PartialObjectMetadata{}, &metav1.PartialObjectMetadata{})).To(BeFalse())
+	})
+})
@@ -0,0 +1,275 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	clusterapiutil "sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	clusterapipatchutil "sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	kapppkgiv1alpha1 "github.com/vmware-tanzu/carvel-kapp-controller/pkg/apis/packaging/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/util"
+	runtanzuv1alpha3 "github.com/vmware-tanzu/tanzu-framework/apis/run/v1alpha3"
+)
+
+// remoteDriftReport collects the out-of-band changes to the addon resources of a cluster found during a reconciliation
+type remoteDriftReport struct {
+	policy string
+	// previous are the resources applied by the previous reconciliations, as recorded in the ClusterBootstrap status
+	previous sets.String
+	// applied are the resources applied by this reconciliation
+	applied sets.String
+	drifts  []string
+}
+
+// remoteDriftKinds are the addon resources created on the remote clusters whose drift is detected. Only their metadata
+// is watched, so that the remote caches do not hold every Secret of the clusters; the content of a resource is read
+// when the cluster is reconciled.
+var remoteDriftKinds = []client.Object{
+	remoteMetadataObject(corev1.SchemeGroupVersion.WithKind("ServiceAccount")),
+	remoteMetadataObject(rbacv1.SchemeGroupVersion.WithKind("ClusterRole")),
+	remoteMetadataObject(rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding")),
+	remoteMetadataObject(corev1.SchemeGroupVersion.WithKind("Secret")),
+	remoteMetadataObject(kapppkgiv1alpha1.SchemeGroupVersion.WithKind("PackageInstall")),
+}
+
+func remoteMetadataObject(gvk schema.GroupVersionKind) client.Object {
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(gvk)
+	return obj
+}
+
+// watchRemoteAddonResources sets the remote watches on the addon resources of the cluster, so that their out-of-band
+// changes and deletions trigger a reconciliation of the cluster. The watches only exist once per cluster.
+func (r *ClusterBootstrapReconciler) watchRemoteAddonResources(cluster *clusterapiv1beta1.Cluster, log logr.Logger) error {
+	if r.tracker == nil {
+		return nil
+	}
+	clusterKey := clusterapiutil.ObjectKey(cluster)
+	for _, kind := range remoteDriftKinds {
+		err := r.tracker.Watch(r.context, remote.WatchInput{
+			Name:         "watchClusterBootstrapDrift" + remoteKindName(kind),
+			Cluster:      clusterKey,
+			Watcher:      r.controller,
+			Kind:         kind,
+			EventHandler: r.remoteAddonResourceEventHandler(clusterKey),
+		})
+		if errors.Is(err, remote.ErrClusterLocked) {
+			// another controller is accessing the cluster, the watch is set on the next reconciliation
+			log.V(4).Info("cluster is locked, skipping setting the remote watches")
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "error watching %s on cluster %s/%s", remoteKindName(kind), cluster.Namespace, cluster.Name)
+		}
+	}
+	return nil
+}
+
+// remoteAddonResourceEventHandler enqueues the cluster when one of its applied addon resources may have drifted: it is
+// modified or deleted out of band. As only the metadata of the resources is watched, the drift of a modified resource
+// is confirmed against its applied hash when the cluster is reconciled.
+func (r *ClusterBootstrapReconciler) remoteAddonResourceEventHandler(clusterKey client.ObjectKey) handler.EventHandler {
+	enqueue := func(q workqueue.RateLimitingInterface) {
+		q.Add(ctrl.Request{NamespacedName: clusterKey})
+	}
+	return handler.Funcs{
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			if isRemoteContentUpdate(e.ObjectOld, e.ObjectNew) {
+				enqueue(q)
+			}
+		},
+		DeleteFunc: func(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			if _, ok := e.Object.GetAnnotations()[constants.AppliedHashAnnotation]; ok {
+				enqueue(q)
+			}
+		},
+	}
+}
+
+// isRemoteContentUpdate returns whether an update of an applied addon resource may have changed its content out of
+// band. The updates applying a new hash are the ones of the controller, and the updates of the resources with a
+// generation only change their content when the generation changes.
+func isRemoteContentUpdate(oldObj, newObj client.Object) bool {
+	applied, ok := newObj.GetAnnotations()[constants.AppliedHashAnnotation]
+	if !ok || oldObj.GetResourceVersion() == newObj.GetResourceVersion() {
+		return false
+	}
+	if applied != oldObj.GetAnnotations()[constants.AppliedHashAnnotation] {
+		return false
+	}
+	return newObj.GetGeneration() == 0 || newObj.GetGeneration() != oldObj.GetGeneration()
+}
+
+// startRemoteDriftReport starts collecting the drift of the addon resources of the cluster, handled according to the
+// drift policy of its ClusterBootstrap
+func (r *ClusterBootstrapReconciler) startRemoteDriftReport(cluster *clusterapiv1beta1.Cluster, clusterBootstrap *runtanzuv1alpha3.ClusterBootstrap) {
+	policy := constants.RemoteDriftPolicyRevert
+	if clusterBootstrap.Annotations[constants.RemoteDriftPolicyAnnotation] == constants.RemoteDriftPolicyReport {
+		policy = constants.RemoteDriftPolicyReport
+	}
+	r.driftLock.Lock()
+	defer r.driftLock.Unlock()
+	r.driftReports[clusterapiutil.ObjectKey(cluster)] = &remoteDriftReport{
+		policy:   policy,
+		previous: sets.NewString(clusterBootstrap.Status.AppliedResources...),
+		applied:  sets.NewString(),
+	}
+}
+
+// createOrPatchOnRemote creates or patches an addon resource on the remote cluster like controllerutil.CreateOrPatch,
+// and records the hash of its content. The resources modified out of band since they were last applied, or missing
+// although they were applied by a previous reconciliation, are reported as drifted, and are only reverted when the
+// drift policy of the ClusterBootstrap is to revert.
+func (r *ClusterBootstrapReconciler) createOrPatchOnRemote(cluster *clusterapiv1beta1.Cluster, clusterClient client.Client,
+	obj client.Object, f controllerutil.MutateFn) (controllerutil.OperationResult, error) {
+
+	r.driftLock.Lock()
+	report := r.driftReports[clusterapiutil.ObjectKey(cluster)]
+	r.driftLock.Unlock()
+	if r.tracker == nil || report == nil {
+		return controllerutil.CreateOrPatch(r.context, clusterClient, obj, f)
+	}
+
+	key := remoteResourceKey(obj)
+	drift := ""
+	live := obj.DeepCopyObject().(client.Object)
+	if err := clusterClient.Get(r.context, client.ObjectKeyFromObject(obj), live); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return controllerutil.OperationResultNone, err
+		}
+		if report.previous.Has(key) {
+			drift = "deleted"
+		}
+	} else if util.HasDrifted(live) {
+		drift = "modified"
+	}
+
+	if drift != "" {
+		r.driftLock.Lock()
+		report.drifts = append(report.drifts, fmt.Sprintf("%s was %s", key, drift))
+		// a reported resource is still applied, so that its deletion keeps being reported
+		report.applied.Insert(key)
+		r.driftLock.Unlock()
+		if report.policy == constants.RemoteDriftPolicyReport {
+			return controllerutil.OperationResultNone, nil
+		}
+	}
+
+	result, err := controllerutil.CreateOrPatch(r.context, clusterClient, obj, func() error {
+		if drift != "" {
+			util.ResetManagedContent(obj)
+		}
+		if f != nil {
+			if err := f(); err != nil {
+				return err
+			}
+		}
+		return util.SetAppliedHash(obj)
+	})
+	if err == nil {
+		r.driftLock.Lock()
+		report.applied.Insert(key)
+		r.driftLock.Unlock()
+	}
+	return result, err
+}
+
+// reconcileRemoteDriftCondition reports the drift found during the reconciliation as events and as the
+// RemoteResourcesDrifted condition of the ClusterBootstrap, and records the applied resources in its status. The
+// condition is removed once the reported drift is gone, and keeps the last reverted drift otherwise. When only part of
+// the resources were applied, e.g. while the package upgrades are held, the previously applied resources are kept.
+func (r *ClusterBootstrapReconciler) reconcileRemoteDriftCondition(cluster *clusterapiv1beta1.Cluster,
+	clusterBootstrap *runtanzuv1alpha3.ClusterBootstrap, partial bool) error {
+
+	r.driftLock.Lock()
+	report := r.driftReports[clusterapiutil.ObjectKey(cluster)]
+	delete(r.driftReports, clusterapiutil.ObjectKey(cluster))
+	r.driftLock.Unlock()
+	if report == nil {
+		return nil
+	}
+
+	patchHelper, err := clusterapipatchutil.NewHelper(clusterBootstrap, r.Client)
+	if err != nil {
+		return err
+	}
+	applied := report.applied
+	if partial {
+		applied = applied.Union(report.previous)
+	}
+	clusterBootstrap.Status.AppliedResources = applied.List()
+	switch {
+	case len(report.drifts) == 0:
+		if conditions.IsTrue(clusterBootstrap, runtanzuv1alpha3.ConditionRemoteResourcesDrifted) {
+			conditions.Delete(clusterBootstrap, runtanzuv1alpha3.ConditionRemoteResourcesDrifted)
+		}
+	case report.policy == constants.RemoteDriftPolicyReport:
+		for _, drift := range report.drifts {
+			r.recorder.Event(clusterBootstrap, corev1.EventTypeWarning, runtanzuv1alpha3.ReasonDriftDetected, drift)
+		}
+		conditions.Set(clusterBootstrap, &clusterapiv1beta1.Condition{
+			Type:     runtanzuv1alpha3.ConditionRemoteResourcesDrifted,
+			Status:   corev1.ConditionTrue,
+			Severity: clusterapiv1beta1.ConditionSeverityWarning,
+			Reason:   runtanzuv1alpha3.ReasonDriftDetected,
+			Message:  strings.Join(report.drifts, "; "),
+		})
+	default:
+		for _, drift := range report.drifts {
+			r.recorder.Event(clusterBootstrap, corev1.EventTypeWarning, runtanzuv1alpha3.ReasonDriftReverted, drift)
+		}
+		conditions.Set(clusterBootstrap, &clusterapiv1beta1.Condition{
+			Type:     runtanzuv1alpha3.ConditionRemoteResourcesDrifted,
+			Status:   corev1.ConditionFalse,
+			Severity: clusterapiv1beta1.ConditionSeverityInfo,
+			Reason:   runtanzuv1alpha3.ReasonDriftReverted,
+			Message:  strings.Join(report.drifts, "; "),
+		})
+	}
+	if err := patchHelper.Patch(r.context, clusterBootstrap); err != nil {
+		return errors.Wrapf(err, "unable to patch the status of ClusterBootstrap %s/%s", clusterBootstrap.Namespace, clusterBootstrap.Name)
+	}
+	return nil
+}
+
+// remoteResourceKey identifies an addon resource in the applied resources of the ClusterBootstrap status
+func remoteResourceKey(obj client.Object) string {
+	return remoteKindName(obj) + " " + remoteObjectName(obj)
+}
+
+func remoteKindName(obj client.Object) string {
+	if metadata, ok := obj.(*metav1.PartialObjectMetadata); ok {
+		return metadata.Kind
+	}
+	return reflect.TypeOf(obj).Elem().Name()
+}
+
+func remoteObjectName(obj client.Object) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
@@ -0,0 +1,128 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/vmware-tanzu/tanzu-framework/addons/controllers/genericconfig"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/util"
+	cpiv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/addonconfigs/cpi/v1alpha1"
+)
+
+const (
+	authenticationSecretName      = "capoci-auth-config"
+	authenticationSecretNamespace = "cluster-api-provider-oci-system"
+)
+
+//+kubebuilder:rbac:groups=cpi.tanzu.vmware.com,resources=oraclecpiconfigs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cpi.tanzu.vmware.com,resources=oraclecpiconfigs/status,verbs=get;update;patch
+
+// NewOracleCPIConfigProvider returns the provider of the OracleCPIConfig CRD for the generic config controller
+func NewOracleCPIConfigProvider() (*genericconfig.Provider, error) {
+	return &genericconfig.Provider{
+		Kind:      constants.OracleCPIConfigKind,
+		AddonName: constants.OracleCPIAddonName,
+		NewConfig: func() client.Object {
+			return &cpiv1alpha1.OracleCPIConfig{}
+		},
+		NewConfigList: func() client.ObjectList {
+			return &cpiv1alpha1.OracleCPIConfigList{}
+		},
+		OwnerClusterRequired: true,
+		DataValues:           mapOracleCPIConfigToDataValues,
+		SetSecretRef: func(config client.Object, secretName string) {
+			config.(*cpiv1alpha1.OracleCPIConfig).Status.SecretRef = secretName
+		},
+	}, nil
+}
+
+// getOracleAuthSecret returns the secret that contains authentication credentials from CAPOCI
+func getOracleAuthSecret(ctx context.Context, c client.Client) (*v1.Secret, error) {
+	var authSecret v1.Secret
+	if err := c.Get(ctx, types.NamespacedName{
+		Name:      authenticationSecretName,
+		Namespace: authenticationSecretNamespace}, &authSecret); err != nil {
+		return nil, err
+	}
+	return &authSecret, nil
+}
+
+// mapOracleCPIConfigToDataValues maps OracleCPIConfig CR to data values
+// the owner cluster and the authentication secret are required to reconcile the OracleCPIConfig
+func mapOracleCPIConfigToDataValues(ctx context.Context, c client.Client, _ client.Object,
+	cluster *clusterapiv1beta1.Cluster) (interface{}, error) {
+
+	logger := log.FromContext(ctx)
+	auth, err := getOracleAuthSecret(ctx, c)
+	if err != nil {
+		logger.Error(err, "Failed to get authentication secret", "name", authenticationSecretName, "namespace", authenticationSecretNamespace)
+		return nil, err
+	}
+
+	fingerprint, ok := auth.Data["fingerprint"]
+	if !ok {
+		logger.Info("Cannot extract fingerprint", "name", authenticationSecretName, "namespace", authenticationSecretNamespace)
+	}
+	key, ok := auth.Data["key"]
+	if !ok {
+		logger.Info("Cannot extract key", "name", authenticationSecretName, "namespace", authenticationSecretNamespace)
+	}
+	region, ok := auth.Data["region"]
+	if !ok {
+		logger.Info("Cannot extract region", "name", authenticationSecretName, "namespace", authenticationSecretNamespace)
+	}
+	tenancy, ok := auth.Data["tenancy"]
+	if !ok {
+		logger.Info("Cannot extract tenancy", "name", authenticationSecretName, "namespace", authenticationSecretNamespace)
+	}
+	user, ok := auth.Data["user"]
+	if !ok {
+		logger.Info("Cannot extract user", "name", authenticationSecretName, "namespace", authenticationSecretNamespace)
+	}
+	// the passphrase is optional, use zero value if not provided
+	passphrase := auth.Data["passphrase"]
+
+	compartment, err := util.ParseClusterVariableString(cluster, "compartmentId")
+	if err != nil {
+		logger.Error(err, "Cannot extract compartment from cluster", "cluster", cluster.Name)
+	}
+	vcn, err := util.ParseClusterVariableString(cluster, "externalVCNId")
+	if err != nil {
+		logger.Error(err, "Cannot extract vcn from cluster", "cluster", cluster.Name)
+	}
+	subnet, err := util.ParseClusterVariableString(cluster, "privateServiceSubnetId")
+	if err != nil {
+		logger.Error(err, "Cannot extract private subnet from cluster", "cluster", cluster.Name)
+	}
+
+	// convert the CPIConfig CR to data values
+	d := &OracleCPIDataValues{
+		Auth: OracleCPIDataValuesAuth{
+			Region:      string(region),
+			Tenancy:     string(tenancy),
+			User:        string(user),
+			Key:         string(key),
+			Fingerprint: string(fingerprint),
+			Passphrase:  string(passphrase),
+		},
+		Compartment: compartment,
+		VCN:         vcn,
+		LoadBalancer: struct {
+			Subnet1 string `yaml:"subnet1"`
+			Subnet2 string `yaml:"subnet2"`
+		}{
+			Subnet1: subnet,
+			Subnet2: subnet,
+		},
+	}
+	return d.Serialize()
+}
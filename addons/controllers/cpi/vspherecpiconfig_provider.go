@@ -0,0 +1,138 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package controllers implements the VSphereCPIConfig and OracleCPIConfig providers of the generic config controller.
+package controllers
+
+import (
+	"context"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	capvvmwarev1beta1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/vmware/v1beta1"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/vmware-tanzu/tanzu-framework/addons/controllers/genericconfig"
+	cutil "github.com/vmware-tanzu/tanzu-framework/addons/controllers/utils"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+	cpiv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/addonconfigs/cpi/v1alpha1"
+)
+
+var providerServiceAccountRBACRules = []rbacv1.PolicyRule{
+	{
+		Verbs:     []string{"get", "create", "update", "patch", "delete"},
+		APIGroups: []string{"vmoperator.vmware.com"},
+		Resources: []string{"virtualmachineservices", "virtualmachineservices/status"},
+	},
+	{
+		Verbs:     []string{"get", "list"},
+		APIGroups: []string{"vmoperator.vmware.com"},
+		Resources: []string{"virtualmachines", "virtualmachines/status"},
+	},
+	{
+		Verbs:     []string{"get", "create", "update", "list", "patch", "delete", "watch"},
+		APIGroups: []string{"nsx.vmware.com"},
+		Resources: []string{"ippools", "ippools/status"},
+	},
+	{
+		Verbs:     []string{"get", "create", "update", "list", "patch", "delete"},
+		APIGroups: []string{"nsx.vmware.com"},
+		Resources: []string{"routesets", "routesets/status"},
+	},
+}
+
+// VsphereCPIProviderServiceAccountAggregatedClusterRole is the cluster role to assign permissions to capv provider
+var vsphereCPIProviderServiceAccountAggregatedClusterRole = &rbacv1.ClusterRole{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: constants.VsphereCPIProviderServiceAccountAggregatedClusterRole,
+		Labels: map[string]string{
+			constants.CAPVClusterRoleAggregationRuleLabelSelectorKey: constants.CAPVClusterRoleAggregationRuleLabelSelectorValue,
+		},
+	},
+}
+
+//+kubebuilder:rbac:groups=cpi.tanzu.vmware.com,resources=vspherecpiconfigs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cpi.tanzu.vmware.com,resources=vspherecpiconfigs/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=vmware.infrastructure.cluster.x-k8s.io,resources=providerserviceaccounts,verbs=get;create;list;watch;update;patch
+
+// NewVSphereCPIConfigProvider returns the provider of the VSphereCPIConfig CRD for the generic config controller
+func NewVSphereCPIConfigProvider() (*genericconfig.Provider, error) {
+	return &genericconfig.Provider{
+		Kind:      constants.VSphereCPIConfigKind,
+		AddonName: constants.CPIAddonName,
+		NewConfig: func() client.Object {
+			return &cpiv1alpha1.VSphereCPIConfig{}
+		},
+		NewConfigList: func() client.ObjectList {
+			return &cpiv1alpha1.VSphereCPIConfigList{}
+		},
+		OwnerClusterRequired: true,
+		// no need to reconcile until the CPI mode is provided
+		Skip: func(config client.Object) bool {
+			return config.(*cpiv1alpha1.VSphereCPIConfig).Spec.VSphereCPI.Mode == nil
+		},
+		DataValues:         mapVSphereCPIConfigToDataValues,
+		ReconcileResources: reconcileVSphereCPIProviderServiceAccount,
+		SetSecretRef: func(config client.Object, secretName string) {
+			config.(*cpiv1alpha1.VSphereCPIConfig).Status.SecretRef = secretName
+		},
+	}, nil
+}
+
+// mapVSphereCPIConfigToDataValues maps VSphereCPIConfig CR to data values
+func mapVSphereCPIConfigToDataValues(ctx context.Context, c client.Client, config client.Object,
+	cluster *clusterapiv1beta1.Cluster) (interface{}, error) {
+
+	r := &vsphereCPIConfigMapper{Client: c, Log: log.FromContext(ctx)}
+	cpiConfigSpec, err := r.mapCPIConfigToDataValues(ctx, config.(*cpiv1alpha1.VSphereCPIConfig), cluster)
+	if err != nil {
+		return nil, err
+	}
+	return cpiConfigSpec.Serialize()
+}
+
+// reconcileVSphereCPIProviderServiceAccount deploys the provider service account for paravirtual mode
+func reconcileVSphereCPIProviderServiceAccount(ctx context.Context, c client.Client, scheme *runtime.Scheme,
+	config client.Object, cluster *clusterapiv1beta1.Cluster) error {
+
+	cpiConfig := config.(*cpiv1alpha1.VSphereCPIConfig)
+	if *cpiConfig.Spec.VSphereCPI.Mode != VSphereCPIParavirtualMode {
+		return nil
+	}
+	logger := log.FromContext(ctx)
+	r := &vsphereCPIConfigMapper{Client: c, Log: logger}
+
+	// create an aggregated cluster role RBAC that will be inherited by CAPV (https://kubernetes.io/docs/reference/access-authn-authz/rbac/#aggregated-clusterroles)
+	// CAPV needs to hold these rules before it can grant it to serviceAccount for CPI
+	_, err := controllerutil.CreateOrPatch(ctx, c, vsphereCPIProviderServiceAccountAggregatedClusterRole, func() error {
+		vsphereCPIProviderServiceAccountAggregatedClusterRole.Rules = providerServiceAccountRBACRules
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "Error creating or patching cluster role", "name", vsphereCPIProviderServiceAccountAggregatedClusterRole)
+		return err
+	}
+
+	vsphereCluster, err := cutil.VSphereClusterParavirtualForCAPICluster(ctx, c, cluster)
+	if err != nil {
+		return err
+	}
+	serviceAccount := &capvvmwarev1beta1.ProviderServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getCCMName(vsphereCluster),
+			Namespace: vsphereCluster.Namespace,
+		},
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, c, serviceAccount, func() error {
+		serviceAccount.Spec = r.mapCPIConfigToProviderServiceAccountSpec(vsphereCluster)
+		return controllerutil.SetControllerReference(vsphereCluster, serviceAccount, scheme)
+	})
+	if err != nil {
+		logger.Error(err, "Error creating or updating ProviderServiceAccount for VSphere CPI")
+	}
+	return nil
+}
@@ -9,8 +9,7 @@ import (
 	"net/url"
 	"strconv"
 
-	"sigs.k8s.io/controller-runtime/pkg/client"
-
+	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -22,38 +21,21 @@ import (
 	capvidentity "sigs.k8s.io/cluster-api-provider-vsphere/pkg/identity"
 	capvmanager "sigs.k8s.io/cluster-api-provider-vsphere/pkg/manager"
 	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
-	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	cutil "github.com/vmware-tanzu/tanzu-framework/addons/controllers/utils"
 	pkgtypes "github.com/vmware-tanzu/tanzu-framework/addons/pkg/types"
 	cpiv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/addonconfigs/cpi/v1alpha1"
 )
 
-// ClusterToVSphereCPIConfig returns a list of Requests with VSphereCPIConfig ObjectKey based on Cluster events
-func (r *VSphereCPIConfigReconciler) ClusterToVSphereCPIConfig(o client.Object) []ctrl.Request {
-	cluster, ok := o.(*clusterapiv1beta1.Cluster)
-	if !ok {
-		r.Log.Error(errors.New("invalid type"),
-			"Expected to receive Cluster resource",
-			"actualType", fmt.Sprintf("%T", o))
-		return nil
-	}
-
-	r.Log.V(4).Info("Mapping Cluster to VSphereCPIConfig")
-
-	cs := &cpiv1alpha1.VSphereCPIConfigList{}
-	_ = r.List(context.Background(), cs)
-	var requests []ctrl.Request
-
-	for _, cpiConfig := range cs.Items {
-		requests = performEnqueueForCPIConfigIfOwnedByCluster(&cpiConfig, cluster, r.Config.ConfigControllerConfig, r.Log, requests)
-	}
-
-	return requests
+// vsphereCPIConfigMapper maps a VSphereCPIConfig and its cluster to the data values and the resources of the CPI addon
+type vsphereCPIConfigMapper struct {
+	client.Client
+	Log logr.Logger
 }
 
 // mapCPIConfigToDataValuesNonParavirtual generates CPI data values for non-paravirtual modes
-func (r *VSphereCPIConfigReconciler) mapCPIConfigToDataValuesNonParavirtual( // nolint
+func (r *vsphereCPIConfigMapper) mapCPIConfigToDataValuesNonParavirtual( // nolint
 	ctx context.Context,
 	cpiConfig *cpiv1alpha1.VSphereCPIConfig, cluster *clusterapiv1beta1.Cluster) (VSphereCPIDataValues, error,
 ) { // nolint:whitespace
@@ -183,7 +165,7 @@ func (r *VSphereCPIConfigReconciler) mapCPIConfigToDataValuesNonParavirtual( //
 }
 
 // mapCPIConfigToDataValuesParavirtual generates CPI data values for paravirtual modes
-func (r *VSphereCPIConfigReconciler) mapCPIConfigToDataValuesParavirtual(ctx context.Context, cpiConfig *cpiv1alpha1.VSphereCPIConfig, cluster *clusterapiv1beta1.Cluster) (VSphereCPIDataValues, error) {
+func (r *vsphereCPIConfigMapper) mapCPIConfigToDataValuesParavirtual(ctx context.Context, cpiConfig *cpiv1alpha1.VSphereCPIConfig, cluster *clusterapiv1beta1.Cluster) (VSphereCPIDataValues, error) {
 	c := cpiConfig.Spec.VSphereCPI.ParavirtualConfig
 
 	d := &VSphereCPIParaVirtDataValues{}
@@ -232,7 +214,7 @@ func getAPIServerPortFromLBService(svc *v1.Service) (int32, error) {
 
 // getSupervisorAPIServerVIP attempts to extract the ingress IP for supervisor API endpoint if the service
 // "kube-system/kube-apiserver-lb-svc" is available
-func (r *VSphereCPIConfigReconciler) getSupervisorAPIServerVIP(ctx context.Context) (string, int32, error) {
+func (r *vsphereCPIConfigMapper) getSupervisorAPIServerVIP(ctx context.Context) (string, int32, error) {
 	svc := &v1.Service{}
 	svcKey := types.NamespacedName{Name: SupervisorLoadBalancerSvcName, Namespace: SupervisorLoadBalancerSvcNamespace}
 	if err := r.Client.Get(ctx, svcKey, svc); err != nil {
@@ -253,7 +235,7 @@ func (r *VSphereCPIConfigReconciler) getSupervisorAPIServerVIP(ctx context.Conte
 }
 
 // getSupervisorAPIServerFIP get a valid Supervisor Cluster Management Network Floating IP (FIP) from the cluster-info configmap
-func (r *VSphereCPIConfigReconciler) getSupervisorAPIServerFIP(ctx context.Context) (string, int32, error) {
+func (r *vsphereCPIConfigMapper) getSupervisorAPIServerFIP(ctx context.Context) (string, int32, error) {
 	urlString, err := r.getSupervisorAPIServerURLWithFIP(ctx)
 	if err != nil {
 		return "", 0, errors.Wrap(err, "unable to get supervisor url")
@@ -274,7 +256,7 @@ func (r *VSphereCPIConfigReconciler) getSupervisorAPIServerFIP(ctx context.Conte
 }
 
 // getSupervisorAPIServerURLWithFIP get a Supervisor Cluster Management Network Floating IP (FIP)
-func (r *VSphereCPIConfigReconciler) getSupervisorAPIServerURLWithFIP(ctx context.Context) (string, error) {
+func (r *vsphereCPIConfigMapper) getSupervisorAPIServerURLWithFIP(ctx context.Context) (string, error) {
 	cm := &v1.ConfigMap{}
 	cmKey := types.NamespacedName{Name: ConfigMapClusterInfo, Namespace: metav1.NamespacePublic}
 	if err := r.Client.Get(ctx, cmKey, cm); err != nil {
@@ -296,7 +278,7 @@ func (r *VSphereCPIConfigReconciler) getSupervisorAPIServerURLWithFIP(ctx contex
 // 2. If not, get the Supervisor Cluster Management Network Floating IP (FIP) from the cluster-info configmap. This is
 // to support non-NSX-T development use cases only. If we are unable to find the cluster-info configmap for some reason,
 // we log the error.
-func (r *VSphereCPIConfigReconciler) getSupervisorAPIServerAddress(ctx context.Context) (string, int32, error) {
+func (r *vsphereCPIConfigMapper) getSupervisorAPIServerAddress(ctx context.Context) (string, int32, error) {
 	supervisorHost, supervisorPort, err := r.getSupervisorAPIServerVIP(ctx)
 	if err != nil {
 		r.Log.Info("Unable to discover supervisor apiserver virtual ip, fallback to floating ip", "reason", err.Error())
@@ -335,7 +317,7 @@ func getClusterFromKubeConfig(config *clientcmdapi.Config) *clientcmdapi.Cluster
 }
 
 // mapCPIConfigToDataValues maps VSphereCPIConfig CR to data values
-func (r *VSphereCPIConfigReconciler) mapCPIConfigToDataValues(ctx context.Context, cpiConfig *cpiv1alpha1.VSphereCPIConfig, cluster *clusterapiv1beta1.Cluster) (VSphereCPIDataValues, error) {
+func (r *vsphereCPIConfigMapper) mapCPIConfigToDataValues(ctx context.Context, cpiConfig *cpiv1alpha1.VSphereCPIConfig, cluster *clusterapiv1beta1.Cluster) (VSphereCPIDataValues, error) {
 	mode := *cpiConfig.Spec.VSphereCPI.Mode
 	switch mode {
 	case VsphereCPINonParavirtualMode:
@@ -349,7 +331,7 @@ func (r *VSphereCPIConfigReconciler) mapCPIConfigToDataValues(ctx context.Contex
 }
 
 // mapCPIConfigToProviderServiceAccountSpec maps CPIConfig and cluster to the corresponding service account spec
-func (r *VSphereCPIConfigReconciler) mapCPIConfigToProviderServiceAccountSpec(vsphereCluster *capvvmwarev1beta1.VSphereCluster) capvvmwarev1beta1.ProviderServiceAccountSpec {
+func (r *vsphereCPIConfigMapper) mapCPIConfigToProviderServiceAccountSpec(vsphereCluster *capvvmwarev1beta1.VSphereCluster) capvvmwarev1beta1.ProviderServiceAccountSpec {
 	return capvvmwarev1beta1.ProviderServiceAccountSpec{
 		Ref:              &v1.ObjectReference{Name: vsphereCluster.Name, Namespace: vsphereCluster.Namespace},
 		Rules:            providerServiceAccountRBACRules,
@@ -359,7 +341,7 @@ func (r *VSphereCPIConfigReconciler) mapCPIConfigToProviderServiceAccountSpec(vs
 }
 
 // getSecret gets the secret object given its name and namespace
-func (r *VSphereCPIConfigReconciler) getSecret(ctx context.Context, namespace, name string) (*v1.Secret, error) {
+func (r *vsphereCPIConfigMapper) getSecret(ctx context.Context, namespace, name string) (*v1.Secret, error) {
 	secret := &v1.Secret{}
 	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
 		if apierrors.IsNotFound(err) {
@@ -0,0 +1,197 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/vmware-tanzu/tanzu-framework/addons/controllers/genericconfig"
+	cutil "github.com/vmware-tanzu/tanzu-framework/addons/controllers/utils"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+	csiv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/addonconfigs/csi/v1alpha1"
+)
+
+var providerServiceAccountRBACRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{"vmoperator.vmware.com"},
+		Resources: []string{"virtualmachines"},
+		Verbs:     []string{"get", "list", "watch", "update", "patch"},
+	},
+	{
+		APIGroups: []string{"cns.vmware.com"},
+		Resources: []string{"cnsvolumemetadatas", "cnsfileaccessconfigs"},
+		Verbs:     []string{"get", "list", "watch", "update", "create", "delete"},
+	},
+	{
+		APIGroups: []string{"cns.vmware.com"},
+		Resources: []string{"cnscsisvfeaturestates"},
+		Verbs:     []string{"get", "list", "watch"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"persistentvolumeclaims"},
+		Verbs:     []string{"get", "list", "watch", "update", "create", "delete"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"persistentvolumeclaims/status"},
+		Verbs:     []string{"get", "update", "patch"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"events"},
+		Verbs:     []string{"list"},
+	},
+}
+
+// VsphereCSIProviderServiceAccountAggregatedClusterRole is the cluster role to assign permissions to capv provider
+var vsphereCSIProviderServiceAccountAggregatedClusterRole = &rbacv1.ClusterRole{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: constants.VsphereCSIProviderServiceAccountAggregatedClusterRole,
+		Labels: map[string]string{
+			constants.CAPVClusterRoleAggregationRuleLabelSelectorKey: constants.CAPVClusterRoleAggregationRuleLabelSelectorValue,
+		},
+	},
+}
+
+//+kubebuilder:rbac:groups=csi.tanzu.vmware.com,resources=vspherecsiconfigs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=csi.tanzu.vmware.com,resources=vspherecsiconfigs/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=csi.tanzu.vmware.com,resources=vspherecsiconfigs/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=topology.tanzu.vmware.com,resources=availabilityzones,verbs=get;list
+//+kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=kubeadmcontrolplanes,verbs=get
+//+kubebuilder:rbac:groups=vmware.infrastructure.cluster.x-k8s.io,resources=providerserviceaccounts,verbs=get;create;list;watch;update;patch
+
+// NewVSphereCSIConfigProvider returns the provider of the VSphereCSIConfig CRD for the generic config controller
+func NewVSphereCSIConfigProvider() (*genericconfig.Provider, error) {
+	return &genericconfig.Provider{
+		Kind: constants.VSphereCSIConfigKind,
+		AddonNameFor: func(config client.Object) (string, error) {
+			mode := config.(*csiv1alpha1.VSphereCSIConfig).Spec.VSphereCSI.Mode
+			switch mode {
+			case VSphereCSINonParavirtualMode:
+				return constants.CSIAddonName, nil
+			case VSphereCSIParavirtualMode:
+				return constants.PVCSIAddonName, nil
+			}
+			return "", errors.Errorf("Invalid CSI mode '%s', must either be '%s' or '%s'",
+				mode, VSphereCSIParavirtualMode, VSphereCSINonParavirtualMode)
+		},
+		NewConfig: func() client.Object {
+			return &csiv1alpha1.VSphereCSIConfig{}
+		},
+		NewConfigList: func() client.ObjectList {
+			return &csiv1alpha1.VSphereCSIConfigList{}
+		},
+		OwnerClusterRequired: true,
+		DataValues:           mapVSphereCSIConfigToDataValues,
+		ReconcileResources:   reconcileVSphereCSIProviderServiceAccount,
+		// (deliberate decision): There is no watch on AvailabilityZone so any change to it will not trigger reconcile
+		// of resources. Based on discussions with TKGS team, availability zone is created at supervisor cluster init time
+		// and does not really change after that.
+		Watches: []genericconfig.Watch{{
+			Object:     &v1.ConfigMap{},
+			Predicates: []predicate.Predicate{featureStatesPredicates},
+			ToConfigs:  configMapToVSphereCSIConfigs,
+		}},
+		SetSecretRef: func(config client.Object, secretName string) {
+			config.(*csiv1alpha1.VSphereCSIConfig).Status.SecretRef = &secretName
+		},
+	}, nil
+}
+
+var featureStatesPredicates = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		return isFeatureStatesConfigMap(e.Object)
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return isFeatureStatesConfigMap(e.ObjectNew) &&
+			e.ObjectOld.GetResourceVersion() != e.ObjectNew.GetResourceVersion()
+	},
+	// Delete is not expected to occur
+}
+
+func isFeatureStatesConfigMap(o metav1.Object) bool {
+	return o.GetNamespace() == VSphereCSIFeatureStateNamespace &&
+		o.GetName() == VSphereCSIFeatureStateConfigMapName
+}
+
+// configMapToVSphereCSIConfigs returns the paravirtual VSphereCSIConfigs, which depend on the feature states ConfigMap
+func configMapToVSphereCSIConfigs(ctx context.Context, c client.Client, _ client.Object) []client.Object {
+	configs := &csiv1alpha1.VSphereCSIConfigList{}
+	if err := c.List(ctx, configs); err != nil {
+		log.FromContext(ctx).Error(err, "Error listing VSphereCSIConfig")
+		return nil
+	}
+	var paravirtualConfigs []client.Object
+	for i := range configs.Items {
+		if configs.Items[i].Spec.VSphereCSI.Mode == VSphereCSIParavirtualMode {
+			paravirtualConfigs = append(paravirtualConfigs, &configs.Items[i])
+		}
+	}
+	return paravirtualConfigs
+}
+
+// mapVSphereCSIConfigToDataValues maps VSphereCSIConfig CR to the marshaled data values
+func mapVSphereCSIConfigToDataValues(ctx context.Context, c client.Client, config client.Object,
+	cluster *clusterapiv1beta1.Cluster) (interface{}, error) {
+
+	r := &vsphereCSIConfigMapper{Client: c, Log: log.FromContext(ctx)}
+	dvs, err := r.mapVSphereCSIConfigToDataValues(ctx, config.(*csiv1alpha1.VSphereCSIConfig), cluster)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(dvs)
+}
+
+// reconcileVSphereCSIProviderServiceAccount deploys the provider service account for paravirtual mode
+func reconcileVSphereCSIProviderServiceAccount(ctx context.Context, c client.Client, scheme *runtime.Scheme,
+	config client.Object, cluster *clusterapiv1beta1.Cluster) error {
+
+	if config.(*csiv1alpha1.VSphereCSIConfig).Spec.VSphereCSI.Mode != VSphereCSIParavirtualMode {
+		return nil
+	}
+	logger := log.FromContext(ctx)
+	r := &vsphereCSIConfigMapper{Client: c, Log: logger}
+
+	// create an aggregated cluster role RBAC that will be inherited by CAPV (https://kubernetes.io/docs/reference/access-authn-authz/rbac/#aggregated-clusterroles)
+	// CAPV needs to hold these rules before it can grant it to serviceAccount for CSI
+	_, err := controllerutil.CreateOrPatch(ctx, c, vsphereCSIProviderServiceAccountAggregatedClusterRole, func() error {
+		vsphereCSIProviderServiceAccountAggregatedClusterRole.Rules = providerServiceAccountRBACRules
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "Error creating or patching cluster role", "name", vsphereCSIProviderServiceAccountAggregatedClusterRole)
+		return err
+	}
+
+	vsphereCluster, err := cutil.VSphereClusterParavirtualForCAPICluster(ctx, c, cluster)
+	if err != nil {
+		return err
+	}
+	serviceAccount := r.mapCSIConfigToProviderServiceAccount(vsphereCluster)
+	_, err = controllerutil.CreateOrUpdate(ctx, c, serviceAccount, func() error {
+		return controllerutil.SetControllerReference(vsphereCluster, serviceAccount, scheme)
+	})
+	if err != nil {
+		logger.Error(err, "Error creating or updating ProviderServiceAccount for VSphere CSI")
+		return err
+	}
+	return nil
+}
@@ -6,8 +6,8 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"strings"
 
+	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -16,70 +16,23 @@ import (
 	capvvmwarev1beta1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/vmware/v1beta1"
 	clusterv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	capictrlpkubeadmv1beta1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
-	clusterapiutil "sigs.k8s.io/cluster-api/util"
-	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	cutil "github.com/vmware-tanzu/tanzu-framework/addons/controllers/utils"
-	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
 	pkgtypes "github.com/vmware-tanzu/tanzu-framework/addons/pkg/types"
 	csiv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/addonconfigs/csi/v1alpha1"
 	topologyv1alpha1 "github.com/vmware-tanzu/vm-operator/external/tanzu-topology/api/v1alpha1"
 )
 
-// ClusterToVSphereCSIConfig returns a list of Requests with VSphereCSIConfig ObjectKey
-func (r *VSphereCSIConfigReconciler) ClusterToVSphereCSIConfig(o client.Object) []ctrl.Request {
-	cluster, ok := o.(*clusterv1beta1.Cluster)
-	if !ok {
-		r.Log.Error(errors.New("invalid type"),
-			"Expected to receive Cluster resource",
-			"actualType", fmt.Sprintf("%T", o))
-		return nil
-	}
-
-	r.Log.V(4).Info("Mapping Cluster to VSphereCSIConfig")
-
-	configs := &csiv1alpha1.VSphereCSIConfigList{}
-
-	if err := r.Client.List(context.Background(), configs); err != nil {
-		r.Log.Error(err, "Error listing VSphereCSIConfig")
-		return nil
-	}
-
-	var requests []ctrl.Request
-	for i := range configs.Items {
-		config := &configs.Items[i]
-		if config.Namespace == cluster.Namespace {
-			// avoid enqueuing reconcile requests for template VSphereCSIConfig CRs in event handler of Cluster CR
-			if _, ok := config.Annotations[constants.TKGAnnotationTemplateConfig]; ok && config.Namespace == r.Config.SystemNamespace {
-				continue
-			}
-
-			// corresponding VSphereCSIConfig should have following ownerRef
-			ownerReference := metav1.OwnerReference{
-				APIVersion: clusterv1beta1.GroupVersion.String(),
-				Kind:       cluster.Kind,
-				Name:       cluster.Name,
-				UID:        cluster.UID,
-			}
-
-			if clusterapiutil.HasOwnerRef(config.OwnerReferences, ownerReference) {
-				r.Log.V(4).Info("Adding VSphereCSIConfig for reconciliation",
-					constants.NamespaceLogKey, config.Namespace, constants.NameLogKey, config.Name)
-
-				requests = append(requests, ctrl.Request{
-					NamespacedName: clusterapiutil.ObjectKey(config),
-				})
-			}
-		}
-	}
-
-	return requests
+// vsphereCSIConfigMapper maps a VSphereCSIConfig and its cluster to the data values and the resources of the CSI addon
+type vsphereCSIConfigMapper struct {
+	client.Client
+	Log logr.Logger
 }
 
 // mapVSphereCSIConfigToDataValues maps VSphereCSIConfig CR to data values
-func (r *VSphereCSIConfigReconciler) mapVSphereCSIConfigToDataValues(ctx context.Context,
+func (r *vsphereCSIConfigMapper) mapVSphereCSIConfigToDataValues(ctx context.Context,
 	vcsiConfig *csiv1alpha1.VSphereCSIConfig,
 	cluster *clusterv1beta1.Cluster) (*DataValues, error) {
 
@@ -96,7 +49,7 @@ func (r *VSphereCSIConfigReconciler) mapVSphereCSIConfigToDataValues(ctx context
 		vcsiConfig.Spec.VSphereCSI.Mode, VSphereCSIParavirtualMode, VSphereCSINonParavirtualMode)
 }
 
-func (r *VSphereCSIConfigReconciler) mapVSphereCSIConfigToDataValuesParavirtual(ctx context.Context,
+func (r *vsphereCSIConfigMapper) mapVSphereCSIConfigToDataValuesParavirtual(ctx context.Context,
 	cluster *clusterv1beta1.Cluster) (*DataValues, error) {
 
 	dvs := &DataValues{}
@@ -133,7 +86,7 @@ func (r *VSphereCSIConfigReconciler) mapVSphereCSIConfigToDataValuesParavirtual(
 	return dvs, nil
 }
 
-func (r *VSphereCSIConfigReconciler) mapVSphereCSIConfigToDataValuesNonParavirtual(ctx context.Context,
+func (r *vsphereCSIConfigMapper) mapVSphereCSIConfigToDataValuesNonParavirtual(ctx context.Context,
 	vcsiConfig *csiv1alpha1.VSphereCSIConfig,
 	cluster *clusterv1beta1.Cluster) (*DataValues, error) {
 
@@ -201,39 +154,8 @@ func (r *VSphereCSIConfigReconciler) mapVSphereCSIConfigToDataValuesNonParavirtu
 	return dvs, nil
 }
 
-// getOwnerCluster verifies that the VSphereCSIConfig has a cluster as its owner reference,
-// and returns the cluster. It tries to read the cluster name from the VSphereCSIConfig's owner reference objects.
-// If not there, we assume the owner cluster and VSphereCSIConfig always has the same name.
-//
-//nolint:unused
-func (r *VSphereCSIConfigReconciler) getOwnerCluster(ctx context.Context,
-	vcsiConfig *csiv1alpha1.VSphereCSIConfig) (*clusterv1beta1.Cluster, error) {
-
-	logger := log.FromContext(ctx)
-	cluster := &clusterv1beta1.Cluster{}
-	clusterName := vcsiConfig.Name // usually the corresponding 'cluster' shares the same name
-
-	// retrieve the owner cluster for the VSphereCSIConfig object
-	for _, ownerRef := range vcsiConfig.GetOwnerReferences() {
-		if strings.EqualFold(ownerRef.Kind, constants.ClusterKind) {
-			clusterName = ownerRef.Name
-			break
-		}
-	}
-	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: vcsiConfig.Namespace, Name: clusterName}, cluster); err != nil {
-		if apierrors.IsNotFound(err) {
-			logger.Info(fmt.Sprintf("Cluster resource '%s/%s' not found", vcsiConfig.Namespace, clusterName))
-			return nil, nil
-		}
-		logger.Error(err, fmt.Sprintf("Unable to fetch cluster '%s/%s'", vcsiConfig.Namespace, clusterName))
-		return nil, err
-	}
-
-	return cluster, nil
-}
-
 // mapCSIConfigToProviderServiceAccount maps CSIConfig and cluster to the corresponding service account
-func (r *VSphereCSIConfigReconciler) mapCSIConfigToProviderServiceAccount(vsphereCluster *capvvmwarev1beta1.VSphereCluster) *capvvmwarev1beta1.ProviderServiceAccount {
+func (r *vsphereCSIConfigMapper) mapCSIConfigToProviderServiceAccount(vsphereCluster *capvvmwarev1beta1.VSphereCluster) *capvvmwarev1beta1.ProviderServiceAccount {
 	serviceAccount := &capvvmwarev1beta1.ProviderServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fmt.Sprintf("%s-%s", vsphereCluster.Name, "pvcsi"),
@@ -249,7 +171,7 @@ func (r *VSphereCSIConfigReconciler) mapCSIConfigToProviderServiceAccount(vspher
 	return serviceAccount
 }
 
-func (r *VSphereCSIConfigReconciler) overrideDerivedValues(ctx context.Context,
+func (r *vsphereCSIConfigMapper) overrideDerivedValues(ctx context.Context,
 	dvscsi *DataValuesVSphereCSI,
 	vcsiConfig *csiv1alpha1.VSphereCSIConfig) error {
 
@@ -262,7 +184,7 @@ func (r *VSphereCSIConfigReconciler) overrideDerivedValues(ctx context.Context,
 	return r.overrideCredentialValues(ctx, dvscsi, vcsiConfig)
 }
 
-func (r *VSphereCSIConfigReconciler) overrideProxyValues(dvscsi *DataValuesVSphereCSI,
+func (r *vsphereCSIConfigMapper) overrideProxyValues(dvscsi *DataValuesVSphereCSI,
 	vcsiConfig *csiv1alpha1.VSphereCSIConfig) {
 
 	config := vcsiConfig.Spec.VSphereCSI.NonParavirtualConfig
@@ -277,7 +199,7 @@ func (r *VSphereCSIConfigReconciler) overrideProxyValues(dvscsi *DataValuesVSphe
 	}
 }
 
-func (r *VSphereCSIConfigReconciler) overrideTimeoutValues(dvscsi *DataValuesVSphereCSI,
+func (r *vsphereCSIConfigMapper) overrideTimeoutValues(dvscsi *DataValuesVSphereCSI,
 	vcsiConfig *csiv1alpha1.VSphereCSIConfig) {
 
 	config := vcsiConfig.Spec.VSphereCSI.NonParavirtualConfig
@@ -292,7 +214,7 @@ func (r *VSphereCSIConfigReconciler) overrideTimeoutValues(dvscsi *DataValuesVSp
 	}
 }
 
-func (r *VSphereCSIConfigReconciler) overrideTopologyValues(ctx context.Context,
+func (r *vsphereCSIConfigMapper) overrideTopologyValues(ctx context.Context,
 	dvscsi *DataValuesVSphereCSI,
 	vcsiConfig *csiv1alpha1.VSphereCSIConfig) {
 
@@ -314,7 +236,7 @@ func (r *VSphereCSIConfigReconciler) overrideTopologyValues(ctx context.Context,
 	}
 }
 
-func (r *VSphereCSIConfigReconciler) overrideCredentialValues(ctx context.Context,
+func (r *vsphereCSIConfigMapper) overrideCredentialValues(ctx context.Context,
 	dvscsi *DataValuesVSphereCSI,
 	vcsiConfig *csiv1alpha1.VSphereCSIConfig) error {
 
@@ -333,7 +255,7 @@ func (r *VSphereCSIConfigReconciler) overrideCredentialValues(ctx context.Contex
 	return nil
 }
 
-func (r *VSphereCSIConfigReconciler) overrideClusterValues(dvscsi *DataValuesVSphereCSI,
+func (r *vsphereCSIConfigMapper) overrideClusterValues(dvscsi *DataValuesVSphereCSI,
 	vcsiConfig *csiv1alpha1.VSphereCSIConfig) {
 
 	config := vcsiConfig.Spec.VSphereCSI.NonParavirtualConfig
@@ -352,7 +274,7 @@ func (r *VSphereCSIConfigReconciler) overrideClusterValues(dvscsi *DataValuesVSp
 	}
 }
 
-func (r *VSphereCSIConfigReconciler) overrideMiscValues(dvscsi *DataValuesVSphereCSI,
+func (r *vsphereCSIConfigMapper) overrideMiscValues(dvscsi *DataValuesVSphereCSI,
 	vcsiConfig *csiv1alpha1.VSphereCSIConfig) {
 
 	config := vcsiConfig.Spec.VSphereCSI.NonParavirtualConfig
@@ -370,7 +292,7 @@ func (r *VSphereCSIConfigReconciler) overrideMiscValues(dvscsi *DataValuesVSpher
 	}
 }
 
-func (r *VSphereCSIConfigReconciler) constrainNumberOfDeploymentReplicas(ctx context.Context, proposedCount int32) int32 {
+func (r *vsphereCSIConfigMapper) constrainNumberOfDeploymentReplicas(ctx context.Context, proposedCount int32) int32 {
 	logger := log.FromContext(ctx)
 	if proposedCount < VSphereCSIMinDeploymentReplicas {
 		logger.Info(fmt.Sprintf("WARNING: adjusting vsphere csi replica count from '%d' to '%d'",
@@ -387,7 +309,7 @@ func (r *VSphereCSIConfigReconciler) constrainNumberOfDeploymentReplicas(ctx con
 	return proposedCount
 }
 
-func (r *VSphereCSIConfigReconciler) computeRecommendedNumberOfDeploymentReplicas(ctx context.Context,
+func (r *vsphereCSIConfigMapper) computeRecommendedNumberOfDeploymentReplicas(ctx context.Context,
 	cluster *clusterv1beta1.Cluster) (int32, error) {
 
 	cpNodeCount, err := r.getNumberOfControlPlaneNodes(ctx, cluster)
@@ -399,7 +321,7 @@ func (r *VSphereCSIConfigReconciler) computeRecommendedNumberOfDeploymentReplica
 	return r.constrainNumberOfDeploymentReplicas(ctx, cpNodeCount), nil
 }
 
-func (r *VSphereCSIConfigReconciler) getNumberOfControlPlaneNodes(ctx context.Context,
+func (r *vsphereCSIConfigMapper) getNumberOfControlPlaneNodes(ctx context.Context,
 	cluster *clusterv1beta1.Cluster) (int32, error) {
 
 	name := cluster.Spec.ControlPlaneRef.Name
@@ -418,7 +340,7 @@ func (r *VSphereCSIConfigReconciler) getNumberOfControlPlaneNodes(ctx context.Co
 
 // Return true if Valid Availability Zones are present
 // i.e. if the cluster is Stretch Supervisor
-func (r *VSphereCSIConfigReconciler) isStretchedSupervisorCluster(ctx context.Context) (bool, error) {
+func (r *vsphereCSIConfigMapper) isStretchedSupervisorCluster(ctx context.Context) (bool, error) {
 	azList := &topologyv1alpha1.AvailabilityZoneList{}
 
 	err := r.Client.List(ctx, azList)
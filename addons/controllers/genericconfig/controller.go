@@ -0,0 +1,310 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package genericconfig
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterapiutil "sigs.k8s.io/cluster-api/util"
+	clusterapipatchutil "sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	addonconfig "github.com/vmware-tanzu/tanzu-framework/addons/pkg/config"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/util"
+	"github.com/vmware-tanzu/tanzu-framework/addons/predicates"
+)
+
+// Reconciler reconciles the addon configs of a Provider into the data values secrets of their addon
+type Reconciler struct {
+	client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Config   addonconfig.ConfigControllerConfig
+	Provider *Provider
+}
+
+// NewReconciler returns a reconciler for the addon configs of the provider
+func NewReconciler(c client.Client, log logr.Logger, scheme *runtime.Scheme, config addonconfig.ConfigControllerConfig,
+	provider *Provider) *Reconciler {
+	return &Reconciler{
+		Client:   c,
+		Log:      log,
+		Scheme:   scheme,
+		Config:   config,
+		Provider: provider,
+	}
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues(r.Provider.Kind, req.NamespacedName)
+	logger.Info(fmt.Sprintf("Start %s reconciliation", r.Provider.Kind))
+	// the provider functions log with the logger of the context
+	ctx = logr.NewContext(ctx, logger)
+
+	config := r.Provider.NewConfig()
+	if err := r.Get(ctx, req.NamespacedName, config); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info(fmt.Sprintf("%s resource not found", r.Provider.Kind))
+			return ctrl.Result{}, nil
+		}
+
+		logger.Error(err, fmt.Sprintf("Unable to fetch %s resource", r.Provider.Kind))
+		return ctrl.Result{}, err
+	}
+
+	if _, ok := config.GetAnnotations()[constants.TKGAnnotationTemplateConfig]; ok {
+		logger.Info(fmt.Sprintf("resource '%v' is a config template. Skipping reconciling", req.NamespacedName))
+		return ctrl.Result{}, nil
+	}
+
+	// deep copy the config to avoid issues if in the future other controllers where interacting with the same copy
+	config = config.DeepCopyObject().(client.Object)
+	cluster, err := r.getOwnerCluster(ctx, config, logger)
+	if cluster == nil {
+		return ctrl.Result{}, err // no need to requeue if cluster is not found
+	}
+
+	if r.Provider.Skip != nil && r.Provider.Skip(config) {
+		logger.Info(fmt.Sprintf("%s is not ready to be reconciled", r.Provider.Kind))
+		return ctrl.Result{}, nil // no need to requeue until the config changes
+	}
+
+	return r.reconcileConfig(ctx, config, cluster, logger)
+}
+
+// getOwnerCluster returns the cluster owning the addon config, or nil when it is not found. The cluster is read from
+// the owner references of the addon config, or has the name of the addon config unless the provider requires it to be
+// listed as owner.
+func (r *Reconciler) getOwnerCluster(ctx context.Context, config client.Object, logger logr.Logger) (*clusterapiv1beta1.Cluster, error) {
+	clusterName := ""
+	for _, ownerRef := range config.GetOwnerReferences() {
+		if strings.EqualFold(ownerRef.Kind, constants.ClusterKind) {
+			clusterName = ownerRef.Name
+			break
+		}
+	}
+	if clusterName == "" {
+		if r.Provider.OwnerClusterRequired {
+			err := errors.Errorf("no owner cluster could be determined for %s/%s", config.GetNamespace(), config.GetName())
+			logger.Error(err, "could not determine owner cluster")
+			return nil, err
+		}
+		// usually the corresponding cluster shares the same name
+		clusterName = config.GetName()
+	}
+
+	cluster := &clusterapiv1beta1.Cluster{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: config.GetNamespace(), Name: clusterName}, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info(fmt.Sprintf("Cluster resource '%s/%s' not found", config.GetNamespace(), clusterName))
+			return nil, nil
+		}
+		logger.Error(err, fmt.Sprintf("Unable to fetch cluster '%s/%s'", config.GetNamespace(), clusterName))
+		return nil, err
+	}
+	return cluster, nil
+}
+
+func (r *Reconciler) reconcileConfig(ctx context.Context, config client.Object, cluster *clusterapiv1beta1.Cluster,
+	logger logr.Logger) (_ ctrl.Result, retErr error) {
+
+	patchHelper, err := clusterapipatchutil.NewHelper(config, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	defer func() {
+		if retErr != nil {
+			// don't modify the config if there is an error
+			return
+		}
+
+		if err := patchHelper.Patch(ctx, config); err != nil {
+			logger.Error(err, fmt.Sprintf("Error patching %s", r.Provider.Kind))
+			retErr = err
+		}
+	}()
+
+	if !config.GetDeletionTimestamp().IsZero() {
+		return ctrl.Result{}, nil // deleted
+	}
+
+	if err := r.reconcileConfigNormal(ctx, config, cluster, logger); err != nil {
+		logger.Error(err, fmt.Sprintf("Error reconciling %s", r.Provider.Kind))
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// reconcileConfigNormal ensures the owner reference of the addon config, generates the data values secret of its
+// addon and reconciles the other resources of the addon
+func (r *Reconciler) reconcileConfigNormal(ctx context.Context, config client.Object, cluster *clusterapiv1beta1.Cluster,
+	logger logr.Logger) error {
+
+	ownerRef := metav1.OwnerReference{
+		APIVersion: clusterapiv1beta1.GroupVersion.String(),
+		Kind:       cluster.Kind,
+		Name:       cluster.Name,
+		UID:        cluster.UID,
+	}
+
+	if !clusterapiutil.HasOwnerRef(config.GetOwnerReferences(), ownerRef) {
+		// the config object is patched in defer func in 'reconcileConfig'
+		config.SetOwnerReferences(clusterapiutil.EnsureOwnerRef(config.GetOwnerReferences(), ownerRef))
+	}
+
+	addonName, err := r.Provider.addonName(config)
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("Unable to determine the addon of %s", r.Provider.Kind))
+		return err
+	}
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      util.GenerateDataValueSecretName(cluster.Name, addonName),
+			Namespace: config.GetNamespace()},
+		Type: v1.SecretTypeOpaque,
+	}
+
+	mutateFn := func() error {
+		secret.StringData = make(map[string]string)
+		dvs, err := r.Provider.DataValues(ctx, r.Client, config, cluster)
+		if err != nil {
+			logger.Error(err, fmt.Sprintf("Error while mapping %s to data values", r.Provider.Kind))
+			return err
+		}
+		yamlBytes, err := marshalDataValues(dvs)
+		if err != nil {
+			logger.Error(err, fmt.Sprintf("Error marshaling %s data values to yaml", r.Provider.Kind))
+			return err
+		}
+		secret.StringData[constants.TKGDataValueFileName] = string(yamlBytes)
+		return nil
+	}
+
+	secret.SetOwnerReferences([]metav1.OwnerReference{ownerRef})
+
+	if _, err := controllerutil.CreateOrPatch(ctx, r.Client, secret, mutateFn); err != nil {
+		logger.Error(err, fmt.Sprintf("Error creating or patching %s data values secret", r.Provider.Kind))
+		return err
+	}
+
+	if r.Provider.ReconcileResources != nil {
+		if err := r.Provider.ReconcileResources(ctx, r.Client, r.Scheme, config, cluster); err != nil {
+			logger.Error(err, fmt.Sprintf("Error reconciling the resources of %s", r.Provider.Kind))
+			return err
+		}
+	}
+
+	r.Provider.SetSecretRef(config, secret.Name)
+	return nil
+}
+
+// clusterToConfigs returns the requests of the addon configs owned by the cluster
+func (r *Reconciler) clusterToConfigs(o client.Object) []ctrl.Request {
+	cluster, ok := o.(*clusterapiv1beta1.Cluster)
+	if !ok {
+		r.Log.Error(errors.New("invalid type"),
+			"Expected to receive Cluster resource",
+			"actualType", fmt.Sprintf("%T", o))
+		return nil
+	}
+
+	r.Log.V(4).Info(fmt.Sprintf("Mapping Cluster to %s", r.Provider.Kind))
+
+	list := r.Provider.NewConfigList()
+	if err := r.List(context.Background(), list, client.InNamespace(cluster.Namespace)); err != nil {
+		r.Log.Error(err, fmt.Sprintf("Unable to list %s resources", r.Provider.Kind))
+		return nil
+	}
+	configs, err := meta.ExtractList(list)
+	if err != nil {
+		r.Log.Error(err, fmt.Sprintf("Unable to extract %s resources", r.Provider.Kind))
+		return nil
+	}
+
+	// the corresponding configs have the following ownerRef
+	ownerReference := metav1.OwnerReference{
+		APIVersion: clusterapiv1beta1.GroupVersion.String(),
+		Kind:       cluster.Kind,
+		Name:       cluster.Name,
+		UID:        cluster.UID,
+	}
+
+	var requests []ctrl.Request
+	for _, obj := range configs {
+		config, ok := obj.(client.Object)
+		if !ok {
+			continue
+		}
+		// avoid enqueuing reconcile requests for template configs in event handler of Cluster CR
+		if _, ok := config.GetAnnotations()[constants.TKGAnnotationTemplateConfig]; ok && config.GetNamespace() == r.Config.SystemNamespace {
+			continue
+		}
+		if clusterapiutil.HasOwnerRef(config.GetOwnerReferences(), ownerReference) {
+			r.Log.V(4).Info(fmt.Sprintf("Adding %s for reconciliation", r.Provider.Kind),
+				constants.NamespaceLogKey, config.GetNamespace(), constants.NameLogKey, config.GetName())
+			requests = append(requests, ctrl.Request{NamespacedName: clusterapiutil.ObjectKey(config)})
+		}
+	}
+	return requests
+}
+
+// watchToConfigs returns the requests of the addon configs to reconcile on the events of the resources of a watch
+func (r *Reconciler) watchToConfigs(watch Watch) handler.MapFunc {
+	return func(o client.Object) []ctrl.Request {
+		var requests []ctrl.Request
+		for _, config := range watch.ToConfigs(context.Background(), r.Client, o) {
+			// avoid enqueuing reconcile requests for template configs in event handler of the watched resources
+			if _, ok := config.GetAnnotations()[constants.TKGAnnotationTemplateConfig]; ok && config.GetNamespace() == r.Config.SystemNamespace {
+				continue
+			}
+			requests = append(requests, ctrl.Request{NamespacedName: clusterapiutil.ObjectKey(config)})
+		}
+		return requests
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(_ context.Context, mgr ctrl.Manager, options controller.Options) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(r.Provider.NewConfig()).
+		WithOptions(options).
+		WithEventFilter(predicates.ConfigOfKindWithoutAnnotation(constants.TKGAnnotationTemplateConfig, r.Provider.Kind, r.Config.SystemNamespace, r.Log))
+	if r.Provider.NewConfigList != nil {
+		b = b.Watches(
+			&source.Kind{Type: &clusterapiv1beta1.Cluster{}},
+			handler.EnqueueRequestsFromMapFunc(r.clusterToConfigs),
+		)
+	}
+	for _, watch := range r.Provider.Watches {
+		b = b.Watches(
+			&source.Kind{Type: watch.Object},
+			handler.EnqueueRequestsFromMapFunc(r.watchToConfigs(watch)),
+			builder.WithPredicates(watch.Predicates...),
+		)
+	}
+	return b.Complete(r)
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package genericconfig
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	yttui "github.com/vmware-tanzu/carvel-ytt/pkg/cmd/ui"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/files"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/workspace"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/workspace/datavalues"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// marshalDataValues returns the YAML of the data values of an addon package
+func marshalDataValues(dataValues interface{}) ([]byte, error) {
+	if yamlBytes, ok := dataValues.([]byte); ok {
+		return yamlBytes, nil
+	}
+	return yaml.Marshal(dataValues)
+}
+
+// YttDataValues returns a DataValuesFunc rendering the data values of an addon package with ytt from the templates, keyed
+// by file name. The addon config and its cluster, in their JSON representation, are the config and cluster data values
+// of the templates, e.g. #@ data.values.config.spec.namespace. The templates must render a single YAML object.
+func YttDataValues(templates map[string]string) (DataValuesFunc, error) {
+	if len(templates) == 0 {
+		return nil, errors.New("no data values template")
+	}
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return func(_ context.Context, _ client.Client, config client.Object, cluster *clusterapiv1beta1.Cluster) (interface{}, error) {
+		configContent, clusterContent, err := toUnstructured(config, cluster)
+		if err != nil {
+			return nil, err
+		}
+		// JSON is valid YAML, the data values file needs no marshaling to YAML
+		values, err := json.Marshal(map[string]interface{}{"config": configContent, "cluster": clusterContent})
+		if err != nil {
+			return nil, err
+		}
+
+		yttFiles := []*files.File{}
+		for _, name := range names {
+			file, err := files.NewFileFromSource(files.NewBytesSource(name, []byte(templates[name])))
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid data values template %s", name)
+			}
+			yttFiles = append(yttFiles, file)
+		}
+		valuesFile, err := files.NewFileFromSource(files.NewBytesSource("values.yaml", append([]byte("#@data/values\n---\n"), values...)))
+		if err != nil {
+			return nil, err
+		}
+		yttFiles = append(yttFiles, valuesFile)
+
+		lib := workspace.NewRootLibrary(yttFiles)
+		libCtx := workspace.LibraryExecutionContext{Current: lib, Root: lib}
+		loader := workspace.NewLibraryExecutionFactory(noopUI{}, workspace.TemplateLoaderOpts{}).New(libCtx)
+		valuesDoc, libraryValuesDocs, err := loader.Values([]*datavalues.Envelope{}, datavalues.NewNullSchema())
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to load the data values of the data values templates")
+		}
+		result, err := loader.Eval(valuesDoc, libraryValuesDocs, []*datavalues.SchemaEnvelope{})
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to render the data values templates")
+		}
+		yamlBytes, err := result.DocSet.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(yamlBytes, &map[string]interface{}{}); err != nil {
+			return nil, errors.Wrap(err, "data values templates did not render to a YAML object")
+		}
+		return yamlBytes, nil
+	}, nil
+}
+
+// toUnstructured returns the JSON representation of the addon config and its cluster
+func toUnstructured(config client.Object, cluster *clusterapiv1beta1.Cluster) (map[string]interface{}, map[string]interface{}, error) {
+	configContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	clusterContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	return configContent, clusterContent, nil
+}
+
+// noopUI discards the output of ytt
+type noopUI struct{}
+
+var _ yttui.UI = noopUI{}
+
+func (noopUI) Printf(string, ...interface{}) {}
+
+func (noopUI) Debugf(string, ...interface{}) {}
+
+func (noopUI) Warnf(string, ...interface{}) {}
+
+func (noopUI) DebugWriter() io.Writer {
+	return io.Discard
+}
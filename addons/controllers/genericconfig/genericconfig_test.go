@@ -0,0 +1,242 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package genericconfig
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonconfig "github.com/vmware-tanzu/tanzu-framework/addons/pkg/config"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+	csiv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/addonconfigs/csi/v1alpha1"
+)
+
+const yttDataValuesTemplate = `#@ load("@ytt:data", "data")
+awsEBSCSIDriver:
+  namespace: #@ data.values.config.spec.awsEBSCSIDriver.namespace
+  cluster: #@ data.values.cluster.metadata.name
+`
+
+func newTestProvider(dataValues DataValuesFunc) *Provider {
+	return &Provider{
+		Kind:      constants.AwsEbsCSIConfigKind,
+		AddonName: constants.AwsEbsCSIAddonName,
+		NewConfig: func() client.Object {
+			return &csiv1alpha1.AwsEbsCSIConfig{}
+		},
+		DataValues: dataValues,
+		SetSecretRef: func(config client.Object, secretName string) {
+			config.(*csiv1alpha1.AwsEbsCSIConfig).Status.SecretRef = &secretName
+		},
+	}
+}
+
+var _ = Describe("Generic config controller", func() {
+	Context("Register()", func() {
+		It("should only register complete providers once", func() {
+			provider := newTestProvider(func(context.Context, client.Client, client.Object, *clusterapiv1beta1.Cluster) (interface{}, error) {
+				return nil, nil
+			})
+			provider.Kind = "FooConfig"
+			provider.SetSecretRef = nil
+			Expect(Register(provider)).NotTo(Succeed())
+
+			provider.SetSecretRef = func(client.Object, string) {}
+			Expect(Register(provider)).To(Succeed())
+			Expect(Providers()).To(ContainElement(provider))
+			Expect(Register(provider)).NotTo(Succeed())
+		})
+
+		It("should return the errors of the provider constructors", func() {
+			err := RegisterProviders(func() (*Provider, error) {
+				return nil, errors.New("invalid template")
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid template"))
+
+			provider := newTestProvider(nil)
+			provider.Kind = "BarConfig"
+			Expect(RegisterProviders(func() (*Provider, error) { return provider, nil })).NotTo(Succeed())
+		})
+	})
+
+	Context("YttDataValues()", func() {
+		cluster := &clusterapiv1beta1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: "default"}}
+
+		It("should render the data values from the addon config and its cluster with ytt", func() {
+			dataValues, err := YttDataValues(map[string]string{"values.star.yaml": yttDataValuesTemplate})
+			Expect(err).NotTo(HaveOccurred())
+
+			config := &csiv1alpha1.AwsEbsCSIConfig{}
+			config.Spec.AwsEbsCSI.Namespace = "csi"
+			dvs, err := dataValues(context.Background(), nil, config, cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(dvs.([]byte))).To(Equal("awsEBSCSIDriver:\n  namespace: csi\n  cluster: cluster1\n"))
+		})
+
+		It("should fail when the templates do not render to a YAML object", func() {
+			_, err := YttDataValues(nil)
+			Expect(err).To(HaveOccurred())
+
+			dataValues, err := YttDataValues(map[string]string{"values.yaml": "#@ data.values.foo.bar"})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = dataValues(context.Background(), nil, &csiv1alpha1.AwsEbsCSIConfig{}, cluster)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Reconcile()", func() {
+		var (
+			scheme     *runtime.Scheme
+			cluster    *clusterapiv1beta1.Cluster
+			config     *csiv1alpha1.AwsEbsCSIConfig
+			reconciler *Reconciler
+		)
+
+		BeforeEach(func() {
+			scheme = runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(clusterapiv1beta1.AddToScheme(scheme)).To(Succeed())
+			Expect(csiv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			cluster = &clusterapiv1beta1.Cluster{
+				TypeMeta:   metav1.TypeMeta{Kind: constants.ClusterKind},
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: "default", UID: "uid1"},
+			}
+			config = &csiv1alpha1.AwsEbsCSIConfig{ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: "default"}}
+			dataValues, err := YttDataValues(map[string]string{"values.star.yaml": yttDataValuesTemplate})
+			Expect(err).NotTo(HaveOccurred())
+			reconciler = NewReconciler(nil, ctrl.Log, scheme, addonconfig.ConfigControllerConfig{SystemNamespace: constants.TKGSystemNS},
+				newTestProvider(dataValues))
+		})
+
+		It("should generate the data values secret of the cluster owning the addon config", func() {
+			reconciler.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, config).Build()
+			_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+			Expect(err).NotTo(HaveOccurred())
+
+			secret := &v1.Secret{}
+			Expect(reconciler.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "cluster1-aws-ebs-csi-data-values"}, secret)).To(Succeed())
+			// the fake client does not merge stringData into data like the API server
+			Expect(secret.StringData[constants.TKGDataValueFileName]).To(ContainSubstring("cluster: cluster1"))
+			Expect(secret.OwnerReferences).To(HaveLen(1))
+
+			Expect(reconciler.Get(context.Background(), client.ObjectKeyFromObject(config), config)).To(Succeed())
+			Expect(config.OwnerReferences).To(HaveLen(1))
+			Expect(config.OwnerReferences[0].Name).To(Equal("cluster1"))
+			Expect(*config.Status.SecretRef).To(Equal(secret.Name))
+		})
+
+		It("should wait for the owner cluster when the provider requires it", func() {
+			reconciler.Provider.OwnerClusterRequired = true
+			reconciler.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, config).Build()
+			_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+			Expect(err).To(HaveOccurred())
+
+			secret := &v1.Secret{}
+			err = reconciler.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "cluster1-aws-ebs-csi-data-values"}, secret)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should use the addon name, resources and skip hooks of the provider", func() {
+			var reconciledCluster string
+			reconciler.Provider.AddonName = ""
+			reconciler.Provider.AddonNameFor = func(client.Object) (string, error) {
+				return "foo", nil
+			}
+			reconciler.Provider.ReconcileResources = func(_ context.Context, _ client.Client, _ *runtime.Scheme, _ client.Object, cluster *clusterapiv1beta1.Cluster) error {
+				reconciledCluster = cluster.Name
+				return nil
+			}
+			reconciler.Provider.Skip = func(config client.Object) bool {
+				return config.(*csiv1alpha1.AwsEbsCSIConfig).Spec.AwsEbsCSI.Namespace == ""
+			}
+			reconciler.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, config).Build()
+			_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reconciledCluster).To(BeEmpty())
+
+			Expect(reconciler.Get(context.Background(), client.ObjectKeyFromObject(config), config)).To(Succeed())
+			config.Spec.AwsEbsCSI.Namespace = "csi"
+			Expect(reconciler.Update(context.Background(), config)).To(Succeed())
+			_, err = reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reconciledCluster).To(Equal("cluster1"))
+			Expect(reconciler.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "cluster1-foo-data-values"}, &v1.Secret{})).To(Succeed())
+		})
+
+		It("should not fail when the cluster does not exist", func() {
+			reconciler.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(config).Build()
+			_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("Webhook", func() {
+		It("should default and validate the addon configs with the provider hooks", func() {
+			provider := newTestProvider(nil)
+			provider.Default = func(config client.Object) {
+				config.(*csiv1alpha1.AwsEbsCSIConfig).Spec.AwsEbsCSI.Namespace = "kube-system"
+			}
+			provider.Validate = func(config, old client.Object) error {
+				if old != nil && old.(*csiv1alpha1.AwsEbsCSIConfig).Spec.AwsEbsCSI.Namespace != config.(*csiv1alpha1.AwsEbsCSIConfig).Spec.AwsEbsCSI.Namespace {
+					return errors.New("namespace is immutable")
+				}
+				return nil
+			}
+			Expect(provider.HasWebhook()).To(BeTrue())
+			wh := &Webhook{Provider: provider}
+
+			config := &csiv1alpha1.AwsEbsCSIConfig{}
+			Expect(wh.Default(context.Background(), config)).To(Succeed())
+			Expect(config.Spec.AwsEbsCSI.Namespace).To(Equal("kube-system"))
+			Expect(wh.ValidateCreate(context.Background(), config)).To(Succeed())
+
+			updated := config.DeepCopy()
+			updated.Spec.AwsEbsCSI.Namespace = "csi"
+			Expect(wh.ValidateUpdate(context.Background(), config, updated)).NotTo(Succeed())
+			Expect(wh.ValidateDelete(context.Background(), config)).To(Succeed())
+		})
+
+		It("should validate the spec of the addon configs against the schema of the provider", func() {
+			provider := newTestProvider(nil)
+			provider.SpecSchema = &apiextensionsv1.JSONSchemaProps{
+				Type:     "object",
+				Required: []string{"awsEBSCSIDriver"},
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"awsEBSCSIDriver": {
+						Type: "object",
+						Properties: map[string]apiextensionsv1.JSONSchemaProps{
+							"namespace": {Type: "string", Pattern: "^[a-z-]+$"},
+						},
+					},
+				},
+			}
+			Expect(provider.HasWebhook()).To(BeTrue())
+			wh := &Webhook{Provider: provider}
+
+			config := &csiv1alpha1.AwsEbsCSIConfig{}
+			config.Spec.AwsEbsCSI.Namespace = "kube-system"
+			Expect(wh.ValidateCreate(context.Background(), config)).To(Succeed())
+
+			config.Spec.AwsEbsCSI.Namespace = "Kube_System"
+			err := wh.ValidateCreate(context.Background(), config)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.awsEBSCSIDriver.namespace"))
+		})
+	})
+})
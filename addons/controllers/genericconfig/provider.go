@@ -0,0 +1,153 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package genericconfig implements the config controller shared by the addon config CRDs. An addon config CRD is
+// registered with a Provider, which maps its objects to the data values of the addon package, and is then reconciled
+// into the data values secret of the addon for the cluster owning it.
+package genericconfig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// DataValuesFunc maps an addon config of a cluster to the data values of the addon package. The data values are
+// either the rendered YAML as []byte, or a value marshaled to YAML.
+type DataValuesFunc func(ctx context.Context, c client.Client, config client.Object, cluster *clusterapiv1beta1.Cluster) (interface{}, error)
+
+// Provider describes an addon config CRD reconciled by the generic config controller
+type Provider struct {
+	// Kind is the kind of the addon config CRD, e.g. AwsEbsCSIConfig
+	Kind string
+	// AddonName is the name of the addon whose data values secret is generated from the addon configs
+	AddonName string
+	// AddonNameFor returns the name of the addon of an addon config, when it depends on the addon config. It is
+	// optional and overrides AddonName.
+	AddonNameFor func(config client.Object) (string, error)
+	// NewConfig returns an empty addon config object
+	NewConfig func() client.Object
+	// NewConfigList returns an empty list of addon config objects. When set, the addon configs are also reconciled
+	// on the events of the clusters owning them.
+	NewConfigList func() client.ObjectList
+	// OwnerClusterRequired is set when the addon configs are only reconciled once a cluster is listed in their owner
+	// references. Otherwise, the cluster with the name of the addon config owns it.
+	OwnerClusterRequired bool
+	// DataValues maps an addon config to the data values of the addon package
+	DataValues DataValuesFunc
+	// SetSecretRef records the name of the data values secret in the status of an addon config
+	SetSecretRef func(config client.Object, secretName string)
+	// Skip returns whether an addon config is not reconciled, e.g. while it misses a required value. It is optional.
+	Skip func(config client.Object) bool
+	// ReconcileResources creates or patches the resources the addon needs besides its data values secret, e.g. the
+	// ProviderServiceAccount of a paravirtual cluster. It is optional.
+	ReconcileResources func(ctx context.Context, c client.Client, scheme *runtime.Scheme, config client.Object, cluster *clusterapiv1beta1.Cluster) error
+	// Watches are the resources other than the clusters whose events trigger the reconciliation of addon configs. They
+	// are optional.
+	Watches []Watch
+	// Default sets the default values of an addon config on admission. It is optional.
+	Default func(config client.Object)
+	// Validate validates an addon config on admission. The old addon config is nil on creation. It is optional.
+	Validate func(config, old client.Object) error
+	// SpecSchema is the OpenAPI v3 schema which the spec of an addon config is validated against on admission, e.g.
+	// the values schema of the addon package. It is optional.
+	SpecSchema *apiextensionsv1.JSONSchemaProps
+}
+
+// Watch describes the resources whose events trigger the reconciliation of addon configs
+type Watch struct {
+	// Object is an empty object of the kind of the watched resources
+	Object client.Object
+	// Predicates filter the events of the watched resources. They are optional.
+	Predicates []predicate.Predicate
+	// ToConfigs returns the addon configs to reconcile on the event of a watched resource. The template configs are
+	// never reconciled.
+	ToConfigs func(ctx context.Context, c client.Client, obj client.Object) []client.Object
+}
+
+var (
+	providersLock sync.Mutex
+	providers     = map[string]*Provider{}
+)
+
+// Register registers the provider of an addon config CRD with the generic config controller
+func Register(provider *Provider) error {
+	switch {
+	case provider.Kind == "":
+		return fmt.Errorf("addon config provider has no kind")
+	case provider.AddonName == "" && provider.AddonNameFor == nil:
+		return fmt.Errorf("addon config provider for %s has no addon name", provider.Kind)
+	case provider.NewConfig == nil:
+		return fmt.Errorf("addon config provider for %s has no NewConfig function", provider.Kind)
+	case provider.DataValues == nil:
+		return fmt.Errorf("addon config provider for %s has no DataValues function", provider.Kind)
+	case provider.SetSecretRef == nil:
+		return fmt.Errorf("addon config provider for %s has no SetSecretRef function", provider.Kind)
+	}
+
+	for _, watch := range provider.Watches {
+		if watch.Object == nil || watch.ToConfigs == nil {
+			return fmt.Errorf("addon config provider for %s has a watch without object or ToConfigs function", provider.Kind)
+		}
+	}
+
+	providersLock.Lock()
+	defer providersLock.Unlock()
+	if _, ok := providers[provider.Kind]; ok {
+		return fmt.Errorf("addon config provider for %s is already registered", provider.Kind)
+	}
+	providers[provider.Kind] = provider
+	return nil
+}
+
+// RegisterProviders creates the providers with the given constructors and registers them
+func RegisterProviders(newProviders ...func() (*Provider, error)) error {
+	for _, newProvider := range newProviders {
+		provider, err := newProvider()
+		if err != nil {
+			return fmt.Errorf("unable to create addon config provider: %w", err)
+		}
+		if err := Register(provider); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Providers returns the registered providers sorted by kind
+func Providers() []*Provider {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+	result := make([]*Provider, 0, len(providers))
+	for _, provider := range providers {
+		result = append(result, provider)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Kind < result[j].Kind
+	})
+	return result
+}
+
+// HasWebhook returns whether the addon configs of the provider are defaulted or validated on admission
+func (p *Provider) HasWebhook() bool {
+	return p.Default != nil || p.hasValidator()
+}
+
+func (p *Provider) hasValidator() bool {
+	return p.Validate != nil || p.SpecSchema != nil
+}
+
+// addonName returns the name of the addon of an addon config
+func (p *Provider) addonName(config client.Object) (string, error) {
+	if p.AddonNameFor != nil {
+		return p.AddonNameFor(config)
+	}
+	return p.AddonName, nil
+}
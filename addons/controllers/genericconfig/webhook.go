@@ -0,0 +1,129 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package genericconfig
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// Webhook implements the defaulting and validating webhooks of the addon configs of a Provider
+type Webhook struct {
+	Provider *Provider
+}
+
+var _ webhook.CustomDefaulter = &Webhook{}
+var _ webhook.CustomValidator = &Webhook{}
+
+// SetupWebhookWithManager sets up the webhooks of the addon configs with the Manager. Only the webhooks of the hooks set
+// by the provider are served.
+func (wh *Webhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewWebhookManagedBy(mgr).For(wh.Provider.NewConfig())
+	if wh.Provider.Default != nil {
+		b = b.WithDefaulter(wh)
+	}
+	if wh.Provider.hasValidator() {
+		b = b.WithValidator(wh)
+	}
+	return b.Complete()
+}
+
+// Default implements webhook.CustomDefaulter
+func (wh *Webhook) Default(_ context.Context, obj runtime.Object) error {
+	config, err := wh.toConfig(obj)
+	if err != nil {
+		return err
+	}
+	wh.Provider.Default(config)
+	return nil
+}
+
+// ValidateCreate implements webhook.CustomValidator
+func (wh *Webhook) ValidateCreate(_ context.Context, obj runtime.Object) error {
+	config, err := wh.toConfig(obj)
+	if err != nil {
+		return err
+	}
+	return wh.validate(config, nil)
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (wh *Webhook) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) error {
+	config, err := wh.toConfig(newObj)
+	if err != nil {
+		return err
+	}
+	old, err := wh.toConfig(oldObj)
+	if err != nil {
+		return err
+	}
+	return wh.validate(config, old)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (wh *Webhook) ValidateDelete(_ context.Context, _ runtime.Object) error {
+	// No validation required for the deletion of addon configs
+	return nil
+}
+
+// validate validates the spec of the addon config against the schema of the provider, then with its Validate hook
+func (wh *Webhook) validate(config, old client.Object) error {
+	if wh.Provider.SpecSchema != nil {
+		if err := validateSpec(config, wh.Provider.SpecSchema); err != nil {
+			return err
+		}
+	}
+	if wh.Provider.Validate == nil {
+		return nil
+	}
+	return wh.Provider.Validate(config, old)
+}
+
+// validateSpec validates the spec of the addon config against an OpenAPI v3 schema
+func validateSpec(config client.Object, schema *apiextensionsv1.JSONSchemaProps) error {
+	validator, err := newSchemaValidator(schema)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(config)
+	if err != nil {
+		return apierrors.NewBadRequest(err.Error())
+	}
+	spec, ok := content["spec"]
+	if !ok {
+		spec = map[string]interface{}{}
+	}
+	if errs := validation.ValidateCustomResource(field.NewPath("spec"), spec, validator); len(errs) != 0 {
+		return apierrors.NewInvalid(config.GetObjectKind().GroupVersionKind().GroupKind(), config.GetName(), errs)
+	}
+	return nil
+}
+
+func newSchemaValidator(schema *apiextensionsv1.JSONSchemaProps) (*validate.SchemaValidator, error) {
+	internalSchema := &apiextensions.JSONSchemaProps{}
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(schema, internalSchema, nil); err != nil {
+		return nil, err
+	}
+	validator, _, err := validation.NewSchemaValidator(&apiextensions.CustomResourceValidation{OpenAPIV3Schema: internalSchema})
+	return validator, err
+}
+
+func (wh *Webhook) toConfig(obj runtime.Object) (client.Object, error) {
+	config, ok := obj.(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("expected a %s but got a %T", wh.Provider.Kind, obj)
+	}
+	return config, nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package controllers implements the KubevipCPIConfig provider of the generic config controller.
+package controllers
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/tanzu-framework/addons/controllers/genericconfig"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+	kvcpiv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/addonconfigs/cpi/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=cpi.tanzu.vmware.com,resources=kubevipcpiconfigs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cpi.tanzu.vmware.com,resources=kubevipcpiconfigs/status,verbs=get;update;patch
+
+// NewKubevipCPIConfigProvider returns the provider of the KubevipCPIConfig CRD for the generic config controller
+func NewKubevipCPIConfigProvider() (*genericconfig.Provider, error) {
+	return &genericconfig.Provider{
+		Kind:      constants.KubevipCPIConfigKind,
+		AddonName: constants.KubevipCloudProviderAddonName,
+		NewConfig: func() client.Object {
+			return &kvcpiv1alpha1.KubevipCPIConfig{}
+		},
+		NewConfigList: func() client.ObjectList {
+			return &kvcpiv1alpha1.KubevipCPIConfigList{}
+		},
+		// the KubevipCPIConfig is owned by its cluster once linked to the ClusterBootstrap
+		OwnerClusterRequired: true,
+		DataValues:           mapKubevipCPIConfigToDataValues,
+		SetSecretRef: func(config client.Object, secretName string) {
+			config.(*kvcpiv1alpha1.KubevipCPIConfig).Status.SecretRef = &secretName
+		},
+	}, nil
+}
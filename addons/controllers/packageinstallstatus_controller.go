@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	kappctrlv1alpha1 "github.com/vmware-tanzu/carvel-kapp-controller/pkg/apis/kappctrl/v1alpha1"
 	kapppkgiv1alpha1 "github.com/vmware-tanzu/carvel-kapp-controller/pkg/apis/packaging/v1alpha1"
 	addonconfig "github.com/vmware-tanzu/tanzu-framework/addons/pkg/config"
 	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
@@ -221,10 +222,12 @@ func (r *PackageInstallStatusReconciler) reconcile(clusterClient client.Client,
 		clusterBootstrap.Spec.CSI,
 	}, clusterBootstrap.Spec.AdditionalPackages...)
 
+	var pkgRefNames []string
 	for _, pkg := range packages {
 		if pkg == nil {
 			continue
 		}
+		pkgRefNames = append(pkgRefNames, pkg.RefName)
 		if err := r.reconcileClusterBootstrapStatus(clusterClient, clusterBootstrap, clusterObjKey, pkg.RefName, r.Config.SystemNamespace, log); err != nil {
 			errorList = append(errorList, err)
 			// in case of error, just log the error and continue with collecting PackageInstallStatus for other packages
@@ -237,6 +240,7 @@ func (r *PackageInstallStatusReconciler) reconcile(clusterClient client.Client,
 	// kapp ctrl pkgi exists only for the workload cluster.
 	// it is installed under cluster.Namespace in the management cluster and should be handled separately
 	if clusterRole == clusterRoleWorkload && clusterBootstrap.Spec.Kapp != nil {
+		pkgRefNames = append(pkgRefNames, clusterBootstrap.Spec.Kapp.RefName)
 		if err := r.reconcileClusterBootstrapStatus(r.Client, clusterBootstrap, clusterObjKey, clusterBootstrap.Spec.Kapp.RefName, cluster.Namespace, log); err != nil {
 			errorList = append(errorList, err)
 			// in case of error, just log the error and proceed with patching the ClusterBootstrapStatus for all packages in a single patch operation
@@ -246,6 +250,8 @@ func (r *PackageInstallStatusReconciler) reconcile(clusterClient client.Client,
 		}
 	}
 
+	util.RemoveStalePackageStatuses(&clusterBootstrap.Status, pkgRefNames)
+
 	return retErr
 }
 
@@ -277,6 +283,9 @@ func (r *PackageInstallStatusReconciler) reconcileClusterBootstrapStatus(
 	objectKey := client.ObjectKey{Namespace: pkgiNamespace, Name: pkgiName}
 
 	if err := clusterClient.Get(r.ctx, objectKey, pkgi); err != nil {
+		if apierrors.IsNotFound(err) {
+			util.SetPackageStatus(&clusterBootstrap.Status, &runtanzuv1alpha3.PackageStatus{RefName: pkgName, State: runtanzuv1alpha3.PackageStatePending})
+		}
 		return errors.Wrapf(err, "unable to get PackageInstall '%s/%s'", pkgiNamespace, pkgiName)
 	}
 
@@ -289,6 +298,8 @@ func (r *PackageInstallStatusReconciler) reconcileClusterBootstrapStatus(
 		return nil
 	}
 
+	util.SetPackageStatus(&clusterBootstrap.Status, packageStatusFromPackageInstall(pkgName, pkgi, pkgiCondition))
+
 	// we populate 'Message' with Carvel's PackageInstall 'UsefulErrorMessage' field as it contains more detailed information in case of an error
 	title := cases.Title(language.Und)
 	// skip adding current timestamp as it frequently triggers downstream controller to go through the CB resource for no reason
@@ -321,6 +332,27 @@ func (r *PackageInstallStatusReconciler) reconcileClusterBootstrapStatus(
 	return nil
 }
 
+// packageStatusFromPackageInstall returns the rollout status of the package from its PackageInstall and its summary condition
+func packageStatusFromPackageInstall(pkgName string, pkgi *kapppkgiv1alpha1.PackageInstall, pkgiCondition *kappctrlv1alpha1.AppCondition) *runtanzuv1alpha3.PackageStatus {
+	pkgStatus := &runtanzuv1alpha3.PackageStatus{RefName: pkgName, State: string(pkgiCondition.Type)}
+	switch pkgiCondition.Type {
+	case kappctrlv1alpha1.ReconcileSucceeded:
+		// the version is only installed once the latest generation of the PackageInstall is reconciled, until then the
+		// condition is the one of the previous generation
+		if pkgi.Status.ObservedGeneration == pkgi.Generation {
+			pkgStatus.Version = pkgi.Status.Version
+		} else {
+			pkgStatus.State = string(kappctrlv1alpha1.Reconciling)
+		}
+	case kappctrlv1alpha1.ReconcileFailed, kappctrlv1alpha1.DeleteFailed:
+		pkgStatus.LastError = util.GetKappUsefulErrorMessage(pkgi.Status.UsefulErrorMessage)
+		if pkgStatus.LastError == "" {
+			pkgStatus.LastError = pkgiCondition.Message
+		}
+	}
+	return pkgStatus
+}
+
 // removeConditionIfExistsForPkgName removes the corresponding condition for the provided pkgRefName from the clusterBootstrapStatus if existing
 func (r *PackageInstallStatusReconciler) removeConditionIfExistsForPkgName(clusterBootstrap *runtanzuv1alpha3.ClusterBootstrap, pkgRefName string) {
 	for i, existingCond := range clusterBootstrap.Status.Conditions {
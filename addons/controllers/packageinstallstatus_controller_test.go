@@ -5,6 +5,7 @@ package controllers
 
 import (
 	"os"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -134,6 +135,16 @@ var _ = Describe("PackageInstallStatus Reconciler", func() {
 			Expect(len(wlcClusterBootstrapStatus.Conditions)).Should(Equal(2))
 			Expect(wlcClusterBootstrapStatus.Conditions[0].Type).Should(Equal(antreaCondType))
 			Expect(wlcClusterBootstrapStatus.Conditions[1].Type).Should(Equal(kappCondType))
+
+			By("verifying ClusterBootstrap 'Status.Packages' reports the state of managed packages")
+			packageStates := map[string]string{}
+			for _, pkgStatus := range wlcClusterBootstrapStatus.Packages {
+				packageStates[strings.Split(pkgStatus.RefName, ".")[0]] = pkgStatus.State
+				Expect(pkgStatus.LastTransitionTime.IsZero()).Should(BeFalse())
+			}
+			Expect(packageStates["antrea"]).Should(Equal(string(kappctrlv1alpha1.ReconcileSucceeded)))
+			Expect(packageStates["kapp-controller"]).Should(Equal(string(kappctrlv1alpha1.Reconciling)))
+			Expect(wlcClusterBootstrapStatus.ReconciledPackages).ShouldNot(BeEmpty())
 		})
 	})
 })
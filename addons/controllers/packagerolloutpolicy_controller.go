@@ -0,0 +1,253 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	clusterapipatchutil "sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/util"
+	runtanzuv1alpha3 "github.com/vmware-tanzu/tanzu-framework/apis/run/v1alpha3"
+)
+
+// PackageRolloutPolicyReconciler reconciles the PackageRolloutPolicy objects. It stages the package upgrades of the
+// selected clusters by approving the TKR the ClusterBootstrap of each cluster may be upgraded to, batch by batch.
+type PackageRolloutPolicyReconciler struct {
+	Client client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	ctx    context.Context
+}
+
+// NewPackageRolloutPolicyReconciler returns a reconciler for PackageRolloutPolicy
+func NewPackageRolloutPolicyReconciler(c client.Client, log logr.Logger, scheme *runtime.Scheme) *PackageRolloutPolicyReconciler {
+	return &PackageRolloutPolicyReconciler{
+		Client: c,
+		Log:    log,
+		Scheme: scheme,
+	}
+}
+
+// +kubebuilder:rbac:groups=run.tanzu.vmware.com,resources=packagerolloutpolicies,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=run.tanzu.vmware.com,resources=packagerolloutpolicies/status,verbs=get;update;patch
+
+// SetupWithManager sets up the controller with the Manager. The policies are reconciled on any change of the clusters
+// and of their ClusterBootstraps, as the selected clusters and their package statuses drive the rollout.
+func (r *PackageRolloutPolicyReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&runtanzuv1alpha3.PackageRolloutPolicy{}).
+		Watches(
+			&source.Kind{Type: &clusterapiv1beta1.Cluster{}},
+			handler.EnqueueRequestsFromMapFunc(r.toPackageRolloutPolicies),
+		).
+		Watches(
+			&source.Kind{Type: &runtanzuv1alpha3.ClusterBootstrap{}},
+			handler.EnqueueRequestsFromMapFunc(r.toPackageRolloutPolicies),
+		).
+		WithOptions(options).
+		Complete(r)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+	r.ctx = ctx
+	return nil
+}
+
+// Reconcile computes the rollout status of the clusters selected by the PackageRolloutPolicy, starts the upgrade of
+// the next batch of clusters and halts the rollout while the upgrade of a cluster is failed
+func (r *PackageRolloutPolicyReconciler) Reconcile(_ context.Context, req ctrl.Request) (_ ctrl.Result, retErr error) {
+	log := r.Log.WithValues("packagerolloutpolicy", req.Name)
+
+	policy := &runtanzuv1alpha3.PackageRolloutPolicy{}
+	if err := r.Client.Get(r.ctx, req.NamespacedName, policy); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("PackageRolloutPolicy not found")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, errors.Wrap(err, "unable to fetch PackageRolloutPolicy")
+	}
+	if !policy.GetDeletionTimestamp().IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	patchHelper, err := clusterapipatchutil.NewHelper(policy, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to init patch helper for PackageRolloutPolicy %s", policy.Name)
+	}
+	defer func() {
+		if err := patchHelper.Patch(r.ctx, policy); err != nil && retErr == nil {
+			retErr = errors.Wrapf(err, "failed to patch PackageRolloutPolicy %s", policy.Name)
+		}
+	}()
+
+	clusters, err := r.getRolloutClusters(policy)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	plan := util.PlanPackageRollout(policy, clusters, time.Now())
+	for i := range plan.Start {
+		if err := r.approveRollout(&plan.Start[i]); err != nil {
+			return ctrl.Result{}, err
+		}
+		log.Info(fmt.Sprintf("started the package upgrade of cluster %s/%s to TKR %s", plan.Start[i].Namespace, plan.Start[i].Name, plan.Start[i].TKR))
+	}
+	if len(plan.NewlyFailed) != 0 {
+		log.Info(fmt.Sprintf("halted the rollout as the package upgrade of %s failed", strings.Join(plan.NewlyFailed, ", ")))
+	}
+	reconcileRolloutPausedCondition(policy, plan)
+	setPackageRolloutPolicyStatus(policy, plan)
+
+	if policy.Status.UpgradingClusters != 0 {
+		// the soak duration and the progress deadline of the upgrading clusters elapse without any event
+		return ctrl.Result{RequeueAfter: constants.RequeueAfterDuration}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// getRolloutClusters returns the clusters selected by the PackageRolloutPolicy, along with their ClusterBootstraps
+func (r *PackageRolloutPolicyReconciler) getRolloutClusters(policy *runtanzuv1alpha3.PackageRolloutPolicy) ([]util.RolloutCluster, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.ClusterSelector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid cluster selector of PackageRolloutPolicy %s", policy.Name)
+	}
+	clusterList := &clusterapiv1beta1.ClusterList{}
+	if err := r.Client.List(r.ctx, clusterList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, errors.Wrap(err, "unable to list clusters")
+	}
+
+	var clusters []util.RolloutCluster
+	for i := range clusterList.Items {
+		cluster := &clusterList.Items[i]
+		tkrName := cluster.Labels[constants.TKRLabelClassyClusters]
+		if tkrName == "" || !cluster.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		rolloutCluster := util.RolloutCluster{Namespace: cluster.Namespace, Name: cluster.Name, TKR: tkrName}
+		clusterBootstrap := &runtanzuv1alpha3.ClusterBootstrap{}
+		if err := r.Client.Get(r.ctx, client.ObjectKeyFromObject(cluster), clusterBootstrap); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, errors.Wrapf(err, "unable to fetch ClusterBootstrap %s/%s", cluster.Namespace, cluster.Name)
+			}
+		} else {
+			rolloutCluster.ClusterBootstrap = clusterBootstrap
+		}
+		clusters = append(clusters, rolloutCluster)
+	}
+	return clusters, nil
+}
+
+// approveRollout annotates the ClusterBootstrap of the cluster with its TKR, and with the revision of its packages once
+// it is upgraded to the TKR, so that the ClusterBootstrap controller upgrades its packages
+func (r *PackageRolloutPolicyReconciler) approveRollout(cluster *util.RolloutCluster) error {
+	clusterBootstrap := cluster.ClusterBootstrap.DeepCopy()
+	if clusterBootstrap.Annotations == nil {
+		clusterBootstrap.Annotations = map[string]string{}
+	}
+	clusterBootstrap.Annotations[constants.RolloutApprovedTKRAnnotation] = cluster.TKR
+	if clusterBootstrap.Status.ResolvedTKR == cluster.TKR {
+		clusterBootstrap.Annotations[constants.RolloutApprovedPackagesAnnotation] = util.GetPackagesRevision(clusterBootstrap.Spec)
+	}
+	if err := r.Client.Patch(r.ctx, clusterBootstrap, client.MergeFrom(cluster.ClusterBootstrap)); err != nil {
+		return errors.Wrapf(err, "unable to approve the upgrade of ClusterBootstrap %s/%s", clusterBootstrap.Namespace, clusterBootstrap.Name)
+	}
+	return nil
+}
+
+// reconcileRolloutPausedCondition sets status.paused while the upgrade of a selected cluster is failed, and reflects
+// it and spec.paused in the RolloutPaused condition
+func reconcileRolloutPausedCondition(policy *runtanzuv1alpha3.PackageRolloutPolicy, plan *util.PackageRolloutPlan) {
+	policy.Status.Paused = len(plan.Failed) != 0
+	switch {
+	case policy.Status.Paused:
+		conditions.Set(policy, &clusterapiv1beta1.Condition{
+			Type:     runtanzuv1alpha3.ConditionRolloutPaused,
+			Status:   corev1.ConditionTrue,
+			Severity: clusterapiv1beta1.ConditionSeverityWarning,
+			Reason:   runtanzuv1alpha3.ReasonRolloutHalted,
+			Message:  fmt.Sprintf("the package upgrade of %s failed", strings.Join(plan.Failed, ", ")),
+		})
+	case policy.Spec.Paused:
+		conditions.Set(policy, &clusterapiv1beta1.Condition{
+			Type:     runtanzuv1alpha3.ConditionRolloutPaused,
+			Status:   corev1.ConditionTrue,
+			Severity: clusterapiv1beta1.ConditionSeverityInfo,
+			Reason:   runtanzuv1alpha3.ReasonRolloutPausedByUser,
+		})
+	default:
+		conditions.Delete(policy, runtanzuv1alpha3.ConditionRolloutPaused)
+	}
+}
+
+// setPackageRolloutPolicyStatus sets the rollout status of the clusters and the summary counts
+func setPackageRolloutPolicyStatus(policy *runtanzuv1alpha3.PackageRolloutPolicy, plan *util.PackageRolloutPlan) {
+	policy.Status.ObservedGeneration = policy.Generation
+	policy.Status.Clusters = plan.Clusters
+	policy.Status.SelectedClusters = int32(len(plan.Clusters))
+	policy.Status.ReadyClusters, policy.Status.UpgradingClusters, policy.Status.FailedClusters = 0, 0, 0
+	for _, clusterStatus := range plan.Clusters {
+		switch clusterStatus.State {
+		case runtanzuv1alpha3.ClusterRolloutStateReady:
+			policy.Status.ReadyClusters++
+		case runtanzuv1alpha3.ClusterRolloutStateUpgrading:
+			policy.Status.UpgradingClusters++
+		case runtanzuv1alpha3.ClusterRolloutStateFailed:
+			policy.Status.FailedClusters++
+		}
+	}
+}
+
+// toPackageRolloutPolicies maps a Cluster or ClusterBootstrap to all the PackageRolloutPolicies, as a label change of
+// a cluster may select or deselect it
+func (r *PackageRolloutPolicyReconciler) toPackageRolloutPolicies(o client.Object) []ctrl.Request {
+	policies := &runtanzuv1alpha3.PackageRolloutPolicyList{}
+	if err := r.Client.List(r.ctx, policies); err != nil {
+		r.Log.Error(err, "unable to list PackageRolloutPolicies")
+		return nil
+	}
+	requests := make([]ctrl.Request, 0, len(policies.Items))
+	for i := range policies.Items {
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&policies.Items[i])})
+	}
+	return requests
+}
+
+// clusterSelectedByPackageRolloutPolicy returns the PackageRolloutPolicy selecting the cluster, nil if there is none.
+// The PackageRolloutPolicy webhook rejects the policies whose cluster selectors overlap, so that at most one policy
+// selects a cluster.
+func clusterSelectedByPackageRolloutPolicy(ctx context.Context, c client.Client, cluster *clusterapiv1beta1.Cluster) (*runtanzuv1alpha3.PackageRolloutPolicy, error) {
+	policies := &runtanzuv1alpha3.PackageRolloutPolicyList{}
+	if err := c.List(ctx, policies); err != nil {
+		return nil, errors.Wrap(err, "unable to list PackageRolloutPolicies")
+	}
+	for i := range policies.Items {
+		selector, err := metav1.LabelSelectorAsSelector(&policies.Items[i].Spec.ClusterSelector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(cluster.Labels)) {
+			return &policies.Items[i], nil
+		}
+	}
+	return nil, nil
+}
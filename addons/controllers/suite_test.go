@@ -43,15 +43,16 @@ import (
 	kappctrl "github.com/vmware-tanzu/carvel-kapp-controller/pkg/apis/kappctrl/v1alpha1"
 	pkgiv1alpha1 "github.com/vmware-tanzu/carvel-kapp-controller/pkg/apis/packaging/v1alpha1"
 	kapppkgv1alpha1 "github.com/vmware-tanzu/carvel-kapp-controller/pkg/apiserver/apis/datapackaging/v1alpha1"
-	antrea "github.com/vmware-tanzu/tanzu-framework/addons/controllers/antrea"
-	awsebscsi "github.com/vmware-tanzu/tanzu-framework/addons/controllers/awsebscsi"
-	azurediskcsi "github.com/vmware-tanzu/tanzu-framework/addons/controllers/azurediskcsi"
-	azurefilecsi "github.com/vmware-tanzu/tanzu-framework/addons/controllers/azurefilecsi"
-	calico "github.com/vmware-tanzu/tanzu-framework/addons/controllers/calico"
-	cpi "github.com/vmware-tanzu/tanzu-framework/addons/controllers/cpi"
-	csi "github.com/vmware-tanzu/tanzu-framework/addons/controllers/csi"
+	antreacontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/antrea"
+	awsebscsicontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/awsebscsi"
+	azurediskcsicontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/azurediskcsi"
+	azurefilecsicontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/azurefilecsi"
+	calicocontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/calico"
+	cpicontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/cpi"
+	csicontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/csi"
+	"github.com/vmware-tanzu/tanzu-framework/addons/controllers/genericconfig"
 	kappcontroller "github.com/vmware-tanzu/tanzu-framework/addons/controllers/kapp-controller"
-	kubevipcpi "github.com/vmware-tanzu/tanzu-framework/addons/controllers/kubevipcpi"
+	kubevipcpicontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/kubevipcpi"
 	addonconfig "github.com/vmware-tanzu/tanzu-framework/addons/pkg/config"
 	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
 	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/crdwait"
@@ -293,60 +294,6 @@ var _ = BeforeSuite(func(done Done) {
 		},
 	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
 
-	Expect((&calico.CalicoConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("CalicoConfig"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.CalicoConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: constants.TKGSystemNS}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
-
-	Expect((&cpi.VSphereCPIConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("VSphereCPIConfig"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.VSphereCPIConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: constants.TKGSystemNS}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
-
-	Expect((&csi.VSphereCSIConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("VSphereCSIConfig"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.VSphereCSIConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: constants.TKGSystemNS}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
-
-	Expect((&azurefilecsi.AzureFileCSIConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("AzureFileCSIConfig"),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
-
-	Expect((&azurediskcsi.AzureDiskCSIConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("AzureFileCSIConfig"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.AzureDiskCSIConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: constants.TKGSystemNS}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
-
-	Expect((&cpi.OracleCPIConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("OracleCPIConfig"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.OracleCPIConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: constants.TKGSystemNS}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
-
-	Expect((&antrea.AntreaConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("AntreaConfig"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.AntreaConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: constants.TKGSystemNS}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
-
 	Expect((&kappcontroller.KappControllerConfigReconciler{
 		Client: mgr.GetClient(),
 		Log:    ctrl.Log.WithName("controllers").WithName("KappController"),
@@ -361,11 +308,32 @@ var _ = BeforeSuite(func(done Done) {
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
 
-	Expect((&awsebscsi.AwsEbsCSIConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("AwsEbsCSIConfig"),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
+	Expect(genericconfig.RegisterProviders(
+		antreacontrollers.NewAntreaConfigProvider,
+		awsebscsicontrollers.NewAwsEbsCSIConfigProvider,
+		azurediskcsicontrollers.NewAzureDiskCSIConfigProvider,
+		azurefilecsicontrollers.NewAzureFileCSIConfigProvider,
+		calicocontrollers.NewCalicoConfigProvider,
+		cpicontrollers.NewVSphereCPIConfigProvider,
+		cpicontrollers.NewOracleCPIConfigProvider,
+		csicontrollers.NewVSphereCSIConfigProvider,
+		kubevipcpicontrollers.NewKubevipCPIConfigProvider,
+	)).To(Succeed())
+	for _, provider := range genericconfig.Providers() {
+		Expect(genericconfig.NewReconciler(
+			mgr.GetClient(),
+			ctrl.Log.WithName("controllers").WithName(provider.Kind),
+			mgr.GetScheme(),
+			addonconfig.ConfigControllerConfig{SystemNamespace: constants.TKGSystemNS},
+			provider,
+		).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
+	}
+
+	// set up a ClusterCacheTracker to provide to the ClusterBootstrap and PackageInstallStatus controllers which require a connection to remote clusters
+	l := ctrl.Log.WithName("remote").WithName("ClusterCacheTracker")
+	tracker, err := capiremote.NewClusterCacheTracker(mgr, capiremote.ClusterCacheTrackerOptions{Log: &l})
+	Expect(err).Should(BeNil())
+	Expect(tracker).ShouldNot(BeNil())
 
 	bootstrapReconciler := NewClusterBootstrapReconciler(
 		mgr.GetClient(),
@@ -380,17 +348,10 @@ var _ = BeforeSuite(func(done Done) {
 			PkgiSyncPeriod:              constants.PackageInstallSyncPeriod,
 			ClusterDeleteTimeout:        time.Second * 10,
 		},
+		tracker,
 	)
 	Expect(bootstrapReconciler.SetupWithManager(context.Background(), mgr, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
 
-	Expect((&kubevipcpi.KubevipCPIConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("KubevipCPIConfig"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.KubevipCPIConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: constants.TKGSystemNS}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
-
 	Expect((&ClusterMetadataReconciler{
 		Client:  mgr.GetClient(),
 		Log:     ctrl.Log.WithName("controllers").WithName("ClusterMetadata"),
@@ -398,12 +359,6 @@ var _ = BeforeSuite(func(done Done) {
 		context: ctx,
 	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
 
-	// set up a ClusterCacheTracker to provide to PackageInstallStatus controller which requires a connection to remote clusters
-	l := ctrl.Log.WithName("remote").WithName("ClusterCacheTracker")
-	tracker, err := capiremote.NewClusterCacheTracker(mgr, capiremote.ClusterCacheTrackerOptions{Log: &l})
-	Expect(err).Should(BeNil())
-	Expect(tracker).ShouldNot(BeNil())
-
 	// set up CluterCacheReconciler to drops the accessor via deleteAccessor upon cluster deletion
 	Expect((&capiremote.ClusterCacheReconciler{
 		Client:  mgr.GetClient(),
@@ -37,15 +37,16 @@ import (
 	kapppkg "github.com/vmware-tanzu/carvel-kapp-controller/pkg/apis/packaging/v1alpha1"
 	kappdatapkg "github.com/vmware-tanzu/carvel-kapp-controller/pkg/apiserver/apis/datapackaging/v1alpha1"
 	"github.com/vmware-tanzu/tanzu-framework/addons/controllers"
-	antreacontroller "github.com/vmware-tanzu/tanzu-framework/addons/controllers/antrea"
-	awsebscsicontroller "github.com/vmware-tanzu/tanzu-framework/addons/controllers/awsebscsi"
-	azurediskcsicontroller "github.com/vmware-tanzu/tanzu-framework/addons/controllers/azurediskcsi"
-	azurefilecsicontroller "github.com/vmware-tanzu/tanzu-framework/addons/controllers/azurefilecsi"
-	calicocontroller "github.com/vmware-tanzu/tanzu-framework/addons/controllers/calico"
-	cpicontroller "github.com/vmware-tanzu/tanzu-framework/addons/controllers/cpi"
-	csicontroller "github.com/vmware-tanzu/tanzu-framework/addons/controllers/csi"
+	antreacontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/antrea"
+	awsebscsicontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/awsebscsi"
+	azurediskcsicontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/azurediskcsi"
+	azurefilecsicontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/azurefilecsi"
+	calicocontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/calico"
+	cpicontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/cpi"
+	csicontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/csi"
+	"github.com/vmware-tanzu/tanzu-framework/addons/controllers/genericconfig"
 	kappcontroller "github.com/vmware-tanzu/tanzu-framework/addons/controllers/kapp-controller"
-	kvcpicontroller "github.com/vmware-tanzu/tanzu-framework/addons/controllers/kubevipcpi"
+	kubevipcpicontrollers "github.com/vmware-tanzu/tanzu-framework/addons/controllers/kubevipcpi"
 	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/buildinfo"
 	addonconfig "github.com/vmware-tanzu/tanzu-framework/addons/pkg/config"
 	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
@@ -111,6 +112,8 @@ type addonFlags struct {
 	ipFamilyClusterVarName          string
 	featureGateClusterBootstrap     bool
 	featureGatePackageInstallStatus bool
+	featureGatePackageRolloutPolicy bool
+	featureGateRemoteDriftDetection bool
 	enablePprof                     bool
 	pprofBindAddress                string
 	tlsMinVersion                   string
@@ -155,6 +158,8 @@ func parseAddonFlags(addonFlags *addonFlags) {
 	flag.StringVar(&addonFlags.ipFamilyClusterVarName, "ip-family-cluster-var-name", constants.DefaultIPFamilyClusterClassVarName, "IP family setting cluster variable name")
 	flag.BoolVar(&addonFlags.featureGateClusterBootstrap, "feature-gate-cluster-bootstrap", false, "Feature gate to enable clusterbootstap and addonconfig controllers that rely on TKR v1alphav3")
 	flag.BoolVar(&addonFlags.featureGatePackageInstallStatus, "feature-gate-package-install-status", false, "Feature gate to enable packageinstallstatus controller")
+	flag.BoolVar(&addonFlags.featureGateRemoteDriftDetection, "feature-gate-remote-drift-detection", false, "Feature gate to enable the detection of out-of-band changes to the addon resources on the clusters by the clusterbootstrap controller")
+	flag.BoolVar(&addonFlags.featureGatePackageRolloutPolicy, "feature-gate-package-rollout-policy", false, "Feature gate to enable packagerolloutpolicy controller, it relies on the packageinstallstatus controller")
 	flag.BoolVar(&addonFlags.enablePprof, "enable-pprof", false, "Enable pprof web server")
 	flag.StringVar(&addonFlags.pprofBindAddress, "pprof-bind-addr", ":18318", "Bind address of pprof web server if enabled")
 	flag.StringVar(&addonFlags.tlsMinVersion, "tls-min-version", "1.2", "minimum TLS version in use by the webhook server. Recommended values are \"1.2\" and \"1.3\".")
@@ -172,6 +177,21 @@ func main() {
 
 	ctx := ctrl.SetupSignalHandler()
 
+	if err := genericconfig.RegisterProviders(
+		antreacontrollers.NewAntreaConfigProvider,
+		awsebscsicontrollers.NewAwsEbsCSIConfigProvider,
+		azurediskcsicontrollers.NewAzureDiskCSIConfigProvider,
+		azurefilecsicontrollers.NewAzureFileCSIConfigProvider,
+		calicocontrollers.NewCalicoConfigProvider,
+		cpicontrollers.NewVSphereCPIConfigProvider,
+		cpicontrollers.NewOracleCPIConfigProvider,
+		csicontrollers.NewVSphereCSIConfigProvider,
+		kubevipcpicontrollers.NewKubevipCPIConfigProvider,
+	); err != nil {
+		setupLog.Error(err, "unable to register addon config providers")
+		os.Exit(1)
+	}
+
 	if flags.enablePprof {
 		go func() {
 			setupLog.Info("[Warn]: pprof web server enabled", "bindAddress", flags.pprofBindAddress)
@@ -243,13 +263,28 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "Addon")
 		os.Exit(1)
 	}
+	// the controllers connecting to remote clusters share a ClusterCacheTracker
+	var tracker *capiremote.ClusterCacheTracker
+	driftDetection := flags.featureGateClusterBootstrap && flags.featureGateRemoteDriftDetection
+	if flags.featureGatePackageInstallStatus || driftDetection {
+		tracker = setupClusterCacheTracker(ctx, mgr)
+	}
+
 	if flags.featureGateClusterBootstrap {
-		enableClusterBootstrapAndConfigControllers(ctx, mgr, flags)
+		var driftTracker *capiremote.ClusterCacheTracker
+		if driftDetection {
+			driftTracker = tracker
+		}
+		enableClusterBootstrapAndConfigControllers(ctx, mgr, flags, driftTracker)
 		enableWebhooks(ctx, mgr, flags)
 	}
 
 	if flags.featureGatePackageInstallStatus {
-		enablePackageInstallStatusController(ctx, mgr, flags)
+		enablePackageInstallStatusController(ctx, mgr, flags, tracker)
+	}
+
+	if flags.featureGateClusterBootstrap && flags.featureGatePackageRolloutPolicy {
+		enablePackageRolloutPolicyController(ctx, mgr)
 	}
 
 	enableClusterMetadata(ctx, mgr)
@@ -286,28 +321,7 @@ func setupChecks(mgr ctrl.Manager) {
 	}
 }
 
-func enableClusterBootstrapAndConfigControllers(ctx context.Context, mgr ctrl.Manager, flags *addonFlags) {
-	if err := (&calicocontroller.CalicoConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("CalicoConfigController"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.CalicoConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: flags.addonNamespace}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1}); err != nil {
-		setupLog.Error(err, "unable to create CalicoConfigController", "controller", "calico")
-		os.Exit(1)
-	}
-
-	if err := (&antreacontroller.AntreaConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("AntreaConfigController"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.AntreaConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: flags.addonNamespace}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1}); err != nil {
-		setupLog.Error(err, "unable to create AntreaConfigController", "controller", "antrea")
-		os.Exit(1)
-	}
+func enableClusterBootstrapAndConfigControllers(ctx context.Context, mgr ctrl.Manager, flags *addonFlags, tracker *capiremote.ClusterCacheTracker) {
 	if err := (&kappcontroller.KappControllerConfigReconciler{
 		Client: mgr.GetClient(),
 		Log:    ctrl.Log.WithName("KappControllerConfig"),
@@ -318,80 +332,18 @@ func enableClusterBootstrapAndConfigControllers(ctx context.Context, mgr ctrl.Ma
 		setupLog.Error(err, "unable to create KappControllerConfig", "controller", "kapp")
 		os.Exit(1)
 	}
-	if err := (&cpicontroller.VSphereCPIConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("VSphereCPIConfig"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.VSphereCPIConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: flags.addonNamespace}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1}); err != nil {
-		setupLog.Error(err, "unable to create CPIConfigController", "controller", "vspherecpi")
-		os.Exit(1)
-	}
-	if err := (&cpicontroller.OracleCPIConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("OracleCPIConfig"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.OracleCPIConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: flags.addonNamespace}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1}); err != nil {
-		setupLog.Error(err, "unable to create CPIConfigController", "controller", "oraclecpi")
-		os.Exit(1)
-	}
-
-	if err := (&csicontroller.VSphereCSIConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("VSphereCSIConfig"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.VSphereCSIConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: flags.addonNamespace}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1}); err != nil {
-		setupLog.Error(err, "unable to create CSIConfigController", "controller", "vspherecsi")
-		os.Exit(1)
-	}
-
-	if err := (&awsebscsicontroller.AwsEbsCSIConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("AwsEbsCSIConfig"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.AwsEbsCSIConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: flags.addonNamespace}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1}); err != nil {
-		setupLog.Error(err, "unable to create AwsEbsCSIConfigController", "controller", "awsebscsi")
-		os.Exit(1)
-	}
 
-	if err := (&azurefilecsicontroller.AzureFileCSIConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("AzureFileCSIConfig"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.AzureFileCSIConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: flags.addonNamespace}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1}); err != nil {
-		setupLog.Error(err, "unable to create CSIConfigController", "controller", "azurefilecsi")
-		os.Exit(1)
-	}
-
-	if err := (&kvcpicontroller.KubevipCPIConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("KubevipCPIConfig"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.KubevipCPIConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: flags.addonNamespace}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1}); err != nil {
-		setupLog.Error(err, "unable to create KubevipCPIConfigController", "controller", "kubevipcloudprovider")
-		os.Exit(1)
-	}
-
-	if err := (&azurediskcsicontroller.AzureDiskCSIConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("AzureDiskCSIConfig"),
-		Scheme: mgr.GetScheme(),
-		Config: addonconfig.AzureDiskCSIConfigControllerConfig{
-			ConfigControllerConfig: addonconfig.ConfigControllerConfig{SystemNamespace: flags.addonNamespace}},
-	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1}); err != nil {
-		setupLog.Error(err, "unable to create CSIConfigController", "controller", "azurediskcsi")
-		os.Exit(1)
+	for _, provider := range genericconfig.Providers() {
+		if err := genericconfig.NewReconciler(
+			mgr.GetClient(),
+			ctrl.Log.WithName(provider.Kind),
+			mgr.GetScheme(),
+			addonconfig.ConfigControllerConfig{SystemNamespace: flags.addonNamespace},
+			provider,
+		).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1}); err != nil {
+			setupLog.Error(err, "unable to create addon config controller", "controller", strings.ToLower(provider.Kind))
+			os.Exit(1)
+		}
 	}
 
 	if err := (&controllers.MachineReconciler{
@@ -408,14 +360,16 @@ func enableClusterBootstrapAndConfigControllers(ctx context.Context, mgr ctrl.Ma
 		ctrl.Log.WithName("ClusterBootstrapController"),
 		mgr.GetScheme(),
 		&addonconfig.ClusterBootstrapControllerConfig{
-			IPFamilyClusterClassVarName: constants.DefaultIPFamilyClusterClassVarName,
-			SystemNamespace:             flags.addonNamespace,
-			PkgiServiceAccount:          constants.PackageInstallServiceAccount,
-			PkgiClusterRole:             constants.PackageInstallClusterRole,
-			PkgiClusterRoleBinding:      constants.PackageInstallClusterRoleBinding,
-			PkgiSyncPeriod:              flags.syncPeriod,
-			ClusterDeleteTimeout:        flags.clusterDeleteTimeout,
+			IPFamilyClusterClassVarName:     constants.DefaultIPFamilyClusterClassVarName,
+			SystemNamespace:                 flags.addonNamespace,
+			PkgiServiceAccount:              constants.PackageInstallServiceAccount,
+			PkgiClusterRole:                 constants.PackageInstallClusterRole,
+			PkgiClusterRoleBinding:          constants.PackageInstallClusterRoleBinding,
+			PkgiSyncPeriod:                  flags.syncPeriod,
+			ClusterDeleteTimeout:            flags.clusterDeleteTimeout,
+			FeatureGatePackageRolloutPolicy: flags.featureGatePackageRolloutPolicy,
 		},
+		tracker,
 	)
 	if err := bootstrapReconciler.SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "clusterbootstrap")
@@ -472,6 +426,15 @@ func enableWebhooks(ctx context.Context, mgr ctrl.Manager, flags *addonFlags) {
 		setupLog.Error(err, "unable to set up webhooks", "webhook", "calico")
 		os.Exit(1)
 	}
+	for _, provider := range genericconfig.Providers() {
+		if !provider.HasWebhook() {
+			continue
+		}
+		if err := (&genericconfig.Webhook{Provider: provider}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to set up webhooks", "webhook", strings.ToLower(provider.Kind))
+			os.Exit(1)
+		}
+	}
 	clusterbootstrapWebhook := addonwebhooks.ClusterBootstrap{
 		Client:          mgr.GetClient(),
 		SystemNamespace: flags.addonNamespace,
@@ -492,11 +455,18 @@ func enableWebhooks(ctx context.Context, mgr ctrl.Manager, flags *addonFlags) {
 		setupLog.Error(err, "unable to set up webhooks", "webhook", "clusterpause")
 		os.Exit(1)
 	}
+	if flags.featureGatePackageRolloutPolicy {
+		packageRolloutPolicyWebhook := addonwebhooks.PackageRolloutPolicy{Client: mgr.GetClient()}
+		if err := packageRolloutPolicyWebhook.SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create PackageRolloutPolicy webhook", "webhook", "packagerolloutpolicy")
+			os.Exit(1)
+		}
+	}
 }
 
-func enablePackageInstallStatusController(ctx context.Context, mgr ctrl.Manager, flags *addonFlags) {
-	// set up a ClusterCacheTracker to provide to PackageInstallStatus controller which requires a connection to remote clusters
-	// the informers/caches are created only for objects accessed through Get/List in the code.
+func setupClusterCacheTracker(ctx context.Context, mgr ctrl.Manager) *capiremote.ClusterCacheTracker {
+	// set up a ClusterCacheTracker to provide to the controllers which require a connection to remote clusters
+	// the informers/caches are created only for objects accessed through Get/List in the code, or watched.
 	// we only read PackageInstall resource through our cached client, by default the client excludes configmap and secret resources.
 	l := ctrl.Log.WithName("remote").WithName("ClusterCacheTracker")
 	tracker, err := capiremote.NewClusterCacheTracker(mgr, capiremote.ClusterCacheTrackerOptions{Log: &l})
@@ -516,7 +486,10 @@ func enablePackageInstallStatusController(ctx context.Context, mgr ctrl.Manager,
 		setupLog.Error(err, "unable to create controller", "controller", "ClusterCacheReconciler")
 		os.Exit(1)
 	}
+	return tracker
+}
 
+func enablePackageInstallStatusController(ctx context.Context, mgr ctrl.Manager, flags *addonFlags, tracker *capiremote.ClusterCacheTracker) {
 	pkgiStatusReconciler := controllers.NewPackageInstallStatusReconciler(
 		mgr.GetClient(),
 		ctrl.Log.WithName("PackageInstallStatusController"),
@@ -540,3 +513,14 @@ func enablePackageInstallStatusController(ctx context.Context, mgr ctrl.Manager,
 		os.Exit(1)
 	}
 }
+
+func enablePackageRolloutPolicyController(ctx context.Context, mgr ctrl.Manager) {
+	if err := controllers.NewPackageRolloutPolicyReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("PackageRolloutPolicyController"),
+		mgr.GetScheme(),
+	).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: 1}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PackageRolloutPolicy")
+		os.Exit(1)
+	}
+}
@@ -36,6 +36,8 @@ type ClusterBootstrapControllerConfig struct {
 	SystemNamespace string
 	// The maximum amount of time that will be spent trying to clean resources before cluster deletion is allowed to proceed.
 	ClusterDeleteTimeout time.Duration
+	// Whether the package upgrades of the clusters selected by a PackageRolloutPolicy wait for the rollout
+	FeatureGatePackageRolloutPolicy bool
 }
 
 // PackageInstallStatusControllerConfig contains configuration information related to PackageInstallStatus
@@ -50,52 +52,7 @@ type ConfigControllerConfig struct {
 	SystemNamespace string
 }
 
-// AntreaConfigControllerConfig contains configuration information of AntreaConfig controller
-type AntreaConfigControllerConfig struct {
-	ConfigControllerConfig
-}
-
-// CalicoConfigControllerConfig contains configuration information of CalicoConfig controller
-type CalicoConfigControllerConfig struct {
-	ConfigControllerConfig
-}
-
 // KappControllerConfigControllerConfig contains configuration information of KappControllerConfig controller
 type KappControllerConfigControllerConfig struct {
 	ConfigControllerConfig
 }
-
-// VSphereCPIConfigControllerConfig contains configuration information of VSphereCPIConfig controller
-type VSphereCPIConfigControllerConfig struct {
-	ConfigControllerConfig
-}
-
-// OracleCPIConfigControllerConfig contains configuration information of OracleCPIConfig controller
-type OracleCPIConfigControllerConfig struct {
-	ConfigControllerConfig
-}
-
-// VSphereCSIConfigControllerConfig contains configuration information of VSphereCSIConfig controller
-type VSphereCSIConfigControllerConfig struct {
-	ConfigControllerConfig
-}
-
-// AzureDiskCSIConfigControllerConfig contains configuration information of AzureDiskCSIConfigControllerConfig controller
-type AzureDiskCSIConfigControllerConfig struct {
-	ConfigControllerConfig
-}
-
-// AwsEbsCSIConfigControllerConfig contains configuration information of VSphereCSIConfig controller
-type AwsEbsCSIConfigControllerConfig struct {
-	ConfigControllerConfig
-}
-
-// AzureFileCSIConfigControllerConfig contains configuration information of AzureFileCSIConfig controller
-type AzureFileCSIConfigControllerConfig struct {
-	ConfigControllerConfig
-}
-
-// KubevipCPIConfigControllerConfig contains configuration information of KubevipLBConfig controller
-type KubevipCPIConfigControllerConfig struct {
-	ConfigControllerConfig
-}
@@ -253,6 +253,10 @@ const (
 	// RequeueAfterDuration determines the duration after which the Controller should requeue the reconcile key
 	RequeueAfterDuration = time.Second * 10
 
+	// PackageDependencyCreationTimeout is how long the packages may wait for a package whose PackageInstall is not
+	// created although it does not wait for other packages, before the ClusterBootstrap reports them as failed
+	PackageDependencyCreationTimeout = time.Minute * 5
+
 	// WebhookCertDir is the directory where the certificate and key are stored for webhook server TLS handshake
 	WebhookCertDir = "/tmp/k8s-webhook-server/serving-certs"
 
@@ -356,6 +360,30 @@ const (
 	// for example
 	// "run.tanzu.vmware.com/skip-packageinstall-deletion": "vsphere-cpi,antrea,load-balancer-and-ingress-service"
 	SkipDeletePackageInstallAnnotation = "run.tanzu.vmware.com/skip-packageinstall-deletion"
+
+	// RolloutApprovedTKRAnnotation is the annotation on the ClusterBootstrap object which holds the TKR its packages
+	// may be upgraded to, set by the PackageRolloutPolicy controller when the rollout starts the upgrade of the cluster
+	RolloutApprovedTKRAnnotation = "run.tanzu.vmware.com/rollout-approved-tkr"
+
+	// RolloutApprovedPackagesAnnotation is the annotation on the ClusterBootstrap object which holds the revision of the
+	// packages which may be rolled out to the cluster, set by the PackageRolloutPolicy controller when the rollout starts
+	// the upgrade of the packages of the cluster, and by the ClusterBootstrap controller when it upgrades the packages
+	// to an approved TKR
+	RolloutApprovedPackagesAnnotation = "run.tanzu.vmware.com/rollout-approved-packages"
+
+	// RemoteDriftPolicyAnnotation is the annotation on the ClusterBootstrap object which selects how the out-of-band
+	// changes to the addon resources on its cluster are handled, either RemoteDriftPolicyRevert or RemoteDriftPolicyReport
+	RemoteDriftPolicyAnnotation = "run.tanzu.vmware.com/remote-drift-policy"
+
+	// RemoteDriftPolicyRevert reverts the out-of-band changes to the addon resources, it is the default policy
+	RemoteDriftPolicyRevert = "revert"
+
+	// RemoteDriftPolicyReport only reports the out-of-band changes to the addon resources, which are left as they are
+	RemoteDriftPolicyReport = "report"
+
+	// AppliedHashAnnotation is the annotation on the addon resources created on a cluster which holds the hash of their
+	// content as last applied by the ClusterBootstrap controller
+	AppliedHashAnnotation = "run.tanzu.vmware.com/applied-hash"
 )
 
 var (
@@ -0,0 +1,98 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kapppkgiv1alpha1 "github.com/vmware-tanzu/carvel-kapp-controller/pkg/apis/packaging/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+)
+
+// managedContent returns the content of an addon resource which is managed by the ClusterBootstrap controller. The
+// content of a ServiceAccount is not managed, only its existence.
+func managedContent(obj client.Object) (interface{}, error) {
+	switch o := obj.(type) {
+	case *corev1.ServiceAccount:
+		return nil, nil
+	case *rbacv1.ClusterRole:
+		return o.Rules, nil
+	case *rbacv1.ClusterRoleBinding:
+		return []interface{}{o.RoleRef, o.Subjects}, nil
+	case *corev1.Secret:
+		// stringData is merged into data by the API server
+		data := make(map[string][]byte, len(o.Data)+len(o.StringData))
+		for k, v := range o.Data {
+			data[k] = v
+		}
+		for k, v := range o.StringData {
+			data[k] = []byte(v)
+		}
+		return data, nil
+	case *kapppkgiv1alpha1.PackageInstall:
+		return o.Spec, nil
+	default:
+		return nil, fmt.Errorf("drift detection is not supported for %T", obj)
+	}
+}
+
+// GetManagedContentHash returns the hash of the content of an addon resource managed by the ClusterBootstrap controller
+func GetManagedContentHash(obj client.Object) (string, error) {
+	content, err := managedContent(obj)
+	if err != nil {
+		return "", err
+	}
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(raw)), nil
+}
+
+// SetAppliedHash records the hash of the managed content of an addon resource in its AppliedHashAnnotation
+func SetAppliedHash(obj client.Object) error {
+	hash, err := GetManagedContentHash(obj)
+	if err != nil {
+		return err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[constants.AppliedHashAnnotation] = hash
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// HasDrifted returns whether the managed content of an addon resource changed since it was last applied. The resources
+// which were never applied with an AppliedHashAnnotation have not drifted.
+func HasDrifted(obj client.Object) bool {
+	applied, ok := obj.GetAnnotations()[constants.AppliedHashAnnotation]
+	if !ok {
+		return false
+	}
+	hash, err := GetManagedContentHash(obj)
+	return err == nil && hash != applied
+}
+
+// ResetManagedContent clears the managed content of an addon resource, so that the out-of-band additions to it are
+// removed when it is applied again
+func ResetManagedContent(obj client.Object) {
+	switch o := obj.(type) {
+	case *rbacv1.ClusterRole:
+		o.Rules = nil
+	case *rbacv1.ClusterRoleBinding:
+		o.Subjects = nil
+	case *corev1.Secret:
+		o.Data = nil
+	case *kapppkgiv1alpha1.PackageInstall:
+		o.Spec = kapppkgiv1alpha1.PackageInstallSpec{}
+	}
+}
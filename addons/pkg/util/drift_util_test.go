@@ -0,0 +1,72 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kapppkgiv1alpha1 "github.com/vmware-tanzu/carvel-kapp-controller/pkg/apis/packaging/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+)
+
+var _ = Describe("Drift util test cases", func() {
+	Context("HasDrifted()", func() {
+		It("should not report the resources which were never applied", func() {
+			Expect(HasDrifted(&rbacv1.ClusterRole{Rules: []rbacv1.PolicyRule{{Verbs: []string{"*"}}}})).To(BeFalse())
+		})
+
+		It("should report the changes to the managed content since it was applied", func() {
+			role := &rbacv1.ClusterRole{Rules: []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}}}
+			Expect(SetAppliedHash(role)).To(Succeed())
+			Expect(role.Annotations).To(HaveKey(constants.AppliedHashAnnotation))
+			Expect(HasDrifted(role)).To(BeFalse())
+
+			role.Labels = map[string]string{"foo": "bar"}
+			Expect(HasDrifted(role)).To(BeFalse())
+
+			role.Rules[0].Verbs = []string{"get"}
+			Expect(HasDrifted(role)).To(BeTrue())
+		})
+
+		It("should compare the data of a secret applied with stringData", func() {
+			desired := &corev1.Secret{StringData: map[string]string{"values.yaml": "foo: bar"}}
+			Expect(SetAppliedHash(desired)).To(Succeed())
+
+			live := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Annotations: desired.Annotations},
+				Data:       map[string][]byte{"values.yaml": []byte("foo: bar")},
+			}
+			Expect(HasDrifted(live)).To(BeFalse())
+
+			live.Data["values.yaml"] = []byte("foo: baz")
+			Expect(HasDrifted(live)).To(BeTrue())
+		})
+
+		It("should only report the deletion of a ServiceAccount", func() {
+			serviceAccount := &corev1.ServiceAccount{}
+			Expect(SetAppliedHash(serviceAccount)).To(Succeed())
+			serviceAccount.Secrets = []corev1.ObjectReference{{Name: "token"}}
+			Expect(HasDrifted(serviceAccount)).To(BeFalse())
+		})
+	})
+
+	Context("ResetManagedContent()", func() {
+		It("should clear the spec of a PackageInstall", func() {
+			pkgi := &kapppkgiv1alpha1.PackageInstall{Spec: kapppkgiv1alpha1.PackageInstallSpec{ServiceAccountName: "foo", Paused: true}}
+			ResetManagedContent(pkgi)
+			Expect(pkgi.Spec).To(Equal(kapppkgiv1alpha1.PackageInstallSpec{}))
+		})
+	})
+
+	Context("GetManagedContentHash()", func() {
+		It("should not support other resources", func() {
+			_, err := GetManagedContentHash(&corev1.ConfigMap{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,120 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"strings"
+
+	runtanzuv1alpha3 "github.com/vmware-tanzu/tanzu-framework/apis/run/v1alpha3"
+)
+
+// PackageMatchesDependency returns true if the ClusterBootstrap package refName is referred to by the dependency.
+// A dependency is either the short name of the package (e.g. antrea), its package name (e.g. antrea.tanzu.vmware.com)
+// or its refName (e.g. antrea.tanzu.vmware.com.1.5.3--vmware.1-tkg.1).
+func PackageMatchesDependency(dependency, pkgRefName string) bool {
+	return dependency != "" && (pkgRefName == dependency || strings.HasPrefix(pkgRefName, dependency+"."))
+}
+
+// GetOrderedRolloutPackages returns the packages of the ClusterBootstrap whose PackageInstalls are rolled out in order
+// on the cluster, i.e. CNI, CPI, CSI and the additional packages. Kapp-controller is always installed first as it
+// reconciles the PackageInstalls of the other packages.
+func GetOrderedRolloutPackages(spec *runtanzuv1alpha3.ClusterBootstrapTemplateSpec) []*runtanzuv1alpha3.ClusterBootstrapPackage {
+	var pkgs []*runtanzuv1alpha3.ClusterBootstrapPackage
+	for _, pkg := range append([]*runtanzuv1alpha3.ClusterBootstrapPackage{spec.CNI, spec.CPI, spec.CSI}, spec.AdditionalPackages...) {
+		if pkg != nil {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs
+}
+
+// GetPackageBlockers returns the packages which need to be reconciled successfully on the cluster before the
+// PackageInstall of pkg is created: the packages pkg depends on and the packages of the lower waves.
+func GetPackageBlockers(pkg *runtanzuv1alpha3.ClusterBootstrapPackage, pkgs []*runtanzuv1alpha3.ClusterBootstrapPackage) []*runtanzuv1alpha3.ClusterBootstrapPackage {
+	var blockers []*runtanzuv1alpha3.ClusterBootstrapPackage
+	for _, other := range pkgs {
+		if other == pkg || other.RefName == pkg.RefName {
+			continue
+		}
+		if other.Wave < pkg.Wave || dependsOn(pkg, other) {
+			blockers = append(blockers, other)
+		}
+	}
+	return blockers
+}
+
+// FindPackageDependencyCycle returns the refNames of the packages forming a dependency cycle, the first package
+// being repeated at the end, or nil if the dependencies of the packages do not form any cycle.
+func FindPackageDependencyCycle(pkgs []*runtanzuv1alpha3.ClusterBootstrapPackage) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[*runtanzuv1alpha3.ClusterBootstrapPackage]int, len(pkgs))
+	var path []*runtanzuv1alpha3.ClusterBootstrapPackage
+
+	var visit func(pkg *runtanzuv1alpha3.ClusterBootstrapPackage) []string
+	visit = func(pkg *runtanzuv1alpha3.ClusterBootstrapPackage) []string {
+		state[pkg] = visiting
+		path = append(path, pkg)
+		for _, other := range pkgs {
+			if other == pkg || !dependsOn(pkg, other) {
+				continue
+			}
+			switch state[other] {
+			case visiting:
+				var cycle []string
+				for i := len(path) - 1; i >= 0; i-- {
+					if path[i] == other {
+						for _, p := range path[i:] {
+							cycle = append(cycle, p.RefName)
+						}
+						break
+					}
+				}
+				return append(cycle, other.RefName)
+			case unvisited:
+				if cycle := visit(other); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[pkg] = visited
+		return nil
+	}
+
+	for _, pkg := range pkgs {
+		if state[pkg] == unvisited {
+			if cycle := visit(pkg); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// InheritPackageOrder sets the dependencies and the wave of the ClusterBootstrap package from the ClusterBootstrapTemplate
+// package of the same name, unless the ClusterBootstrap package defines its own order
+func InheritPackageOrder(pkg, templatePkg *runtanzuv1alpha3.ClusterBootstrapPackage) {
+	if pkg == nil || templatePkg == nil || len(pkg.DependsOn) != 0 || pkg.Wave != 0 ||
+		packageShortName(pkg.RefName) != packageShortName(templatePkg.RefName) {
+		return
+	}
+	if templatePkg.DependsOn != nil {
+		pkg.DependsOn = append([]string{}, templatePkg.DependsOn...)
+	}
+	pkg.Wave = templatePkg.Wave
+}
+
+// dependsOn returns true if the package declares a dependency on the other package
+func dependsOn(pkg, other *runtanzuv1alpha3.ClusterBootstrapPackage) bool {
+	for _, dependency := range pkg.DependsOn {
+		if PackageMatchesDependency(dependency, other.RefName) {
+			return true
+		}
+	}
+	return false
+}
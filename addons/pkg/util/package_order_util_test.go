@@ -0,0 +1,111 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	runtanzuv1alpha3 "github.com/vmware-tanzu/tanzu-framework/apis/run/v1alpha3"
+)
+
+const (
+	antreaRefName      = "antrea.tanzu.vmware.com.1.5.3--vmware.1-tkg.1"
+	vsphereCSIRefName  = "vsphere-csi.tanzu.vmware.com.2.5.2--vmware.1-tkg.1"
+	vsphereCPIRefName  = "vsphere-cpi.tanzu.vmware.com.1.22.6--vmware.1-tkg.1"
+	certManagerRefName = "cert-manager.tanzu.vmware.com.1.7.2--vmware.1-tkg.1"
+	metricsRefName     = "metrics-server.tanzu.vmware.com.0.6.1--vmware.1-tkg.1"
+)
+
+func refNames(pkgs []*runtanzuv1alpha3.ClusterBootstrapPackage) []string {
+	var names []string
+	for _, pkg := range pkgs {
+		names = append(names, pkg.RefName)
+	}
+	return names
+}
+
+var _ = Describe("Package order util test cases", func() {
+	Context("PackageMatchesDependency()", func() {
+		It("should match the short name, the package name and the refName", func() {
+			Expect(PackageMatchesDependency("antrea", antreaRefName)).To(BeTrue())
+			Expect(PackageMatchesDependency("antrea.tanzu.vmware.com", antreaRefName)).To(BeTrue())
+			Expect(PackageMatchesDependency(antreaRefName, antreaRefName)).To(BeTrue())
+		})
+		It("should not match other packages", func() {
+			Expect(PackageMatchesDependency("vsphere", vsphereCSIRefName)).To(BeFalse())
+			Expect(PackageMatchesDependency("antr", antreaRefName)).To(BeFalse())
+			Expect(PackageMatchesDependency("", antreaRefName)).To(BeFalse())
+		})
+	})
+
+	Context("GetPackageBlockers()", func() {
+		var (
+			cni, csi, cpi, certManager, metrics *runtanzuv1alpha3.ClusterBootstrapPackage
+			pkgs                                []*runtanzuv1alpha3.ClusterBootstrapPackage
+		)
+		BeforeEach(func() {
+			cni = &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: antreaRefName}
+			cpi = &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: vsphereCPIRefName}
+			csi = &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: vsphereCSIRefName, DependsOn: []string{"antrea"}}
+			certManager = &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: certManagerRefName, Wave: 1}
+			metrics = &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: metricsRefName, Wave: 2, DependsOn: []string{"cert-manager.tanzu.vmware.com"}}
+			pkgs = GetOrderedRolloutPackages(&runtanzuv1alpha3.ClusterBootstrapTemplateSpec{
+				CNI:                cni,
+				CPI:                cpi,
+				CSI:                csi,
+				AdditionalPackages: []*runtanzuv1alpha3.ClusterBootstrapPackage{certManager, metrics},
+			})
+		})
+		It("should not block packages without dependencies in the first wave", func() {
+			Expect(GetPackageBlockers(cni, pkgs)).To(BeEmpty())
+			Expect(GetPackageBlockers(cpi, pkgs)).To(BeEmpty())
+		})
+		It("should block packages on their dependencies", func() {
+			Expect(refNames(GetPackageBlockers(csi, pkgs))).To(Equal([]string{antreaRefName}))
+		})
+		It("should block packages on the packages of the lower waves", func() {
+			Expect(refNames(GetPackageBlockers(certManager, pkgs))).To(Equal([]string{antreaRefName, vsphereCPIRefName, vsphereCSIRefName}))
+			Expect(refNames(GetPackageBlockers(metrics, pkgs))).To(Equal([]string{antreaRefName, vsphereCPIRefName, vsphereCSIRefName, certManagerRefName}))
+		})
+	})
+
+	Context("FindPackageDependencyCycle()", func() {
+		It("should not find any cycle in acyclic dependencies", func() {
+			Expect(FindPackageDependencyCycle([]*runtanzuv1alpha3.ClusterBootstrapPackage{
+				{RefName: antreaRefName},
+				{RefName: vsphereCSIRefName, DependsOn: []string{"antrea"}},
+				{RefName: metricsRefName, DependsOn: []string{"antrea", "vsphere-csi"}},
+			})).To(BeNil())
+		})
+		It("should find the cycle in cyclic dependencies", func() {
+			Expect(FindPackageDependencyCycle([]*runtanzuv1alpha3.ClusterBootstrapPackage{
+				{RefName: antreaRefName},
+				{RefName: vsphereCSIRefName, DependsOn: []string{"antrea", "metrics-server"}},
+				{RefName: metricsRefName, DependsOn: []string{"cert-manager"}},
+				{RefName: certManagerRefName, DependsOn: []string{"vsphere-csi"}},
+			})).To(Equal([]string{vsphereCSIRefName, metricsRefName, certManagerRefName, vsphereCSIRefName}))
+		})
+	})
+
+	Context("InheritPackageOrder()", func() {
+		It("should inherit the order of the template package", func() {
+			pkg := &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: vsphereCSIRefName}
+			InheritPackageOrder(pkg, &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: "vsphere-csi.tanzu.vmware.com.2.6.0--vmware.1-tkg.1", DependsOn: []string{"antrea"}, Wave: 1})
+			Expect(pkg.DependsOn).To(Equal([]string{"antrea"}))
+			Expect(pkg.Wave).To(Equal(int32(1)))
+		})
+		It("should keep the order of the package", func() {
+			pkg := &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: vsphereCSIRefName, Wave: 2}
+			InheritPackageOrder(pkg, &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: vsphereCSIRefName, DependsOn: []string{"antrea"}, Wave: 1})
+			Expect(pkg.DependsOn).To(BeEmpty())
+			Expect(pkg.Wave).To(Equal(int32(2)))
+		})
+		It("should not inherit the order of another package", func() {
+			pkg := &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: "calico.tanzu.vmware.com.3.22.1--vmware.1-tkg.1"}
+			InheritPackageOrder(pkg, &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: antreaRefName, Wave: 1})
+			Expect(pkg.Wave).To(BeZero())
+		})
+	})
+})
@@ -0,0 +1,88 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/carvel-kapp-controller/pkg/apis/kappctrl/v1alpha1"
+	runtanzuv1alpha3 "github.com/vmware-tanzu/tanzu-framework/apis/run/v1alpha3"
+)
+
+// SetPackageStatus adds the rollout status of a package to the ClusterBootstrap status, or updates the status of the
+// package of the same short name, as its refName changes on upgrade. The installed version is kept until a new version
+// is reconciled successfully, the last error until the package is reconciled successfully and the last transition time
+// until the state of the package changes.
+func SetPackageStatus(status *runtanzuv1alpha3.ClusterBootstrapStatus, pkgStatus *runtanzuv1alpha3.PackageStatus) {
+	for i := range status.Packages {
+		existing := &status.Packages[i]
+		if packageShortName(existing.RefName) != packageShortName(pkgStatus.RefName) {
+			continue
+		}
+		existing.RefName = pkgStatus.RefName
+		if pkgStatus.Version != "" {
+			existing.Version = pkgStatus.Version
+		}
+		if pkgStatus.LastError != "" || pkgStatus.State == string(v1alpha1.ReconcileSucceeded) {
+			existing.LastError = pkgStatus.LastError
+		}
+		if existing.State != pkgStatus.State {
+			existing.State = pkgStatus.State
+			existing.LastTransitionTime = metav1.Now()
+		}
+		return
+	}
+	newStatus := pkgStatus.DeepCopy()
+	newStatus.LastTransitionTime = metav1.Now()
+	status.Packages = append(status.Packages, *newStatus)
+}
+
+// RemoveStalePackageStatuses removes the rollout statuses of the packages which are not part of the ClusterBootstrap
+// anymore and summarizes the number of packages reconciled successfully out of the packages of the ClusterBootstrap
+func RemoveStalePackageStatuses(status *runtanzuv1alpha3.ClusterBootstrapStatus, pkgRefNames []string) {
+	shortNames := make(map[string]bool, len(pkgRefNames))
+	for _, refName := range pkgRefNames {
+		shortNames[packageShortName(refName)] = true
+	}
+	var packages []runtanzuv1alpha3.PackageStatus
+	var reconciled int
+	for i := range status.Packages {
+		if !shortNames[packageShortName(status.Packages[i].RefName)] {
+			continue
+		}
+		packages = append(packages, status.Packages[i])
+		if status.Packages[i].State == string(v1alpha1.ReconcileSucceeded) {
+			reconciled++
+		}
+	}
+	status.Packages = packages
+	status.ReconciledPackages = ""
+	if len(shortNames) != 0 {
+		status.ReconciledPackages = fmt.Sprintf("%d/%d", reconciled, len(shortNames))
+	}
+}
+
+// GetPackagesHealth returns whether all the packages of the ClusterBootstrap are reconciled successfully at their
+// current refName, and the refNames of the packages which failed to reconcile
+func GetPackagesHealth(clusterBootstrap *runtanzuv1alpha3.ClusterBootstrap) (bool, []string) {
+	states := make(map[string]string, len(clusterBootstrap.Status.Packages))
+	var failed []string
+	for _, pkgStatus := range clusterBootstrap.Status.Packages {
+		states[pkgStatus.RefName] = pkgStatus.State
+		if pkgStatus.State == string(v1alpha1.ReconcileFailed) {
+			failed = append(failed, pkgStatus.RefName)
+		}
+	}
+	if clusterBootstrap.Spec == nil || len(failed) != 0 {
+		return false, failed
+	}
+	for _, pkg := range GetOrderedRolloutPackages(clusterBootstrap.Spec) {
+		if states[pkg.RefName] != string(v1alpha1.ReconcileSucceeded) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
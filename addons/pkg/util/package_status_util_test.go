@@ -0,0 +1,85 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/carvel-kapp-controller/pkg/apis/kappctrl/v1alpha1"
+	runtanzuv1alpha3 "github.com/vmware-tanzu/tanzu-framework/apis/run/v1alpha3"
+)
+
+var _ = Describe("Package status util test cases", func() {
+	var status *runtanzuv1alpha3.ClusterBootstrapStatus
+
+	BeforeEach(func() {
+		status = &runtanzuv1alpha3.ClusterBootstrapStatus{}
+	})
+
+	Context("SetPackageStatus()", func() {
+		It("should add the status of a new package", func() {
+			SetPackageStatus(status, &runtanzuv1alpha3.PackageStatus{RefName: antreaRefName, State: runtanzuv1alpha3.PackageStatePending})
+			Expect(status.Packages).To(HaveLen(1))
+			Expect(status.Packages[0].State).To(Equal(runtanzuv1alpha3.PackageStatePending))
+			Expect(status.Packages[0].LastTransitionTime.IsZero()).To(BeFalse())
+		})
+
+		It("should keep the last error and the installed version until the package is reconciled", func() {
+			lastTransitionTime := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+			status.Packages = []runtanzuv1alpha3.PackageStatus{{
+				RefName:            antreaRefName,
+				Version:            "1.5.3+vmware.1-tkg.1",
+				State:              string(v1alpha1.ReconcileFailed),
+				LastError:          "kapp: Error: timed out",
+				LastTransitionTime: lastTransitionTime,
+			}}
+
+			SetPackageStatus(status, &runtanzuv1alpha3.PackageStatus{RefName: antreaRefName, State: string(v1alpha1.ReconcileFailed), LastError: "kapp: Error: timed out"})
+			Expect(status.Packages[0].LastTransitionTime).To(Equal(lastTransitionTime))
+
+			SetPackageStatus(status, &runtanzuv1alpha3.PackageStatus{RefName: "antrea.tanzu.vmware.com.1.7.1--vmware.1-tkg.1", State: string(v1alpha1.Reconciling)})
+			Expect(status.Packages).To(HaveLen(1))
+			Expect(status.Packages[0].RefName).To(Equal("antrea.tanzu.vmware.com.1.7.1--vmware.1-tkg.1"))
+			Expect(status.Packages[0].Version).To(Equal("1.5.3+vmware.1-tkg.1"))
+			Expect(status.Packages[0].LastError).To(Equal("kapp: Error: timed out"))
+			Expect(status.Packages[0].LastTransitionTime).NotTo(Equal(lastTransitionTime))
+
+			SetPackageStatus(status, &runtanzuv1alpha3.PackageStatus{RefName: "antrea.tanzu.vmware.com.1.7.1--vmware.1-tkg.1", State: string(v1alpha1.ReconcileSucceeded), Version: "1.7.1+vmware.1-tkg.1"})
+			Expect(status.Packages[0].Version).To(Equal("1.7.1+vmware.1-tkg.1"))
+			Expect(status.Packages[0].LastError).To(BeEmpty())
+		})
+	})
+
+	Context("RemoveStalePackageStatuses()", func() {
+		It("should remove the stale packages and summarize the reconciled packages", func() {
+			status.Packages = []runtanzuv1alpha3.PackageStatus{
+				{RefName: antreaRefName, State: string(v1alpha1.ReconcileSucceeded)},
+				{RefName: vsphereCSIRefName, State: string(v1alpha1.ReconcileFailed)},
+				{RefName: metricsRefName, State: string(v1alpha1.ReconcileSucceeded)},
+			}
+			RemoveStalePackageStatuses(status, []string{antreaRefName, vsphereCSIRefName, certManagerRefName})
+			Expect(refNamesOfStatuses(status.Packages)).To(Equal([]string{antreaRefName, vsphereCSIRefName}))
+			Expect(status.ReconciledPackages).To(Equal("1/3"))
+		})
+
+		It("should not summarize a ClusterBootstrap without packages", func() {
+			status.ReconciledPackages = "1/1"
+			RemoveStalePackageStatuses(status, nil)
+			Expect(status.Packages).To(BeEmpty())
+			Expect(status.ReconciledPackages).To(BeEmpty())
+		})
+	})
+})
+
+func refNamesOfStatuses(statuses []runtanzuv1alpha3.PackageStatus) []string {
+	var names []string
+	for _, pkgStatus := range statuses {
+		names = append(names, pkgStatus.RefName)
+	}
+	return names
+}
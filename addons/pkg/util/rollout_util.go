@@ -0,0 +1,255 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+	runtanzuv1alpha3 "github.com/vmware-tanzu/tanzu-framework/apis/run/v1alpha3"
+)
+
+const (
+	// defaultRolloutProgressDeadline is the time the packages of a cluster may take to be reconciled successfully
+	// before its upgrade fails, when the PackageRolloutPolicy does not define it
+	defaultRolloutProgressDeadline = 30 * time.Minute
+)
+
+// RolloutCluster is a cluster selected by a PackageRolloutPolicy
+type RolloutCluster struct {
+	Namespace string
+	Name      string
+	// TKR is the TKR of the cluster, its packages are upgraded to
+	TKR string
+	// ClusterBootstrap is the ClusterBootstrap of the cluster, nil if it does not exist yet
+	ClusterBootstrap *runtanzuv1alpha3.ClusterBootstrap
+}
+
+// PackageRolloutPlan is the outcome of a PackageRolloutPolicy reconciliation
+type PackageRolloutPlan struct {
+	// Clusters is the rollout status of the selected clusters, sorted by namespace and name
+	Clusters []runtanzuv1alpha3.ClusterRolloutStatus
+	// Start is the clusters whose upgrade starts
+	Start []RolloutCluster
+	// NewlyFailed is the namespaced names of the clusters whose upgrade failed since the last reconciliation
+	NewlyFailed []string
+	// Failed is the namespaced names of the clusters whose upgrade failed, which halt the rollout
+	Failed []string
+}
+
+// PlanPackageRollout computes the rollout status of the clusters selected by the PackageRolloutPolicy from their
+// ClusterBootstraps and the previous status of the policy, and picks the next batch of clusters to upgrade. The next
+// batch only starts when the policy is not paused, no upgrade failed, no cluster is upgrading, and the number of
+// unavailable clusters is below MaxUnavailable. The package changes of the clusters whose upgrade failed start
+// regardless, as they are meant to fix the failed upgrade.
+func PlanPackageRollout(policy *runtanzuv1alpha3.PackageRolloutPolicy, clusters []RolloutCluster, now time.Time) *PackageRolloutPlan {
+	sort.Slice(clusters, func(i, j int) bool {
+		return rolloutClusterKey(clusters[i].Namespace, clusters[i].Name) < rolloutClusterKey(clusters[j].Namespace, clusters[j].Name)
+	})
+	previous := make(map[string]*runtanzuv1alpha3.ClusterRolloutStatus, len(policy.Status.Clusters))
+	for i := range policy.Status.Clusters {
+		prev := &policy.Status.Clusters[i]
+		previous[rolloutClusterKey(prev.Namespace, prev.Name)] = prev
+	}
+
+	plan := &PackageRolloutPlan{}
+	var pending, retried []int
+	var upgrading, unavailable int
+	for i := range clusters {
+		key := rolloutClusterKey(clusters[i].Namespace, clusters[i].Name)
+		prev := previous[key]
+		clusterStatus := getClusterRolloutStatus(policy, &clusters[i], prev, now)
+		switch clusterStatus.State {
+		case runtanzuv1alpha3.ClusterRolloutStatePending:
+			switch {
+			case clusters[i].ClusterBootstrap == nil || clusters[i].ClusterBootstrap.Status.ResolvedTKR == "":
+			case prev != nil && prev.State == runtanzuv1alpha3.ClusterRolloutStateFailed && prev.TKR == clusterStatus.TKR:
+				retried = append(retried, i)
+			default:
+				pending = append(pending, i)
+			}
+		case runtanzuv1alpha3.ClusterRolloutStateUpgrading:
+			upgrading++
+			unavailable++
+		case runtanzuv1alpha3.ClusterRolloutStateFailed:
+			unavailable++
+			plan.Failed = append(plan.Failed, key)
+			if prev == nil || prev.State != runtanzuv1alpha3.ClusterRolloutStateFailed || prev.TKR != clusterStatus.TKR {
+				plan.NewlyFailed = append(plan.NewlyFailed, key)
+			}
+		case runtanzuv1alpha3.ClusterRolloutStateUnhealthy:
+			unavailable++
+		}
+		plan.Clusters = append(plan.Clusters, *clusterStatus)
+	}
+
+	for _, i := range retried {
+		startClusterRollout(plan, clusters, i, now)
+		plan.Clusters[i].Message = fmt.Sprintf("retrying the upgrade of the packages to TKR %s", clusters[i].TKR)
+	}
+	if policy.Spec.Paused || len(plan.Failed) != 0 || len(retried) != 0 || upgrading != 0 {
+		return plan
+	}
+	batchSize := int(policy.Spec.BatchSize)
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	if available := getRolloutMaxUnavailable(policy, len(clusters)) - unavailable; available < batchSize {
+		batchSize = available
+	}
+	for _, i := range pending {
+		if len(plan.Start) >= batchSize {
+			break
+		}
+		startClusterRollout(plan, clusters, i, now)
+	}
+	return plan
+}
+
+// startClusterRollout starts the upgrade of the packages of the i-th cluster
+func startClusterRollout(plan *PackageRolloutPlan, clusters []RolloutCluster, i int, now time.Time) {
+	plan.Start = append(plan.Start, clusters[i])
+	plan.Clusters[i].State = runtanzuv1alpha3.ClusterRolloutStateUpgrading
+	plan.Clusters[i].StartTime = &metav1.Time{Time: now}
+	plan.Clusters[i].HealthySince = nil
+	plan.Clusters[i].Message = fmt.Sprintf("upgrading the packages to TKR %s", clusters[i].TKR)
+}
+
+// getClusterRolloutStatus computes the rollout status of a cluster from its ClusterBootstrap and its previous status
+func getClusterRolloutStatus(policy *runtanzuv1alpha3.PackageRolloutPolicy, cluster *RolloutCluster,
+	prev *runtanzuv1alpha3.ClusterRolloutStatus, now time.Time) *runtanzuv1alpha3.ClusterRolloutStatus {
+
+	clusterStatus := &runtanzuv1alpha3.ClusterRolloutStatus{Namespace: cluster.Namespace, Name: cluster.Name, TKR: cluster.TKR}
+	clusterBootstrap := cluster.ClusterBootstrap
+	if clusterBootstrap == nil {
+		clusterStatus.State = runtanzuv1alpha3.ClusterRolloutStatePending
+		clusterStatus.Message = "waiting for the ClusterBootstrap to be created"
+		return clusterStatus
+	}
+
+	approved := clusterBootstrap.Annotations[constants.RolloutApprovedTKRAnnotation] == cluster.TKR
+	upToDate := clusterBootstrap.Status.ResolvedTKR == cluster.TKR
+	if !upToDate && !approved {
+		clusterStatus.State = runtanzuv1alpha3.ClusterRolloutStatePending
+		clusterStatus.Message = fmt.Sprintf("waiting for the rollout to upgrade the packages from TKR %s", clusterBootstrap.Status.ResolvedTKR)
+		return clusterStatus
+	}
+	packagesPending := IsPackageUpgradePending(clusterBootstrap)
+	if upToDate && packagesPending {
+		// the packages of the ClusterBootstrap changed at the same TKR, e.g. the version of a package was bumped
+		approved = clusterBootstrap.Annotations[constants.RolloutApprovedPackagesAnnotation] == GetPackagesRevision(clusterBootstrap.Spec)
+		if !approved {
+			clusterStatus.State = runtanzuv1alpha3.ClusterRolloutStatePending
+			clusterStatus.Message = "waiting for the rollout to upgrade the changed packages"
+			return clusterStatus
+		}
+	}
+
+	healthy, failed := GetPackagesHealth(clusterBootstrap)
+	inProgress := prev != nil && prev.TKR == cluster.TKR &&
+		(prev.State == runtanzuv1alpha3.ClusterRolloutStateUpgrading || prev.State == runtanzuv1alpha3.ClusterRolloutStateFailed)
+	if !inProgress && ((upToDate && !packagesPending) || !approved) {
+		// the cluster is not upgraded by the rollout, either it is created at its TKR or its upgrade is complete
+		if healthy {
+			clusterStatus.State = runtanzuv1alpha3.ClusterRolloutStateReady
+		} else {
+			clusterStatus.State = runtanzuv1alpha3.ClusterRolloutStateUnhealthy
+			clusterStatus.Message = "the packages are not reconciled successfully"
+		}
+		return clusterStatus
+	}
+
+	clusterStatus.StartTime = &metav1.Time{Time: now}
+	if inProgress && prev.StartTime != nil {
+		clusterStatus.StartTime = prev.StartTime
+	}
+	switch {
+	case !upToDate || packagesPending:
+		// the package statuses are not the ones of the upgraded packages until their PackageInstalls are patched
+		clusterStatus.State = runtanzuv1alpha3.ClusterRolloutStateUpgrading
+		clusterStatus.Message = fmt.Sprintf("upgrading the packages to TKR %s", cluster.TKR)
+		if now.Sub(clusterStatus.StartTime.Time) > getRolloutProgressDeadline(policy) {
+			clusterStatus.State = runtanzuv1alpha3.ClusterRolloutStateFailed
+			clusterStatus.Message = fmt.Sprintf("the packages are not reconciled successfully within %s", getRolloutProgressDeadline(policy))
+		}
+	case len(failed) != 0:
+		clusterStatus.State = runtanzuv1alpha3.ClusterRolloutStateFailed
+		clusterStatus.Message = fmt.Sprintf("packages failed to reconcile: %s", strings.Join(failed, ", "))
+	case healthy && upToDate:
+		clusterStatus.HealthySince = &metav1.Time{Time: now}
+		if inProgress && prev.HealthySince != nil {
+			clusterStatus.HealthySince = prev.HealthySince
+		}
+		if now.Sub(clusterStatus.HealthySince.Time) >= policy.Spec.HealthGate.SoakDuration.Duration {
+			clusterStatus.State = runtanzuv1alpha3.ClusterRolloutStateReady
+			return clusterStatus
+		}
+		clusterStatus.State = runtanzuv1alpha3.ClusterRolloutStateUpgrading
+		clusterStatus.Message = fmt.Sprintf("the packages are reconciled successfully, soaking for %s", policy.Spec.HealthGate.SoakDuration.Duration)
+	case now.Sub(clusterStatus.StartTime.Time) > getRolloutProgressDeadline(policy):
+		clusterStatus.State = runtanzuv1alpha3.ClusterRolloutStateFailed
+		clusterStatus.Message = fmt.Sprintf("the packages are not reconciled successfully within %s", getRolloutProgressDeadline(policy))
+	default:
+		clusterStatus.State = runtanzuv1alpha3.ClusterRolloutStateUpgrading
+		clusterStatus.Message = fmt.Sprintf("upgrading the packages to TKR %s", cluster.TKR)
+	}
+	return clusterStatus
+}
+
+// GetPackagesRevision returns the revision of the packages of the ClusterBootstrap, which changes with the refName of
+// any package, e.g. when the version of a package is bumped
+func GetPackagesRevision(spec *runtanzuv1alpha3.ClusterBootstrapTemplateSpec) string {
+	if spec == nil {
+		return ""
+	}
+	var refNames []string
+	if spec.Kapp != nil {
+		refNames = append(refNames, spec.Kapp.RefName)
+	}
+	for _, pkg := range GetOrderedRolloutPackages(spec) {
+		refNames = append(refNames, pkg.RefName)
+	}
+	sort.Strings(refNames)
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strings.Join(refNames, ",")))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// IsPackageUpgradePending returns whether the packages of the ClusterBootstrap changed since they were rolled out to
+// the cluster. The packages which were never rolled out, e.g. of a new cluster, are not pending an upgrade.
+func IsPackageUpgradePending(clusterBootstrap *runtanzuv1alpha3.ClusterBootstrap) bool {
+	return clusterBootstrap.Status.PackagesRevision != "" &&
+		clusterBootstrap.Status.PackagesRevision != GetPackagesRevision(clusterBootstrap.Spec)
+}
+
+// getRolloutMaxUnavailable returns the maximum number of unavailable clusters, at least 1 so that the rollout progresses
+func getRolloutMaxUnavailable(policy *runtanzuv1alpha3.PackageRolloutPolicy, total int) int {
+	maxUnavailable := intstr.FromInt(1)
+	if policy.Spec.MaxUnavailable != nil {
+		maxUnavailable = *policy.Spec.MaxUnavailable
+	}
+	value, err := intstr.GetScaledValueFromIntOrPercent(&maxUnavailable, total, false)
+	if err != nil || value < 1 {
+		return 1
+	}
+	return value
+}
+
+func getRolloutProgressDeadline(policy *runtanzuv1alpha3.PackageRolloutPolicy) time.Duration {
+	if policy.Spec.HealthGate.ProgressDeadline == nil {
+		return defaultRolloutProgressDeadline
+	}
+	return policy.Spec.HealthGate.ProgressDeadline.Duration
+}
+
+func rolloutClusterKey(namespace, name string) string {
+	return namespace + "/" + name
+}
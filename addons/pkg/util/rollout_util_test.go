@@ -0,0 +1,269 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/vmware-tanzu/carvel-kapp-controller/pkg/apis/kappctrl/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/addons/pkg/constants"
+	runtanzuv1alpha3 "github.com/vmware-tanzu/tanzu-framework/apis/run/v1alpha3"
+)
+
+const (
+	oldTKR = "v1.22.9---vmware.1-tkg.1"
+	newTKR = "v1.23.8---vmware.2-tkg.1"
+)
+
+func newRolloutCluster(name, resolvedTKR, approvedTKR, state string) RolloutCluster {
+	clusterBootstrap := &runtanzuv1alpha3.ClusterBootstrap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: &runtanzuv1alpha3.ClusterBootstrapTemplateSpec{
+			CNI: &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: antreaRefName},
+		},
+		Status: runtanzuv1alpha3.ClusterBootstrapStatus{
+			ResolvedTKR: resolvedTKR,
+			Packages:    []runtanzuv1alpha3.PackageStatus{{RefName: antreaRefName, State: state}},
+		},
+	}
+	if approvedTKR != "" {
+		clusterBootstrap.Annotations = map[string]string{constants.RolloutApprovedTKRAnnotation: approvedTKR}
+	}
+	return RolloutCluster{Namespace: "default", Name: name, TKR: newTKR, ClusterBootstrap: clusterBootstrap}
+}
+
+func rolloutStates(plan *PackageRolloutPlan) []runtanzuv1alpha3.ClusterRolloutState {
+	var states []runtanzuv1alpha3.ClusterRolloutState
+	for _, clusterStatus := range plan.Clusters {
+		states = append(states, clusterStatus.State)
+	}
+	return states
+}
+
+func startedClusters(plan *PackageRolloutPlan) []string {
+	var names []string
+	for _, cluster := range plan.Start {
+		names = append(names, cluster.Name)
+	}
+	return names
+}
+
+var _ = Describe("Rollout util test cases", func() {
+	var (
+		policy *runtanzuv1alpha3.PackageRolloutPolicy
+		now    time.Time
+	)
+
+	BeforeEach(func() {
+		policy = &runtanzuv1alpha3.PackageRolloutPolicy{}
+		now = time.Now()
+	})
+
+	Context("PlanPackageRollout()", func() {
+		It("should start the upgrade of the first batch of clusters", func() {
+			policy.Spec.BatchSize = 2
+			maxUnavailable := intstr.FromString("50%")
+			policy.Spec.MaxUnavailable = &maxUnavailable
+			plan := PlanPackageRollout(policy, []RolloutCluster{
+				newRolloutCluster("c3", oldTKR, "", string(v1alpha1.ReconcileSucceeded)),
+				newRolloutCluster("c1", oldTKR, "", string(v1alpha1.ReconcileSucceeded)),
+				newRolloutCluster("c2", oldTKR, "", string(v1alpha1.ReconcileSucceeded)),
+				newRolloutCluster("c4", newTKR, "", string(v1alpha1.ReconcileSucceeded)),
+			}, now)
+			Expect(startedClusters(plan)).To(Equal([]string{"c1", "c2"}))
+			Expect(rolloutStates(plan)).To(Equal([]runtanzuv1alpha3.ClusterRolloutState{
+				runtanzuv1alpha3.ClusterRolloutStateUpgrading,
+				runtanzuv1alpha3.ClusterRolloutStateUpgrading,
+				runtanzuv1alpha3.ClusterRolloutStatePending,
+				runtanzuv1alpha3.ClusterRolloutStateReady,
+			}))
+			Expect(plan.Clusters[0].StartTime.Time).To(Equal(now))
+		})
+
+		It("should not start the upgrade of more clusters than MaxUnavailable allows", func() {
+			policy.Spec.BatchSize = 2
+			plan := PlanPackageRollout(policy, []RolloutCluster{
+				newRolloutCluster("c1", oldTKR, "", string(v1alpha1.ReconcileSucceeded)),
+				newRolloutCluster("c2", oldTKR, "", string(v1alpha1.ReconcileSucceeded)),
+				newRolloutCluster("c3", newTKR, "", string(v1alpha1.ReconcileFailed)),
+			}, now)
+			Expect(startedClusters(plan)).To(BeEmpty())
+			Expect(plan.Clusters[2].State).To(Equal(runtanzuv1alpha3.ClusterRolloutStateUnhealthy))
+		})
+
+		It("should wait for the upgrading clusters to soak before starting the next batch", func() {
+			policy.Spec.HealthGate.SoakDuration = metav1.Duration{Duration: 10 * time.Minute}
+			startTime := metav1.NewTime(now.Add(-20 * time.Minute))
+			healthySince := metav1.NewTime(now.Add(-5 * time.Minute))
+			policy.Status.Clusters = []runtanzuv1alpha3.ClusterRolloutStatus{{
+				Namespace:    "default",
+				Name:         "c1",
+				TKR:          newTKR,
+				State:        runtanzuv1alpha3.ClusterRolloutStateUpgrading,
+				StartTime:    &startTime,
+				HealthySince: &healthySince,
+			}}
+			clusters := []RolloutCluster{
+				newRolloutCluster("c1", newTKR, newTKR, string(v1alpha1.ReconcileSucceeded)),
+				newRolloutCluster("c2", oldTKR, "", string(v1alpha1.ReconcileSucceeded)),
+			}
+			plan := PlanPackageRollout(policy, clusters, now)
+			Expect(plan.Clusters[0].State).To(Equal(runtanzuv1alpha3.ClusterRolloutStateUpgrading))
+			Expect(plan.Clusters[0].StartTime).To(Equal(&startTime))
+			Expect(startedClusters(plan)).To(BeEmpty())
+
+			plan = PlanPackageRollout(policy, clusters, now.Add(5*time.Minute))
+			Expect(plan.Clusters[0].State).To(Equal(runtanzuv1alpha3.ClusterRolloutStateReady))
+			Expect(startedClusters(plan)).To(Equal([]string{"c2"}))
+		})
+
+		It("should fail the upgrade of the clusters whose packages failed and halt the rollout", func() {
+			startTime := metav1.NewTime(now.Add(-time.Minute))
+			policy.Status.Clusters = []runtanzuv1alpha3.ClusterRolloutStatus{{
+				Namespace: "default",
+				Name:      "c1",
+				TKR:       newTKR,
+				State:     runtanzuv1alpha3.ClusterRolloutStateUpgrading,
+				StartTime: &startTime,
+			}}
+			maxUnavailable := intstr.FromInt(2)
+			policy.Spec.MaxUnavailable = &maxUnavailable
+			clusters := []RolloutCluster{
+				newRolloutCluster("c1", newTKR, newTKR, string(v1alpha1.ReconcileFailed)),
+				newRolloutCluster("c2", oldTKR, "", string(v1alpha1.ReconcileSucceeded)),
+			}
+			plan := PlanPackageRollout(policy, clusters, now)
+			Expect(plan.Clusters[0].State).To(Equal(runtanzuv1alpha3.ClusterRolloutStateFailed))
+			Expect(plan.Clusters[0].Message).To(ContainSubstring(antreaRefName))
+			Expect(plan.NewlyFailed).To(Equal([]string{"default/c1"}))
+			Expect(plan.Failed).To(Equal([]string{"default/c1"}))
+			Expect(startedClusters(plan)).To(BeEmpty())
+
+			policy.Status.Clusters = plan.Clusters
+			plan = PlanPackageRollout(policy, clusters, now)
+			Expect(plan.NewlyFailed).To(BeEmpty())
+			Expect(plan.Failed).To(Equal([]string{"default/c1"}))
+			Expect(startedClusters(plan)).To(BeEmpty())
+
+			// the rollout resumes once the packages of the failed cluster are reconciled successfully
+			clusters[0] = newRolloutCluster("c1", newTKR, newTKR, string(v1alpha1.ReconcileSucceeded))
+			policy.Status.Clusters = plan.Clusters
+			plan = PlanPackageRollout(policy, clusters, now)
+			Expect(plan.Clusters[0].State).To(Equal(runtanzuv1alpha3.ClusterRolloutStateReady))
+			Expect(plan.Failed).To(BeEmpty())
+			Expect(startedClusters(plan)).To(Equal([]string{"c2"}))
+		})
+
+		It("should hold the package changes at the same TKR until the rollout starts the upgrade of the cluster", func() {
+			cluster := newRolloutCluster("c1", newTKR, "", string(v1alpha1.ReconcileSucceeded))
+			Expect(IsPackageUpgradePending(cluster.ClusterBootstrap)).To(BeFalse())
+			cluster.ClusterBootstrap.Status.PackagesRevision = GetPackagesRevision(cluster.ClusterBootstrap.Spec)
+			cluster.ClusterBootstrap.Spec.CNI.RefName = "antrea.tanzu.vmware.com.1.7.1--vmware.1-tkg.1"
+			Expect(IsPackageUpgradePending(cluster.ClusterBootstrap)).To(BeTrue())
+
+			policy.Spec.Paused = true
+			plan := PlanPackageRollout(policy, []RolloutCluster{cluster}, now)
+			Expect(plan.Clusters[0].State).To(Equal(runtanzuv1alpha3.ClusterRolloutStatePending))
+			Expect(startedClusters(plan)).To(BeEmpty())
+
+			policy.Spec.Paused = false
+			plan = PlanPackageRollout(policy, []RolloutCluster{cluster}, now)
+			Expect(startedClusters(plan)).To(Equal([]string{"c1"}))
+
+			// the upgrade is in progress until the changed packages are rolled out to the cluster
+			cluster.ClusterBootstrap.Annotations = map[string]string{
+				constants.RolloutApprovedPackagesAnnotation: GetPackagesRevision(cluster.ClusterBootstrap.Spec),
+			}
+			policy.Status.Clusters = plan.Clusters
+			plan = PlanPackageRollout(policy, []RolloutCluster{cluster}, now)
+			Expect(plan.Clusters[0].State).To(Equal(runtanzuv1alpha3.ClusterRolloutStateUpgrading))
+
+			cluster.ClusterBootstrap.Status.PackagesRevision = GetPackagesRevision(cluster.ClusterBootstrap.Spec)
+			cluster.ClusterBootstrap.Status.Packages[0].RefName = cluster.ClusterBootstrap.Spec.CNI.RefName
+			policy.Status.Clusters = plan.Clusters
+			plan = PlanPackageRollout(policy, []RolloutCluster{cluster}, now)
+			Expect(plan.Clusters[0].State).To(Equal(runtanzuv1alpha3.ClusterRolloutStateReady))
+		})
+
+		It("should retry the upgrade of a failed cluster whose packages changed", func() {
+			cluster := newRolloutCluster("c1", newTKR, newTKR, string(v1alpha1.ReconcileFailed))
+			cluster.ClusterBootstrap.Status.PackagesRevision = GetPackagesRevision(cluster.ClusterBootstrap.Spec)
+			cluster.ClusterBootstrap.Spec.CNI.RefName = "antrea.tanzu.vmware.com.1.7.1--vmware.1-tkg.1"
+			startTime := metav1.NewTime(now.Add(-time.Minute))
+			policy.Status.Clusters = []runtanzuv1alpha3.ClusterRolloutStatus{{
+				Namespace: "default",
+				Name:      "c1",
+				TKR:       newTKR,
+				State:     runtanzuv1alpha3.ClusterRolloutStateFailed,
+				StartTime: &startTime,
+			}}
+			plan := PlanPackageRollout(policy, []RolloutCluster{
+				cluster,
+				newRolloutCluster("c2", oldTKR, "", string(v1alpha1.ReconcileSucceeded)),
+			}, now)
+			Expect(startedClusters(plan)).To(Equal([]string{"c1"}))
+			Expect(plan.Clusters[0].Message).To(ContainSubstring("retrying"))
+		})
+
+		It("should fail the upgrade of the clusters whose packages are not reconciled before the progress deadline", func() {
+			policy.Spec.HealthGate.ProgressDeadline = &metav1.Duration{Duration: 10 * time.Minute}
+			startTime := metav1.NewTime(now.Add(-11 * time.Minute))
+			policy.Status.Clusters = []runtanzuv1alpha3.ClusterRolloutStatus{{
+				Namespace: "default",
+				Name:      "c1",
+				TKR:       newTKR,
+				State:     runtanzuv1alpha3.ClusterRolloutStateUpgrading,
+				StartTime: &startTime,
+			}}
+			plan := PlanPackageRollout(policy, []RolloutCluster{
+				newRolloutCluster("c1", newTKR, newTKR, string(v1alpha1.Reconciling)),
+			}, now)
+			Expect(plan.Clusters[0].State).To(Equal(runtanzuv1alpha3.ClusterRolloutStateFailed))
+			Expect(plan.NewlyFailed).To(Equal([]string{"default/c1"}))
+		})
+
+		It("should not start any upgrade while paused", func() {
+			policy.Spec.Paused = true
+			plan := PlanPackageRollout(policy, []RolloutCluster{
+				newRolloutCluster("c1", oldTKR, "", string(v1alpha1.ReconcileSucceeded)),
+			}, now)
+			Expect(startedClusters(plan)).To(BeEmpty())
+			Expect(plan.Clusters[0].State).To(Equal(runtanzuv1alpha3.ClusterRolloutStatePending))
+		})
+	})
+
+	Context("GetPackagesRevision()", func() {
+		It("should change with the refName of any package", func() {
+			spec := &runtanzuv1alpha3.ClusterBootstrapTemplateSpec{
+				Kapp: &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: "kapp-controller.tanzu.vmware.com.0.38.5+vmware.1-tkg.1"},
+				CNI:  &runtanzuv1alpha3.ClusterBootstrapPackage{RefName: antreaRefName},
+			}
+			revision := GetPackagesRevision(spec)
+			Expect(revision).To(HaveLen(8))
+			spec.CNI.ValuesFrom = &runtanzuv1alpha3.ValuesFrom{SecretRef: "antrea-values"}
+			Expect(GetPackagesRevision(spec)).To(Equal(revision))
+			spec.Kapp.RefName = "kapp-controller.tanzu.vmware.com.0.41.2+vmware.1-tkg.1"
+			Expect(GetPackagesRevision(spec)).NotTo(Equal(revision))
+			Expect(GetPackagesRevision(nil)).To(BeEmpty())
+		})
+	})
+
+	Context("GetPackagesHealth()", func() {
+		It("should not be healthy until the current packages are reconciled", func() {
+			cluster := newRolloutCluster("c1", newTKR, "", string(v1alpha1.ReconcileSucceeded))
+			healthy, failed := GetPackagesHealth(cluster.ClusterBootstrap)
+			Expect(healthy).To(BeTrue())
+			Expect(failed).To(BeEmpty())
+
+			cluster.ClusterBootstrap.Spec.CNI.RefName = "antrea.tanzu.vmware.com.1.7.1--vmware.1-tkg.1"
+			healthy, _ = GetPackagesHealth(cluster.ClusterBootstrap)
+			Expect(healthy).To(BeFalse())
+		})
+	})
+})
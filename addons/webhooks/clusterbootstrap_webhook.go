@@ -6,6 +6,7 @@ package webhooks
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -197,6 +198,8 @@ func (wh *ClusterBootstrap) ValidateCreate(ctx context.Context, obj runtime.Obje
 		}
 	}
 
+	allErrs = append(allErrs, validatePackageOrder(clusterBootstrap.Spec)...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -206,6 +209,66 @@ func (wh *ClusterBootstrap) ValidateCreate(ctx context.Context, obj runtime.Obje
 		clusterBootstrap.Name, allErrs)
 }
 
+// validatePackageOrder validates the dependencies and the waves of the packages, which must refer to other packages of
+// the same or lower waves and must not form any cycle. kapp-controller is always installed first and can't be ordered.
+func validatePackageOrder(spec *runv1alpha3.ClusterBootstrapTemplateSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec == nil {
+		return allErrs
+	}
+	if spec.Kapp != nil && (len(spec.Kapp.DependsOn) != 0 || spec.Kapp.Wave != 0) {
+		allErrs = append(allErrs, field.Forbidden(getFieldPath("kapp"), "kapp-controller is always installed first, dependsOn and wave are not allowed"))
+	}
+
+	fldPaths := map[*runv1alpha3.ClusterBootstrapPackage]*field.Path{
+		spec.CNI: getFieldPath("cni"),
+		spec.CPI: getFieldPath("cpi"),
+		spec.CSI: getFieldPath("csi"),
+	}
+	for idx, pkg := range spec.AdditionalPackages {
+		fldPaths[pkg] = getFieldPath("additionalPackages").Index(idx)
+	}
+	pkgs := util.GetOrderedRolloutPackages(spec)
+	for _, pkg := range pkgs {
+		for idx, dependency := range pkg.DependsOn {
+			fldPath := fldPaths[pkg].Child("dependsOn").Index(idx)
+			if util.PackageMatchesDependency(dependency, pkg.RefName) {
+				allErrs = append(allErrs, field.Invalid(fldPath, dependency, "package can't depend on itself"))
+				continue
+			}
+			if spec.Kapp != nil && util.PackageMatchesDependency(dependency, spec.Kapp.RefName) {
+				// kapp-controller is always installed first
+				continue
+			}
+			var found bool
+			for _, other := range pkgs {
+				if !util.PackageMatchesDependency(dependency, other.RefName) {
+					continue
+				}
+				found = true
+				if other.Wave > pkg.Wave {
+					allErrs = append(allErrs, field.Invalid(fldPath, dependency,
+						fmt.Sprintf("package can't depend on package %s of the higher wave %d", other.RefName, other.Wave)))
+				}
+			}
+			if !found {
+				allErrs = append(allErrs, field.Invalid(fldPath, dependency, "package depends on unknown package"))
+			}
+		}
+	}
+
+	if cycle := util.FindPackageDependencyCycle(pkgs); cycle != nil {
+		for _, pkg := range pkgs {
+			if pkg.RefName == cycle[0] {
+				allErrs = append(allErrs, field.Invalid(fldPaths[pkg].Child("dependsOn"), pkg.DependsOn,
+					fmt.Sprintf("packages have cyclic dependencies: %s", strings.Join(cycle, " -> "))))
+				break
+			}
+		}
+	}
+	return allErrs
+}
+
 // validateClusterBootstrapPackage validates content clusterBootstrapPackage
 func (wh *ClusterBootstrap) validateClusterBootstrapPackage(ctx context.Context, pkg *runv1alpha3.ClusterBootstrapPackage, clusterBootstrapNamespace string, fldPath *field.Path) *field.Error {
 	if pkg == nil {
@@ -402,6 +465,8 @@ func (wh *ClusterBootstrap) ValidateUpdate(ctx context.Context, oldObj, newObj r
 		allErrs = append(allErrs, err...)
 	}
 
+	allErrs = append(allErrs, validatePackageOrder(newClusterBootstrap.Spec)...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -87,6 +87,8 @@ func (wh *ClusterBootstrapTemplate) ValidateCreate(ctx context.Context, obj runt
 		}
 	}
 
+	allErrs = append(allErrs, validatePackageOrder(clusterBootstrapTemplate.Spec)...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
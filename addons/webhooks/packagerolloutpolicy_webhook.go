@@ -0,0 +1,158 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	runv1alpha3 "github.com/vmware-tanzu/tanzu-framework/apis/run/v1alpha3"
+)
+
+var packagerolloutpolicylog = logf.Log.WithName("packagerolloutpolicy-resource")
+
+// PackageRolloutPolicy implements a validating webhook for PackageRolloutPolicy.
+type PackageRolloutPolicy struct {
+	Client client.Client
+}
+
+// SetupWebhookWithManager sets up PackageRolloutPolicy webhooks.
+func (wh *PackageRolloutPolicy) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&runv1alpha3.PackageRolloutPolicy{}).
+		WithValidator(wh).
+		Complete()
+}
+
+//+kubebuilder:webhook:verbs=create;update,path=/validate-run-tanzu-vmware-com-v1alpha3-packagerolloutpolicy,mutating=false,failurePolicy=fail,groups=run.tanzu.vmware.com,resources=packagerolloutpolicies,versions=v1alpha3,name=packagerolloutpolicy.validating.vmware.com
+
+var _ webhook.CustomValidator = &PackageRolloutPolicy{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (wh *PackageRolloutPolicy) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	policy, ok := obj.(*runv1alpha3.PackageRolloutPolicy)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a PackageRolloutPolicy but got a %T", obj))
+	}
+	packagerolloutpolicylog.Info("validate create", "name", policy.Name)
+	return wh.validateClusterSelector(ctx, policy)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (wh *PackageRolloutPolicy) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	policy, ok := newObj.(*runv1alpha3.PackageRolloutPolicy)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a PackageRolloutPolicy but got a %T", newObj))
+	}
+	packagerolloutpolicylog.Info("validate update", "name", policy.Name)
+	return wh.validateClusterSelector(ctx, policy)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (wh *PackageRolloutPolicy) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	// No validation required for deletion
+	return nil
+}
+
+// validateClusterSelector rejects the cluster selectors which may select the same cluster as the selector of another
+// PackageRolloutPolicy, as the package upgrades of a cluster are rolled out by a single policy
+func (wh *PackageRolloutPolicy) validateClusterSelector(ctx context.Context, policy *runv1alpha3.PackageRolloutPolicy) error {
+	fldPath := field.NewPath("spec").Child("clusterSelector")
+	if _, err := metav1.LabelSelectorAsSelector(&policy.Spec.ClusterSelector); err != nil {
+		return apierrors.NewInvalid(schema.GroupKind{Group: "run.tanzu.vmware.com", Kind: "PackageRolloutPolicy"},
+			policy.Name, field.ErrorList{field.Invalid(fldPath, policy.Spec.ClusterSelector, err.Error())})
+	}
+
+	policies := &runv1alpha3.PackageRolloutPolicyList{}
+	if err := wh.Client.List(ctx, policies); err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	var allErrs field.ErrorList
+	for i := range policies.Items {
+		other := &policies.Items[i]
+		if other.Name == policy.Name {
+			continue
+		}
+		overlap, err := labelSelectorsOverlap(&policy.Spec.ClusterSelector, &other.Spec.ClusterSelector)
+		if err != nil {
+			continue
+		}
+		if overlap {
+			allErrs = append(allErrs, field.Forbidden(fldPath,
+				fmt.Sprintf("the cluster selector may select the clusters selected by PackageRolloutPolicy %s", other.Name)))
+		}
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: "run.tanzu.vmware.com", Kind: "PackageRolloutPolicy"}, policy.Name, allErrs)
+}
+
+// labelSelectorsOverlap returns whether some labels match both label selectors. The requirements of both selectors
+// are combined by label key, and the labels exist unless the combined requirements of a key contradict each other.
+func labelSelectorsOverlap(selector, other *metav1.LabelSelector) (bool, error) {
+	var requirements labels.Requirements
+	for _, s := range []*metav1.LabelSelector{selector, other} {
+		parsed, err := metav1.LabelSelectorAsSelector(s)
+		if err != nil {
+			return false, err
+		}
+		reqs, _ := parsed.Requirements()
+		requirements = append(requirements, reqs...)
+	}
+
+	byKey := make(map[string][]labels.Requirement)
+	for _, requirement := range requirements {
+		byKey[requirement.Key()] = append(byKey[requirement.Key()], requirement)
+	}
+	for _, reqs := range byKey {
+		if !labelRequirementsSatisfiable(reqs) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// labelRequirementsSatisfiable returns whether a value of the label, or its absence, meets all the requirements of
+// the label
+func labelRequirementsSatisfiable(requirements []labels.Requirement) bool {
+	var allowed, excluded sets.String
+	var exists, notExists bool
+	for i := range requirements {
+		values := requirements[i].Values()
+		switch requirements[i].Operator() {
+		case selection.In, selection.Equals, selection.DoubleEquals:
+			exists = true
+			if allowed == nil {
+				allowed = values
+			} else {
+				allowed = allowed.Intersection(values)
+			}
+		case selection.NotIn, selection.NotEquals:
+			excluded = excluded.Union(values)
+		case selection.Exists:
+			exists = true
+		case selection.DoesNotExist:
+			notExists = true
+		}
+	}
+	if exists && notExists {
+		return false
+	}
+	// a label value always exists outside of the excluded values, unless the values are restricted
+	return allowed == nil || allowed.Difference(excluded).Len() != 0
+}
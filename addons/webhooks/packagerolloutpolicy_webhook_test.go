@@ -0,0 +1,68 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/tanzu-framework/addons/webhooks"
+	runv1alpha3 "github.com/vmware-tanzu/tanzu-framework/apis/run/v1alpha3"
+)
+
+func newPackageRolloutPolicy(name string, selector metav1.LabelSelector) *runv1alpha3.PackageRolloutPolicy {
+	return &runv1alpha3.PackageRolloutPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       runv1alpha3.PackageRolloutPolicySpec{ClusterSelector: selector},
+	}
+}
+
+var _ = Describe("PackageRolloutPolicyWebhook", func() {
+	var wh *webhooks.PackageRolloutPolicy
+
+	BeforeEach(func() {
+		policyScheme := runtime.NewScheme()
+		Expect(runv1alpha3.AddToScheme(policyScheme)).To(Succeed())
+		existing := newPackageRolloutPolicy("production", metav1.LabelSelector{
+			MatchLabels: map[string]string{"environment": "production"},
+		})
+		wh = &webhooks.PackageRolloutPolicy{Client: fake.NewClientBuilder().WithScheme(policyScheme).WithObjects(existing).Build()}
+	})
+
+	It("should accept the policies whose cluster selectors do not overlap", func() {
+		for _, selector := range []metav1.LabelSelector{
+			{MatchLabels: map[string]string{"environment": "staging"}},
+			{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "environment", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"production"}}}},
+			{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "environment", Operator: metav1.LabelSelectorOpDoesNotExist}}},
+		} {
+			Expect(wh.ValidateCreate(context.TODO(), newPackageRolloutPolicy("other", selector))).To(Succeed())
+		}
+	})
+
+	It("should reject the policies whose cluster selectors overlap", func() {
+		for _, selector := range []metav1.LabelSelector{
+			{},
+			{MatchLabels: map[string]string{"region": "emea"}},
+			{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "environment", Operator: metav1.LabelSelectorOpIn, Values: []string{"production", "staging"}}}},
+			{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "environment", Operator: metav1.LabelSelectorOpExists}}},
+		} {
+			err := wh.ValidateCreate(context.TODO(), newPackageRolloutPolicy("other", selector))
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("PackageRolloutPolicy production"))
+		}
+	})
+
+	It("should not compare the updated policy with itself", func() {
+		policy := newPackageRolloutPolicy("production", metav1.LabelSelector{
+			MatchLabels: map[string]string{"environment": "production", "region": "emea"},
+		})
+		Expect(wh.ValidateUpdate(context.TODO(), policy, policy)).To(Succeed())
+	})
+})
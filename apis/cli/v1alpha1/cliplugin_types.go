@@ -34,6 +34,11 @@ type Artifact struct {
 	URI string `json:"uri,omitempty"`
 	// SHA256 hash of the plugin binary.
 	Digest string `json:"digest,omitempty"`
+	// Signature is the base64 encoded cosign signature of the plugin binary.
+	// Not required for Image artifacts as the signature is attached to the image.
+	Signature string `json:"signature,omitempty"`
+	// Certificate is the base64 encoded PEM signing certificate for keyless signatures.
+	Certificate string `json:"certificate,omitempty"`
 	// Type of the binary artifact. Valid values are S3, GCP, OCIImage.
 	Type string `json:"type"`
 	// OS of the plugin binary in `GOOS` format.
@@ -42,6 +47,43 @@ type Artifact struct {
 	Arch string `json:"arch"`
 }
 
+// PluginDependency is a plugin required by another plugin.
+type PluginDependency struct {
+	// Name is the name of the required plugin.
+	Name string `json:"name"`
+	// Target is the target of the required plugin.
+	// Defaults to the target of the plugin declaring the dependency.
+	Target Target `json:"target,omitempty"`
+	// Version is the semantic version constraint of the required plugin
+	// E.g., ">= v1.2.0, < v2.0.0"
+	Version string `json:"version,omitempty"`
+}
+
+// PluginRequirements defines the compatibility and dependency requirements of a plugin version.
+type PluginRequirements struct {
+	// CoreVersion is the semantic version constraint of the Tanzu CLI core
+	// the plugin is compatible with. E.g., ">= v0.28.0"
+	CoreVersion string `json:"coreVersion,omitempty"`
+	// Dependencies are the plugins required by the plugin.
+	Dependencies []PluginDependency `json:"dependencies,omitempty"`
+}
+
+// PluginPermissions declares the capabilities a plugin version needs. The CLI core
+// passes only those to the plugin when running it.
+type PluginPermissions struct {
+	// Network is the list of network endpoints the plugin connects to, as host or
+	// host:port patterns. The host may start with a "*." wildcard, e.g. "*.tmc.cloud.vmware.com:443".
+	Network []string `json:"network,omitempty" yaml:"network,omitempty"`
+	// ConfigKeys is the list of config keys the plugin reads, either top-level keys,
+	// e.g. "contexts", or client options, e.g. "clientOptions.features".
+	ConfigKeys []string `json:"configKeys,omitempty" yaml:"configKeys,omitempty"`
+	// Env is the list of environment variables the plugin reads in addition to the
+	// standard ones, e.g. "PATH" or "HOME". Patterns may end with a "*" wildcard, e.g. "KUBE*".
+	Env []string `json:"env,omitempty" yaml:"env,omitempty"`
+	// Credentials specifies whether the plugin needs the access token of the current contexts.
+	Credentials bool `json:"credentials,omitempty" yaml:"credentials,omitempty"`
+}
+
 // CLIPluginSpec defines the desired state of CLIPlugin.
 type CLIPluginSpec struct {
 	// Description is the plugin's description.
@@ -60,6 +102,10 @@ type CLIPluginSpec struct {
 	Optional bool `json:"optional"`
 	// Target specifies the target of the plugin. Only needed for standalone plugins
 	Target Target `json:"target,omitempty"`
+	// Requirements contains the compatibility and dependency requirements for every supported version.
+	Requirements map[string]PluginRequirements `json:"requirements,omitempty"`
+	// Permissions contains the permissions declared for every supported version.
+	Permissions map[string]PluginPermissions `json:"permissions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
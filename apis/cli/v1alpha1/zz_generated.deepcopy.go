@@ -122,6 +122,20 @@ func (in *CLIPluginSpec) DeepCopyInto(out *CLIPluginSpec) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.Requirements != nil {
+		in, out := &in.Requirements, &out.Requirements
+		*out = make(map[string]PluginRequirements, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make(map[string]PluginPermissions, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CLIPluginSpec.
@@ -133,3 +147,68 @@ func (in *CLIPluginSpec) DeepCopy() *CLIPluginSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginDependency) DeepCopyInto(out *PluginDependency) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginDependency.
+func (in *PluginDependency) DeepCopy() *PluginDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginPermissions) DeepCopyInto(out *PluginPermissions) {
+	*out = *in
+	if in.Network != nil {
+		in, out := &in.Network, &out.Network
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigKeys != nil {
+		in, out := &in.ConfigKeys, &out.ConfigKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginPermissions.
+func (in *PluginPermissions) DeepCopy() *PluginPermissions {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginPermissions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginRequirements) DeepCopyInto(out *PluginRequirements) {
+	*out = *in
+	if in.Dependencies != nil {
+		in, out := &in.Dependencies, &out.Dependencies
+		*out = make([]PluginDependency, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginRequirements.
+func (in *PluginRequirements) DeepCopy() *PluginRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -9,6 +9,34 @@ import (
 	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
+const (
+	// ConditionPackagesBlocked is true when the PackageInstalls of some packages are not created on the cluster yet,
+	// as the packages they depend on are not reconciled successfully
+	ConditionPackagesBlocked clusterapiv1beta1.ConditionType = "PackagesBlocked"
+
+	// ReasonDependenciesNotReconciled is the reason of ConditionPackagesBlocked while the packages they depend on are
+	// being created and reconciled
+	ReasonDependenciesNotReconciled = "DependenciesNotReconciled"
+	// ReasonDependenciesFailed is the reason of ConditionPackagesBlocked when the PackageInstall of a package they depend
+	// on failed to reconcile
+	ReasonDependenciesFailed = "DependenciesFailed"
+	// ReasonDependenciesNotCreated is the reason of ConditionPackagesBlocked when the PackageInstall of a package they
+	// depend on is not created for too long, although it does not wait for other packages
+	ReasonDependenciesNotCreated = "DependenciesNotCreated"
+
+	// ConditionRemoteResourcesDrifted is true when the addon resources on the cluster were changed or deleted out of
+	// band and the changes are only reported, false when the last changes were reverted
+	ConditionRemoteResourcesDrifted clusterapiv1beta1.ConditionType = "RemoteResourcesDrifted"
+
+	// ReasonDriftDetected is the reason of ConditionRemoteResourcesDrifted when the changes are only reported
+	ReasonDriftDetected = "DriftDetected"
+	// ReasonDriftReverted is the reason of ConditionRemoteResourcesDrifted when the changes were reverted
+	ReasonDriftReverted = "DriftReverted"
+
+	// PackageStatePending is the state of the packages whose PackageInstall is not created on the cluster yet
+	PackageStatePending = "Pending"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:path=clusterbootstraps,shortName=cb,scope=Namespaced
@@ -18,6 +46,8 @@ import (
 // +kubebuilder:printcolumn:name="Kapp",type="string",JSONPath=".spec.kapp.refName",description="Kapp package name and version"
 // +kubebuilder:printcolumn:name="Additional Packages",type="string",JSONPath=".spec.additionalPackages[*].refName",description="Additional packages",priority=10
 // +kubebuilder:printcolumn:name="Resolved_TKR",type="string",JSONPath=".status.resolvedTKR",description="Resolved TKR name"
+// +kubebuilder:printcolumn:name="Reconciled",type="string",JSONPath=".status.reconciledPackages",description="Packages reconciled successfully out of all packages"
+// +kubebuilder:printcolumn:name="Package_States",type="string",JSONPath=".status.packages[*].state",description="Reconcile state of the packages",priority=10
 
 // ClusterBootstrap is the Schema for the ClusterBootstraps API
 type ClusterBootstrap struct {
@@ -33,6 +63,48 @@ type ClusterBootstrapStatus struct {
 	ResolvedTKR string `json:"resolvedTKR,omitempty"`
 
 	Conditions clusterapiv1beta1.Conditions `json:"conditions,omitempty"`
+
+	// Packages is the rollout status of each package on the cluster
+	// +optional
+	Packages []PackageStatus `json:"packages,omitempty"`
+
+	// ReconciledPackages is the number of packages reconciled successfully out of all packages, e.g. 4/5
+	// +optional
+	ReconciledPackages string `json:"reconciledPackages,omitempty"`
+
+	// PackagesRevision is the revision of the packages whose PackageInstalls are created or patched on the cluster.
+	// The package changes of a cluster selected by a PackageRolloutPolicy are only rolled out once the policy approves
+	// the revision of the packages of the ClusterBootstrap.
+	// +optional
+	PackagesRevision string `json:"packagesRevision,omitempty"`
+
+	// AppliedResources are the addon resources created or patched on the cluster, e.g. Secret tkg-system/foo-values.
+	// An applied resource missing from the cluster was deleted out of band, and is reported as drifted.
+	// +optional
+	AppliedResources []string `json:"appliedResources,omitempty"`
+}
+
+// PackageStatus is the rollout status of a package on the cluster
+type PackageStatus struct {
+	// RefName is the refName of the package in the ClusterBootstrap
+	RefName string `json:"refName"`
+
+	// Version is the version of the package installed successfully on the cluster
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// State is the reconcile state of the PackageInstall of the package: Pending while the PackageInstall is not
+	// created yet, otherwise the summarized condition of the PackageInstall, e.g. ReconcileSucceeded or ReconcileFailed
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// LastError is the error of the last failed reconciliation of the PackageInstall, cleared once it succeeds
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// LastTransitionTime is the last time the state of the package changed
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // GetConditions returns the set of conditions for this object. implements Setter interface
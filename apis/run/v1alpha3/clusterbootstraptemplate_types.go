@@ -49,6 +49,16 @@ type ClusterBootstrapPackage struct {
 	RefName string `json:"refName"`
 	// +optional
 	ValuesFrom *ValuesFrom `json:"valuesFrom,omitempty"`
+	// DependsOn lists the packages which must be reconciled successfully on the cluster before the
+	// PackageInstall of this package is created. A package is referred to by its short name (e.g. antrea),
+	// its package name (e.g. antrea.tanzu.vmware.com) or its refName.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Wave orders the rollout of the packages. The PackageInstall of a package is created once the packages
+	// of all lower waves are reconciled successfully on the cluster.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Wave int32 `json:"wave,omitempty"`
 }
 
 // ValuesFrom specifies how values for package install are retrieved from
@@ -0,0 +1,187 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	// ConditionRolloutPaused is true when the rollout does not start the upgrades of further clusters
+	ConditionRolloutPaused clusterapiv1beta1.ConditionType = "RolloutPaused"
+
+	// ReasonRolloutPausedByUser is the reason of ConditionRolloutPaused when the policy is paused by the user
+	ReasonRolloutPausedByUser = "PausedByUser"
+	// ReasonRolloutHalted is the reason of ConditionRolloutPaused when the rollout is halted as the upgrade of a
+	// cluster failed
+	ReasonRolloutHalted = "Halted"
+)
+
+// ClusterRolloutState is the state of the package upgrade of a cluster selected by a PackageRolloutPolicy
+type ClusterRolloutState string
+
+const (
+	// ClusterRolloutStatePending is the state of the clusters waiting for the rollout to start their upgrade
+	ClusterRolloutStatePending ClusterRolloutState = "Pending"
+	// ClusterRolloutStateUpgrading is the state of the clusters whose upgrade started and whose packages are not
+	// healthy for the soak duration yet
+	ClusterRolloutStateUpgrading ClusterRolloutState = "Upgrading"
+	// ClusterRolloutStateFailed is the state of the clusters whose packages failed to reconcile during the upgrade,
+	// or did not become healthy before the progress deadline
+	ClusterRolloutStateFailed ClusterRolloutState = "Failed"
+	// ClusterRolloutStateReady is the state of the up-to-date clusters whose packages are healthy
+	ClusterRolloutStateReady ClusterRolloutState = "Ready"
+	// ClusterRolloutStateUnhealthy is the state of the up-to-date clusters whose packages are not healthy
+	ClusterRolloutStateUnhealthy ClusterRolloutState = "Unhealthy"
+)
+
+// PackageRolloutPolicySpec defines the desired state of PackageRolloutPolicy
+type PackageRolloutPolicySpec struct {
+	// ClusterSelector selects the clusters whose package upgrades are rolled out by the policy. The packages of a
+	// ClusterBootstrap are only upgraded to the TKR of its cluster once the policy starts the upgrade of the cluster.
+	ClusterSelector metav1.LabelSelector `json:"clusterSelector"`
+
+	// BatchSize is the number of clusters whose upgrades start together. The next batch starts once all the clusters
+	// of the current batch are ready. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	BatchSize int32 `json:"batchSize,omitempty"`
+
+	// MaxUnavailable is the maximum number of selected clusters which may be upgrading or unhealthy when a batch
+	// starts, either an absolute number or a percentage of the selected clusters. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// HealthGate defines when the upgrade of a cluster is complete
+	// +optional
+	HealthGate PackageRolloutHealthGate `json:"healthGate,omitempty"`
+
+	// Paused stops the rollout from starting the upgrades of further clusters
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// PackageRolloutHealthGate defines when the upgrade of a cluster is complete, based on the status of the
+// PackageInstalls of its packages
+type PackageRolloutHealthGate struct {
+	// SoakDuration is how long all the packages of a cluster must be reconciled successfully before its upgrade is
+	// complete. Defaults to 0.
+	// +optional
+	SoakDuration metav1.Duration `json:"soakDuration,omitempty"`
+
+	// ProgressDeadline is how long the packages of a cluster may take to be reconciled successfully before its
+	// upgrade fails. Defaults to 30m.
+	// +optional
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
+}
+
+// PackageRolloutPolicyStatus defines the observed state of PackageRolloutPolicy
+type PackageRolloutPolicyStatus struct {
+	// ObservedGeneration is the latest generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// SelectedClusters is the number of clusters selected by the policy
+	// +optional
+	SelectedClusters int32 `json:"selectedClusters,omitempty"`
+
+	// ReadyClusters is the number of selected clusters which are up-to-date and healthy
+	// +optional
+	ReadyClusters int32 `json:"readyClusters,omitempty"`
+
+	// UpgradingClusters is the number of selected clusters being upgraded
+	// +optional
+	UpgradingClusters int32 `json:"upgradingClusters,omitempty"`
+
+	// FailedClusters is the number of selected clusters whose upgrade failed
+	// +optional
+	FailedClusters int32 `json:"failedClusters,omitempty"`
+
+	// Paused is true while the rollout is halted as the upgrade of a selected cluster failed. The rollout resumes once
+	// the packages of the failed clusters are reconciled successfully, or the clusters are not selected anymore.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Clusters is the rollout status of the selected clusters
+	// +optional
+	Clusters []ClusterRolloutStatus `json:"clusters,omitempty"`
+
+	// +optional
+	Conditions clusterapiv1beta1.Conditions `json:"conditions,omitempty"`
+}
+
+// ClusterRolloutStatus is the rollout status of a cluster selected by a PackageRolloutPolicy
+type ClusterRolloutStatus struct {
+	// Namespace is the namespace of the cluster
+	Namespace string `json:"namespace"`
+
+	// Name is the name of the cluster
+	Name string `json:"name"`
+
+	// TKR is the TKR the packages of the cluster are upgraded to
+	// +optional
+	TKR string `json:"tkr,omitempty"`
+
+	// State is the state of the upgrade of the cluster
+	// +optional
+	State ClusterRolloutState `json:"state,omitempty"`
+
+	// StartTime is the time the upgrade of the cluster started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// HealthySince is the time since which all the packages of the upgraded cluster are reconciled successfully
+	// +optional
+	HealthySince *metav1.Time `json:"healthySince,omitempty"`
+
+	// Message describes the state of the upgrade of the cluster
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=packagerolloutpolicies,shortName=prp,scope=Cluster
+// +kubebuilder:printcolumn:name="Selected",type="integer",JSONPath=".status.selectedClusters",description="Number of selected clusters"
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyClusters",description="Number of up-to-date and healthy clusters"
+// +kubebuilder:printcolumn:name="Upgrading",type="integer",JSONPath=".status.upgradingClusters",description="Number of clusters being upgraded"
+// +kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failedClusters",description="Number of clusters whose upgrade failed"
+// +kubebuilder:printcolumn:name="Paused",type="boolean",JSONPath=".spec.paused",description="Whether the rollout is paused by the user"
+// +kubebuilder:printcolumn:name="Halted",type="boolean",JSONPath=".status.paused",description="Whether the rollout is halted as the upgrade of a cluster failed"
+
+// PackageRolloutPolicy is the Schema for the PackageRolloutPolicies API. It staggers the package upgrades of the
+// ClusterBootstraps of the selected clusters.
+type PackageRolloutPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PackageRolloutPolicySpec   `json:"spec,omitempty"`
+	Status PackageRolloutPolicyStatus `json:"status,omitempty"`
+}
+
+// GetConditions returns the set of conditions for this object. implements Setter interface
+func (p *PackageRolloutPolicy) GetConditions() clusterapiv1beta1.Conditions {
+	return p.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object. implements Setter interface
+func (p *PackageRolloutPolicy) SetConditions(conditions clusterapiv1beta1.Conditions) {
+	p.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// PackageRolloutPolicyList contains a list of PackageRolloutPolicy
+type PackageRolloutPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PackageRolloutPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PackageRolloutPolicy{}, &PackageRolloutPolicyList{})
+}
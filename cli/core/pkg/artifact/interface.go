@@ -8,6 +8,7 @@ package artifact
 import (
 	"net/url"
 	"path/filepath"
+	"strings"
 )
 
 // Artifact is an interface to download a single plugin binary.
@@ -30,6 +31,8 @@ func NewURIArtifact(uri string) (Artifact, error) {
 		return NewHTTPArtifact(uri), nil
 	case uriSchemeLocal:
 		return NewLocalArtifact(filepath.Join(u.Host, u.Path)), nil
+	case uriSchemeOCI:
+		return NewOCIArtifact(strings.TrimPrefix(uri, uriSchemeOCI+"://")), nil
 	default:
 		// The URI could point to a relative path without specifying any scheme
 		// as prefix. Hence, defaulting to local artifact.
@@ -30,6 +30,14 @@ var _ = Describe("Unit tests for local artifact", func() {
 			Expect(err).To(BeNil())
 		})
 	})
+	When("url is oci", func() {
+		It("should return an OCI artifact for the image", func() {
+			uriArtifact, err := NewURIArtifact("oci://localhost:5000/tanzu-cli-plugins/login:v0.2.0")
+			Expect(err).To(BeNil())
+			Expect(uriArtifact).To(BeAssignableToTypeOf(&OCIArtifact{}))
+			Expect(uriArtifact.(*OCIArtifact).Image).To(Equal("localhost:5000/tanzu-cli-plugins/login:v0.2.0"))
+		})
+	})
 	When("url is default", func() {
 		It("should not return error", func() {
 			uriArtifact, err := NewURIArtifact("/default")
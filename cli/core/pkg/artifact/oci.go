@@ -10,9 +10,14 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/registry"
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/utils"
 )
 
+const (
+	uriSchemeOCI = "oci"
+)
+
 // A file map getter takes an OCI image name and returns a map representing the
 // file contents.
 type fileMapGetterFn func(string) (map[string][]byte, error)
@@ -31,6 +36,15 @@ func NewOCIArtifact(image string) Artifact {
 	}
 }
 
+// NewOCIArtifactFromRegistry creates OCI Artifact object which fetches
+// the image using the given registry client
+func NewOCIArtifactFromRegistry(image string, reg registry.Registry) Artifact {
+	return &OCIArtifact{
+		Image:                image,
+		getFilesMapFromImage: reg.GetFiles,
+	}
+}
+
 // Fetch an artifact.
 func (g *OCIArtifact) Fetch() ([]byte, error) {
 	filesMap, err := g.getFilesMapFromImage(g.Image)
@@ -4,6 +4,8 @@ package artifact
 
 import (
 	"testing"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/fakes"
 )
 
 func TestOCIArtifactWhenMultipleFilesFound(t *testing.T) {
@@ -33,3 +35,22 @@ func TestOCIArtifactWhenMultipleFilesFound(t *testing.T) {
 		t.Fatalf("Did not receive the expected error message. Expected '%s', got '%s'", expectedErrorMessage, err.Error())
 	}
 }
+
+func TestOCIArtifactFromRegistry(t *testing.T) {
+	reg := &fakes.Registry{}
+	reg.GetFilesReturns(map[string][]byte{
+		"tanzu-foo":      []byte("plugin binary"),
+		"test/tanzu-foo": []byte("test plugin binary"),
+	}, nil)
+
+	data, err := NewOCIArtifactFromRegistry("example.com/plugins/foo:v1.0.0", reg).Fetch()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(data) != "plugin binary" {
+		t.Fatalf("Expected the plugin binary, got '%s'", string(data))
+	}
+	if reg.GetFilesArgsForCall(0) != "example.com/plugins/foo:v1.0.0" {
+		t.Fatalf("Unexpected image in call to get files map: '%s'", reg.GetFilesArgsForCall(0))
+	}
+}
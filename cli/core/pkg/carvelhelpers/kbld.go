@@ -7,12 +7,17 @@ package carvelhelpers
 import (
 	"bytes"
 	"os"
+	"sync"
 
 	"github.com/cppforlife/go-cli-ui/ui"
 	"github.com/k14s/kbld/pkg/kbld/cmd"
 	"github.com/pkg/errors"
 )
 
+// stderrMutex serializes the replacement of os.Stderr as plugin discovery
+// sources are processed concurrently
+var stderrMutex sync.Mutex
+
 // ResolveImagesInPackage resolves the images using kbld tool
 // Implements similar functionality as `kbld -f <file1> -f <file2>`
 func ResolveImagesInPackage(files []string) ([]byte, error) {
@@ -23,6 +28,8 @@ func ResolveImagesInPackage(files []string) ([]byte, error) {
 	kbldResolveOptions.BuildConcurrency = 1
 
 	// backup and reset stderr to avoid kbld to write anything to stderr
+	stderrMutex.Lock()
+	defer stderrMutex.Unlock()
 	stdErr := os.Stderr
 	os.Stderr = nil
 	err := kbldResolveOptions.Run()
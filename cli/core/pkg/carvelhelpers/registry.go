@@ -21,7 +21,7 @@ import (
 // It takes os environment variables for custom repository and proxy
 // configuration into account while downloading image from repository
 func GetFilesMapFromImage(imageWithTag string) (map[string][]byte, error) {
-	reg, err := newRegistry()
+	reg, err := NewRegistry()
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to initialize registry")
 	}
@@ -31,7 +31,7 @@ func GetFilesMapFromImage(imageWithTag string) (map[string][]byte, error) {
 // DownloadImageBundleAndSaveFilesToTempDir reads OCI image and saves file to temp dir
 // returns temp configuration dir with downloaded imgpkg bundle
 func DownloadImageBundleAndSaveFilesToTempDir(imageWithTag string) (string, error) {
-	reg, err := newRegistry()
+	reg, err := NewRegistry()
 	if err != nil {
 		return "", errors.Wrapf(err, "unable to initialize registry")
 	}
@@ -47,19 +47,31 @@ func DownloadImageBundleAndSaveFilesToTempDir(imageWithTag string) (string, erro
 	return tmpDir, nil
 }
 
-// newRegistry returns a new registry object by also
+// NewRegistry returns a new registry object by also
 // taking into account for any custom registry or proxy
 // environment variable provided by the user
-func newRegistry() (registry.Registry, error) {
+func NewRegistry() (registry.Registry, error) {
+	registryOpts, err := NewRegistryOpts()
+	if err != nil {
+		return nil, err
+	}
+	return registry.New(registryOpts)
+}
+
+// NewRegistryOpts returns the options to access the registries with the
+// CA certificates and TLS verification configured by the user
+func NewRegistryOpts() (*ctlimg.Opts, error) {
 	verifyCerts := true
 	skipVerifyCerts := os.Getenv(constants.ConfigVariableCustomImageRepositorySkipTLSVerify)
 	if strings.EqualFold(skipVerifyCerts, "true") {
 		verifyCerts = false
 	}
 
+	// Registry credentials are resolved from the docker config and credential helpers
 	registryOpts := &ctlimg.Opts{
 		VerifyCerts: verifyCerts,
-		Anon:        true,
+		Anon:        false,
+		CACertPaths: registry.GetCACertPaths(),
 	}
 
 	if runtime.GOOS == "windows" {
@@ -82,5 +94,5 @@ func newRegistry() (registry.Registry, error) {
 		registryOpts.CACertPaths = append(registryOpts.CACertPaths, filePath)
 	}
 
-	return registry.New(registryOpts)
+	return registryOpts, nil
 }
@@ -4,23 +4,25 @@
 package catalog
 
 import (
-	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
 
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apimachineryjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
 
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/common"
-	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/utils"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/config"
 	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
 )
 
 const (
-	// catalogCacheFileName is the name of the file which holds Catalog cache
+	// catalogCacheFileName is the name of the file which holds the Catalog cache
+	// of previous CLI versions. It is migrated to the plugin inventory.
 	catalogCacheFileName = "catalog.yaml"
 )
 
@@ -30,65 +32,207 @@ var (
 )
 
 // ContextCatalog denotes a local plugin catalog for a given context or
-// stand-alone.
+// stand-alone. The plugins are stored in the plugin inventory.
 type ContextCatalog struct {
-	sharedCatalog *cliapi.Catalog
-	plugins       cliapi.PluginAssociation
+	context string
+}
+
+// PluginHistoryEntry is an installation of a plugin recorded in the catalog history.
+type PluginHistoryEntry struct {
+	// Descriptor is the descriptor of the installed plugin.
+	Descriptor cliapi.PluginDescriptor
+	// InstalledAt is the time at which the installation was activated.
+	InstalledAt time.Time
+	// Active is true for the installation currently used by the CLI.
+	Active bool
 }
 
 // NewContextCatalog creates context-aware catalog
 func NewContextCatalog(context string) (*ContextCatalog, error) {
-	sc, err := getCatalogCache()
-	if err != nil {
+	// Opening the inventory migrates the catalog cache of previous CLI versions
+	if err := withInventory(func(*Inventory) error { return nil }); err != nil {
 		return nil, err
 	}
+	return &ContextCatalog{context: context}, nil
+}
 
-	var plugins cliapi.PluginAssociation
-	if context == "" {
-		plugins = sc.StandAlonePlugins
-	} else {
-		var ok bool
-		plugins, ok = sc.ServerPlugins[context]
-		if !ok {
-			plugins = make(cliapi.PluginAssociation)
-			sc.ServerPlugins[context] = plugins
+// Upsert inserts/updates the given plugin. The binaries of the installations
+// dropped from the history which are not used by any other plugin are deleted.
+func (c *ContextCatalog) Upsert(plugin *cliapi.PluginDescriptor) error {
+	var unused []string
+	err := c.update(func(tx *InventoryTx) error {
+		pluginNameTarget := PluginNameTarget(plugin.Name, plugin.Target)
+		rec, err := tx.Get(c.context, pluginNameTarget)
+		if err != nil {
+			return err
 		}
-	}
+		if rec == nil {
+			rec = &PluginRecord{Context: c.context, Name: plugin.Name, Target: plugin.Target}
+		}
+		pruned := recordInstallation(rec, plugin.InstallationPath)
+		rec.InstallationPath = plugin.InstallationPath
+		rec.Source = plugin.Discovery
 
-	return &ContextCatalog{
-		sharedCatalog: sc,
-		plugins:       plugins,
-	}, nil
+		if err := tx.PutDescriptor(plugin); err != nil {
+			return err
+		}
+		if err := tx.Put(rec); err != nil {
+			return err
+		}
+		unused, err = unusedInstallations(tx, pruned)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	// The binaries are only deleted once they are no longer referenced by the inventory
+	for _, installationPath := range unused {
+		if err := os.Remove(installationPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return errors.Wrapf(err, "could not delete the pruned installation %q of plugin %q", installationPath, plugin.Name)
+		}
+	}
+	return nil
 }
 
-// Upsert inserts/updates the given plugin.
-func (c *ContextCatalog) Upsert(plugin *cliapi.PluginDescriptor) error {
-	pluginNameTarget := PluginNameTarget(plugin.Name, plugin.Target)
+// recordInstallation adds the installation path to the front of the plugin history
+// and drops the installations exceeding the configured history limit. It returns the
+// installation paths dropped from the history.
+func recordInstallation(rec *PluginRecord, installationPath string) []string {
+	history := rec.History
+	if len(history) == 0 && rec.InstallationPath != "" && rec.InstallationPath != installationPath {
+		// Seed the history with the installation activated before history was tracked
+		history = []cliapi.PluginInstallation{{InstallationPath: rec.InstallationPath}}
+	}
+	if len(history) != 0 && history[0].InstallationPath == installationPath {
+		return nil
+	}
 
-	c.plugins[pluginNameTarget] = plugin.InstallationPath
-	c.sharedCatalog.IndexByPath[plugin.InstallationPath] = *plugin
+	updated := []cliapi.PluginInstallation{{InstallationPath: installationPath, InstalledAt: metav1.Now()}}
+	for _, installation := range history {
+		if installation.InstallationPath != installationPath {
+			updated = append(updated, installation)
+		}
+	}
+	var pruned []string
+	if limit := config.GetPluginHistoryLimit() + 1; len(updated) > limit {
+		for _, installation := range updated[limit:] {
+			pruned = append(pruned, installation.InstallationPath)
+		}
+		updated = updated[:limit]
+	}
+	rec.History = updated
+	return pruned
+}
+
+// unusedInstallations returns the given installation paths which are neither active nor
+// part of the history of any plugin in any context, and deletes their descriptors.
+func unusedInstallations(tx *InventoryTx, installationPaths []string) ([]string, error) {
+	if len(installationPaths) == 0 {
+		return nil, nil
+	}
+	records, err := tx.Query(&PluginQuery{})
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	for i := range records {
+		used[records[i].InstallationPath] = true
+		for _, installation := range records[i].History {
+			used[installation.InstallationPath] = true
+		}
+	}
 
-	if !utils.ContainsString(c.sharedCatalog.IndexByName[pluginNameTarget], plugin.InstallationPath) {
-		c.sharedCatalog.IndexByName[pluginNameTarget] = append(c.sharedCatalog.IndexByName[pluginNameTarget], plugin.InstallationPath)
+	var unused []string
+	for _, installationPath := range installationPaths {
+		if used[installationPath] {
+			continue
+		}
+		if err := tx.DeleteDescriptor(installationPath); err != nil {
+			return nil, err
+		}
+		unused = append(unused, installationPath)
 	}
+	return unused, nil
+}
+
+// Rollback activates the previously installed version of the given plugin
+// and returns its descriptor. The current installation is dropped from the history.
+func (c *ContextCatalog) Rollback(plugin string) (cliapi.PluginDescriptor, error) {
+	var pd cliapi.PluginDescriptor
+	err := c.update(func(tx *InventoryTx) error {
+		rec, err := tx.Get(c.context, plugin)
+		if err != nil {
+			return err
+		}
+		if rec == nil || len(rec.History) < 2 {
+			return errors.Errorf("no previous installation of plugin %q found", plugin)
+		}
+
+		previous := rec.History[1].InstallationPath
+		var ok bool
+		pd, ok, err = tx.Descriptor(previous)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.Errorf("descriptor of the previous installation of plugin %q not found", plugin)
+		}
+		if _, err := os.Stat(previous); err != nil {
+			return errors.Wrapf(err, "previous installation of plugin %q not found", plugin)
+		}
 
-	return saveCatalogCache(c.sharedCatalog)
+		rec.InstallationPath = previous
+		rec.Source = pd.Discovery
+		rec.History = rec.History[1:]
+		return tx.Put(rec)
+	})
+	return pd, err
+}
+
+// History returns the installations of the given plugin, most recent first.
+func (c *ContextCatalog) History(plugin string) []PluginHistoryEntry {
+	entries := make([]PluginHistoryEntry, 0)
+	_ = c.view(func(tx *InventoryTx) error {
+		rec, err := tx.Get(c.context, plugin)
+		if err != nil || rec == nil {
+			return err
+		}
+		history := rec.History
+		if len(history) == 0 {
+			history = []cliapi.PluginInstallation{{InstallationPath: rec.InstallationPath}}
+		}
+		for _, installation := range history {
+			pd, ok, err := tx.Descriptor(installation.InstallationPath)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			entries = append(entries, PluginHistoryEntry{
+				Descriptor:  pd,
+				InstalledAt: installation.InstalledAt.Time,
+				Active:      installation.InstallationPath == rec.InstallationPath,
+			})
+		}
+		return nil
+	})
+	return entries
 }
 
 // Get looks up the descriptor of a plugin given its name.
 func (c *ContextCatalog) Get(plugin string) (cliapi.PluginDescriptor, bool) {
 	pd := cliapi.PluginDescriptor{}
-	path, ok := c.plugins[plugin]
-	if !ok {
-		return pd, false
-	}
-
-	pd, ok = c.sharedCatalog.IndexByPath[path]
-	if !ok {
-		return pd, false
-	}
-
-	return pd, true
+	found := false
+	_ = c.view(func(tx *InventoryTx) error {
+		rec, err := tx.Get(c.context, plugin)
+		if err != nil || rec == nil {
+			return err
+		}
+		pd, found, err = tx.Descriptor(rec.InstallationPath)
+		return err
+	})
+	return pd, found
 }
 
 // List returns the list of active plugins.
@@ -96,9 +240,12 @@ func (c *ContextCatalog) Get(plugin string) (cliapi.PluginDescriptor, bool) {
 // based on the current logged-in server.
 func (c *ContextCatalog) List() []cliapi.PluginDescriptor {
 	pds := make([]cliapi.PluginDescriptor, 0)
-	for _, installationPath := range c.plugins {
-		pd := c.sharedCatalog.IndexByPath[installationPath]
-		pds = append(pds, pd)
+	plugins, err := QueryInstalledPlugins(&PluginQuery{Contexts: []string{c.context}})
+	if err != nil {
+		return pds
+	}
+	for i := range plugins {
+		pds = append(pds, plugins[i].Descriptor)
 	}
 	return pds
 }
@@ -106,12 +253,19 @@ func (c *ContextCatalog) List() []cliapi.PluginDescriptor {
 // Delete deletes the given plugin from the catalog, but it does not delete
 // the installation.
 func (c *ContextCatalog) Delete(plugin string) error {
-	_, ok := c.plugins[plugin]
-	if ok {
-		delete(c.plugins, plugin)
-	}
+	return c.update(func(tx *InventoryTx) error {
+		return tx.Delete(c.context, plugin)
+	})
+}
+
+func (c *ContextCatalog) view(fn func(tx *InventoryTx) error) error {
+	return viewInventory(fn)
+}
 
-	return saveCatalogCache(c.sharedCatalog)
+func (c *ContextCatalog) update(fn func(tx *InventoryTx) error) error {
+	return withInventory(func(inv *Inventory) error {
+		return inv.Update(fn)
+	})
 }
 
 // getCatalogCacheDir returns the local directory in which tanzu state is stored.
@@ -122,10 +276,12 @@ func getCatalogCacheDir() (path string) {
 // newSharedCatalog creates an instance of the shared catalog file.
 func newSharedCatalog() (*cliapi.Catalog, error) {
 	c := &cliapi.Catalog{
-		IndexByPath:       map[string]cliapi.PluginDescriptor{},
-		IndexByName:       map[string][]string{},
-		StandAlonePlugins: map[string]string{},
-		ServerPlugins:     map[string]cliapi.PluginAssociation{},
+		IndexByPath:             map[string]cliapi.PluginDescriptor{},
+		IndexByName:             map[string][]string{},
+		StandAlonePlugins:       map[string]string{},
+		ServerPlugins:           map[string]cliapi.PluginAssociation{},
+		StandAlonePluginHistory: cliapi.PluginHistory{},
+		ServerPluginHistory:     map[string]cliapi.PluginHistory{},
 	}
 
 	err := ensureRoot()
@@ -169,45 +325,22 @@ func getCatalogCache() (catalog *cliapi.Catalog, err error) {
 	if c.ServerPlugins == nil {
 		c.ServerPlugins = map[string]cliapi.PluginAssociation{}
 	}
-
-	return &c, nil
-}
-
-// saveCatalogCache saves the catalog in the local directory.
-func saveCatalogCache(catalog *cliapi.Catalog) error {
-	catalogCachePath := getCatalogCachePath()
-	_, err := os.Stat(catalogCachePath)
-	if os.IsNotExist(err) {
-		err = os.MkdirAll(getCatalogCacheDir(), 0755)
-		if err != nil {
-			return errors.Wrap(err, "could not make tanzu cache directory")
-		}
-	} else if err != nil {
-		return errors.Wrap(err, "could not create catalog cache path")
+	if c.StandAlonePluginHistory == nil {
+		c.StandAlonePluginHistory = cliapi.PluginHistory{}
 	}
-
-	scheme, err := cliapi.SchemeBuilder.Build()
-	if err != nil {
-		return errors.Wrap(err, "failed to create scheme")
+	if c.ServerPluginHistory == nil {
+		c.ServerPluginHistory = map[string]cliapi.PluginHistory{}
 	}
 
-	s := apimachineryjson.NewSerializerWithOptions(apimachineryjson.DefaultMetaFactory, scheme, scheme,
-		apimachineryjson.SerializerOptions{Yaml: true, Pretty: false, Strict: false})
-	catalog.GetObjectKind().SetGroupVersionKind(cliapi.GroupVersionKindCatalog)
-	buf := new(bytes.Buffer)
-	if err := s.Encode(catalog, buf); err != nil {
-		return errors.Wrap(err, "failed to encode catalog cache file")
-	}
-	if err = os.WriteFile(catalogCachePath, buf.Bytes(), 0644); err != nil {
-		return errors.Wrap(err, "failed to write catalog cache file")
-	}
-	return nil
+	return &c, nil
 }
 
-// CleanCatalogCache cleans the catalog cache
+// CleanCatalogCache cleans the plugin inventory and the catalog cache
 func CleanCatalogCache() error {
-	if err := os.Remove(getCatalogCachePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
-		return err
+	for _, path := range []string{getInventoryPath(), getCatalogCachePath(), getCatalogCachePath() + migratedCatalogCacheSuffix} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
 	}
 	return nil
 }
@@ -233,14 +366,9 @@ func testPath() string {
 }
 
 // UpdateCatalogCache when updating the core CLI from v0.x.x to v1.x.x. This is
-// needed to group the standalone plugins by context type.
+// needed to migrate the plugins of the catalog cache to the plugin inventory.
 func UpdateCatalogCache() error {
-	c, err := getCatalogCache()
-	if err != nil {
-		return err
-	}
-
-	return saveCatalogCache(c)
+	return withInventory(func(*Inventory) error { return nil })
 }
 
 func PluginNameTarget(pluginName string, target cliv1alpha1.Target) string {
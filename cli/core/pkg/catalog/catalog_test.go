@@ -12,6 +12,7 @@ import (
 
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/common"
 	cliconfig "github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/config"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/constants"
 	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
 	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
 )
@@ -170,6 +171,85 @@ func Test_ContextCatalog_With_Context(t *testing.T) {
 	os.RemoveAll(common.DefaultPluginRoot)
 }
 
+func Test_ContextCatalog_History_And_Rollback(t *testing.T) {
+	common.DefaultCacheDir = t.TempDir()
+	t.Setenv(constants.ConfigVariablePluginHistoryLimit, "1")
+
+	assert := assert.New(t)
+
+	cc, err := NewContextCatalog("")
+	assert.Nil(err)
+
+	_, err = cc.Rollback("fakeplugin")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), `no previous installation of plugin "fakeplugin" found`)
+
+	binDir := t.TempDir()
+	for _, version := range []string{"1.0.0", "2.0.0", "3.0.0"} {
+		path := filepath.Join(binDir, version)
+		assert.Nil(os.WriteFile(path, []byte(version), 0755))
+		assert.Nil(cc.Upsert(&cliapi.PluginDescriptor{Name: "fakeplugin", InstallationPath: path, Version: version}))
+	}
+	// Reinstalling the active version does not change the history
+	assert.Nil(cc.Upsert(&cliapi.PluginDescriptor{Name: "fakeplugin", InstallationPath: filepath.Join(binDir, "3.0.0"), Version: "3.0.0"}))
+	// The binary of the installation dropped from the history is deleted
+	assert.NoFileExists(filepath.Join(binDir, "1.0.0"))
+
+	// Only the active and one previous installation are kept
+	history := cc.History("fakeplugin")
+	assert.Equal(2, len(history))
+	assert.Equal("3.0.0", history[0].Descriptor.Version)
+	assert.True(history[0].Active)
+	assert.False(history[0].InstalledAt.IsZero())
+	assert.Equal("2.0.0", history[1].Descriptor.Version)
+	assert.False(history[1].Active)
+
+	pd, err := cc.Rollback("fakeplugin")
+	assert.Nil(err)
+	assert.Equal("2.0.0", pd.Version)
+
+	// The rollback is persisted in the catalog
+	cc2, err := NewContextCatalog("")
+	assert.Nil(err)
+	pd, exists := cc2.Get("fakeplugin")
+	assert.True(exists)
+	assert.Equal("2.0.0", pd.Version)
+	history = cc2.History("fakeplugin")
+	assert.Equal(1, len(history))
+	assert.True(history[0].Active)
+
+	_, err = cc2.Rollback("fakeplugin")
+	assert.NotNil(err)
+
+	// Rollback fails when the previous binary no longer exists
+	assert.Nil(cc2.Upsert(&cliapi.PluginDescriptor{Name: "fakeplugin", InstallationPath: filepath.Join(binDir, "3.0.0"), Version: "3.0.0"}))
+	assert.Nil(os.Remove(filepath.Join(binDir, "2.0.0")))
+	_, err = cc2.Rollback("fakeplugin")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), `previous installation of plugin "fakeplugin" not found`)
+	pd, _ = cc2.Get("fakeplugin")
+	assert.Equal("3.0.0", pd.Version)
+
+	assert.Nil(cc2.Delete("fakeplugin"))
+	assert.Equal(0, len(cc2.History("fakeplugin")))
+
+	// Binaries dropped from the history are kept while another context uses them
+	cc3, err := NewContextCatalog("other-context")
+	assert.Nil(err)
+	shared := filepath.Join(binDir, "4.0.0")
+	assert.Nil(os.WriteFile(shared, []byte("4.0.0"), 0755))
+	assert.Nil(cc3.Upsert(&cliapi.PluginDescriptor{Name: "fakeplugin", InstallationPath: shared, Version: "4.0.0"}))
+	for _, version := range []string{"4.0.0", "5.0.0", "6.0.0"} {
+		path := filepath.Join(binDir, version)
+		assert.Nil(os.WriteFile(path, []byte(version), 0755))
+		assert.Nil(cc2.Upsert(&cliapi.PluginDescriptor{Name: "fakeplugin", InstallationPath: path, Version: version}))
+	}
+	assert.FileExists(shared)
+	pd, exists = cc3.Get("fakeplugin")
+	assert.True(exists)
+	assert.Equal("4.0.0", pd.Version)
+}
+
 // Test_CatalogCacheFileName tests we default to catalog.yaml file when
 // the featuregate is configured to true by default
 func Test_CatalogCacheFileName(t *testing.T) {
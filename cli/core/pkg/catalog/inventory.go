@@ -0,0 +1,539 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
+	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
+)
+
+const (
+	// inventoryFileName is the name of the file which holds the plugin inventory
+	inventoryFileName = "plugin_inventory.db"
+	// inventorySchemaVersion is the schema version of the plugin inventory
+	// supported by this version of the CLI
+	inventorySchemaVersion = 1
+	// inventoryOpenTimeout is the maximum duration to wait for another
+	// process to release the plugin inventory
+	inventoryOpenTimeout = 10 * time.Second
+	// keySeparator separates the parts of the composite keys of the inventory
+	keySeparator = "\x00"
+	// migratedCatalogCacheSuffix is appended to the name of the catalog cache
+	// file once it is migrated to the plugin inventory
+	migratedCatalogCacheSuffix = ".migrated"
+)
+
+// Buckets of the plugin inventory
+var (
+	// metaBucket holds the inventory metadata such as the schema version
+	metaBucket = []byte("meta")
+	// descriptorsBucket maps the installation path of a plugin binary to its descriptor
+	descriptorsBucket = []byte("descriptors")
+	// pluginsBucket maps the context and name of a plugin to its PluginRecord
+	pluginsBucket = []byte("plugins")
+	// The index buckets map the indexed value and the key of a plugin record to nothing
+	nameIndexBucket    = []byte("index_name")
+	targetIndexBucket  = []byte("index_target")
+	contextIndexBucket = []byte("index_context")
+	sourceIndexBucket  = []byte("index_source")
+
+	schemaVersionKey = []byte("schemaVersion")
+)
+
+// inventoryMigrations upgrade the schema of the plugin inventory. The migration at
+// index i upgrades the inventory from schema version i to schema version i+1.
+var inventoryMigrations = []func(tx *InventoryTx) error{
+	migrateCatalogCacheToInventory,
+}
+
+// PluginRecord is the active installation of a plugin in a context
+type PluginRecord struct {
+	// Context is the context of the plugin. Empty for stand-alone plugins.
+	Context string `json:"context"`
+	// Name is the name of the plugin.
+	Name string `json:"name"`
+	// Target is the target of the plugin.
+	Target cliv1alpha1.Target `json:"target,omitempty"`
+	// Source is the name of the discovery source from where the plugin was installed.
+	Source string `json:"source,omitempty"`
+	// InstallationPath is the installation path of the active plugin binary.
+	InstallationPath string `json:"installationPath"`
+	// History is the list of activated installations of the plugin, most recent first.
+	History []cliapi.PluginInstallation `json:"history,omitempty"`
+}
+
+// PluginQuery selects plugins from the plugin inventory. Empty fields match any value.
+type PluginQuery struct {
+	// Name is the name of the plugin.
+	Name string
+	// Target is the target of the plugin.
+	Target cliv1alpha1.Target
+	// Source is the name of the discovery source of the plugin.
+	Source string
+	// Contexts are the contexts of the plugin. The empty context selects the
+	// stand-alone plugins. Nil matches all contexts.
+	Contexts []string
+}
+
+// InstalledPlugin is an installed plugin returned by a query of the plugin inventory
+type InstalledPlugin struct {
+	// Context is the context of the plugin. Empty for stand-alone plugins.
+	Context string
+	// Descriptor is the descriptor of the active installation of the plugin.
+	Descriptor cliapi.PluginDescriptor
+}
+
+// Inventory is a transactional store of the installed plugins indexed
+// by name, target, context and discovery source
+type Inventory struct {
+	db *bolt.DB
+}
+
+// InventoryTx is a transaction of the plugin inventory
+type InventoryTx struct {
+	tx *bolt.Tx
+}
+
+// OpenInventory opens the plugin inventory and upgrades its schema if required.
+// The inventory is locked until it is closed.
+func OpenInventory() (*Inventory, error) {
+	if err := os.MkdirAll(getCatalogCacheDir(), 0755); err != nil {
+		return nil, errors.Wrap(err, "could not make tanzu cache directory")
+	}
+	db, err := bolt.Open(getInventoryPath(), 0644, &bolt.Options{Timeout: inventoryOpenTimeout})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open plugin inventory")
+	}
+	inv := &Inventory{db: db}
+	migratedCatalogCache, err := inv.migrate()
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if migratedCatalogCache {
+		// keep the stale catalog cache aside so that it is never mistaken for the installed plugins
+		if err := os.Rename(getCatalogCachePath(), getCatalogCachePath()+migratedCatalogCacheSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			_ = db.Close()
+			return nil, errors.Wrap(err, "could not rename migrated catalog cache")
+		}
+	}
+	return inv, nil
+}
+
+// OpenInventoryReadOnly opens the plugin inventory for reads only. The inventory
+// can be opened for reads by several processes at once but not while it is opened
+// by OpenInventory. If the inventory does not exist yet or its schema has to be
+// upgraded, it is opened with OpenInventory instead.
+func OpenInventoryReadOnly() (*Inventory, error) {
+	if _, err := os.Stat(getInventoryPath()); err != nil {
+		return OpenInventory()
+	}
+	db, err := bolt.Open(getInventoryPath(), 0644, &bolt.Options{Timeout: inventoryOpenTimeout, ReadOnly: true})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open plugin inventory")
+	}
+	var version int
+	err = db.View(func(tx *bolt.Tx) error {
+		version, err = schemaVersion(tx)
+		return err
+	})
+	if err == nil && version == inventorySchemaVersion {
+		return &Inventory{db: db}, nil
+	}
+	_ = db.Close()
+	if err != nil {
+		return nil, err
+	}
+	return OpenInventory()
+}
+
+// Close closes the plugin inventory
+func (inv *Inventory) Close() error {
+	return inv.db.Close()
+}
+
+// View runs fn in a read-only transaction
+func (inv *Inventory) View(fn func(tx *InventoryTx) error) error {
+	return inv.db.View(func(tx *bolt.Tx) error {
+		return fn(&InventoryTx{tx: tx})
+	})
+}
+
+// Update runs fn in a read-write transaction. The changes are committed
+// if fn returns nil and rolled back otherwise.
+func (inv *Inventory) Update(fn func(tx *InventoryTx) error) error {
+	return inv.db.Update(func(tx *bolt.Tx) error {
+		return fn(&InventoryTx{tx: tx})
+	})
+}
+
+// withInventory opens the plugin inventory for the duration of fn so that the
+// inventory is never locked for longer than a single operation
+func withInventory(fn func(inv *Inventory) error) error {
+	inv, err := OpenInventory()
+	if err != nil {
+		return err
+	}
+	defer inv.Close()
+	return fn(inv)
+}
+
+// viewInventory opens the plugin inventory for reads only and runs fn in a
+// read-only transaction
+func viewInventory(fn func(tx *InventoryTx) error) error {
+	inv, err := OpenInventoryReadOnly()
+	if err != nil {
+		return err
+	}
+	defer inv.Close()
+	return inv.View(fn)
+}
+
+// schemaVersion returns the schema version of the inventory, 0 for a new inventory
+func schemaVersion(tx *bolt.Tx) (int, error) {
+	meta := tx.Bucket(metaBucket)
+	if meta == nil {
+		return 0, nil
+	}
+	b := meta.Get(schemaVersionKey)
+	if b == nil {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(string(b))
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid plugin inventory schema version")
+	}
+	if version > inventorySchemaVersion {
+		return 0, errors.Errorf("plugin inventory schema version %d is newer than the version %d supported by this CLI", version, inventorySchemaVersion)
+	}
+	return version, nil
+}
+
+// migrate upgrades the schema of the inventory to inventorySchemaVersion and
+// returns whether the catalog cache of previous CLI versions was migrated
+func (inv *Inventory) migrate() (migratedCatalogCache bool, err error) {
+	err = inv.Update(func(tx *InventoryTx) error {
+		version, err := schemaVersion(tx.tx)
+		if err != nil {
+			return err
+		}
+		meta, err := tx.tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+		migratedCatalogCache = version == 0
+		for ; version < inventorySchemaVersion; version++ {
+			if err := inventoryMigrations[version](tx); err != nil {
+				return errors.Wrapf(err, "could not migrate plugin inventory to schema version %d", version+1)
+			}
+		}
+		return meta.Put(schemaVersionKey, []byte(strconv.Itoa(version)))
+	})
+	return migratedCatalogCache && err == nil, err
+}
+
+// migrateCatalogCacheToInventory creates the inventory buckets and imports
+// the plugins from the catalog cache file used by previous versions of the CLI
+func migrateCatalogCacheToInventory(tx *InventoryTx) error {
+	for _, bucket := range [][]byte{descriptorsBucket, pluginsBucket, nameIndexBucket, targetIndexBucket, contextIndexBucket, sourceIndexBucket} {
+		if _, err := tx.tx.CreateBucketIfNotExists(bucket); err != nil {
+			return err
+		}
+	}
+
+	c, err := getCatalogCache()
+	if err != nil {
+		return err
+	}
+	importPlugins := func(context string, plugins cliapi.PluginAssociation, history cliapi.PluginHistory) error {
+		for plugin, path := range plugins {
+			pd, ok := c.IndexByPath[path]
+			if !ok {
+				continue
+			}
+			for _, installation := range history[plugin] {
+				if hpd, ok := c.IndexByPath[installation.InstallationPath]; ok {
+					if err := tx.PutDescriptor(&hpd); err != nil {
+						return err
+					}
+				}
+			}
+			if err := tx.PutDescriptor(&pd); err != nil {
+				return err
+			}
+			err := tx.Put(&PluginRecord{
+				Context:          context,
+				Name:             pd.Name,
+				Target:           pd.Target,
+				Source:           pd.Discovery,
+				InstallationPath: path,
+				History:          history[plugin],
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := importPlugins("", c.StandAlonePlugins, c.StandAlonePluginHistory); err != nil {
+		return err
+	}
+	for context, plugins := range c.ServerPlugins {
+		if err := importPlugins(context, plugins, c.ServerPluginHistory[context]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the record of the plugin in the given context, or nil if the plugin is not installed.
+// The plugin is identified by its name and target as returned by PluginNameTarget.
+func (t *InventoryTx) Get(context, plugin string) (*PluginRecord, error) {
+	b := t.tx.Bucket(pluginsBucket).Get(recordKey(context, plugin))
+	if b == nil {
+		return nil, nil
+	}
+	var rec PluginRecord
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return nil, errors.Wrapf(err, "could not decode inventory record of plugin %q", plugin)
+	}
+	return &rec, nil
+}
+
+// Put inserts or updates the plugin record and its indexes
+func (t *InventoryTx) Put(rec *PluginRecord) error {
+	key := recordKey(rec.Context, PluginNameTarget(rec.Name, rec.Target))
+	if err := t.deleteIndexes(key); err != nil {
+		return err
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return errors.Wrapf(err, "could not encode inventory record of plugin %q", rec.Name)
+	}
+	if err := t.tx.Bucket(pluginsBucket).Put(key, b); err != nil {
+		return err
+	}
+	for bucket, value := range recordIndexes(rec) {
+		if err := t.tx.Bucket([]byte(bucket)).Put(indexKey(value, key), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete deletes the record of the plugin in the given context and its indexes
+func (t *InventoryTx) Delete(context, plugin string) error {
+	key := recordKey(context, plugin)
+	if err := t.deleteIndexes(key); err != nil {
+		return err
+	}
+	return t.tx.Bucket(pluginsBucket).Delete(key)
+}
+
+// Descriptor returns the descriptor of the plugin binary at the given installation path
+func (t *InventoryTx) Descriptor(installationPath string) (cliapi.PluginDescriptor, bool, error) {
+	var pd cliapi.PluginDescriptor
+	b := t.tx.Bucket(descriptorsBucket).Get([]byte(installationPath))
+	if b == nil {
+		return pd, false, nil
+	}
+	if err := json.Unmarshal(b, &pd); err != nil {
+		return pd, false, errors.Wrapf(err, "could not decode plugin descriptor of %q", installationPath)
+	}
+	return pd, true, nil
+}
+
+// PutDescriptor inserts or updates the descriptor of the plugin binary at its installation path
+func (t *InventoryTx) PutDescriptor(pd *cliapi.PluginDescriptor) error {
+	b, err := json.Marshal(pd)
+	if err != nil {
+		return errors.Wrapf(err, "could not encode plugin descriptor of %q", pd.Name)
+	}
+	return t.tx.Bucket(descriptorsBucket).Put([]byte(pd.InstallationPath), b)
+}
+
+// DeleteDescriptor deletes the descriptor of the plugin binary at the given installation path
+func (t *InventoryTx) DeleteDescriptor(installationPath string) error {
+	return t.tx.Bucket(descriptorsBucket).Delete([]byte(installationPath))
+}
+
+// Query returns the plugin records matching the query. The most selective
+// index of the query is used so that only the matching records are read.
+func (t *InventoryTx) Query(q *PluginQuery) ([]PluginRecord, error) {
+	var keys [][]byte
+	switch {
+	case q.Name != "":
+		keys = t.indexLookup(nameIndexBucket, q.Name)
+	case q.Source != "":
+		keys = t.indexLookup(sourceIndexBucket, q.Source)
+	case q.Contexts != nil:
+		for _, context := range q.Contexts {
+			keys = append(keys, t.indexLookup(contextIndexBucket, context)...)
+		}
+	case q.Target != "":
+		keys = t.indexLookup(targetIndexBucket, string(q.Target))
+	default:
+		err := t.tx.Bucket(pluginsBucket).ForEach(func(k, _ []byte) error {
+			keys = append(keys, append([]byte{}, k...))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	records := make([]PluginRecord, 0, len(keys))
+	for _, key := range keys {
+		context, plugin, _ := strings.Cut(string(key), keySeparator)
+		rec, err := t.Get(context, plugin)
+		if err != nil {
+			return nil, err
+		}
+		if rec != nil && q.matches(rec) {
+			records = append(records, *rec)
+		}
+	}
+	return records, nil
+}
+
+// Names returns the sorted names of the plugins starting with the given prefix.
+// Only the name index is read.
+func (t *InventoryTx) Names(prefix string) []string {
+	names := make([]string, 0)
+	c := t.tx.Bucket(nameIndexBucket).Cursor()
+	for k, _ := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, _ = c.Next() {
+		name, _, _ := strings.Cut(string(k), keySeparator)
+		if len(names) == 0 || names[len(names)-1] != name {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (t *InventoryTx) indexLookup(bucket []byte, value string) [][]byte {
+	keys := make([][]byte, 0)
+	prefix := []byte(value + keySeparator)
+	c := t.tx.Bucket(bucket).Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		keys = append(keys, append([]byte{}, k[len(prefix):]...))
+	}
+	return keys
+}
+
+func (t *InventoryTx) deleteIndexes(key []byte) error {
+	b := t.tx.Bucket(pluginsBucket).Get(key)
+	if b == nil {
+		return nil
+	}
+	var old PluginRecord
+	if err := json.Unmarshal(b, &old); err != nil {
+		return errors.Wrap(err, "could not decode inventory record")
+	}
+	for bucket, value := range recordIndexes(&old) {
+		if err := t.tx.Bucket([]byte(bucket)).Delete(indexKey(value, key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *PluginQuery) matches(rec *PluginRecord) bool {
+	if q.Name != "" && q.Name != rec.Name {
+		return false
+	}
+	if q.Target != "" && q.Target != rec.Target {
+		return false
+	}
+	if q.Source != "" && q.Source != rec.Source {
+		return false
+	}
+	if q.Contexts != nil {
+		for _, context := range q.Contexts {
+			if context == rec.Context {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// recordIndexes returns the indexed values of the record by index bucket
+func recordIndexes(rec *PluginRecord) map[string]string {
+	return map[string]string{
+		string(nameIndexBucket):    rec.Name,
+		string(targetIndexBucket):  string(rec.Target),
+		string(contextIndexBucket): rec.Context,
+		string(sourceIndexBucket):  rec.Source,
+	}
+}
+
+func recordKey(context, plugin string) []byte {
+	return []byte(context + keySeparator + plugin)
+}
+
+func indexKey(value string, key []byte) []byte {
+	return append([]byte(value+keySeparator), key...)
+}
+
+// QueryInstalledPlugins returns the active installations of the plugins matching the query
+func QueryInstalledPlugins(q *PluginQuery) ([]InstalledPlugin, error) {
+	plugins := make([]InstalledPlugin, 0)
+	err := viewInventory(func(tx *InventoryTx) error {
+		records, err := tx.Query(q)
+		if err != nil {
+			return err
+		}
+		for i := range records {
+			pd, ok, err := tx.Descriptor(records[i].InstallationPath)
+			if err != nil {
+				return err
+			}
+			if ok {
+				plugins = append(plugins, InstalledPlugin{Context: records[i].Context, Descriptor: pd})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(plugins, func(i, j int) bool {
+		if plugins[i].Context != plugins[j].Context {
+			return plugins[i].Context < plugins[j].Context
+		}
+		return PluginNameTarget(plugins[i].Descriptor.Name, plugins[i].Descriptor.Target) <
+			PluginNameTarget(plugins[j].Descriptor.Name, plugins[j].Descriptor.Target)
+	})
+	return plugins, nil
+}
+
+// InstalledPluginNames returns the sorted names of the installed plugins
+// starting with the given prefix without reading the plugin descriptors
+func InstalledPluginNames(prefix string) ([]string, error) {
+	var names []string
+	err := viewInventory(func(tx *InventoryTx) error {
+		names = tx.Names(prefix)
+		return nil
+	})
+	return names, err
+}
+
+// getInventoryPath gets the plugin inventory path
+func getInventoryPath() string {
+	return filepath.Join(getCatalogCacheDir(), inventoryFileName)
+}
@@ -0,0 +1,207 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package catalog
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/common"
+	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
+)
+
+const testCatalogCache = `apiVersion: cli.tanzu.vmware.com/v1alpha1
+kind: Catalog
+metadata:
+  creationTimestamp: null
+indexByPath:
+  /plugins/login/v0.1.0:
+    name: login
+    version: v0.1.0
+    discovery: default
+    installationPath: /plugins/login/v0.1.0
+  /plugins/login/v0.2.0:
+    name: login
+    version: v0.2.0
+    discovery: default
+    installationPath: /plugins/login/v0.2.0
+  /plugins/cluster/v0.2.0_kubernetes:
+    name: cluster
+    version: v0.2.0
+    target: kubernetes
+    discovery: mgmt
+    installationPath: /plugins/cluster/v0.2.0_kubernetes
+standAlonePlugins:
+  login: /plugins/login/v0.2.0
+serverPlugins:
+  mgmt:
+    cluster_kubernetes: /plugins/cluster/v0.2.0_kubernetes
+standAlonePluginHistory:
+  login:
+  - installationPath: /plugins/login/v0.2.0
+  - installationPath: /plugins/login/v0.1.0
+`
+
+func TestInventoryMigrationFromCatalogCache(t *testing.T) {
+	assert := assert.New(t)
+
+	common.DefaultCacheDir = t.TempDir()
+	assert.Nil(os.WriteFile(getCatalogCachePath(), []byte(testCatalogCache), 0644))
+
+	cc, err := NewContextCatalog("")
+	assert.Nil(err)
+	pd, exists := cc.Get("login")
+	assert.True(exists)
+	assert.Equal("v0.2.0", pd.Version)
+	history := cc.History("login")
+	assert.Equal(2, len(history))
+	assert.Equal("v0.1.0", history[1].Descriptor.Version)
+
+	cc, err = NewContextCatalog("mgmt")
+	assert.Nil(err)
+	pd, exists = cc.Get("cluster_kubernetes")
+	assert.True(exists)
+	assert.Equal(cliv1alpha1.TargetK8s, pd.Target)
+
+	// The migrated catalog cache is renamed
+	_, err = os.Stat(getCatalogCachePath())
+	assert.True(os.IsNotExist(err))
+	_, err = os.Stat(getCatalogCachePath() + migratedCatalogCacheSuffix)
+	assert.Nil(err)
+
+	// The catalog cache is migrated only once
+	assert.Nil(cc.Delete("cluster_kubernetes"))
+	assert.Nil(UpdateCatalogCache())
+	_, exists = cc.Get("cluster_kubernetes")
+	assert.False(exists)
+}
+
+func TestInventoryQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	common.DefaultCacheDir = t.TempDir()
+
+	plugins := []struct {
+		context string
+		pd      cliapi.PluginDescriptor
+	}{
+		{"", cliapi.PluginDescriptor{Name: "login", Version: "v0.2.0", Discovery: "default", InstallationPath: "/plugins/login"}},
+		{"", cliapi.PluginDescriptor{Name: "cluster", Version: "v0.2.0", Target: cliv1alpha1.TargetTMC, Discovery: "default", InstallationPath: "/plugins/cluster_mission-control"}},
+		{"mgmt", cliapi.PluginDescriptor{Name: "cluster", Version: "v1.6.0", Target: cliv1alpha1.TargetK8s, Discovery: "mgmt", InstallationPath: "/plugins/cluster_kubernetes"}},
+		{"mgmt", cliapi.PluginDescriptor{Name: "management-cluster", Version: "v1.6.0", Target: cliv1alpha1.TargetK8s, Discovery: "mgmt", InstallationPath: "/plugins/management-cluster"}},
+	}
+	for i := range plugins {
+		cc, err := NewContextCatalog(plugins[i].context)
+		assert.Nil(err)
+		assert.Nil(cc.Upsert(&plugins[i].pd))
+	}
+
+	queryNames := func(q *PluginQuery) []string {
+		result, err := QueryInstalledPlugins(q)
+		assert.Nil(err)
+		names := make([]string, 0, len(result))
+		for i := range result {
+			names = append(names, result[i].Context+"/"+PluginNameTarget(result[i].Descriptor.Name, result[i].Descriptor.Target))
+		}
+		return names
+	}
+
+	assert.Equal([]string{"/cluster_mission-control", "/login", "mgmt/cluster_kubernetes", "mgmt/management-cluster_kubernetes"}, queryNames(&PluginQuery{}))
+	assert.Equal([]string{"/cluster_mission-control", "mgmt/cluster_kubernetes"}, queryNames(&PluginQuery{Name: "cluster"}))
+	assert.Equal([]string{"mgmt/cluster_kubernetes"}, queryNames(&PluginQuery{Name: "cluster", Target: cliv1alpha1.TargetK8s}))
+	assert.Equal([]string{"mgmt/cluster_kubernetes", "mgmt/management-cluster_kubernetes"}, queryNames(&PluginQuery{Target: cliv1alpha1.TargetK8s}))
+	assert.Equal([]string{"/cluster_mission-control", "/login"}, queryNames(&PluginQuery{Contexts: []string{""}}))
+	assert.Equal([]string{"/cluster_mission-control", "/login"}, queryNames(&PluginQuery{Source: "default"}))
+	assert.Equal([]string{"mgmt/cluster_kubernetes"}, queryNames(&PluginQuery{Name: "cluster", Contexts: []string{"mgmt", "other"}}))
+	assert.Empty(queryNames(&PluginQuery{Contexts: []string{}}))
+
+	// The indexes are updated with the record
+	cc, err := NewContextCatalog("")
+	assert.Nil(err)
+	assert.Nil(cc.Upsert(&cliapi.PluginDescriptor{Name: "login", Version: "v0.3.0", Discovery: "other", InstallationPath: "/plugins/login_v0.3.0"}))
+	assert.Equal([]string{"/cluster_mission-control"}, queryNames(&PluginQuery{Source: "default"}))
+	assert.Equal([]string{"/login"}, queryNames(&PluginQuery{Source: "other"}))
+	assert.Nil(cc.Delete("login"))
+	assert.Empty(queryNames(&PluginQuery{Source: "other"}))
+	assert.Empty(queryNames(&PluginQuery{Name: "login"}))
+
+	names, err := InstalledPluginNames("")
+	assert.Nil(err)
+	assert.Equal([]string{"cluster", "management-cluster"}, names)
+	names, err = InstalledPluginNames("man")
+	assert.Nil(err)
+	assert.Equal([]string{"management-cluster"}, names)
+}
+
+func TestInventorySchemaVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	common.DefaultCacheDir = t.TempDir()
+	inv, err := OpenInventory()
+	assert.Nil(err)
+	err = inv.Update(func(tx *InventoryTx) error {
+		return tx.tx.Bucket(metaBucket).Put(schemaVersionKey, []byte("99"))
+	})
+	assert.Nil(err)
+	assert.Nil(inv.Close())
+
+	_, err = NewContextCatalog("")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "plugin inventory schema version 99 is newer than the version 1 supported by this CLI")
+
+	// A failed transaction is rolled back
+	assert.Nil(os.Remove(getInventoryPath()))
+	inv, err = OpenInventory()
+	assert.Nil(err)
+	defer inv.Close()
+	err = inv.Update(func(tx *InventoryTx) error {
+		if err := tx.Put(&PluginRecord{Name: "login", InstallationPath: "/plugins/login"}); err != nil {
+			return err
+		}
+		return bolt.ErrTxNotWritable
+	})
+	assert.Equal(bolt.ErrTxNotWritable, err)
+	err = inv.View(func(tx *InventoryTx) error {
+		rec, err := tx.Get("", "login")
+		assert.Nil(rec)
+		return err
+	})
+	assert.Nil(err)
+}
+
+func TestInventoryReadOnly(t *testing.T) {
+	assert := assert.New(t)
+
+	common.DefaultCacheDir = t.TempDir()
+
+	// The inventory is created on first read
+	inv, err := OpenInventoryReadOnly()
+	assert.Nil(err)
+	assert.Nil(inv.Close())
+
+	cc, err := NewContextCatalog("")
+	assert.Nil(err)
+	assert.Nil(cc.Upsert(&cliapi.PluginDescriptor{Name: "login", Version: "v0.2.0", InstallationPath: "/plugins/login"}))
+
+	// The inventory can be read by several readers at once
+	inv, err = OpenInventoryReadOnly()
+	assert.Nil(err)
+	defer inv.Close()
+	other, err := OpenInventoryReadOnly()
+	assert.Nil(err)
+	defer other.Close()
+	err = other.View(func(tx *InventoryTx) error {
+		rec, err := tx.Get("", "login")
+		assert.NotNil(rec)
+		return err
+	})
+	assert.Nil(err)
+
+	err = inv.Update(func(tx *InventoryTx) error { return nil })
+	assert.NotNil(err)
+}
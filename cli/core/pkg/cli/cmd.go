@@ -21,7 +21,7 @@ func GetCmd(p *cliapi.PluginDescriptor) *cobra.Command {
 		Use:   p.Name,
 		Short: p.Description,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			runner := NewRunner(p.Name, p.InstallationPath, args)
+			runner := NewRunner(p.Name, p.InstallationPath, args, WithPermissions(p.Permissions))
 			ctx := context.Background()
 			return runner.Run(ctx)
 		},
@@ -37,36 +37,18 @@ func GetCmd(p *cliapi.PluginDescriptor) *cobra.Command {
 	// Handle command line completion types.
 	if p.CompletionType == cliapi.NativePluginCompletion {
 		cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-			// Parses the completion info provided by cobra.Command. This should be formatted similar to:
-			//   help	Help about any command
-			//   :4
-			//   Completion ended with directive: ShellCompDirectiveNoFileComp
-			completion := []string{"__complete"}
+			completion := []string{cobra.ShellCompRequestCmd}
 			completion = append(completion, args...)
 			completion = append(completion, toComplete)
 
-			runner := NewRunner(p.Name, p.InstallationPath, completion)
-			ctx := context.Background()
-			output, _, err := runner.RunOutput(ctx)
-			if err != nil {
-				return nil, cobra.ShellCompDirectiveError
-			}
-
-			lines := strings.Split(strings.Trim(output, "\n"), "\n")
-			var results []string
-			for _, line := range lines {
-				if strings.HasPrefix(line, ":") {
-					// Special marker in output to indicate the end
-					directive, err := strconv.Atoi(line[1:])
-					if err != nil {
-						return results, cobra.ShellCompDirectiveError
-					}
-					return results, cobra.ShellCompDirective(directive)
+			return cachedCompletion(p, completion, func() ([]string, cobra.ShellCompDirective) {
+				runner := NewRunner(p.Name, p.InstallationPath, completion, WithPermissions(p.Permissions))
+				output, _, err := runner.RunOutput(context.Background())
+				if err != nil {
+					return nil, cobra.ShellCompDirectiveError
 				}
-				results = append(results, line)
-			}
-
-			return []string{}, cobra.ShellCompDirectiveError
+				return parseNativeCompletion(output)
+			})
 		}
 	} else if p.CompletionType == cliapi.StaticPluginCompletion {
 		cmd.ValidArgs = p.CompletionArgs
@@ -78,16 +60,17 @@ func GetCmd(p *cliapi.PluginDescriptor) *cobra.Command {
 			completion = append(completion, args...)
 			completion = append(completion, toComplete)
 
-			runner := NewRunner(p.Name, p.InstallationPath, completion)
-			ctx := context.Background()
-			output, stderr, err := runner.RunOutput(ctx)
-			if err != nil || stderr != "" {
-				return nil, cobra.ShellCompDirectiveError
-			}
+			return cachedCompletion(p, completion, func() ([]string, cobra.ShellCompDirective) {
+				runner := NewRunner(p.Name, p.InstallationPath, completion, WithPermissions(p.Permissions))
+				output, stderr, err := runner.RunOutput(context.Background())
+				if err != nil || stderr != "" {
+					return nil, cobra.ShellCompDirectiveError
+				}
 
-			// Expectation is that plugins will return a list of nouns, one per line. Can be either just
-			// the noun, or "noun[tab]Description".
-			return strings.Split(strings.Trim(output, "\n"), "\n"), cobra.ShellCompDirectiveNoFileComp
+				// Expectation is that plugins will return a list of nouns, one per line. Can be either just
+				// the noun, or "noun[tab]Description".
+				return strings.Split(strings.Trim(output, "\n"), "\n"), cobra.ShellCompDirectiveNoFileComp
+			})
 		}
 	}
 
@@ -103,7 +86,7 @@ func GetCmd(p *cliapi.PluginDescriptor) *cobra.Command {
 		helpArgs := getHelpArguments()
 
 		// Pass this new command in to our plugin to have it handle help output
-		runner := NewRunner(p.Name, p.InstallationPath, helpArgs)
+		runner := NewRunner(p.Name, p.InstallationPath, helpArgs, WithPermissions(p.Permissions))
 		ctx := context.Background()
 		err := runner.Run(ctx)
 		if err != nil {
@@ -113,6 +96,30 @@ func GetCmd(p *cliapi.PluginDescriptor) *cobra.Command {
 	return cmd
 }
 
+// parseNativeCompletion parses the output of the cobra __complete command of a plugin,
+// which lists the completion results followed by the directive, formatted similar to:
+//
+//	help	Help about any command
+//	:4
+//	Completion ended with directive: ShellCompDirectiveNoFileComp
+func parseNativeCompletion(output string) ([]string, cobra.ShellCompDirective) {
+	lines := strings.Split(strings.Trim(output, "\n"), "\n")
+	var results []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, ":") {
+			// Special marker in output to indicate the end
+			directive, err := strconv.Atoi(line[1:])
+			if err != nil {
+				return results, cobra.ShellCompDirectiveError
+			}
+			return results, cobra.ShellCompDirective(directive)
+		}
+		results = append(results, line)
+	}
+
+	return []string{}, cobra.ShellCompDirectiveError
+}
+
 // getHelpArguments extracts the command line to pass along to help calls.
 // The help function is only ever called for help commands in the format of
 // "tanzu help cmd", so we can assume anything two after "help" should get
@@ -141,7 +148,7 @@ func TestCmd(p *cliapi.PluginDescriptor) *cobra.Command {
 		Use:   p.Name,
 		Short: p.Description,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			runner := NewRunner(p.Name, p.InstallationPath, args)
+			runner := NewRunner(p.Name, p.InstallationPath, args, WithPermissions(p.Permissions))
 			ctx := context.Background()
 			return runner.RunTest(ctx)
 		},
@@ -0,0 +1,136 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aunum/log"
+	"github.com/spf13/cobra"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/common"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/config"
+	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
+	configlib "github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+)
+
+// completionCacheDirName is the name of the directory under the cache
+// directory where the completion results of the plugins are cached
+const completionCacheDirName = "completion"
+
+// completionFunc computes the completion results of a plugin
+type completionFunc func() ([]string, cobra.ShellCompDirective)
+
+// cachedCompletionResults is the on-disk representation of completion results
+type cachedCompletionResults struct {
+	Results   []string                 `json:"results"`
+	Directive cobra.ShellCompDirective `json:"directive"`
+}
+
+// completionCacheKey identifies the completion results of a plugin. The current
+// contexts are part of the key as the results usually depend on them, e.g. the
+// names of the clusters of the current management cluster.
+type completionCacheKey struct {
+	Plugin           string   `json:"plugin"`
+	InstallationPath string   `json:"installationPath"`
+	Args             []string `json:"args"`
+	CurrentContexts  []string `json:"currentContexts"`
+}
+
+// cachedCompletion returns the completion results of the plugin for the arguments
+// from the cache if they were computed within the configured duration, otherwise
+// computes them and updates the cache. Results with the error directive are never cached.
+func cachedCompletion(p *cliapi.PluginDescriptor, args []string, complete completionFunc) ([]string, cobra.ShellCompDirective) {
+	ttl := config.GetCompletionCacheTTL()
+	if ttl <= 0 {
+		return complete()
+	}
+
+	cacheFile, err := completionCacheFile(p, args)
+	if err != nil {
+		return complete()
+	}
+	if cached, ok := readCompletionCache(cacheFile, ttl); ok {
+		return cached.Results, cached.Directive
+	}
+
+	results, directive := complete()
+	if directive&cobra.ShellCompDirectiveError == 0 {
+		if err := writeCompletionCache(cacheFile, &cachedCompletionResults{Results: results, Directive: directive}); err != nil {
+			log.Infof("unable to cache completion results of plugin '%v': %v", p.Name, err.Error())
+		}
+	}
+	return results, directive
+}
+
+func completionCacheFile(p *cliapi.PluginDescriptor, args []string) (string, error) {
+	// The current contexts are not available without a config file, which
+	// simply results in an empty list
+	currentContexts, _ := configlib.GetAllCurrentContextsList()
+	b, err := json.Marshal(completionCacheKey{
+		Plugin:           p.Name,
+		InstallationPath: p.InstallationPath,
+		Args:             args,
+		CurrentContexts:  currentContexts,
+	})
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(GetCompletionCacheDir(), fmt.Sprintf("%s-%x.json", p.Name, sha256.Sum256(b))), nil
+}
+
+func readCompletionCache(cacheFile string, ttl time.Duration) (*cachedCompletionResults, bool) {
+	fi, err := os.Stat(cacheFile)
+	if err != nil || time.Since(fi.ModTime()) > ttl {
+		return nil, false
+	}
+	b, err := os.ReadFile(cacheFile)
+	if err != nil {
+		return nil, false
+	}
+	var cached cachedCompletionResults
+	if err := json.Unmarshal(b, &cached); err != nil {
+		return nil, false
+	}
+	return &cached, true
+}
+
+func writeCompletionCache(cacheFile string, cached *cachedCompletionResults) error {
+	b, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+		return err
+	}
+	// Write to a temporary file first so that concurrent completions never see partial content
+	f, err := os.CreateTemp(filepath.Dir(cacheFile), filepath.Base(cacheFile))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), cacheFile)
+}
+
+// GetCompletionCacheDir returns the directory where the completion results of the plugins are cached
+func GetCompletionCacheDir() string {
+	return filepath.Join(common.DefaultCacheDir, completionCacheDirName)
+}
+
+// CleanCompletionCache removes all the cached completion results
+func CleanCompletionCache() error {
+	return os.RemoveAll(GetCompletionCacheDir())
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/common"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/constants"
+	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
+)
+
+func setupCompletionCache(t *testing.T) {
+	cacheDir := common.DefaultCacheDir
+	common.DefaultCacheDir = t.TempDir()
+	t.Cleanup(func() { common.DefaultCacheDir = cacheDir })
+
+	configDir := t.TempDir()
+	t.Setenv("TANZU_CONFIG", filepath.Join(configDir, "config.yaml"))
+	t.Setenv("TANZU_CONFIG_NEXT_GEN", filepath.Join(configDir, "config-ng.yaml"))
+}
+
+func TestCachedCompletion(t *testing.T) {
+	assert := assert.New(t)
+	setupCompletionCache(t)
+
+	p := &cliapi.PluginDescriptor{Name: "cluster", InstallationPath: "/plugins/cluster"}
+	calls := 0
+	complete := func() ([]string, cobra.ShellCompDirective) {
+		calls++
+		return []string{"cluster-1", "cluster-2"}, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	results, directive := cachedCompletion(p, []string{"__complete", "get", ""}, complete)
+	assert.Equal([]string{"cluster-1", "cluster-2"}, results)
+	assert.Equal(cobra.ShellCompDirectiveNoFileComp, directive)
+	assert.Equal(1, calls)
+
+	// The results are returned from the cache
+	results, directive = cachedCompletion(p, []string{"__complete", "get", ""}, complete)
+	assert.Equal([]string{"cluster-1", "cluster-2"}, results)
+	assert.Equal(cobra.ShellCompDirectiveNoFileComp, directive)
+	assert.Equal(1, calls)
+
+	// Other arguments and plugin versions are not completed from the cache
+	cachedCompletion(p, []string{"__complete", "get", "c"}, complete)
+	assert.Equal(2, calls)
+	cachedCompletion(&cliapi.PluginDescriptor{Name: "cluster", InstallationPath: "/plugins/cluster-v2"}, []string{"__complete", "get", ""}, complete)
+	assert.Equal(3, calls)
+
+	assert.Nil(CleanCompletionCache())
+	cachedCompletion(p, []string{"__complete", "get", ""}, complete)
+	assert.Equal(4, calls)
+}
+
+func TestCachedCompletionErrorsAndDisabledCache(t *testing.T) {
+	assert := assert.New(t)
+	setupCompletionCache(t)
+
+	p := &cliapi.PluginDescriptor{Name: "cluster", InstallationPath: "/plugins/cluster"}
+	calls := 0
+	failing := func() ([]string, cobra.ShellCompDirective) {
+		calls++
+		return nil, cobra.ShellCompDirectiveError
+	}
+	cachedCompletion(p, []string{"__complete", ""}, failing)
+	_, directive := cachedCompletion(p, []string{"__complete", ""}, failing)
+	assert.Equal(cobra.ShellCompDirectiveError, directive)
+	assert.Equal(2, calls)
+
+	t.Setenv(constants.ConfigVariableCompletionCacheTTL, "0")
+	calls = 0
+	complete := func() ([]string, cobra.ShellCompDirective) {
+		calls++
+		return []string{"get"}, cobra.ShellCompDirectiveNoFileComp
+	}
+	cachedCompletion(p, []string{"__complete", "g"}, complete)
+	cachedCompletion(p, []string{"__complete", "g"}, complete)
+	assert.Equal(2, calls)
+}
+
+func TestParseNativeCompletion(t *testing.T) {
+	assert := assert.New(t)
+
+	results, directive := parseNativeCompletion("get\tGet a cluster\nlist\tList clusters\n:4\nCompletion ended with directive: ShellCompDirectiveNoFileComp\n")
+	assert.Equal([]string{"get\tGet a cluster", "list\tList clusters"}, results)
+	assert.Equal(cobra.ShellCompDirectiveNoFileComp, directive)
+
+	_, directive = parseNativeCompletion("get\n")
+	assert.Equal(cobra.ShellCompDirectiveError, directive)
+
+	_, directive = parseNativeCompletion("get\n:x\n")
+	assert.Equal(cobra.ShellCompDirectiveError, directive)
+}
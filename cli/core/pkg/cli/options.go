@@ -8,6 +8,7 @@ import (
 
 	"github.com/adrg/xdg"
 
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
 	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
 )
 
@@ -30,6 +31,9 @@ type optionsConfig struct {
 
 	// VersionSelector is the means to find versions of plugins in a repository.
 	versionSelector VersionSelector
+
+	// permissions are the permissions declared by the plugin to run.
+	permissions *cliv1alpha1.PluginPermissions
 }
 
 var (
@@ -98,3 +102,11 @@ func WithVersionSelector(finder VersionSelector) Option {
 		o.versionSelector = finder
 	}
 }
+
+// WithPermissions sets the permissions declared by the plugin to run. A plugin declaring
+// permissions is run in a sandbox, see Runner.
+func WithPermissions(permissions *cliv1alpha1.PluginPermissions) Option {
+	return func(o *optionsConfig) {
+		o.permissions = permissions
+	}
+}
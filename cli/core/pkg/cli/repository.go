@@ -15,12 +15,14 @@ import (
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/aunum/log"
 	"github.com/pkg/errors"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 	"gopkg.in/yaml.v2"
 
 	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
 )
 
 // Repository is a remote repository containing plugin artifacts.
@@ -150,8 +152,13 @@ type GCPBucketRepository struct {
 }
 
 // LoadRepositories loads the repositories from the config file along with the known repositories.
+// The repositories are overlaid by the profile in use.
 func LoadRepositories(c *configapi.ClientConfig) []Repository {
 	repos := []Repository{}
+	c = c.DeepCopy()
+	if err := config.ApplyCurrentProfile(c); err != nil {
+		log.Warningf("ignoring the profile in use: %v", err)
+	}
 	if c.ClientOptions == nil {
 		c.ClientOptions = &configapi.ClientOptions{}
 	}
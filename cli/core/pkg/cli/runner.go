@@ -14,14 +14,21 @@ import (
 	"strings"
 
 	"github.com/aunum/log"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
 )
 
-// Runner is a plugin runner.
+// Runner is a plugin runner. Plugins which do not declare permissions are run with the
+// environment of the CLI. Plugins declaring permissions are run in a sandbox: only the
+// standard and declared environment variables are passed, the config is replaced by a view
+// of the declared config keys holding no refresh tokens, and the declared network endpoints
+// are passed for the runtime library to restrict the connections to.
 type Runner struct {
 	name          string
 	args          []string
 	pluginRoot    string
 	pluginAbsPath string
+	permissions   *cliv1alpha1.PluginPermissions
 }
 
 // NewRunner creates an instance of Runner.
@@ -33,6 +40,7 @@ func NewRunner(name, pluginAbsPath string, args []string, options ...Option) *Ru
 		args:          args,
 		pluginRoot:    opts.pluginRoot,
 		pluginAbsPath: pluginAbsPath,
+		permissions:   opts.permissions,
 	}
 	return r
 }
@@ -105,7 +113,18 @@ func (r *Runner) run(ctx context.Context, pluginPath string, stdout, stderr *byt
 		return fmt.Errorf("close state file: %w", err)
 	}
 
-	env := append(os.Environ(), fmt.Sprintf("%s=%s", EnvPluginStateKey, stateFile.Name()))
+	// The plugins which do not declare their permissions read the config, but the secrets
+	// of the environment are not handed over to them
+	env := scrubSecretEnv(os.Environ())
+	if r.permissions != nil {
+		s, err := newSandbox(r.permissions)
+		if err != nil {
+			return fmt.Errorf("create plugin sandbox: %w", err)
+		}
+		defer s.cleanup()
+		env = s.env
+	}
+	env = append(env, fmt.Sprintf("%s=%s", EnvPluginStateKey, stateFile.Name()))
 
 	log.Debugf("running command path %s args: %+v", pluginPath, r.args)
 	cmd := exec.CommandContext(ctx, pluginPath, r.args...) //nolint:gosec
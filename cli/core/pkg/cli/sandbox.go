@@ -0,0 +1,288 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/aunum/log"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/auth/csp"
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	configlib "github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+)
+
+// sandboxEnvKeys are the patterns of the environment variables passed to every plugin
+// declaring its permissions, as they are needed to run any binary. The home directories
+// are replaced by the ones of the sandbox.
+var sandboxEnvKeys = []string{
+	"PATH", "USER", "USERNAME", "LOGNAME", "SHELL", "TERM", "COLORTERM", "NO_COLOR", "TZ",
+	"LANG", "LANGUAGE", "LC_*", "TMPDIR", "TMP", "TEMP",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY",
+	"SYSTEMROOT", "SYSTEMDRIVE", "WINDIR", "COMSPEC", "PATHEXT", "PROGRAMDATA",
+}
+
+// secretEnvKeys are the patterns of the environment variables holding secrets, which are
+// removed from the environment of the plugins not declaring their permissions
+var secretEnvKeys = []string{"*TOKEN*", "*SECRET*", "*PASSWORD*", "*PASSWD*", "*API_KEY*", "*APIKEY*", "*ACCESS_KEY*"}
+
+// sandboxConfigKeys are the config keys present in every config view
+var sandboxConfigKeys = []string{configlib.KeyAPIVersion, configlib.KeyKind, configlib.KeyMetadata, configlib.KeySchemaVersion}
+
+// sandbox is the environment a plugin declaring its permissions is run in. The environment
+// variables are scrubbed, the home directory is replaced by an empty one and the config
+// files are replaced by a view of the config which only contains the declared config keys.
+type sandbox struct {
+	// dir is the temporary directory of the config files of the view and of the home
+	// directory of the plugin
+	dir string
+	// env is the environment of the plugin
+	env []string
+}
+
+// newSandbox creates the sandbox of a plugin declaring the permissions
+func newSandbox(permissions *cliv1alpha1.PluginPermissions) (*sandbox, error) {
+	cfg, err := configlib.GetClientConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the config")
+	}
+	if permissions.Credentials {
+		refreshCurrentTokens(cfg)
+	}
+	view, err := configView(cfg, permissions)
+	if err != nil {
+		return nil, err
+	}
+	// The view is written to both config files and read in unified mode, so that
+	// plugins built with older versions of the runtime library read it as well
+	metadata, err := yaml.Marshal(&configapi.Metadata{
+		ConfigMetadata: &configapi.ConfigMetadata{
+			Settings: map[string]string{configlib.SettingUseUnifiedConfig: "true"},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal the config metadata")
+	}
+
+	dir, err := os.MkdirTemp("", "tanzu-plugin-sandbox")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create the sandbox directory")
+	}
+	s := &sandbox{dir: dir, env: scrubEnv(os.Environ(), permissions.Env)}
+	files := []struct {
+		envKey  string
+		name    string
+		content []byte
+	}{
+		{envKey: configlib.EnvConfigKey, name: "config.yaml", content: view},
+		{envKey: configlib.EnvConfigNextGenKey, name: "config-ng.yaml", content: view},
+		{envKey: configlib.EnvConfigMetadataKey, name: "config-metadata.yaml", content: metadata},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, f.content, 0600); err != nil {
+			s.cleanup()
+			return nil, errors.Wrapf(err, "unable to write %q", path)
+		}
+		s.env = append(s.env, fmt.Sprintf("%s=%s", f.envKey, path))
+	}
+	home := filepath.Join(dir, "home")
+	if err := os.Mkdir(home, 0700); err != nil {
+		s.cleanup()
+		return nil, errors.Wrap(err, "unable to create the sandbox home directory")
+	}
+	s.env = append(s.env, homeEnv(home)...)
+	s.env = append(s.env,
+		fmt.Sprintf("%s=%s", configlib.EnvCredentialStoreKey, configlib.CredentialStoreConfig),
+		fmt.Sprintf("%s=%s", EnvPluginNetworkKey, strings.Join(permissions.Network, ",")),
+	)
+	return s, nil
+}
+
+// homeEnv returns the environment variables of the home directories of the user pointing to
+// the home directory, so that the plugin does not find the files of the user, e.g. the config
+// and the credentials of the CLI or the kubeconfig
+func homeEnv(home string) []string {
+	env := []string{
+		"HOME=" + home,
+		"XDG_CONFIG_HOME=" + filepath.Join(home, ".config"),
+		"XDG_CACHE_HOME=" + filepath.Join(home, ".cache"),
+		"XDG_DATA_HOME=" + filepath.Join(home, ".local", "share"),
+		"XDG_STATE_HOME=" + filepath.Join(home, ".local", "state"),
+	}
+	if runtime.GOOS == "windows" {
+		env = append(env,
+			"USERPROFILE="+home,
+			"APPDATA="+filepath.Join(home, "AppData", "Roaming"),
+			"LOCALAPPDATA="+filepath.Join(home, "AppData", "Local"),
+		)
+	}
+	return env
+}
+
+// cleanup removes the config files of the view
+func (s *sandbox) cleanup() {
+	if err := os.RemoveAll(s.dir); err != nil {
+		log.Errorf("unable to remove plugin sandbox directory: %v", err)
+	}
+}
+
+// scrubEnv returns the environment variables matching the standard patterns or the
+// declared ones. Variable names are matched regardless of their case.
+func scrubEnv(environ, declared []string) []string {
+	patterns := append(append([]string{}, sandboxEnvKeys...), declared...)
+	var env []string
+	for _, kv := range environ {
+		if envKeyMatches(kv, patterns) {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+// scrubSecretEnv returns the environment variables which do not hold secrets, for the
+// plugins not declaring their permissions
+func scrubSecretEnv(environ []string) []string {
+	var env []string
+	for _, kv := range environ {
+		if !envKeyMatches(kv, secretEnvKeys) {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+// envKeyMatches checks whether the name of the environment variable matches one of the
+// patterns regardless of its case
+func envKeyMatches(kv string, patterns []string) bool {
+	key := strings.SplitN(kv, "=", 2)[0]
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(strings.ToUpper(pattern), strings.ToUpper(key)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// configView returns the view of the config the plugin is permitted to read. The profile in
+// use is applied to the client options, the tokens are removed unless the plugin needs
+// credentials, in which case only the access tokens of the current contexts are kept, and
+// the config keys which are not declared are removed.
+func configView(cfg *configapi.ClientConfig, permissions *cliv1alpha1.PluginPermissions) ([]byte, error) {
+	view := cfg.DeepCopy()
+	// A missing profile is reported when reading the config, the view is left without it
+	_ = configlib.ApplyCurrentProfile(view)
+	view.Profiles = nil
+	view.CurrentProfile = ""
+	scrubCredentials(view, permissions.Credentials)
+
+	b, err := yaml.Marshal(view)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal the config view")
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(b, &node); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal the config view")
+	}
+	if node.Kind != yaml.DocumentNode || len(node.Content) == 0 {
+		return nil, errors.New("unable to build the config view")
+	}
+	root := node.Content[0]
+	root.Content = append(root.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: configlib.KeySchemaVersion},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(configlib.CurrentSchemaVersion())},
+	)
+	filterConfigNode(root, append(append([]string{}, sandboxConfigKeys...), permissions.ConfigKeys...))
+	return yaml.Marshal(&node)
+}
+
+// filterConfigNode keeps the entries of the mapping node matching the dotted config keys,
+// e.g. "contexts" keeps the contexts and "clientOptions.features" keeps the features of
+// the client options only
+func filterConfigNode(node *yaml.Node, keys []string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	subKeys := make(map[string][]string)
+	whole := make(map[string]bool)
+	for _, key := range keys {
+		parts := strings.SplitN(key, ".", 2)
+		if len(parts) == 1 {
+			whole[parts[0]] = true
+		} else {
+			subKeys[parts[0]] = append(subKeys[parts[0]], parts[1])
+		}
+	}
+	var content []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		if whole[key] {
+			content = append(content, node.Content[i], value)
+		} else if len(subKeys[key]) != 0 && value.Kind == yaml.MappingNode {
+			filterConfigNode(value, subKeys[key])
+			content = append(content, node.Content[i], value)
+		}
+	}
+	node.Content = content
+}
+
+// scrubCredentials removes the refresh tokens of the contexts and servers of the config
+// and, unless credentials are kept, their access tokens. The access tokens are short-lived,
+// the ones of the contexts which are not current are removed in any case.
+func scrubCredentials(cfg *configapi.ClientConfig, credentials bool) {
+	current := make(map[string]bool)
+	if credentials {
+		for _, name := range cfg.CurrentContext {
+			current[name] = true
+		}
+		current[cfg.CurrentServer] = true
+	}
+	for _, ctx := range cfg.KnownContexts {
+		if ctx.GlobalOpts != nil {
+			scrubAuth(&ctx.GlobalOpts.Auth, current[ctx.Name])
+		}
+	}
+	for _, s := range cfg.KnownServers {
+		if s.GlobalOpts != nil {
+			scrubAuth(&s.GlobalOpts.Auth, current[s.Name])
+		}
+	}
+}
+
+// scrubAuth removes the refresh token and, unless kept, the access tokens of the auth
+func scrubAuth(auth *configapi.GlobalServerAuth, keepAccessToken bool) {
+	auth.RefreshToken = ""
+	auth.CredentialRef = ""
+	if !keepAccessToken {
+		auth.AccessToken = ""
+		auth.IDToken = ""
+	}
+}
+
+// refreshCurrentTokens refreshes the expired access tokens of the current contexts, as
+// the plugin cannot refresh them without the refresh tokens, and stores them
+func refreshCurrentTokens(cfg *configapi.ClientConfig) {
+	for ctxType, name := range cfg.CurrentContext {
+		ctx, err := cfg.GetContext(name)
+		if err != nil || ctx.GlobalOpts == nil || ctx.GlobalOpts.Auth.RefreshToken == "" ||
+			!csp.IsExpired(ctx.GlobalOpts.Auth.Expiration.Time) {
+			continue
+		}
+		if _, err := csp.GetToken(&ctx.GlobalOpts.Auth); err != nil {
+			log.Warningf("unable to refresh the access token of the current %s context %q: %v", ctxType, name, err)
+			continue
+		}
+		if err := configlib.SetContext(ctx, false); err != nil {
+			log.Warningf("unable to store the access token of the current %s context %q: %v", ctxType, name, err)
+		}
+	}
+}
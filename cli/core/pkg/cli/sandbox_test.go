@@ -0,0 +1,128 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
+	configlib "github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+)
+
+const sandboxTestConfig = `contexts:
+  - name: tmc-current
+    type: tmc
+    globalOpts:
+      endpoint: tmc.example.com:443
+      auth:
+        accessToken: current-access-token
+        IDToken: current-id-token
+        refresh_token: current-refresh-token
+        expiration: "2099-01-01T00:00:00Z"
+        type: api-token
+  - name: tmc-other
+    type: tmc
+    globalOpts:
+      endpoint: other.example.com:443
+      auth:
+        accessToken: other-access-token
+        refresh_token: other-refresh-token
+        expiration: "2099-01-01T00:00:00Z"
+        type: api-token
+currentContext:
+  tmc: tmc-current
+schemaVersion: 2
+`
+
+func TestSandbox(t *testing.T) {
+	assert := assert.New(t)
+
+	configDir := t.TempDir()
+	t.Setenv(configlib.EnvConfigKey, filepath.Join(configDir, "config.yaml"))
+	t.Setenv(configlib.EnvConfigNextGenKey, filepath.Join(configDir, "config-ng.yaml"))
+	t.Setenv(configlib.EnvConfigMetadataKey, filepath.Join(configDir, "config-metadata.yaml"))
+	t.Setenv(configlib.EnvCredentialStoreKey, configlib.CredentialStoreConfig)
+	assert.NoError(os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte("clientOptions:\n  features:\n    global:\n      context-target: \"true\"\n  env:\n    FOO: bar\n"), 0600))
+	assert.NoError(os.WriteFile(filepath.Join(configDir, "config-ng.yaml"), []byte(sandboxTestConfig), 0600))
+	t.Setenv("TANZU_API_TOKEN", "secret")
+	t.Setenv("KUBECONFIG", "/tmp/kubeconfig")
+	t.Setenv("HOME", "/home/test")
+
+	s, err := newSandbox(&cliv1alpha1.PluginPermissions{
+		Network:     []string{"tmc.example.com:443"},
+		ConfigKeys:  []string{"contexts", "currentContext", "clientOptions.features"},
+		Env:         []string{"KUBE*"},
+		Credentials: true,
+	})
+	assert.NoError(err)
+	defer s.cleanup()
+
+	env := make(map[string]string)
+	for _, kv := range s.env {
+		parts := strings.SplitN(kv, "=", 2)
+		env[parts[0]] = parts[1]
+	}
+	assert.Equal(filepath.Join(s.dir, "home"), env["HOME"])
+	assert.Equal(filepath.Join(s.dir, "home", ".config"), env["XDG_CONFIG_HOME"])
+	assert.Equal("/tmp/kubeconfig", env["KUBECONFIG"])
+	assert.NotContains(env, "TANZU_API_TOKEN")
+	assert.Equal("tmc.example.com:443", env[EnvPluginNetworkKey])
+	assert.Equal(configlib.CredentialStoreConfig, env[configlib.EnvCredentialStoreKey])
+
+	// The plugin reads the view of the config
+	for _, key := range []string{configlib.EnvConfigKey, configlib.EnvConfigNextGenKey, configlib.EnvConfigMetadataKey} {
+		assert.True(strings.HasPrefix(env[key], s.dir))
+		t.Setenv(key, env[key])
+	}
+	cfg, err := configlib.GetClientConfig()
+	assert.NoError(err)
+	assert.Len(cfg.KnownContexts, 2)
+	current, err := cfg.GetContext("tmc-current")
+	assert.NoError(err)
+	assert.Equal("current-access-token", current.GlobalOpts.Auth.AccessToken)
+	assert.Equal("current-id-token", current.GlobalOpts.Auth.IDToken)
+	assert.Empty(current.GlobalOpts.Auth.RefreshToken)
+	other, err := cfg.GetContext("tmc-other")
+	assert.NoError(err)
+	assert.Empty(other.GlobalOpts.Auth.AccessToken)
+	assert.Empty(other.GlobalOpts.Auth.RefreshToken)
+	assert.Equal("true", cfg.ClientOptions.Features["global"]["context-target"])
+	assert.Empty(cfg.ClientOptions.Env)
+
+	s.cleanup()
+	_, err = os.Stat(s.dir)
+	assert.True(os.IsNotExist(err))
+}
+
+func TestConfigViewWithoutCredentials(t *testing.T) {
+	assert := assert.New(t)
+
+	configDir := t.TempDir()
+	t.Setenv(configlib.EnvConfigKey, filepath.Join(configDir, "config.yaml"))
+	t.Setenv(configlib.EnvConfigNextGenKey, filepath.Join(configDir, "config-ng.yaml"))
+	t.Setenv(configlib.EnvConfigMetadataKey, filepath.Join(configDir, "config-metadata.yaml"))
+	t.Setenv(configlib.EnvCredentialStoreKey, configlib.CredentialStoreConfig)
+	assert.NoError(os.WriteFile(filepath.Join(configDir, "config-ng.yaml"), []byte(sandboxTestConfig), 0600))
+	cfg, err := configlib.GetClientConfig()
+	assert.NoError(err)
+
+	view, err := configView(cfg, &cliv1alpha1.PluginPermissions{ConfigKeys: []string{"contexts"}})
+	assert.NoError(err)
+	assert.Contains(string(view), "tmc-current")
+	assert.Contains(string(view), "schemaVersion: 2")
+	assert.NotContains(string(view), "currentContext")
+	assert.NotContains(string(view), "accessToken")
+	assert.NotContains(string(view), "IDToken")
+	assert.NotContains(string(view), "refresh_token")
+}
+
+func TestScrubSecretEnv(t *testing.T) {
+	env := scrubSecretEnv([]string{"PATH=/bin", "TANZU_API_TOKEN=secret", "AWS_SECRET_ACCESS_KEY=secret", "vsphere_password=secret", "KUBECONFIG=/tmp/kubeconfig"})
+	assert.Equal(t, []string{"PATH=/bin", "KUBECONFIG=/tmp/kubeconfig"}, env)
+}
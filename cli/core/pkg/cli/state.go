@@ -13,6 +13,10 @@ const (
 	// EnvPluginStateKey is the environment key that contains the path to the
 	// plugin state file.
 	EnvPluginStateKey = "TANZU_STATE"
+
+	// EnvPluginNetworkKey is the environment key that contains the comma separated
+	// list of network endpoints a plugin declaring its permissions may connect to.
+	EnvPluginNetworkKey = "TANZU_PLUGIN_NETWORK"
 )
 
 // PluginState is state that will be passed to plugins.
@@ -13,7 +13,10 @@ import (
 	"github.com/lithammer/dedent"
 	"github.com/spf13/cobra"
 
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/catalog"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/utils"
 	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
+	configlib "github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
 )
 
 var (
@@ -108,3 +111,88 @@ func runCompletion(out io.Writer, cmd *cobra.Command, args []string) error {
 		return errors.New("unrecognized shell type specified")
 	}
 }
+
+// completionFunc is the signature of the cobra dynamic completion functions
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// completeFirstArg completes the first positional argument of a command only
+func completeFirstArg(complete completionFunc) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return complete(cmd, args, toComplete)
+	}
+}
+
+// completeContextNames completes the names of the contexts not given as arguments yet,
+// described with their type
+func completeContextNames(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := configlib.GetClientConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	for _, ctx := range cfg.KnownContexts {
+		if strings.HasPrefix(ctx.Name, toComplete) && !utils.ContainsString(args, ctx.Name) {
+			names = append(names, fmt.Sprintf("%s\t%s", ctx.Name, ctx.Type))
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeServerNames completes the names of the servers
+func completeServerNames(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := configlib.GetClientConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	for _, s := range cfg.KnownServers {
+		if strings.HasPrefix(s.Name, toComplete) {
+			names = append(names, fmt.Sprintf("%s\t%s", s.Name, s.Type))
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfileNames completes the names of the profiles
+func completeProfileNames(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := configlib.GetClientConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	for _, p := range cfg.Profiles {
+		if strings.HasPrefix(p.Name, toComplete) {
+			names = append(names, p.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeInstalledPluginNames completes the names of the installed plugins
+func completeInstalledPluginNames(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, err := catalog.InstalledPluginNames(toComplete)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeDiscoverySourceNames completes the names of the standalone discovery sources,
+// described with their type
+func completeDiscoverySourceNames(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	discoverySources, err := configlib.GetCLIDiscoverySources()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	for _, ds := range discoverySources {
+		dsName, dsType := discoverySourceNameAndType(ds)
+		if strings.HasPrefix(dsName, toComplete) {
+			names = append(names, fmt.Sprintf("%s\t%s", dsName, dsType))
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
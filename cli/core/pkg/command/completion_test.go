@@ -7,6 +7,15 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/catalog"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/common"
+	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	configlib "github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
 )
 
 // Test_runCompletion_MissingArg validates functionality when shell name is not provided.
@@ -123,3 +132,42 @@ func Test_runCompletion_Pwsh(t *testing.T) {
 		t.Errorf("Unexpected output for the powershell script: %s", out.String())
 	}
 }
+
+// Test_completeNames validates the dynamic completion of the names of contexts, plugins and discovery sources.
+func Test_completeNames(t *testing.T) {
+	assert := assert.New(t)
+	setupContextTestConfig(t)
+	cacheDir := common.DefaultCacheDir
+	common.DefaultCacheDir = t.TempDir()
+	t.Cleanup(func() { common.DefaultCacheDir = cacheDir })
+
+	assert.NoError(configlib.AddContext(&configapi.Context{Name: "mgmt", Type: configapi.CtxTypeK8s}, true))
+	assert.NoError(configlib.AddContext(&configapi.Context{Name: "mgmt-2", Type: configapi.CtxTypeK8s}, false))
+	assert.NoError(configlib.AddContext(&configapi.Context{Name: "tmc", Type: configapi.CtxTypeTMC}, true))
+	assert.NoError(configlib.SetCLIDiscoverySources([]configapi.PluginDiscovery{
+		{OCI: &configapi.OCIDiscovery{Name: "default", Image: "example.com/discovery:v1"}},
+		{Local: &configapi.LocalDiscovery{Name: "local", Path: "standalone"}},
+	}))
+	cc, err := catalog.NewContextCatalog("")
+	assert.NoError(err)
+	assert.NoError(cc.Upsert(&cliapi.PluginDescriptor{Name: "login", Version: "v0.2.0", InstallationPath: "/plugins/login"}))
+	assert.NoError(cc.Upsert(&cliapi.PluginDescriptor{Name: "package", Version: "v0.2.0", InstallationPath: "/plugins/package"}))
+
+	names, directive := completeFirstArg(completeContextNames)(useCtxCmd, nil, "mg")
+	assert.Equal([]string{"mgmt\tk8s", "mgmt-2\tk8s"}, names)
+	assert.Equal(cobra.ShellCompDirectiveNoFileComp, directive)
+
+	names, _ = completeFirstArg(completeContextNames)(useCtxCmd, []string{"mgmt"}, "")
+	assert.Empty(names)
+
+	// The contexts already given as arguments are not completed again
+	names, _ = completeContextNames(exportCtxCmd, []string{"mgmt"}, "")
+	assert.Equal([]string{"mgmt-2\tk8s", "tmc\ttmc"}, names)
+
+	names, directive = completeInstalledPluginNames(deletePluginCmd, nil, "p")
+	assert.Equal([]string{"package"}, names)
+	assert.Equal(cobra.ShellCompDirectiveNoFileComp, directive)
+
+	names, _ = completeDiscoverySourceNames(deleteDiscoverySourceCmd, nil, "")
+	assert.Equal([]string{"default\toci", "local\tlocal"}, names)
+}
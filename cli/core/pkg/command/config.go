@@ -36,9 +36,13 @@ func init() {
 		setConfigCmd,
 		unsetConfigCmd,
 		serversCmd,
+		doctorConfigCmd,
+		profileCmd,
 	)
 	serversCmd.AddCommand(listServersCmd)
 	addDeleteServersCmd()
+	initConfigDoctorCmd()
+	initConfigProfileCmd()
 }
 
 var unattended bool
@@ -276,8 +280,9 @@ var listServersCmd = &cobra.Command{
 
 // Note: Shall be deprecated in a future version. Superseded by 'tanzu context delete' command.
 var deleteServersCmd = &cobra.Command{
-	Use:   "delete SERVER_NAME",
-	Short: "Delete a server from the config",
+	Use:               "delete SERVER_NAME",
+	Short:             "Delete a server from the config",
+	ValidArgsFunction: completeFirstArg(completeServerNames),
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
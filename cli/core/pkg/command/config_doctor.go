@@ -0,0 +1,85 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/aunum/log"
+	"github.com/spf13/cobra"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/component"
+	configlib "github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+)
+
+var (
+	doctorRepair        bool
+	doctorSchemaVersion int
+	doctorOutputFormat  string
+)
+
+func initConfigDoctorCmd() {
+	doctorConfigCmd.Flags().BoolVar(&doctorRepair, "repair", false, "repair the issues found, backing up the config first")
+	doctorConfigCmd.Flags().IntVar(&doctorSchemaVersion, "schema-version", -1, fmt.Sprintf("migrate the config to the schema version, between 0 and %d, e.g. before switching to an older version of the CLI", configlib.CurrentSchemaVersion()))
+	doctorConfigCmd.Flags().StringVarP(&doctorOutputFormat, "output", "o", "table", "output format: table|yaml|json")
+	doctorConfigCmd.MarkFlagsMutuallyExclusive("repair", "schema-version")
+}
+
+var doctorConfigCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Validate and repair the config",
+	Long: "Validate the config files and report the issues found, e.g. invalid files, outdated schema versions, " +
+		"duplicate contexts or servers and missing current contexts. The config files are backed up before being repaired or migrated.",
+	Example: `
+	# Report the issues of the config
+	tanzu config doctor
+
+	# Repair the issues of the config
+	tanzu config doctor --repair
+
+	# Migrate the config back to the schema version of an older version of the CLI
+	tanzu config doctor --schema-version 0`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("schema-version") {
+			if err := configlib.MigrateSchema(doctorSchemaVersion); err != nil {
+				return err
+			}
+			log.Successf("successfully migrated the config to the schema version %d", doctorSchemaVersion)
+			return nil
+		}
+
+		diagnose := configlib.DiagnoseConfig
+		if doctorRepair {
+			diagnose = configlib.RepairConfig
+		}
+		issues, err := diagnose()
+		if err != nil {
+			return err
+		}
+		if len(issues) == 0 {
+			log.Success("the config is healthy")
+			return nil
+		}
+
+		output := component.NewOutputWriter(cmd.OutOrStdout(), doctorOutputFormat, "Document", "Issue", "Repair")
+		for _, issue := range issues {
+			repair := issue.Repair
+			if repair == "" {
+				repair = "manual"
+			}
+			output.AddRow(issue.Document, issue.Description, repair)
+		}
+		output.Render()
+		// Only the table output is followed by messages, so that the other outputs can be parsed
+		if doctorOutputFormat != string(component.TableOutputType) {
+			return nil
+		}
+		if doctorRepair {
+			log.Successf("successfully repaired the config, the previous config is backed up in the %q directory next to the config files", "backups")
+		} else {
+			log.Info("run 'tanzu config doctor --repair' to repair the issues")
+		}
+		return nil
+	},
+}
@@ -0,0 +1,48 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigDoctor(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	t.Setenv("TANZU_CONFIG", cfgPath)
+	t.Setenv("TANZU_CONFIG_NEXT_GEN", filepath.Join(dir, "config-ng.yaml"))
+	t.Setenv("TANZU_CONFIG_METADATA", filepath.Join(dir, "config-metadata.yaml"))
+	assert.NoError(os.WriteFile(cfgPath, []byte("current: missing-mc\n"), 0644))
+
+	doctor := func(args ...string) []map[string]string {
+		var out bytes.Buffer
+		doctorRepair, doctorOutputFormat = false, "table"
+		doctorConfigCmd.SetOut(&out)
+		assert.NoError(doctorConfigCmd.Flags().Parse(args))
+		assert.NoError(doctorConfigCmd.RunE(doctorConfigCmd, nil))
+		// No issues are rendered for a healthy config
+		var issues []map[string]string
+		if out.Len() > 0 {
+			assert.NoError(json.Unmarshal(out.Bytes(), &issues))
+		}
+		return issues
+	}
+
+	issues := doctor("-o", "json")
+	assert.Len(issues, 2)
+	assert.Equal("the schema version 0 is older than the current version 2", issues[0]["issue"])
+	assert.Equal(`the current server "missing-mc" does not exist`, issues[1]["issue"])
+	assert.Equal(cfgPath, issues[1]["document"])
+
+	assert.Equal(issues, doctor("-o", "json", "--repair"))
+	assert.Empty(doctor("-o", "json"))
+}
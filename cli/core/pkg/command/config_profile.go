@@ -0,0 +1,348 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aunum/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/cli"
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/component"
+	configlib "github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+)
+
+var (
+	profileFile                         string
+	profileEnvs, profileFeatures        []string
+	profileDiscoverySources             []string
+	profileEdition, profileOutputFormat string
+	profileOverwrite, profileUnset      bool
+)
+
+func initConfigProfileCmd() {
+	profileCmd.SetUsageFunc(cli.SubCmdUsageFunc)
+	profileCmd.AddCommand(
+		createProfileCmd,
+		useProfileCmd,
+		listProfileCmd,
+		diffProfileCmd,
+	)
+
+	createProfileCmd.Flags().StringVarP(&profileFile, "file", "f", "", "path of a YAML file with the env, features, discoverySources, repositories and edition of the profile")
+	createProfileCmd.Flags().StringArrayVar(&profileEnvs, "env", nil, "env variable of the profile as <variable>=<value>, can be repeated")
+	createProfileCmd.Flags().StringArrayVar(&profileFeatures, "feature", nil, "feature flag of the profile as <plugin>.<feature>=<value>, e.g. global.context-target=true, can be repeated")
+	createProfileCmd.Flags().StringArrayVar(&profileDiscoverySources, "discovery-source", nil, "discovery source of the profile as <name>=<type>:<uri>, e.g. standalone=oci:registry.example.com/plugins:latest, can be repeated")
+	createProfileCmd.Flags().StringVar(&profileEdition, "edition", "", "edition of the profile (tkg|tce)")
+	createProfileCmd.Flags().BoolVar(&profileOverwrite, "overwrite", false, "replace the existing profile of the same name")
+
+	useProfileCmd.Flags().BoolVar(&profileUnset, "unset", false, "stop using a profile")
+
+	listProfileCmd.Flags().StringVarP(&profileOutputFormat, "output", "o", "table", "output format: table|yaml|json")
+	diffProfileCmd.Flags().StringVarP(&profileOutputFormat, "output", "o", "table", "output format: table|yaml|json")
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage the profiles of the config",
+	Long: "Manage the profiles of the config. A profile is a named bundle of env variables, feature flags, discovery sources, " +
+		"repositories and edition overlaying the ones of the config while the profile is in use. " +
+		"The profile in use is set with 'tanzu config profile use' and overridden by the TANZU_PROFILE env variable.",
+}
+
+var createProfileCmd = &cobra.Command{
+	Use:   "create PROFILE_NAME",
+	Short: "Create a profile",
+	Args:  cobra.ExactArgs(1),
+	Example: `
+	# Create a profile with env variables and feature flags
+	tanzu config profile create lab --env TKG_CUSTOM_IMAGE_REPOSITORY=lab.example.com/tkg --feature global.context-target=true
+
+	# Create a profile with a discovery source
+	tanzu config profile create prod --discovery-source standalone=oci:registry.example.com/plugins/standalone:latest
+
+	# Create a profile from a YAML file
+	tanzu config profile create prod -f prod-profile.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := newProfile(args[0])
+		if err != nil {
+			return err
+		}
+		exists, err := configlib.ProfileExists(p.Name)
+		if err != nil {
+			return err
+		}
+		if exists && !profileOverwrite {
+			return errors.Errorf("profile %q already exists, use --overwrite to replace it", p.Name)
+		}
+		if err := configlib.SetProfile(p); err != nil {
+			return err
+		}
+		log.Successf("successfully created the profile %q", p.Name)
+		return nil
+	},
+}
+
+var useProfileCmd = &cobra.Command{
+	Use:               "use PROFILE_NAME",
+	Short:             "Use a profile",
+	ValidArgsFunction: completeFirstArg(completeProfileNames),
+	Example: `
+	# Use the lab profile
+	tanzu config profile use lab
+
+	# Stop using a profile
+	tanzu config profile use --unset`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if profileUnset {
+			if len(args) > 0 {
+				return errors.New("no profile name is expected with --unset")
+			}
+			if err := configlib.SetCurrentProfile(""); err != nil {
+				return err
+			}
+			log.Success("successfully unset the current profile")
+			return nil
+		}
+		if len(args) != 1 {
+			return errors.New("profile name is required")
+		}
+		if err := configlib.SetCurrentProfile(args[0]); err != nil {
+			return err
+		}
+		if name := os.Getenv(configlib.EnvProfileKey); name != "" && name != args[0] {
+			log.Warningf("the profile %q is in use as long as the %s env variable is set", name, configlib.EnvProfileKey)
+		}
+		log.Successf("successfully set the current profile to %q", args[0])
+		return nil
+	},
+}
+
+var listProfileCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the profiles",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := configlib.GetClientConfig()
+		if err != nil {
+			return err
+		}
+		current, err := configlib.GetCurrentProfileName()
+		if err != nil {
+			return err
+		}
+
+		output := component.NewOutputWriter(cmd.OutOrStdout(), profileOutputFormat, "Name", "Current", "Env", "Features", "DiscoverySources", "Repositories", "Edition")
+		for _, p := range cfg.Profiles {
+			var features, discoverySources, repositories []string
+			for plugin, flags := range p.Features {
+				for flag := range flags {
+					features = append(features, plugin+"."+flag)
+				}
+			}
+			for _, ds := range p.DiscoverySources {
+				name, _ := discoverySourceNameAndType(ds)
+				discoverySources = append(discoverySources, name)
+			}
+			for _, repo := range p.Repositories {
+				if repo.GCPPluginRepository != nil {
+					repositories = append(repositories, repo.GCPPluginRepository.Name)
+				}
+			}
+			sort.Strings(features)
+			output.AddRow(p.Name, p.Name == current, strings.Join(sortedKeys(p.Env), ","), strings.Join(features, ","),
+				strings.Join(discoverySources, ","), strings.Join(repositories, ","), p.Edition)
+		}
+		output.Render()
+		return nil
+	},
+}
+
+var diffProfileCmd = &cobra.Command{
+	Use:               "diff [PROFILE_NAME]",
+	Short:             "Show the changes of a profile to the config",
+	Long:              "Show the env variables, feature flags, discovery sources, repositories and edition changed by a profile, the profile in use by default.",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeFirstArg(completeProfileNames),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := configlib.GetClientConfig()
+		if err != nil {
+			return err
+		}
+		var name string
+		if len(args) == 1 {
+			name = args[0]
+		} else if name, err = configlib.GetCurrentProfileName(); err != nil {
+			return err
+		}
+		if name == "" {
+			return errors.New("no profile is in use, a profile name is required")
+		}
+		p, err := cfg.GetProfile(name)
+		if err != nil {
+			return err
+		}
+
+		base := &configapi.ClientOptions{}
+		if cfg.ClientOptions != nil {
+			base = cfg.ClientOptions.DeepCopy()
+		}
+		overlaid := base.DeepCopy()
+		configlib.ApplyProfile(overlaid, p)
+
+		output := component.NewOutputWriter(cmd.OutOrStdout(), profileOutputFormat, "Setting", "Name", "Config", "Profile")
+		for _, change := range diffClientOptions(base, overlaid) {
+			output.AddRow(change[0], change[1], change[2], change[3])
+		}
+		output.Render()
+		return nil
+	},
+}
+
+// newProfile returns the profile of the name from the file and the flags of the create command
+func newProfile(name string) (*configapi.Profile, error) {
+	p := &configapi.Profile{}
+	if profileFile != "" {
+		b, err := os.ReadFile(profileFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read the profile file %q", profileFile)
+		}
+		if err := yaml.Unmarshal(b, p); err != nil {
+			return nil, errors.Wrapf(err, "unable to parse the profile file %q", profileFile)
+		}
+	}
+	p.Name = name
+
+	for _, env := range profileEnvs {
+		key, value, found := strings.Cut(env, "=")
+		if !found || key == "" {
+			return nil, errors.Errorf("invalid env variable %q, expected <variable>=<value>", env)
+		}
+		if p.Env == nil {
+			p.Env = make(map[string]string)
+		}
+		p.Env[key] = value
+	}
+	for _, feature := range profileFeatures {
+		path, value, found := strings.Cut(feature, "=")
+		plugin, flag, validPath := strings.Cut(path, ".")
+		if !found || !validPath || plugin == "" || flag == "" {
+			return nil, errors.Errorf("invalid feature flag %q, expected <plugin>.<feature>=<value>", feature)
+		}
+		if p.Features == nil {
+			p.Features = make(map[string]configapi.FeatureMap)
+		}
+		if p.Features[plugin] == nil {
+			p.Features[plugin] = make(configapi.FeatureMap)
+		}
+		p.Features[plugin][flag] = value
+	}
+	for _, discoverySource := range profileDiscoverySources {
+		dsName, location, found := strings.Cut(discoverySource, "=")
+		dsType, uri, validLocation := strings.Cut(location, ":")
+		if !found || !validLocation || dsName == "" {
+			return nil, errors.Errorf("invalid discovery source %q, expected <name>=<type>:<uri>", discoverySource)
+		}
+		ds, err := createDiscoverySource(dsType, dsName, uri)
+		if err != nil {
+			return nil, err
+		}
+		p.DiscoverySources = append(p.DiscoverySources, ds)
+	}
+	if profileEdition != "" {
+		p.Edition = configapi.EditionSelector(profileEdition)
+	}
+	switch p.Edition {
+	case "", configapi.EditionStandard, configapi.EditionCommunity:
+	default:
+		return nil, errors.Errorf("unsupported edition %q, expected %s or %s", p.Edition, configapi.EditionStandard, configapi.EditionCommunity)
+	}
+	return p, nil
+}
+
+// diffClientOptions returns the setting, name, config value and profile value of the
+// client options changed by a profile
+func diffClientOptions(base, overlaid *configapi.ClientOptions) [][]string {
+	var changes [][]string
+	for _, key := range sortedKeys(overlaid.Env) {
+		if value, exists := base.Env[key]; !exists || value != overlaid.Env[key] {
+			changes = append(changes, []string{"env", key, value, overlaid.Env[key]})
+		}
+	}
+	plugins := make([]string, 0, len(overlaid.Features))
+	for plugin := range overlaid.Features {
+		plugins = append(plugins, plugin)
+	}
+	sort.Strings(plugins)
+	for _, plugin := range plugins {
+		for _, flag := range sortedKeys(overlaid.Features[plugin]) {
+			value := base.Features[plugin][flag]
+			if value != overlaid.Features[plugin][flag] {
+				changes = append(changes, []string{"feature", plugin + "." + flag, value, overlaid.Features[plugin][flag]})
+			}
+		}
+	}
+
+	baseCLI, overlaidCLI := base.CLI, overlaid.CLI
+	if baseCLI == nil {
+		baseCLI = &configapi.CLIOptions{}
+	}
+	if overlaidCLI == nil {
+		return changes
+	}
+	baseDiscoverySources := make(map[string]string)
+	for _, ds := range baseCLI.DiscoverySources {
+		name, _ := discoverySourceNameAndType(ds)
+		baseDiscoverySources[name] = compactJSON(ds)
+	}
+	for _, ds := range overlaidCLI.DiscoverySources {
+		name, _ := discoverySourceNameAndType(ds)
+		if value := compactJSON(ds); value != baseDiscoverySources[name] {
+			changes = append(changes, []string{"discovery source", name, baseDiscoverySources[name], value})
+		}
+	}
+	baseRepositories := make(map[string]string)
+	for _, repo := range baseCLI.Repositories {
+		if repo.GCPPluginRepository != nil {
+			baseRepositories[repo.GCPPluginRepository.Name] = compactJSON(repo)
+		}
+	}
+	for _, repo := range overlaidCLI.Repositories {
+		if repo.GCPPluginRepository == nil {
+			continue
+		}
+		name := repo.GCPPluginRepository.Name
+		if value := compactJSON(repo); value != baseRepositories[name] {
+			changes = append(changes, []string{"repository", name, baseRepositories[name], value})
+		}
+	}
+	if baseCLI.Edition != overlaidCLI.Edition { //nolint:staticcheck
+		changes = append(changes, []string{"edition", "", string(baseCLI.Edition), string(overlaidCLI.Edition)}) //nolint:staticcheck
+	}
+	return changes
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func compactJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	configlib "github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+)
+
+func TestNewProfile(t *testing.T) {
+	assert := assert.New(t)
+
+	file := filepath.Join(t.TempDir(), "profile.yaml")
+	assert.NoError(os.WriteFile(file, []byte(`name: ignored
+env:
+  FOO: file
+repositories:
+  - gcpPluginRepository:
+      name: lab
+      bucketName: lab-bucket
+`), 0644))
+	t.Cleanup(func() {
+		profileFile, profileEnvs, profileFeatures, profileDiscoverySources, profileEdition = "", nil, nil, nil, ""
+	})
+	profileFile = file
+	profileEnvs = []string{"FOO=flag", "BAR=a=b"}
+	profileFeatures = []string{"global.context-target=true"}
+	profileDiscoverySources = []string{"lab=oci:registry.example.com/plugins:v1"}
+	profileEdition = "tce"
+
+	p, err := newProfile("lab")
+	assert.NoError(err)
+	assert.Equal(&configapi.Profile{
+		Name:     "lab",
+		Env:      map[string]string{"FOO": "flag", "BAR": "a=b"},
+		Features: map[string]configapi.FeatureMap{"global": {"context-target": "true"}},
+		DiscoverySources: []configapi.PluginDiscovery{
+			{OCI: &configapi.OCIDiscovery{Name: "lab", Image: "registry.example.com/plugins:v1"}},
+		},
+		Repositories: []configapi.PluginRepository{
+			{GCPPluginRepository: &configapi.GCPPluginRepository{Name: "lab", BucketName: "lab-bucket"}},
+		},
+		Edition: configapi.EditionCommunity,
+	}, p)
+
+	for _, invalid := range []func(){
+		func() { profileEnvs = []string{"FOO"} },
+		func() { profileFeatures = []string{"context-target=true"} },
+		func() { profileDiscoverySources = []string{"lab=registry"} },
+		func() { profileEdition = "other" },
+	} {
+		profileFile, profileEnvs, profileFeatures, profileDiscoverySources, profileEdition = "", nil, nil, nil, ""
+		invalid()
+		_, err := newProfile("lab")
+		assert.Error(err)
+	}
+}
+
+func TestDiffClientOptions(t *testing.T) {
+	base := &configapi.ClientOptions{
+		Env:      map[string]string{"FOO": "base", "BAR": "base"},
+		Features: map[string]configapi.FeatureMap{"global": {"context-target": "false"}},
+		CLI: &configapi.CLIOptions{
+			DiscoverySources: []configapi.PluginDiscovery{{Local: &configapi.LocalDiscovery{Name: "default", Path: "standalone"}}},
+			Edition:          configapi.EditionStandard,
+		},
+	}
+	overlaid := base.DeepCopy()
+	configlib.ApplyProfile(overlaid, &configapi.Profile{
+		Name:             "lab",
+		Env:              map[string]string{"FOO": "lab", "BAR": "base"},
+		Features:         map[string]configapi.FeatureMap{"global": {"context-target": "true"}},
+		DiscoverySources: []configapi.PluginDiscovery{{Local: &configapi.LocalDiscovery{Name: "default", Path: "standalone"}}},
+		Edition:          configapi.EditionCommunity,
+	})
+
+	assert.Equal(t, [][]string{
+		{"env", "FOO", "base", "lab"},
+		{"feature", "global.context-target", "false", "true"},
+		{"edition", "", "tkg", "tce"},
+	}, diffClientOptions(base, overlaid))
+}
@@ -60,9 +60,13 @@ func init() {
 		getCtxCmd,
 		deleteCtxCmd,
 		useCtxCmd,
+		exportCtxCmd,
+		importCtxCmd,
+		syncKubeconfigCtxCmd,
 	)
 
 	initCreateCtxCmd()
+	initContextTransferCmds()
 
 	listCtxCmd.Flags().StringVarP(&ctxType, "type", "t", "", "context type (k8s|tmc)")
 	listCtxCmd.Flags().BoolVar(&onlyCurrent, "current", false, "list only current active contexts")
@@ -127,7 +131,7 @@ func createCtx(_ *cobra.Command, _ []string) (err error) {
 
 	// Sync all required plugins if the "features.global.context-aware-cli-for-plugins" feature is enabled
 	if config.IsFeatureActivated(cliconfig.FeatureContextAwareCLIForPlugins) {
-		if err = pluginmanager.SyncPlugins(); err != nil {
+		if err = pluginmanager.SyncPlugins(pluginmanager.InstallOptions{}); err != nil {
 			log.Warning("unable to automatically sync the plugins from target context. Please run 'tanzu plugin sync' command to sync plugins manually")
 		}
 	}
@@ -487,9 +491,10 @@ func listCtx(cmd *cobra.Command, _ []string) error {
 }
 
 var getCtxCmd = &cobra.Command{
-	Use:   "get CONTEXT_NAME",
-	Short: "Display a context from the config",
-	RunE:  getCtx,
+	Use:               "get CONTEXT_NAME",
+	Short:             "Display a context from the config",
+	ValidArgsFunction: completeFirstArg(completeContextNames),
+	RunE:              getCtx,
 }
 
 func getCtx(cmd *cobra.Command, args []string) error {
@@ -569,9 +574,10 @@ func getKeys(m map[string]*configapi.Context) []string {
 }
 
 var deleteCtxCmd = &cobra.Command{
-	Use:   "delete CONTEXT_NAME",
-	Short: "Delete a context from the config",
-	RunE:  deleteCtx,
+	Use:               "delete CONTEXT_NAME",
+	Short:             "Delete a context from the config",
+	ValidArgsFunction: completeFirstArg(completeContextNames),
+	RunE:              deleteCtx,
 }
 
 func deleteCtx(_ *cobra.Command, args []string) error {
@@ -604,9 +610,10 @@ func deleteCtx(_ *cobra.Command, args []string) error {
 }
 
 var useCtxCmd = &cobra.Command{
-	Use:   "use CONTEXT_NAME",
-	Short: "Set the context to be used by default",
-	RunE:  useCtx,
+	Use:               "use CONTEXT_NAME",
+	Short:             "Set the context to be used by default",
+	ValidArgsFunction: completeFirstArg(completeContextNames),
+	RunE:              useCtx,
 }
 
 func useCtx(_ *cobra.Command, args []string) error {
@@ -625,6 +632,13 @@ func useCtx(_ *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+
+	// Activate the plugins of the context if the "features.global.context-aware-cli-for-plugins" feature is enabled
+	if config.IsFeatureActivated(cliconfig.FeatureContextAwareCLIForPlugins) {
+		if err = pluginmanager.ActivateContextPlugins(name); err != nil {
+			log.Warningf("unable to activate the plugins of context %q: %v. Please run 'tanzu plugin sync' command to sync plugins manually", name, err)
+		}
+	}
 	return nil
 }
 
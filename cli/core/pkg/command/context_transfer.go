@@ -0,0 +1,530 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/aunum/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/scrypt"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/yaml"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/constants"
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/component"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+)
+
+const (
+	contextExportKind          = "ContextExport"
+	encryptedContextExportKind = "EncryptedContextExport"
+
+	// Parameters of the scrypt key derivation of encrypted context exports
+	contextExportSaltSize = 16
+	contextExportKeySize  = 32
+	contextExportScryptN  = 1 << 15
+	contextExportScryptR  = 8
+	contextExportScryptP  = 1
+)
+
+// Results of the synchronization of a context with its kubeconfig
+const (
+	kubeconfigSyncUnchanged = "unchanged"
+	kubeconfigSyncUpdated   = "updated"
+	kubeconfigSyncStale     = "stale"
+	kubeconfigSyncPruned    = "pruned"
+)
+
+var (
+	ctxExportFile, ctxImportFile                    string
+	ctxIncludeCredentials, ctxEncrypt, ctxOverwrite bool
+	ctxPrune, ctxDryRun                             bool
+
+	contextExportAPIVersion = configapi.GroupVersion.String()
+
+	errContextExportPassphraseRequired = errors.New("a passphrase is required to decrypt the contexts")
+
+	// readContextPassphrase is overridden by tests
+	readContextPassphrase = promptContextPassphrase
+)
+
+// contextExport is the portable document carrying exported contexts
+type contextExport struct {
+	APIVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	Contexts   []*configapi.Context `json:"contexts"`
+
+	// Kubeconfigs are the minified kubeconfigs of the kubernetes contexts by context name.
+	// They are exported only with the credentials of the contexts.
+	Kubeconfigs map[string]string `json:"kubeconfigs,omitempty"`
+}
+
+// encryptedContextExport is a context export encrypted with AES-256-GCM using a key
+// derived from a passphrase with scrypt
+type encryptedContextExport struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Salt       string `json:"salt"`
+	Data       string `json:"data"`
+}
+
+// kubeconfigSyncResult is the result of the synchronization of a context with its kubeconfig
+type kubeconfigSyncResult struct {
+	Context string
+	Action  string
+	Details string
+}
+
+func initContextTransferCmds() {
+	exportCtxCmd.Flags().StringVarP(&ctxExportFile, "file", "f", "", "path of the file to export the contexts to (default stdout)")
+	exportCtxCmd.Flags().BoolVar(&ctxIncludeCredentials, "include-credentials", false, "include the tokens of the contexts and the kubeconfigs of the kubernetes contexts")
+	exportCtxCmd.Flags().BoolVar(&ctxEncrypt, "encrypt", false, fmt.Sprintf("encrypt the exported contexts with a passphrase, read from $%s or prompted", constants.ConfigVariableContextPassphrase))
+
+	importCtxCmd.Flags().StringVarP(&ctxImportFile, "file", "f", "", "path of the file to import the contexts from")
+	importCtxCmd.Flags().BoolVar(&ctxOverwrite, "overwrite", false, "overwrite the existing contexts and kubeconfig clusters, users and contexts of the same name")
+	importCtxCmd.Flags().StringVar(&kubeConfig, "kubeconfig", "", "path of the kubeconfig file to merge the imported kubeconfigs into (default $KUBECONFIG or $HOME/.kube/config)")
+	_ = cobra.MarkFlagRequired(importCtxCmd.Flags(), "file")
+
+	syncKubeconfigCtxCmd.Flags().BoolVar(&ctxPrune, "prune", false, "delete the contexts whose kubeconfig context no longer exists")
+	syncKubeconfigCtxCmd.Flags().BoolVar(&ctxDryRun, "dry-run", false, "only report the changes without updating the contexts")
+}
+
+var exportCtxCmd = &cobra.Command{
+	Use:               "export [CONTEXT_NAME...]",
+	Short:             "Export contexts into a portable document",
+	ValidArgsFunction: completeContextNames,
+	Long:              "Export contexts and their discovery sources into a portable document. All contexts are exported if no context name is given.",
+	Example: `
+	# Export all contexts without credentials
+	tanzu context export -f contexts.yaml
+
+	# Export a context with its credentials encrypted with a passphrase
+	tanzu context export mgmt-cluster --include-credentials --encrypt -f mgmt-cluster.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if ctxIncludeCredentials && !ctxEncrypt {
+			log.Warning("Exporting the credentials of the contexts without encryption")
+		}
+		export, err := exportContexts(args, ctxIncludeCredentials)
+		if err != nil {
+			return err
+		}
+		passphrase := ""
+		if ctxEncrypt {
+			if passphrase, err = readContextPassphrase(); err != nil {
+				return err
+			}
+		}
+		data, err := marshalContextExport(export, passphrase)
+		if err != nil {
+			return err
+		}
+		if ctxExportFile == "" {
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		}
+		if err := os.WriteFile(ctxExportFile, data, 0600); err != nil {
+			return errors.Wrap(err, "failed to write the exported contexts")
+		}
+		log.Successf("successfully exported %d context(s) to %q", len(export.Contexts), ctxExportFile)
+		return nil
+	},
+}
+
+var importCtxCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import contexts from a portable document",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(ctxImportFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to read the contexts to import")
+		}
+		export, err := unmarshalContextExport(data, readContextPassphrase)
+		if err != nil {
+			return err
+		}
+		kubeconfigPath := kubeConfig
+		if kubeconfigPath == "" {
+			kubeconfigPath = getDefaultKubeconfigPath()
+		}
+		imported, err := importContexts(export, ctxOverwrite, kubeconfigPath)
+		if err != nil {
+			return err
+		}
+		log.Successf("successfully imported %d context(s)", len(imported))
+		return nil
+	},
+}
+
+var syncKubeconfigCtxCmd = &cobra.Command{
+	Use:   "sync-kubeconfig",
+	Short: "Synchronize the kubernetes contexts with their kubeconfig files",
+	Long: "Reconcile the kubeconfig path, kubeconfig context and endpoint of the kubernetes contexts against their kubeconfig files. " +
+		"Contexts whose kubeconfig context no longer exists are reported as stale and are deleted with --prune.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		results, err := syncContextsWithKubeconfig(ctxPrune, ctxDryRun)
+		if err != nil {
+			return err
+		}
+		output := component.NewOutputWriter(cmd.OutOrStdout(), string(component.TableOutputType), "Context", "Action", "Details")
+		for _, r := range results {
+			output.AddRow(r.Context, r.Action, r.Details)
+		}
+		output.Render()
+		return nil
+	},
+}
+
+// exportContexts returns the portable document of the contexts of the given names,
+// or of all contexts if no name is given
+func exportContexts(names []string, includeCredentials bool) (*contextExport, error) {
+	cfg, err := config.GetClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	contexts := cfg.KnownContexts
+	if len(names) != 0 {
+		contexts = nil
+		for _, name := range names {
+			ctx, err := cfg.GetContext(name)
+			if err != nil {
+				return nil, err
+			}
+			contexts = append(contexts, ctx)
+		}
+	}
+
+	export := &contextExport{APIVersion: contextExportAPIVersion, Kind: contextExportKind}
+	for _, c := range contexts {
+		ctx := c.DeepCopy()
+		if ctx.GlobalOpts != nil && !includeCredentials {
+			ctx.GlobalOpts.Auth.AccessToken = ""
+			ctx.GlobalOpts.Auth.IDToken = ""
+			ctx.GlobalOpts.Auth.RefreshToken = ""
+		}
+		if ctx.ClusterOpts != nil && includeCredentials && ctx.ClusterOpts.Context != "" {
+			kubeconfig, err := minifiedKubeconfig(ctx.ClusterOpts.Path, ctx.ClusterOpts.Context)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to export the kubeconfig of context %q", ctx.Name)
+			}
+			if export.Kubeconfigs == nil {
+				export.Kubeconfigs = make(map[string]string)
+			}
+			export.Kubeconfigs[ctx.Name] = string(kubeconfig)
+		}
+		export.Contexts = append(export.Contexts, ctx)
+	}
+	return export, nil
+}
+
+// importContexts adds the contexts of the portable document to the config and merges
+// their kubeconfigs into the given kubeconfig file. It returns the names of the imported contexts.
+func importContexts(export *contextExport, overwrite bool, kubeconfigPath string) ([]string, error) {
+	var imported []string
+	for _, ctx := range export.Contexts {
+		exists, err := config.ContextExists(ctx.Name)
+		if err != nil {
+			return imported, err
+		}
+		if exists && !overwrite {
+			log.Warningf("Skipping context %q as it already exists, use --overwrite to replace it", ctx.Name)
+			continue
+		}
+		if kubeconfig, ok := export.Kubeconfigs[ctx.Name]; ok && ctx.ClusterOpts != nil {
+			skipped, err := mergeKubeconfig(kubeconfigPath, []byte(kubeconfig), overwrite)
+			if err != nil {
+				return imported, errors.Wrapf(err, "unable to import the kubeconfig of context %q", ctx.Name)
+			}
+			for _, entry := range skipped {
+				log.Warningf("Skipping the %s of context %q as a different one of the same name exists in %q, use --overwrite to replace it", entry, ctx.Name, kubeconfigPath)
+			}
+			ctx.ClusterOpts.Path = kubeconfigPath
+		}
+		if err := config.SetContext(ctx, false); err != nil {
+			return imported, err
+		}
+		imported = append(imported, ctx.Name)
+	}
+	return imported, nil
+}
+
+// marshalContextExport marshals the portable document, encrypting it if a passphrase is given
+func marshalContextExport(export *contextExport, passphrase string) ([]byte, error) {
+	data, err := yaml.Marshal(export)
+	if err != nil || passphrase == "" {
+		return data, err
+	}
+
+	salt := make([]byte, contextExportSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	gcm, err := contextExportCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(&encryptedContextExport{
+		APIVersion: contextExportAPIVersion,
+		Kind:       encryptedContextExportKind,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Data:       base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, data, nil)),
+	})
+}
+
+// unmarshalContextExport unmarshals the portable document, decrypting it with the
+// passphrase returned by getPassphrase if it is encrypted
+func unmarshalContextExport(data []byte, getPassphrase func() (string, error)) (*contextExport, error) {
+	var encrypted encryptedContextExport
+	if err := yaml.Unmarshal(data, &encrypted); err != nil {
+		return nil, errors.Wrap(err, "invalid context export")
+	}
+	if encrypted.Kind == encryptedContextExportKind {
+		passphrase, err := getPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		if passphrase == "" {
+			return nil, errContextExportPassphraseRequired
+		}
+		salt, err := base64.StdEncoding.DecodeString(encrypted.Salt)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid encrypted context export")
+		}
+		ciphertext, err := base64.StdEncoding.DecodeString(encrypted.Data)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid encrypted context export")
+		}
+		gcm, err := contextExportCipher(passphrase, salt)
+		if err != nil {
+			return nil, err
+		}
+		if len(ciphertext) < gcm.NonceSize() {
+			return nil, errors.New("invalid encrypted context export")
+		}
+		data, err = gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], nil)
+		if err != nil {
+			return nil, errors.New("unable to decrypt the contexts, the passphrase is incorrect")
+		}
+	}
+
+	var export contextExport
+	if err := yaml.Unmarshal(data, &export); err != nil {
+		return nil, errors.Wrap(err, "invalid context export")
+	}
+	if export.Kind != contextExportKind {
+		return nil, errors.Errorf("invalid context export kind %q", export.Kind)
+	}
+	return &export, nil
+}
+
+func contextExportCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, contextExportScryptN, contextExportScryptR, contextExportScryptP, contextExportKeySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func promptContextPassphrase() (passphrase string, err error) {
+	if passphrase, ok := os.LookupEnv(constants.ConfigVariableContextPassphrase); ok {
+		return passphrase, nil
+	}
+	err = component.Prompt(
+		&component.PromptConfig{
+			Message:   "Enter the passphrase of the exported contexts",
+			Sensitive: true,
+		},
+		&passphrase,
+		getPromptOpts()...,
+	)
+	return passphrase, err
+}
+
+// minifiedKubeconfig returns the kubeconfig containing only the given context of the
+// kubeconfig file, with the referenced certificates and keys embedded
+func minifiedKubeconfig(path, context string) ([]byte, error) {
+	if path == "" {
+		path = getDefaultKubeconfigPath()
+	}
+	kubeconfig, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	kubeconfig.CurrentContext = context
+	if err := clientcmdapi.MinifyConfig(kubeconfig); err != nil {
+		return nil, err
+	}
+	if err := clientcmdapi.FlattenConfig(kubeconfig); err != nil {
+		return nil, err
+	}
+	return clientcmd.Write(*kubeconfig)
+}
+
+// mergeKubeconfig merges the clusters, users and contexts of the kubeconfig into the kubeconfig file.
+// Entries colliding with different entries of the same name in the kubeconfig file are only replaced
+// if overwrite is set, otherwise they are skipped and returned.
+func mergeKubeconfig(path string, data []byte, overwrite bool) ([]string, error) {
+	kubeconfig, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, err
+	}
+	existing := clientcmdapi.NewConfig()
+	if _, err := os.Stat(path); err == nil {
+		if existing, err = clientcmd.LoadFromFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var skipped []string
+	for name, cluster := range kubeconfig.Clusters {
+		if c, ok := existing.Clusters[name]; ok && !overwrite && !kubeconfigEntriesEqual(c, cluster) {
+			skipped = append(skipped, fmt.Sprintf("kubeconfig cluster %q", name))
+			continue
+		}
+		existing.Clusters[name] = cluster
+	}
+	for name, authInfo := range kubeconfig.AuthInfos {
+		if a, ok := existing.AuthInfos[name]; ok && !overwrite && !kubeconfigEntriesEqual(a, authInfo) {
+			skipped = append(skipped, fmt.Sprintf("kubeconfig user %q", name))
+			continue
+		}
+		existing.AuthInfos[name] = authInfo
+	}
+	for name, context := range kubeconfig.Contexts {
+		if c, ok := existing.Contexts[name]; ok && !overwrite && !kubeconfigEntriesEqual(c, context) {
+			skipped = append(skipped, fmt.Sprintf("kubeconfig context %q", name))
+			continue
+		}
+		existing.Contexts[name] = context
+	}
+	sort.Strings(skipped)
+	return skipped, clientcmd.WriteToFile(*existing, path)
+}
+
+// kubeconfigEntriesEqual returns whether the clusters, users or contexts are the same,
+// regardless of the kubeconfig file they were loaded from
+func kubeconfigEntriesEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case *clientcmdapi.Cluster:
+		x, y := *a, *b.(*clientcmdapi.Cluster)
+		x.LocationOfOrigin, y.LocationOfOrigin = "", ""
+		return reflect.DeepEqual(x, y)
+	case *clientcmdapi.AuthInfo:
+		x, y := *a, *b.(*clientcmdapi.AuthInfo)
+		x.LocationOfOrigin, y.LocationOfOrigin = "", ""
+		return reflect.DeepEqual(x, y)
+	case *clientcmdapi.Context:
+		x, y := *a, *b.(*clientcmdapi.Context)
+		x.LocationOfOrigin, y.LocationOfOrigin = "", ""
+		return reflect.DeepEqual(x, y)
+	}
+	return false
+}
+
+// syncContextsWithKubeconfig reconciles the kubeconfig path, kubeconfig context and endpoint of the
+// kubernetes contexts against their kubeconfig files. Contexts whose kubeconfig context does not exist
+// either in their kubeconfig file or in the default kubeconfig file are stale and are deleted if prune is set.
+func syncContextsWithKubeconfig(prune, dryRun bool) ([]kubeconfigSyncResult, error) {
+	cfg, err := config.GetClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []kubeconfigSyncResult
+	for _, ctx := range cfg.KnownContexts {
+		if ctx.Type != configapi.CtxTypeK8s || ctx.ClusterOpts == nil || ctx.ClusterOpts.Context == "" {
+			continue
+		}
+		result := kubeconfigSyncResult{Context: ctx.Name, Action: kubeconfigSyncUnchanged}
+		path, endpoint, found, err := findKubeconfigContext(ctx.ClusterOpts.Path, ctx.ClusterOpts.Context)
+		if err != nil {
+			return results, errors.Wrapf(err, "unable to sync context %q", ctx.Name)
+		}
+		// An empty path stands for the default kubeconfig file, and is left empty
+		if ctx.ClusterOpts.Path == "" && path == getDefaultKubeconfigPath() {
+			path = ""
+		}
+		switch {
+		case !found:
+			result.Action = kubeconfigSyncStale
+			result.Details = fmt.Sprintf("kubeconfig context %q not found", ctx.ClusterOpts.Context)
+			if prune {
+				result.Action = kubeconfigSyncPruned
+				if !dryRun {
+					if err := config.RemoveContext(ctx.Name); err != nil {
+						return results, err
+					}
+				}
+			}
+		case path != ctx.ClusterOpts.Path || (endpoint != "" && endpoint != ctx.ClusterOpts.Endpoint):
+			result.Action = kubeconfigSyncUpdated
+			result.Details = fmt.Sprintf("path: %q, endpoint: %q", path, endpoint)
+			ctx.ClusterOpts.Path = path
+			if endpoint != "" {
+				ctx.ClusterOpts.Endpoint = endpoint
+			}
+			if !dryRun {
+				if err := config.SetContext(ctx, false); err != nil {
+					return results, err
+				}
+			}
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Context < results[j].Context })
+	return results, nil
+}
+
+// findKubeconfigContext looks up the kubeconfig context in the kubeconfig file, falling back to the
+// default kubeconfig file. It returns the path of the kubeconfig file and the server of the cluster
+// of the kubeconfig context. Missing kubeconfig files do not contain the kubeconfig context, other
+// errors reading the kubeconfig files are returned.
+func findKubeconfigContext(path, context string) (string, string, bool, error) {
+	paths := []string{path}
+	if defaultPath := getDefaultKubeconfigPath(); path != defaultPath {
+		paths = append(paths, defaultPath)
+	}
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		kubeconfig, err := clientcmd.LoadFromFile(p)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", "", false, errors.Wrapf(err, "unable to read the kubeconfig file %q", p)
+		}
+		kubeContext, ok := kubeconfig.Contexts[context]
+		if !ok {
+			continue
+		}
+		endpoint := ""
+		if cluster, ok := kubeconfig.Clusters[kubeContext.Cluster]; ok {
+			endpoint = cluster.Server
+		}
+		return p, endpoint, true, nil
+	}
+	return "", "", false, nil
+}
@@ -0,0 +1,223 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+)
+
+func setupContextTestConfig(t *testing.T) string {
+	dir := t.TempDir()
+	t.Setenv("TANZU_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("TANZU_CONFIG_NEXT_GEN", filepath.Join(dir, "config-ng.yaml"))
+	t.Setenv("TANZU_CONFIG_METADATA", filepath.Join(dir, "config-metadata.yaml"))
+	t.Setenv(clientcmd.RecommendedConfigPathEnvVar, filepath.Join(dir, "kubeconfig"))
+	return dir
+}
+
+func writeTestKubeconfig(t *testing.T, path string, contexts map[string]string) {
+	kubeconfig := clientcmdapi.NewConfig()
+	for name, server := range contexts {
+		kubeconfig.Clusters[name] = &clientcmdapi.Cluster{Server: server}
+		kubeconfig.AuthInfos[name] = &clientcmdapi.AuthInfo{Token: name + "-token"}
+		kubeconfig.Contexts[name] = &clientcmdapi.Context{Cluster: name, AuthInfo: name}
+	}
+	assert.NoError(t, clientcmd.WriteToFile(*kubeconfig, path))
+}
+
+func Test_exportImportContexts(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := setupContextTestConfig(t)
+	kubeconfigPath := filepath.Join(dir, "mgmt-kubeconfig")
+	writeTestKubeconfig(t, kubeconfigPath, map[string]string{"mgmt-admin@mgmt": "https://mgmt:6443", "other": "https://other:6443"})
+
+	mgmtCtx := &configapi.Context{
+		Name:        "mgmt",
+		Type:        configapi.CtxTypeK8s,
+		ClusterOpts: &configapi.ClusterServer{Path: kubeconfigPath, Context: "mgmt-admin@mgmt", IsManagementCluster: true},
+		DiscoverySources: []configapi.PluginDiscovery{
+			{OCI: &configapi.OCIDiscovery{Name: "mgmt-oci", Image: "example.com/plugins:v1"}},
+		},
+	}
+	tmcCtx := &configapi.Context{
+		Name: "tmc",
+		Type: configapi.CtxTypeTMC,
+		GlobalOpts: &configapi.GlobalServer{
+			Endpoint: "tmc.example.com:443",
+			Auth:     configapi.GlobalServerAuth{Issuer: "issuer", AccessToken: "access-token", RefreshToken: "refresh-token", Type: "api-token"},
+		},
+	}
+	assert.NoError(config.SetContext(mgmtCtx, true))
+	assert.NoError(config.SetContext(tmcCtx, true))
+
+	// Export without credentials
+	export, err := exportContexts(nil, false)
+	assert.NoError(err)
+	assert.Equal(2, len(export.Contexts))
+	assert.Empty(export.Kubeconfigs)
+	for _, ctx := range export.Contexts {
+		if ctx.GlobalOpts != nil {
+			assert.Empty(ctx.GlobalOpts.Auth.AccessToken)
+			assert.Empty(ctx.GlobalOpts.Auth.RefreshToken)
+		}
+	}
+
+	_, err = exportContexts([]string{"not-exists"}, false)
+	assert.Error(err)
+
+	// Export with credentials, encrypted with a passphrase
+	export, err = exportContexts([]string{"mgmt", "tmc"}, true)
+	assert.NoError(err)
+	assert.Contains(export.Kubeconfigs, "mgmt")
+	assert.NotContains(export.Kubeconfigs["mgmt"], "other")
+	data, err := marshalContextExport(export, "secret")
+	assert.NoError(err)
+	assert.NotContains(string(data), "refresh-token")
+	assert.NotContains(string(data), "mgmt-admin@mgmt")
+
+	_, err = unmarshalContextExport(data, func() (string, error) { return "wrong", nil })
+	assert.Error(err)
+	assert.Contains(err.Error(), "the passphrase is incorrect")
+	_, err = unmarshalContextExport(data, func() (string, error) { return "", nil })
+	assert.Equal(errContextExportPassphraseRequired, err)
+	imported, err := unmarshalContextExport(data, func() (string, error) { return "secret", nil })
+	assert.NoError(err)
+
+	// Import the contexts on another machine
+	dir = setupContextTestConfig(t)
+	importedKubeconfigPath := filepath.Join(dir, "kubeconfig")
+	names, err := importContexts(imported, false, importedKubeconfigPath)
+	assert.NoError(err)
+	assert.Equal([]string{"mgmt", "tmc"}, names)
+
+	ctx, err := config.GetContext("mgmt")
+	assert.NoError(err)
+	assert.Equal(importedKubeconfigPath, ctx.ClusterOpts.Path)
+	assert.Equal(mgmtCtx.DiscoverySources, ctx.DiscoverySources)
+	kubeconfig, err := clientcmd.LoadFromFile(importedKubeconfigPath)
+	assert.NoError(err)
+	assert.Contains(kubeconfig.Contexts, "mgmt-admin@mgmt")
+	assert.Equal("mgmt-admin@mgmt-token", kubeconfig.AuthInfos["mgmt-admin@mgmt"].Token)
+
+	ctx, err = config.GetContext("tmc")
+	assert.NoError(err)
+	assert.Equal("refresh-token", ctx.GlobalOpts.Auth.RefreshToken)
+
+	// Existing contexts are only replaced with overwrite
+	names, err = importContexts(imported, false, importedKubeconfigPath)
+	assert.NoError(err)
+	assert.Empty(names)
+	names, err = importContexts(imported, true, importedKubeconfigPath)
+	assert.NoError(err)
+	assert.Equal(2, len(names))
+}
+
+func Test_mergeKubeconfig(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kubeconfig")
+	writeTestKubeconfig(t, path, map[string]string{"mgmt": "https://mgmt:6443", "other": "https://other:6443"})
+	importedPath := filepath.Join(dir, "imported")
+	writeTestKubeconfig(t, importedPath, map[string]string{"mgmt": "https://mgmt-new:6443", "other": "https://other:6443", "new": "https://new:6443"})
+	data, err := os.ReadFile(importedPath)
+	assert.NoError(err)
+
+	// Colliding entries which differ are skipped without overwrite
+	skipped, err := mergeKubeconfig(path, data, false)
+	assert.NoError(err)
+	assert.Equal([]string{`kubeconfig cluster "mgmt"`}, skipped)
+	kubeconfig, err := clientcmd.LoadFromFile(path)
+	assert.NoError(err)
+	assert.Equal("https://mgmt:6443", kubeconfig.Clusters["mgmt"].Server)
+	assert.Equal("https://new:6443", kubeconfig.Clusters["new"].Server)
+	assert.Contains(kubeconfig.Contexts, "new")
+
+	skipped, err = mergeKubeconfig(path, data, true)
+	assert.NoError(err)
+	assert.Empty(skipped)
+	kubeconfig, err = clientcmd.LoadFromFile(path)
+	assert.NoError(err)
+	assert.Equal("https://mgmt-new:6443", kubeconfig.Clusters["mgmt"].Server)
+}
+
+func Test_syncContextsWithKubeconfig(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := setupContextTestConfig(t)
+	defaultKubeconfigPath := filepath.Join(dir, "kubeconfig")
+	movedKubeconfigPath := filepath.Join(dir, "moved-kubeconfig")
+	writeTestKubeconfig(t, defaultKubeconfigPath, map[string]string{"moved": "https://moved:6443", "current": "https://current-new:6443"})
+
+	contexts := []*configapi.Context{
+		{Name: "current", Type: configapi.CtxTypeK8s, ClusterOpts: &configapi.ClusterServer{Path: defaultKubeconfigPath, Context: "current", Endpoint: "https://current:6443"}},
+		{Name: "default", Type: configapi.CtxTypeK8s, ClusterOpts: &configapi.ClusterServer{Context: "current", Endpoint: "https://current-new:6443"}},
+		{Name: "moved", Type: configapi.CtxTypeK8s, ClusterOpts: &configapi.ClusterServer{Path: movedKubeconfigPath, Context: "moved", Endpoint: "https://moved:6443"}},
+		{Name: "stale", Type: configapi.CtxTypeK8s, ClusterOpts: &configapi.ClusterServer{Path: defaultKubeconfigPath, Context: "stale"}},
+		{Name: "tmc", Type: configapi.CtxTypeTMC, GlobalOpts: &configapi.GlobalServer{Endpoint: "tmc.example.com:443"}},
+	}
+	for _, ctx := range contexts {
+		assert.NoError(config.SetContext(ctx, false))
+	}
+
+	// Dry run does not update the contexts
+	results, err := syncContextsWithKubeconfig(true, true)
+	assert.NoError(err)
+	assert.Equal(4, len(results))
+	assert.Equal(kubeconfigSyncUpdated, results[0].Action)
+	assert.Equal(kubeconfigSyncUnchanged, results[1].Action)
+	assert.Equal(kubeconfigSyncUpdated, results[2].Action)
+	assert.Equal(kubeconfigSyncPruned, results[3].Action)
+	ctx, err := config.GetContext("moved")
+	assert.NoError(err)
+	assert.Equal(movedKubeconfigPath, ctx.ClusterOpts.Path)
+
+	results, err = syncContextsWithKubeconfig(false, false)
+	assert.NoError(err)
+	assert.Equal([]kubeconfigSyncResult{
+		{Context: "current", Action: kubeconfigSyncUpdated, Details: `path: "` + defaultKubeconfigPath + `", endpoint: "https://current-new:6443"`},
+		{Context: "default", Action: kubeconfigSyncUnchanged},
+		{Context: "moved", Action: kubeconfigSyncUpdated, Details: `path: "` + defaultKubeconfigPath + `", endpoint: "https://moved:6443"`},
+		{Context: "stale", Action: kubeconfigSyncStale, Details: `kubeconfig context "stale" not found`},
+	}, results)
+	ctx, err = config.GetContext("current")
+	assert.NoError(err)
+	assert.Equal("https://current-new:6443", ctx.ClusterOpts.Endpoint)
+	ctx, err = config.GetContext("moved")
+	assert.NoError(err)
+	assert.Equal(defaultKubeconfigPath, ctx.ClusterOpts.Path)
+	// The empty path of the default kubeconfig file is left empty
+	ctx, err = config.GetContext("default")
+	assert.NoError(err)
+	assert.Empty(ctx.ClusterOpts.Path)
+
+	results, err = syncContextsWithKubeconfig(true, false)
+	assert.NoError(err)
+	assert.Equal(kubeconfigSyncUnchanged, results[0].Action)
+	assert.Equal(kubeconfigSyncPruned, results[3].Action)
+	exists, err := config.ContextExists("stale")
+	assert.NoError(err)
+	assert.False(exists)
+	_, err = os.Stat(defaultKubeconfigPath)
+	assert.NoError(err)
+
+	// Contexts are not pruned when their kubeconfig file cannot be read
+	assert.NoError(os.WriteFile(defaultKubeconfigPath, []byte("not a kubeconfig"), 0600))
+	_, err = syncContextsWithKubeconfig(true, false)
+	assert.Error(err)
+	assert.Contains(err.Error(), "unable to read the kubeconfig file")
+	exists, err = config.ContextExists("current")
+	assert.NoError(err)
+	assert.True(exists)
+}
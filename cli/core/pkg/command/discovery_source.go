@@ -103,14 +103,18 @@ func outputFromDiscoverySources(discoverySources []configapi.PluginDiscovery, sc
 var addDiscoverySourceCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add a discovery source",
-	Long:  "Add a discovery source. Supported discovery types are: oci, local",
+	Long:  "Add a discovery source. Supported discovery types are: oci, local, rest, git",
 	Example: `
     # Add a local discovery source. If URI is relative path,
     # $HOME/.config/tanzu-plugins will be considered based path
     tanzu plugin source add --name standalone-local --type local --uri path/to/local/discovery
 
     # Add an OCI discovery source. URI should be an OCI image.
-    tanzu plugin source add --name standalone-oci --type oci --uri projects.registry.vmware.com/tkg/tanzu-plugins/standalone:latest`,
+    tanzu plugin source add --name standalone-oci --type oci --uri projects.registry.vmware.com/tkg/tanzu-plugins/standalone:latest
+
+    # Add a git discovery source. URI should be a git repository URL optionally followed
+    # by '#<ref>' and ':<path>' of the directory containing the plugin manifests.
+    tanzu plugin source add --name standalone-git --type git --uri https://github.com/my-org/tanzu-plugins.git#v1.0.0:discovery`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Acquire tanzu config lock
@@ -144,9 +148,10 @@ var addDiscoverySourceCmd = &cobra.Command{
 }
 
 var updateDiscoverySourceCmd = &cobra.Command{
-	Use:   "update [name]",
-	Short: "Update a discovery source configuration",
-	Args:  cobra.ExactArgs(1),
+	Use:               "update [name]",
+	Short:             "Update a discovery source configuration",
+	ValidArgsFunction: completeFirstArg(completeDiscoverySourceNames),
+	Args:              cobra.ExactArgs(1),
 	Example: `
     # Update a local discovery source. If URI is relative path, 
     # $HOME/.config/tanzu-plugins will be considered base path
@@ -191,9 +196,10 @@ var updateDiscoverySourceCmd = &cobra.Command{
 }
 
 var deleteDiscoverySourceCmd = &cobra.Command{
-	Use:   "delete [name]",
-	Short: "Delete a discovery source",
-	Args:  cobra.ExactArgs(1),
+	Use:               "delete [name]",
+	Short:             "Delete a discovery source",
+	ValidArgsFunction: completeFirstArg(completeDiscoverySourceNames),
+	Args:              cobra.ExactArgs(1),
 	Example: `
     # Delete a discovery source
     tanzu plugin discovery delete standalone-oci`,
@@ -225,6 +231,12 @@ func createDiscoverySource(dsType, dsName, uri string) (configapi.PluginDiscover
 		pluginDiscoverySource.OCI = createOCIDiscoverySource(dsName, uri)
 	case common.DiscoveryTypeREST:
 		pluginDiscoverySource.REST = createRESTDiscoverySource(dsName, uri)
+	case common.DiscoveryTypeGit:
+		gitDiscovery, err := createGitDiscoverySource(dsName, uri)
+		if err != nil {
+			return pluginDiscoverySource, err
+		}
+		pluginDiscoverySource.Git = gitDiscovery
 	case common.DiscoveryTypeGCP, common.DiscoveryTypeKubernetes:
 		return pluginDiscoverySource, errors.Errorf("discovery source type '%s' is not yet supported", dsType)
 	default:
@@ -254,6 +266,20 @@ func createRESTDiscoverySource(discoveryName, uri string) *configapi.GenericREST
 	}
 }
 
+// createGitDiscoverySource creates a git discovery source from a URI of the
+// format `<repository-url>[#<ref>[:<path>]]`
+func createGitDiscoverySource(discoveryName, uri string) (*configapi.GitDiscovery, error) {
+	gitDiscovery := &configapi.GitDiscovery{
+		Name: discoveryName,
+		URL:  uri,
+	}
+	if idx := strings.LastIndex(uri, "#"); idx != -1 {
+		gitDiscovery.URL = uri[:idx]
+		gitDiscovery.Ref, gitDiscovery.Path, _ = strings.Cut(uri[idx+1:], ":")
+	}
+	return gitDiscovery, discovery.ValidateGitSource(gitDiscovery.URL, gitDiscovery.Ref)
+}
+
 func discoverySourceNameAndType(ds configapi.PluginDiscovery) (string, string) {
 	switch {
 	case ds.GCP != nil:
@@ -266,6 +292,8 @@ func discoverySourceNameAndType(ds configapi.PluginDiscovery) (string, string) {
 		return ds.OCI.Name, common.DiscoveryTypeOCI
 	case ds.REST != nil:
 		return ds.REST.Name, common.DiscoveryTypeREST
+	case ds.Git != nil:
+		return ds.Git.Name, common.DiscoveryTypeGit
 	default:
 		return "-", "Unknown" // Unknown discovery source found
 	}
@@ -62,6 +62,23 @@ func Test_createDiscoverySource(t *testing.T) {
 	assert.Equal(pd.REST.Name, "fake-discovery-name")
 	assert.Equal(pd.REST.Endpoint, "fake/path")
 
+	// When discovery source is git
+	pd, err = createDiscoverySource("git", "fake-discovery-name", "https://github.com/fake/plugins.git")
+	assert.Nil(err)
+	assert.NotNil(pd.Git)
+	assert.Equal(pd.Git.Name, "fake-discovery-name")
+	assert.Equal(pd.Git.URL, "https://github.com/fake/plugins.git")
+	assert.Empty(pd.Git.Ref)
+	assert.Empty(pd.Git.Path)
+
+	// When discovery source is git with ref and path
+	pd, err = createDiscoverySource("git", "fake-discovery-name", "git@github.com:fake/plugins.git#v1.0.0:discovery/standalone")
+	assert.Nil(err)
+	assert.NotNil(pd.Git)
+	assert.Equal(pd.Git.URL, "git@github.com:fake/plugins.git")
+	assert.Equal(pd.Git.Ref, "v1.0.0")
+	assert.Equal(pd.Git.Path, "discovery/standalone")
+
 	// When discovery source is an unknown value
 	_, err = createDiscoverySource("unexpectedValue", "fake-discovery-name", "fake/path")
 	assert.NotNil(err)
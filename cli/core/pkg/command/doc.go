@@ -106,7 +106,7 @@ func genREADME(plugins []*cliapi.PluginDescriptor) error {
 func genMarkdownTreePlugins(plugins []*cliapi.PluginDescriptor) error {
 	args := []string{"generate-docs", "--docs-dir", docsDir}
 	for _, p := range plugins {
-		runner := cli.NewRunner(p.Name, p.InstallationPath, args)
+		runner := cli.NewRunner(p.Name, p.InstallationPath, args, cli.WithPermissions(p.Permissions))
 		ctx := context.Background()
 		if err := runner.Run(ctx); err != nil {
 			return err
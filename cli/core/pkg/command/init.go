@@ -72,5 +72,5 @@ func initPluginsWithContextAwareCLI() error {
 	if err := catalog.UpdateCatalogCache(); err != nil {
 		return err
 	}
-	return pluginmanager.SyncPlugins()
+	return pluginmanager.SyncPlugins(pluginmanager.InstallOptions{})
 }
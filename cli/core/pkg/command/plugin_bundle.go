@@ -0,0 +1,80 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"github.com/aunum/log"
+	"github.com/spf13/cobra"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/pluginmanager"
+)
+
+var (
+	bundleFile        string
+	bundleAllVersions bool
+	bundlePlatforms   []string
+	bundleStageDir    string
+)
+
+var pluginBundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Export and import plugin bundles for air-gapped installations",
+	Long:  "Export plugins from the configured discovery sources into a single tarball and import them on a machine without access to the discovery sources.",
+}
+
+func init() {
+	pluginBundleCmd.SetUsageFunc(cli.SubCmdUsageFunc)
+	pluginBundleCmd.AddCommand(
+		exportPluginBundleCmd,
+		importPluginBundleCmd,
+	)
+
+	exportPluginBundleCmd.Flags().StringVarP(&bundleFile, "file", "f", "tanzu-plugins.tar.gz", "path of the plugin bundle tarball")
+	exportPluginBundleCmd.Flags().BoolVarP(&bundleAllVersions, "all-versions", "", false, "export all supported versions instead of only the recommended version of the plugins")
+	exportPluginBundleCmd.Flags().StringSliceVarP(&bundlePlatforms, "platform", "", nil, "platforms of the plugin binaries to export in os/arch format (default platform of the CLI)")
+	exportPluginBundleCmd.Flags().BoolVarP(&refresh, "refresh", "", false, "ignore the discovery cache and discover the plugins again from all discovery sources")
+
+	importPluginBundleCmd.Flags().StringVarP(&bundleFile, "file", "f", "", "path of the plugin bundle tarball")
+	importPluginBundleCmd.Flags().StringVarP(&bundleStageDir, "stage-dir", "", "", "extract the plugin bundle to the directory without installing the plugins")
+	_ = cobra.MarkFlagRequired(importPluginBundleCmd.Flags(), "file")
+}
+
+var exportPluginBundleCmd = &cobra.Command{
+	Use:   "export [plugin-name...]",
+	Short: "Export plugins from the discovery sources into a plugin bundle",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := refreshDiscoveryCacheIfRequested(); err != nil {
+			return err
+		}
+		err := pluginmanager.ExportPluginBundle(&pluginmanager.ExportPluginBundleOptions{
+			Plugins:     args,
+			AllVersions: bundleAllVersions,
+			Platforms:   bundlePlatforms,
+			OutputFile:  bundleFile,
+		})
+		if err != nil {
+			return err
+		}
+		log.Successf("successfully exported plugin bundle %q", bundleFile)
+		return nil
+	},
+}
+
+var importPluginBundleCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Install or stage the plugins of a plugin bundle",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := pluginmanager.ImportPluginBundle(&pluginmanager.ImportPluginBundleOptions{
+			File:          bundleFile,
+			StageDir:      bundleStageDir,
+			AllowUnsigned: allowUnsigned,
+		})
+		if err != nil {
+			return err
+		}
+		log.Successf("successfully imported plugin bundle %q", bundleFile)
+		return nil
+	},
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aunum/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	cliconfig "github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/config"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/pluginmanager"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/component"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+)
+
+func init() {
+	historyPluginCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (yaml|json|table)")
+
+	if config.IsFeatureActivated(cliconfig.FeatureContextCommand) {
+		rollbackPluginCmd.Flags().StringVarP(&target, "target", "", "", "target of the plugin (kubernetes[k8s]/mission-control[tmc])")
+		historyPluginCmd.Flags().StringVarP(&target, "target", "", "", "target of the plugin (kubernetes[k8s]/mission-control[tmc])")
+	}
+}
+
+var rollbackPluginCmd = &cobra.Command{
+	Use:   "rollback [name]",
+	Short: "Rollback a plugin to the previously installed version",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("must provide plugin name as positional argument")
+		}
+		if !config.IsFeatureActivated(cliconfig.FeatureContextAwareCLIForPlugins) {
+			return errors.New("plugin rollback is only supported with the context-aware plugin discovery feature")
+		}
+		pluginName := args[0]
+
+		pd, err := pluginmanager.RollbackPlugin(pluginmanager.PluginHistoryOptions{
+			PluginName: pluginName,
+			Target:     getTarget(),
+		})
+		if err != nil {
+			return err
+		}
+		log.Successf("successfully rolled back plugin '%s' to version '%s'", pluginName, pd.Version)
+		return nil
+	},
+}
+
+var historyPluginCmd = &cobra.Command{
+	Use:   "history [name]",
+	Short: "List the installed versions of a plugin available for rollback",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("must provide plugin name as positional argument")
+		}
+		if !config.IsFeatureActivated(cliconfig.FeatureContextAwareCLIForPlugins) {
+			return errors.New("plugin history is only supported with the context-aware plugin discovery feature")
+		}
+
+		history, err := pluginmanager.PluginHistory(pluginmanager.PluginHistoryOptions{
+			PluginName: args[0],
+			Target:     getTarget(),
+		})
+		if err != nil {
+			return err
+		}
+
+		output := component.NewOutputWriter(cmd.OutOrStdout(), outputFormat, "Version", "Discovery", "Installed", "Status")
+		for i := range history {
+			installed := ""
+			if !history[i].InstalledAt.IsZero() {
+				installed = history[i].InstalledAt.Format(time.RFC3339)
+			}
+			status := ""
+			if history[i].Active {
+				status = "active"
+			}
+			output.AddRow(history[i].Descriptor.Version, history[i].Descriptor.Discovery, installed, status)
+		}
+		output.Render()
+		return nil
+	},
+}
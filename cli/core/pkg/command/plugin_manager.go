@@ -36,6 +36,14 @@ var (
 	version     string
 	forceDelete bool
 	target      string
+	refresh     bool
+	lockFile    string
+	fromLock    string
+
+	allowUnsigned bool
+
+	describePermissions bool
+	describeVersion     string
 )
 
 func init() {
@@ -50,12 +58,26 @@ func init() {
 		cleanPluginCmd,
 		syncPluginCmd,
 		discoverySourceCmd,
+		pluginBundleCmd,
+		rollbackPluginCmd,
+		historyPluginCmd,
 	)
 	listPluginCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (yaml|json|table)")
 	listPluginCmd.Flags().StringVarP(&local, "local", "l", "", "path to local discovery/distribution source")
 	installPluginCmd.Flags().StringVarP(&local, "local", "l", "", "path to local discovery/distribution source")
 	installPluginCmd.Flags().StringVarP(&version, "version", "v", cli.VersionLatest, "version of the plugin")
 	deletePluginCmd.Flags().BoolVarP(&forceDelete, "yes", "y", false, "delete the plugin without asking for confirmation")
+	listPluginCmd.Flags().BoolVarP(&refresh, "refresh", "", false, "ignore the discovery cache and discover the plugins again from all discovery sources")
+	syncPluginCmd.Flags().BoolVarP(&refresh, "refresh", "", false, "ignore the discovery cache and discover the plugins again from all discovery sources")
+	syncPluginCmd.Flags().StringVarP(&lockFile, "lock", "", "", fmt.Sprintf("write the installed plugins to the lockfile given as --lock=<path> after sync (default file %q)", pluginmanager.DefaultLockfileName))
+	syncPluginCmd.Flags().Lookup("lock").NoOptDefVal = pluginmanager.DefaultLockfileName
+	syncPluginCmd.Flags().StringVarP(&fromLock, "from-lock", "", "", "install exactly the plugins recorded in the given lockfile")
+	describePluginCmd.Flags().BoolVarP(&describePermissions, "permissions", "", false, "show the network endpoints the plugin declares, and the config keys, environment variables and credentials it is handed")
+	describePluginCmd.Flags().StringVarP(&describeVersion, "version", "v", "", "version of the plugin whose permissions are shown, to review them before installing it (default the installed or recommended version)")
+	describePluginCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format of the permissions (yaml|json|table)")
+	for _, cmd := range []*cobra.Command{installPluginCmd, upgradePluginCmd, syncPluginCmd, importPluginBundleCmd} {
+		cmd.Flags().BoolVarP(&allowUnsigned, "allow-unsigned", "", false, "install plugins without a signature although a signature trust root is configured")
+	}
 
 	if config.IsFeatureActivated(cliconfig.FeatureContextCommand) {
 		installPluginCmd.Flags().StringVarP(&target, "target", "", "", "target of the plugin (kubernetes[k8s]/mission-control[tmc])")
@@ -81,6 +103,9 @@ var listPluginCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var err error
 		var availablePlugins []plugin.Discovered
+		if err = refreshDiscoveryCacheIfRequested(); err != nil {
+			return err
+		}
 		if local != "" {
 			// get absolute local path
 			local, err = filepath.Abs(local)
@@ -111,6 +136,13 @@ var listPluginCmd = &cobra.Command{
 var describePluginCmd = &cobra.Command{
 	Use:   "describe [name]",
 	Short: "Describe a plugin",
+	Example: `
+	# Describe an installed plugin
+	tanzu plugin describe cluster
+
+	# Review the permissions of a plugin version before installing it
+	tanzu plugin describe cluster --permissions --version v0.28.0`,
+	ValidArgsFunction: completeFirstArg(completeInstalledPluginNames),
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		if len(args) != 1 {
 			return fmt.Errorf("must provide plugin name as positional argument")
@@ -118,6 +150,15 @@ var describePluginCmd = &cobra.Command{
 		pluginName := args[0]
 
 		if config.IsFeatureActivated(cliconfig.FeatureContextAwareCLIForPlugins) {
+			if describePermissions {
+				permissions, pluginVersion, err := pluginmanager.GetPluginPermissions(pluginName, describeVersion, getTarget())
+				if err != nil {
+					return err
+				}
+				displayPluginPermissions(cmd.OutOrStdout(), pluginName, pluginVersion, permissions)
+				return nil
+			}
+
 			pd, err := pluginmanager.DescribePlugin(pluginName, getTarget())
 			if err != nil {
 				return err
@@ -132,6 +173,10 @@ var describePluginCmd = &cobra.Command{
 			return nil
 		}
 
+		if describePermissions {
+			return fmt.Errorf("the permissions of the plugins are only available when the %q feature is activated", cliconfig.FeatureContextAwareCLIForPlugins)
+		}
+
 		repos := getRepositories()
 
 		repo, err := repos.Find(pluginName)
@@ -171,7 +216,7 @@ var installPluginCmd = &cobra.Command{
 				if err != nil {
 					return err
 				}
-				err = pluginmanager.InstallPluginsFromLocalSource(pluginName, version, getTarget(), local, false)
+				err = pluginmanager.InstallPluginsFromLocalSource(pluginName, version, getTarget(), local, false, installOptions())
 				if err != nil {
 					return err
 				}
@@ -185,7 +230,7 @@ var installPluginCmd = &cobra.Command{
 
 			// Invoke plugin sync if install all plugins is mentioned
 			if pluginName == cli.AllPlugins {
-				err = pluginmanager.SyncPlugins()
+				err = pluginmanager.SyncPlugins(installOptions())
 				if err != nil {
 					return err
 				}
@@ -201,7 +246,7 @@ var installPluginCmd = &cobra.Command{
 				}
 			}
 
-			err = pluginmanager.InstallPlugin(pluginName, pluginVersion, getTarget())
+			err = pluginmanager.InstallPlugin(pluginName, pluginVersion, getTarget(), installOptions())
 			if err != nil {
 				return err
 			}
@@ -236,8 +281,9 @@ var installPluginCmd = &cobra.Command{
 }
 
 var upgradePluginCmd = &cobra.Command{
-	Use:   "upgrade [name]",
-	Short: "Upgrade a plugin",
+	Use:               "upgrade [name]",
+	Short:             "Upgrade a plugin",
+	ValidArgsFunction: completeFirstArg(completeInstalledPluginNames),
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		if len(args) != 1 {
 			return fmt.Errorf("must provide plugin name as positional argument")
@@ -250,7 +296,7 @@ var upgradePluginCmd = &cobra.Command{
 				return err
 			}
 
-			err = pluginmanager.UpgradePlugin(pluginName, pluginVersion, getTarget())
+			err = pluginmanager.UpgradePlugin(pluginName, pluginVersion, getTarget(), installOptions())
 			if err != nil {
 				return err
 			}
@@ -280,8 +326,9 @@ var upgradePluginCmd = &cobra.Command{
 }
 
 var deletePluginCmd = &cobra.Command{
-	Use:   "delete [name]",
-	Short: "Delete a plugin",
+	Use:               "delete [name]",
+	Short:             "Delete a plugin",
+	ValidArgsFunction: completeFirstArg(completeInstalledPluginNames),
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		if len(args) != 1 {
 			return fmt.Errorf("must provide plugin name as positional argument")
@@ -338,12 +385,32 @@ var cleanPluginCmd = &cobra.Command{
 var syncPluginCmd = &cobra.Command{
 	Use:   "sync",
 	Short: "Sync the plugins",
+	Args: func(cmd *cobra.Command, args []string) error {
+		// The path of the lockfile is optional, so it is only taken from the
+		// flag value and never from the next argument
+		if len(args) != 0 && cmd.Flags().Changed("lock") {
+			return errors.Errorf("unexpected argument %q, use --lock=<path> to specify the lockfile", args[0])
+		}
+		return cobra.NoArgs(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		if config.IsFeatureActivated(cliconfig.FeatureContextAwareCLIForPlugins) {
-			err = pluginmanager.SyncPlugins()
+			if err = refreshDiscoveryCacheIfRequested(); err != nil {
+				return err
+			}
+			if fromLock != "" {
+				err = pluginmanager.SyncPluginsFromLockfile(fromLock, installOptions())
+			} else {
+				err = pluginmanager.SyncPlugins(installOptions())
+			}
 			if err != nil {
 				return err
 			}
+			if lockFile != "" {
+				if err = pluginmanager.WritePluginLockfile(lockFile); err != nil {
+					return err
+				}
+			}
 			log.Success("Done")
 			return nil
 		}
@@ -351,6 +418,17 @@ var syncPluginCmd = &cobra.Command{
 	},
 }
 
+// refreshDiscoveryCacheIfRequested cleans the discovery cache if the `--refresh` flag is set
+func refreshDiscoveryCacheIfRequested() error {
+	if !refresh {
+		return nil
+	}
+	if err := pluginmanager.CleanDiscoveryCache(); err != nil {
+		return errors.Wrap(err, "unable to clean the discovery cache")
+	}
+	return nil
+}
+
 func getRepositories() *cli.MultiRepo {
 	cfg, err := config.GetClientConfig()
 	if err != nil {
@@ -456,3 +534,33 @@ func displayPluginListOutputSplitViewContext(availablePlugins []plugin.Discovere
 func getTarget() cliv1alpha1.Target {
 	return cliv1alpha1.StringToTarget(target)
 }
+
+func installOptions() pluginmanager.InstallOptions {
+	return pluginmanager.InstallOptions{AllowUnsigned: allowUnsigned}
+}
+
+// displayPluginPermissions displays the permissions of the plugin version, one row per
+// permitted network endpoint, config key, environment variable and credential
+func displayPluginPermissions(writer io.Writer, pluginName, pluginVersion string, permissions *cliv1alpha1.PluginPermissions) {
+	if permissions == nil {
+		log.Warningf("plugin '%v:%v' does not declare permissions, it is run with full access to the config and the credentials, only the secrets of the environment are removed", pluginName, pluginVersion)
+		return
+	}
+	output := component.NewOutputWriter(writer, outputFormat, "Permission", "Value")
+	// The network endpoints are declared, the connections of the plugin are not restricted to them
+	for _, endpoint := range permissions.Network {
+		output.AddRow("network (declared)", endpoint)
+	}
+	for _, key := range permissions.ConfigKeys {
+		output.AddRow("config", key)
+	}
+	for _, env := range permissions.Env {
+		output.AddRow("env", env)
+	}
+	if permissions.Credentials {
+		output.AddRow("credentials", "access tokens of the current contexts")
+	} else {
+		output.AddRow("credentials", "none")
+	}
+	output.Render()
+}
@@ -4,11 +4,14 @@
 package command
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/plugin"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/pluginmanager"
 )
 
 func Test_getInstalledElseAvailablePluginVersion(t *testing.T) {
@@ -26,3 +29,39 @@ func Test_getInstalledElseAvailablePluginVersion(t *testing.T) {
 	version = getInstalledElseAvailablePluginVersion(&p)
 	assert.Equal(version, p.RecommendedVersion)
 }
+
+func Test_displayPluginPermissions(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+	displayPluginPermissions(&out, "cluster", "v1.0.0", &cliv1alpha1.PluginPermissions{
+		Network:    []string{"*.example.com:443"},
+		ConfigKeys: []string{"contexts"},
+		Env:        []string{"KUBECONFIG"},
+	})
+	assert.Regexp(`network \(declared\)\s+\*\.example\.com:443`, out.String())
+	assert.Regexp(`config\s+contexts`, out.String())
+	assert.Regexp(`env\s+KUBECONFIG`, out.String())
+	assert.Regexp(`credentials\s+none`, out.String())
+}
+
+func Test_syncPluginCmdArgs(t *testing.T) {
+	assert := assert.New(t)
+	defer func() {
+		lockFile = ""
+		syncPluginCmd.Flags().Lookup("lock").Changed = false
+	}()
+
+	assert.Nil(syncPluginCmd.Args(syncPluginCmd, []string{}))
+	assert.NotNil(syncPluginCmd.Args(syncPluginCmd, []string{"ci.lock"}))
+
+	// `--lock ci.lock` uses the default lockfile and leaves the path as an argument
+	assert.Nil(syncPluginCmd.ParseFlags([]string{"--lock", "ci.lock"}))
+	assert.Equal(pluginmanager.DefaultLockfileName, lockFile)
+	err := syncPluginCmd.Args(syncPluginCmd, syncPluginCmd.Flags().Args())
+	assert.NotNil(err)
+	assert.Contains(err.Error(), `unexpected argument "ci.lock", use --lock=<path> to specify the lockfile`)
+
+	assert.Nil(syncPluginCmd.ParseFlags([]string{"--lock=ci.lock"}))
+	assert.Equal("ci.lock", lockFile)
+}
@@ -56,6 +56,14 @@ func NewRootCmd() (*cobra.Command, error) {
 		genAllDocsCmd,
 	)
 
+	// The plugins are not loaded from config files which cannot be parsed, so that the
+	// config can still be repaired with 'tanzu config doctor'
+	if _, err := config.GetSchemaVersion(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning, unable to load the plugins: %v\n", err)
+		RootCmd.DisableFlagParsing = true
+		return RootCmd, nil
+	}
+
 	// If the context and target feature is enabled, add the corresponding commands under root.
 	if config.IsFeatureActivated(cliconfig.FeatureContextCommand) {
 		RootCmd.AddCommand(
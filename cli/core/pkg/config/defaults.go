@@ -6,7 +6,11 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/aunum/log"
 
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/common"
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/constants"
@@ -31,6 +35,22 @@ var (
 	DefaultStandaloneDiscoveryLocalPath = ""
 )
 
+// Default plugin discovery cache and timeout configuration
+var (
+	// DefaultDiscoveryCacheTTL is the default duration for which discovered plugins are cached
+	DefaultDiscoveryCacheTTL = 30 * time.Minute
+	// DefaultDiscoveryTimeout is the default maximum duration to wait for a discovery source
+	DefaultDiscoveryTimeout = 60 * time.Second
+)
+
+// DefaultCompletionCacheTTL is the default duration for which the completion
+// results returned by the plugins are cached
+var DefaultCompletionCacheTTL = 10 * time.Second
+
+// DefaultPluginHistoryLimit is the default number of previously installed versions
+// of every plugin kept for rollback
+var DefaultPluginHistoryLimit = 3
+
 // CoreRepositoryName is the core repository name.
 const CoreRepositoryName = "core"
 
@@ -134,6 +154,51 @@ func GetTrustedRegistries() []string {
 	return trustedRegistries
 }
 
+// GetDiscoveryCacheTTL returns the duration for which the plugins discovered from
+// a discovery source are cached
+func GetDiscoveryCacheTTL() time.Duration {
+	return getDurationFromEnv(constants.ConfigVariableDiscoveryCacheTTL, DefaultDiscoveryCacheTTL)
+}
+
+// GetDiscoveryTimeout returns the maximum duration to wait for a discovery source
+func GetDiscoveryTimeout() time.Duration {
+	return getDurationFromEnv(constants.ConfigVariableDiscoveryTimeout, DefaultDiscoveryTimeout)
+}
+
+// GetCompletionCacheTTL returns the duration for which the completion results
+// returned by the plugins are cached
+func GetCompletionCacheTTL() time.Duration {
+	return getDurationFromEnv(constants.ConfigVariableCompletionCacheTTL, DefaultCompletionCacheTTL)
+}
+
+// GetPluginHistoryLimit returns the number of previously installed versions of
+// every plugin kept in the plugin catalog for rollback
+func GetPluginHistoryLimit() int {
+	value := os.Getenv(constants.ConfigVariablePluginHistoryLimit)
+	if value == "" {
+		return DefaultPluginHistoryLimit
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		log.Warningf("invalid limit %q configured for %s, using default %v", value, constants.ConfigVariablePluginHistoryLimit, DefaultPluginHistoryLimit)
+		return DefaultPluginHistoryLimit
+	}
+	return limit
+}
+
+func getDurationFromEnv(variable string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(variable)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Warningf("invalid duration %q configured for %s, using default %v", value, variable, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
 func getHTTPURIForGCPPluginRepository(repo configapi.GCPPluginRepository) string {
 	return fmt.Sprintf("https://storage.googleapis.com/%s/", repo.BucketName)
 }
@@ -4,8 +4,12 @@
 package config
 
 import (
+	"os"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/constants"
 )
 
 var _ = Describe("defaults test cases", func() {
@@ -21,4 +25,20 @@ var _ = Describe("defaults test cases", func() {
 			DefaultAllowedPluginRepositories = ""
 		})
 	})
+	Context("plugin history limit", func() {
+		AfterEach(func() {
+			os.Unsetenv(constants.ConfigVariablePluginHistoryLimit)
+		})
+		It("should return the default limit if not configured", func() {
+			Expect(GetPluginHistoryLimit()).To(Equal(DefaultPluginHistoryLimit))
+		})
+		It("should return the configured limit", func() {
+			os.Setenv(constants.ConfigVariablePluginHistoryLimit, "5")
+			Expect(GetPluginHistoryLimit()).To(Equal(5))
+		})
+		It("should return the default limit if the configured limit is invalid", func() {
+			os.Setenv(constants.ConfigVariablePluginHistoryLimit, "-1")
+			Expect(GetPluginHistoryLimit()).To(Equal(DefaultPluginHistoryLimit))
+		})
+	})
 })
@@ -17,7 +17,9 @@ func init() {
 
 	c, err := config.GetClientConfigNoLock()
 	if err != nil {
+		// The defaults are added once the config is repaired with 'tanzu config doctor'
 		log.Warningf("unable to get client config: %v", err)
+		return
 	}
 
 	addedDefaultDiscovery := populateDefaultStandaloneDiscovery(c)
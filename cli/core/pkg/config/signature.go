@@ -0,0 +1,48 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/signature"
+)
+
+// GetPluginSignatureVerifier returns the verifier for the configured trust root
+// that is used to verify the signatures of the plugins before installation.
+// Returns nil if no trust root is configured.
+//
+// The trust root is either a public key (TANZU_CLI_PLUGIN_SIGNATURE_PUBLIC_KEY)
+// or a root CA bundle and certificate identity for keyless signatures
+// (TANZU_CLI_PLUGIN_SIGNATURE_ROOT_CA, TANZU_CLI_PLUGIN_SIGNATURE_CERTIFICATE_IDENTITY
+// and optionally TANZU_CLI_PLUGIN_SIGNATURE_CERTIFICATE_OIDC_ISSUER)
+func GetPluginSignatureVerifier() (signature.Verifier, error) {
+	publicKey := os.Getenv(constants.PluginSignaturePublicKey)
+	rootCA := os.Getenv(constants.PluginSignatureRootCA)
+	identity := os.Getenv(constants.PluginSignatureCertIdentity)
+
+	switch {
+	case publicKey != "" && rootCA != "":
+		return nil, errors.Errorf("only one of %s and %s can be configured", constants.PluginSignaturePublicKey, constants.PluginSignatureRootCA)
+	case publicKey != "":
+		return signature.NewPublicKeyVerifierFromFile(publicKey)
+	case rootCA != "":
+		return signature.NewCertificateVerifierFromFile(rootCA, identity, os.Getenv(constants.PluginSignatureCertOIDCIssuer))
+	case identity != "":
+		return nil, errors.Errorf("%s must be configured to verify keyless signatures", constants.PluginSignatureRootCA)
+	}
+	return nil, nil
+}
+
+// AllowUnsignedPlugins returns whether the plugins without a signature can be
+// installed although a trust root is configured
+// (TANZU_CLI_PLUGIN_SIGNATURE_ALLOW_UNSIGNED)
+func AllowUnsignedPlugins() bool {
+	allow, _ := strconv.ParseBool(os.Getenv(constants.PluginSignatureAllowUnsigned))
+	return allow
+}
@@ -15,3 +15,40 @@ const (
 	ConfigVariableDefaultStandaloneDiscoveryType      = "TKG_DEFAULT_STANDALONE_DISCOVERY_TYPE"
 	ConfigVariableDefaultStandaloneDiscoveryLocalPath = "TKG_DEFAULT_STANDALONE_DISCOVERY_LOCAL_PATH"
 )
+
+// Configuration variables for plugin discovery
+const (
+	// ConfigVariableDiscoveryCacheTTL is the duration for which the plugins discovered from a
+	// discovery source are cached. Caching is disabled if the duration is 0.
+	ConfigVariableDiscoveryCacheTTL = "TANZU_CLI_DISCOVERY_CACHE_TTL"
+	// ConfigVariableDiscoveryTimeout is the maximum duration to wait for a discovery source
+	ConfigVariableDiscoveryTimeout = "TANZU_CLI_DISCOVERY_TIMEOUT"
+)
+
+// Configuration variables for shell completion
+const (
+	// ConfigVariableCompletionCacheTTL is the duration for which the completion results
+	// returned by the plugins are cached. Caching is disabled if the duration is 0.
+	ConfigVariableCompletionCacheTTL = "TANZU_CLI_COMPLETION_CACHE_TTL"
+)
+
+// Configuration variables for plugin installation
+const (
+	// ConfigVariablePluginHistoryLimit is the number of previously installed versions
+	// of every plugin kept in the plugin catalog for rollback
+	ConfigVariablePluginHistoryLimit = "TANZU_CLI_PLUGIN_HISTORY_LIMIT"
+)
+
+// Configuration variables for image registries
+const (
+	// ConfigVariableRegistryCACertPaths is the list of CA bundle files, separated by the OS path
+	// list separator, trusted when connecting to image registries
+	ConfigVariableRegistryCACertPaths = "TANZU_CLI_REGISTRY_CA_CERT_PATHS"
+)
+
+// Configuration variables for contexts
+const (
+	// ConfigVariableContextPassphrase is the passphrase used to encrypt and decrypt
+	// exported contexts instead of prompting for it
+	ConfigVariableContextPassphrase = "TANZU_CLI_CONTEXT_PASSPHRASE"
+)
@@ -14,3 +14,13 @@ const (
 const (
 	AllowedRegistries = "ALLOWED_REGISTRY"
 )
+
+// environment variables to configure the trust root used to verify the
+// cosign signatures of plugin binaries and discovery images
+const (
+	PluginSignaturePublicKey      = "TANZU_CLI_PLUGIN_SIGNATURE_PUBLIC_KEY"
+	PluginSignatureRootCA         = "TANZU_CLI_PLUGIN_SIGNATURE_ROOT_CA"
+	PluginSignatureCertIdentity   = "TANZU_CLI_PLUGIN_SIGNATURE_CERTIFICATE_IDENTITY"
+	PluginSignatureCertOIDCIssuer = "TANZU_CLI_PLUGIN_SIGNATURE_CERTIFICATE_OIDC_ISSUER"
+	PluginSignatureAllowUnsigned  = "TANZU_CLI_PLUGIN_SIGNATURE_ALLOW_UNSIGNED"
+)
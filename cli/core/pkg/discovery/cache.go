@@ -0,0 +1,197 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aunum/log"
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/common"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/plugin"
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+)
+
+// discoveryCacheDirName is the name of the directory under the cache
+// directory where the discovered plugins are cached
+const discoveryCacheDirName = "discovery"
+
+// CachedDiscovery is a discovery which caches the plugins listed by the
+// underlying discovery on disk for the configured duration
+type CachedDiscovery struct {
+	Discovery
+	// cacheFile is the file where the discovered plugins are cached
+	cacheFile string
+	// ttl is the duration for which the cached plugins are used
+	ttl time.Duration
+}
+
+// cachedPlugin is the on-disk representation of a discovered plugin
+type cachedPlugin struct {
+	plugin.Discovered
+	// Distribution shadows the Distribution interface of the discovered
+	// plugin so that the artifacts can be unmarshalled again
+	Distribution distribution.Artifacts
+}
+
+// cacheEntry is the on-disk representation of the plugins cached for a discovery
+type cacheEntry struct {
+	// Version is the version of the content of the discovery the plugins were listed from
+	Version string
+	Plugins []cachedPlugin
+}
+
+// NewCachedDiscovery returns a discovery caching the plugins listed by the given discovery.
+// The cache is keyed by the name of the discovery source and the digest of its configuration
+// so that any change to the discovery source invalidates the cache. If the discovery is a
+// ContentVersioner, the cache is also invalidated when the version of its content changes,
+// e.g. when the tag of a discovery image is moved to another digest.
+func NewCachedDiscovery(d Discovery, pd configapi.PluginDiscovery, ttl time.Duration) (Discovery, error) {
+	b, err := json.Marshal(pd)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to compute digest of discovery source")
+	}
+	cacheFile := fmt.Sprintf("%s-%x.json", d.Name(), sha256.Sum256(b))
+
+	return &CachedDiscovery{
+		Discovery: d,
+		cacheFile: filepath.Join(GetDiscoveryCacheDir(), cacheFile),
+		ttl:       ttl,
+	}, nil
+}
+
+// List available plugins from the cache if the cache is still valid,
+// otherwise lists the plugins from the underlying discovery and updates the cache.
+func (c *CachedDiscovery) List() ([]plugin.Discovered, error) {
+	return c.ListContext(context.Background())
+}
+
+// ListContext lists the available plugins like List.
+func (c *CachedDiscovery) ListContext(ctx context.Context) ([]plugin.Discovered, error) {
+	version := c.contentVersion(ctx)
+	if plugins, ok := c.readCache(version); ok {
+		return plugins, nil
+	}
+
+	plugins, err := ListContext(ctx, c.Discovery)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.writeCache(version, plugins); err != nil {
+		log.Infof("unable to cache plugins discovered from '%v': %v", c.Name(), err.Error())
+	}
+	return plugins, nil
+}
+
+// contentVersion returns the version of the content of the underlying discovery,
+// or an empty string if it is unknown
+func (c *CachedDiscovery) contentVersion(ctx context.Context) string {
+	cv, ok := c.Discovery.(ContentVersioner)
+	if !ok {
+		return ""
+	}
+	version, err := cv.ContentVersion(ctx)
+	if err != nil {
+		log.Infof("unable to get the content version of discovery '%v': %v", c.Name(), err.Error())
+		return ""
+	}
+	return version
+}
+
+// Describe a plugin.
+func (c *CachedDiscovery) Describe(name string) (p plugin.Discovered, err error) {
+	plugins, err := c.List()
+	if err != nil {
+		return
+	}
+
+	for i := range plugins {
+		if plugins[i].Name == name {
+			p = plugins[i]
+			return
+		}
+	}
+	err = errors.Errorf("cannot find plugin with name '%v'", name)
+	return
+}
+
+// readCache returns the cached plugins if the cache has not expired. The cache is
+// not used if it was written for another version of the content of the discovery.
+// An unknown version, e.g. when the discovery cannot be reached, matches any version.
+func (c *CachedDiscovery) readCache(version string) ([]plugin.Discovered, bool) {
+	fi, err := os.Stat(c.cacheFile)
+	if err != nil || time.Since(fi.ModTime()) > c.ttl {
+		return nil, false
+	}
+	b, err := os.ReadFile(c.cacheFile)
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return nil, false
+	}
+	if version != "" && entry.Version != version {
+		return nil, false
+	}
+
+	plugins := make([]plugin.Discovered, 0, len(entry.Plugins))
+	for i := range entry.Plugins {
+		p := entry.Plugins[i].Discovered
+		p.Distribution = entry.Plugins[i].Distribution
+		plugins = append(plugins, p)
+	}
+	return plugins, true
+}
+
+func (c *CachedDiscovery) writeCache(version string, plugins []plugin.Discovered) error {
+	cachedPlugins := make([]cachedPlugin, 0, len(plugins))
+	for i := range plugins {
+		artifacts, ok := plugins[i].Distribution.(distribution.Artifacts)
+		if plugins[i].Distribution != nil && !ok {
+			return errors.Errorf("unsupported distribution type %T", plugins[i].Distribution)
+		}
+		cachedPlugins = append(cachedPlugins, cachedPlugin{Discovered: plugins[i], Distribution: artifacts})
+	}
+
+	b, err := json.Marshal(cacheEntry{Version: version, Plugins: cachedPlugins})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.cacheFile), 0755); err != nil {
+		return err
+	}
+	// Write to a temporary file first so that concurrent readers never see partial content
+	f, err := os.CreateTemp(filepath.Dir(c.cacheFile), filepath.Base(c.cacheFile))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.cacheFile)
+}
+
+// GetDiscoveryCacheDir returns the directory where the discovered plugins are cached
+func GetDiscoveryCacheDir() string {
+	return filepath.Join(common.DefaultCacheDir, discoveryCacheDirName)
+}
+
+// CleanDiscoveryCache removes all the cached discovered plugins
+func CleanDiscoveryCache() error {
+	return os.RemoveAll(GetDiscoveryCacheDir())
+}
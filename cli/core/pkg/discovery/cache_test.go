@@ -0,0 +1,177 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/common"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/plugin"
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+)
+
+// countingDiscovery is a discovery which counts the number of List calls
+type countingDiscovery struct {
+	GCPDiscovery
+	plugins []plugin.Discovered
+	err     error
+	calls   int
+}
+
+func (d *countingDiscovery) List() ([]plugin.Discovered, error) {
+	d.calls++
+	return d.plugins, d.err
+}
+
+// versionedDiscovery is a counting discovery which reports the version of its content
+type versionedDiscovery struct {
+	countingDiscovery
+	version    string
+	versionErr error
+}
+
+func (d *versionedDiscovery) ContentVersion(_ context.Context) (string, error) {
+	return d.version, d.versionErr
+}
+
+func setupDiscoveryCacheDir(t *testing.T) {
+	cacheDir := common.DefaultCacheDir
+	common.DefaultCacheDir = t.TempDir()
+	t.Cleanup(func() { common.DefaultCacheDir = cacheDir })
+}
+
+func TestCachedDiscovery(t *testing.T) {
+	assert := assert.New(t)
+	setupDiscoveryCacheDir(t)
+
+	artifacts := distribution.Artifacts{
+		"v1.0.0": distribution.ArtifactList{{Image: "example.com/plugins/foo:v1.0.0", Digest: "digest", OS: "linux", Arch: "amd64"}},
+	}
+	d := &countingDiscovery{
+		GCPDiscovery: GCPDiscovery{name: "oci-source"},
+		plugins: []plugin.Discovered{
+			{Name: "foo", RecommendedVersion: "v1.0.0", Source: "oci-source", DiscoveryImage: "example.com/discovery:v1", Distribution: artifacts},
+		},
+	}
+	pd := configapi.PluginDiscovery{OCI: &configapi.OCIDiscovery{Name: "oci-source", Image: "example.com/discovery:v1"}}
+
+	cd, err := NewCachedDiscovery(d, pd, time.Hour)
+	assert.Nil(err)
+	assert.Equal("oci-source", cd.Name())
+
+	plugins, err := cd.List()
+	assert.Nil(err)
+	assert.Equal(1, d.calls)
+	assert.Equal(d.plugins, plugins)
+
+	// Plugins are returned from the cache including the distribution
+	plugins, err = cd.List()
+	assert.Nil(err)
+	assert.Equal(1, d.calls)
+	assert.Equal(d.plugins, plugins)
+
+	p, err := cd.Describe("foo")
+	assert.Nil(err)
+	assert.Equal(1, d.calls)
+	b, err := p.Distribution.Fetch("v1.0.0", "darwin", "amd64")
+	assert.NotNil(err)
+	assert.Nil(b)
+	digest, err := p.Distribution.GetDigest("v1.0.0", "linux", "amd64")
+	assert.Nil(err)
+	assert.Equal("digest", digest)
+
+	_, err = cd.Describe("bar")
+	assert.NotNil(err)
+
+	// Change in discovery source configuration invalidates the cache
+	pd.OCI.Image = "example.com/discovery:v2"
+	cd, err = NewCachedDiscovery(d, pd, time.Hour)
+	assert.Nil(err)
+	_, err = cd.List()
+	assert.Nil(err)
+	assert.Equal(2, d.calls)
+
+	// Expired cache
+	cd, err = NewCachedDiscovery(d, pd, time.Nanosecond)
+	assert.Nil(err)
+	time.Sleep(time.Millisecond)
+	_, err = cd.List()
+	assert.Nil(err)
+	assert.Equal(3, d.calls)
+
+	// Clean cache
+	cd, err = NewCachedDiscovery(d, pd, time.Hour)
+	assert.Nil(err)
+	assert.Nil(CleanDiscoveryCache())
+	_, err = os.Stat(GetDiscoveryCacheDir())
+	assert.True(os.IsNotExist(err))
+	_, err = cd.List()
+	assert.Nil(err)
+	assert.Equal(4, d.calls)
+}
+
+func TestCachedDiscoveryErrorsAreNotCached(t *testing.T) {
+	assert := assert.New(t)
+	setupDiscoveryCacheDir(t)
+
+	d := &countingDiscovery{
+		GCPDiscovery: GCPDiscovery{name: "rest-source"},
+		err:          errors.New("unavailable"),
+	}
+	pd := configapi.PluginDiscovery{REST: &configapi.GenericRESTDiscovery{Name: "rest-source", Endpoint: "https://example.com"}}
+	cd, err := NewCachedDiscovery(d, pd, time.Hour)
+	assert.Nil(err)
+
+	_, err = cd.List()
+	assert.NotNil(err)
+	_, err = cd.List()
+	assert.NotNil(err)
+	assert.Equal(2, d.calls)
+
+	files, err := filepath.Glob(filepath.Join(GetDiscoveryCacheDir(), "*"))
+	assert.Nil(err)
+	assert.Empty(files)
+}
+
+func TestCachedDiscoveryContentVersion(t *testing.T) {
+	assert := assert.New(t)
+	setupDiscoveryCacheDir(t)
+
+	d := &versionedDiscovery{
+		countingDiscovery: countingDiscovery{
+			GCPDiscovery: GCPDiscovery{name: "oci-source"},
+			plugins:      []plugin.Discovered{{Name: "foo", RecommendedVersion: "v1.0.0"}},
+		},
+		version: "sha256:1",
+	}
+	pd := configapi.PluginDiscovery{OCI: &configapi.OCIDiscovery{Name: "oci-source", Image: "example.com/discovery:v1"}}
+	cd, err := NewCachedDiscovery(d, pd, time.Hour)
+	assert.Nil(err)
+
+	_, err = cd.List()
+	assert.Nil(err)
+	_, err = cd.List()
+	assert.Nil(err)
+	assert.Equal(1, d.calls)
+
+	// Change of the content invalidates the cache before it expires
+	d.version = "sha256:2"
+	_, err = cd.List()
+	assert.Nil(err)
+	assert.Equal(2, d.calls)
+
+	// The cache is used when the version of the content is unknown
+	d.versionErr = errors.New("unavailable")
+	_, err = cd.List()
+	assert.Nil(err)
+	assert.Equal(2, d.calls)
+}
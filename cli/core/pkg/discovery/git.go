@@ -0,0 +1,220 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/aunum/log"
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/common"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/plugin"
+)
+
+// gitCheckoutDirName is the name of the directory under the cache
+// directory where the git repositories of the discovery sources are checked out
+const gitCheckoutDirName = "git-discovery"
+
+// gitAllowedProtocols are the transports git may use to access the repositories
+// of the discovery sources. Notably the "ext" transport, which runs arbitrary
+// commands, is not allowed.
+const gitAllowedProtocols = "file:git:http:https:ssh"
+
+// GitDiscovery is an artifact discovery endpoint utilizing a git repository
+type GitDiscovery struct {
+	// name is a name of the discovery
+	name string
+	// url is the URL of the git repository
+	url string
+	// ref is the branch, tag or commit to read the plugin manifests from
+	ref string
+	// path is the directory in the repository containing YAML files,
+	// each of which contains single CLIPlugin API resource
+	path string
+}
+
+// NewGitDiscovery returns a new git repository discovery.
+// If ref is empty, the default branch of the repository is used.
+func NewGitDiscovery(name, url, ref, path string) Discovery {
+	return &GitDiscovery{
+		name: name,
+		url:  url,
+		ref:  ref,
+		path: path,
+	}
+}
+
+// List available plugins.
+func (gd *GitDiscovery) List() ([]plugin.Discovered, error) {
+	return gd.Manifest()
+}
+
+// ListContext lists the available plugins.
+func (gd *GitDiscovery) ListContext(ctx context.Context) ([]plugin.Discovered, error) {
+	return gd.manifest(ctx)
+}
+
+// ContentVersion returns the commit the configured ref points to in the remote
+// repository, or an empty string if the ref is not a branch or tag
+func (gd *GitDiscovery) ContentVersion(ctx context.Context) (string, error) {
+	if err := ValidateGitSource(gd.url, gd.ref); err != nil {
+		return "", err
+	}
+	ref := gd.ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	out, err := runGit(ctx, "", "ls-remote", "--", gd.url, ref)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to resolve %q of git repository %q", ref, gd.url)
+	}
+	if fields := strings.Fields(out); len(fields) > 0 {
+		return fields[0], nil
+	}
+	return "", nil
+}
+
+// Describe a plugin.
+func (gd *GitDiscovery) Describe(name string) (p plugin.Discovered, err error) {
+	plugins, err := gd.Manifest()
+	if err != nil {
+		return
+	}
+
+	for i := range plugins {
+		if plugins[i].Name == name {
+			p = plugins[i]
+			return
+		}
+	}
+	err = errors.Errorf("cannot find plugin with name '%v'", name)
+	return
+}
+
+// Name of the repository.
+func (gd *GitDiscovery) Name() string {
+	return gd.name
+}
+
+// Type of the discovery.
+func (gd *GitDiscovery) Type() string {
+	return common.DiscoveryTypeGit
+}
+
+// Manifest returns the plugins from the manifests in the git repository.
+// The repository is checked out once and fetched again on later runs.
+func (gd *GitDiscovery) Manifest() ([]plugin.Discovered, error) {
+	return gd.manifest(context.Background())
+}
+
+func (gd *GitDiscovery) manifest(ctx context.Context) ([]plugin.Discovered, error) {
+	if err := ValidateGitSource(gd.url, gd.ref); err != nil {
+		return nil, err
+	}
+	manifestPath := filepath.Clean(gd.path)
+	if filepath.IsAbs(manifestPath) || strings.HasPrefix(manifestPath, "..") {
+		return nil, errors.Errorf("manifest path %q must be relative to the root of the git repository", gd.path)
+	}
+
+	checkoutDir, err := gd.checkout(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plugins, err := NewLocalDiscovery(gd.name, filepath.Join(checkoutDir, manifestPath)).List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range plugins {
+		plugins[i].DiscoveryType = gd.Type()
+	}
+	return plugins, nil
+}
+
+// checkout clones the repository or fetches it if it was cloned before and
+// checks out the configured ref. Returns the directory of the checkout.
+func (gd *GitDiscovery) checkout(ctx context.Context) (string, error) {
+	dir := gd.checkoutDir()
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		if err := os.RemoveAll(dir); err != nil {
+			return "", errors.Wrap(err, "unable to clean git checkout directory")
+		}
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			return "", errors.Wrap(err, "unable to create git checkout directory")
+		}
+		if _, err := runGit(ctx, "", "clone", "--quiet", "--no-checkout", "--", gd.url, dir); err != nil {
+			return "", errors.Wrapf(err, "unable to clone git repository %q", gd.url)
+		}
+	} else if _, err := runGit(ctx, dir, "fetch", "--quiet", "--force", "--prune", "--tags", "origin"); err != nil {
+		log.Warningf("unable to fetch git repository %q, using the previous checkout: %v", gd.url, err)
+	}
+
+	rev, err := gd.resolveRef(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	if _, err := runGit(ctx, dir, "checkout", "--quiet", "--force", "--detach", rev); err != nil {
+		return "", errors.Wrapf(err, "unable to checkout %q of git repository %q", gd.ref, gd.url)
+	}
+	return dir, nil
+}
+
+// resolveRef returns the commit of the configured ref. Branches are resolved
+// against the remote so that the latest fetched commit is used.
+func (gd *GitDiscovery) resolveRef(ctx context.Context, dir string) (string, error) {
+	candidates := []string{"origin/HEAD"}
+	if gd.ref != "" {
+		candidates = []string{"origin/" + gd.ref, gd.ref}
+	}
+	for _, candidate := range candidates {
+		if rev, err := runGit(ctx, dir, "rev-parse", "--verify", "--quiet", candidate+"^{commit}"); err == nil {
+			return rev, nil
+		}
+	}
+	return "", errors.Errorf("unable to find ref %q in git repository %q", gd.ref, gd.url)
+}
+
+// ValidateGitSource validates the repository URL and ref of a git discovery source.
+// Neither of them may start with a dash, so that they are never taken for options of git.
+func ValidateGitSource(url, ref string) error {
+	if url == "" {
+		return errors.New("git repository URL cannot be empty")
+	}
+	if strings.HasPrefix(url, "-") {
+		return errors.Errorf("invalid git repository URL %q", url)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return errors.Errorf("invalid git ref %q", ref)
+	}
+	return nil
+}
+
+// checkoutDir returns the directory where the repository is checked out. The
+// directory is keyed by the repository and ref so that discovery sources using
+// different refs of the same repository do not share a checkout.
+func (gd *GitDiscovery) checkoutDir() string {
+	key := fmt.Sprintf("%x", sha256.Sum256([]byte(gd.url+"#"+gd.ref)))
+	return filepath.Join(common.DefaultCacheDir, gitCheckoutDirName, key)
+}
+
+// runGit runs git with the given arguments in the given directory and returns its trimmed output.
+// git is killed once the context is done.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Never prompt for credentials, git credential helpers are still used
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ALLOW_PROTOCOL="+gitAllowedProtocols)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", errors.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
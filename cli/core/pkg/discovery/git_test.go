@@ -0,0 +1,134 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/common"
+)
+
+const gitTestPluginManifest = `apiVersion: cli.tanzu.vmware.com/v1alpha1
+kind: CLIPlugin
+metadata:
+  name: %s
+spec:
+  description: %s plugin
+  recommendedVersion: v0.2.0
+  artifacts:
+    v0.2.0:
+    - uri: https://example.com/%s
+      os: linux
+      arch: amd64
+      type: http
+`
+
+// commitGitTestPlugin adds the manifest of the plugin to the work tree, commits it
+// and pushes the commit to the origin repository
+func commitGitTestPlugin(t *testing.T, workDir, name string) {
+	manifest := filepath.Join(workDir, "plugins", name+".yaml")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(manifest), 0755))
+	assert.Nil(t, os.WriteFile(manifest, []byte(fmt.Sprintf(gitTestPluginManifest, name, name, name)), 0644))
+	runGitForTest(t, workDir, "add", ".")
+	runGitForTest(t, workDir, "commit", "--quiet", "-m", "add "+name)
+	runGitForTest(t, workDir, "push", "--quiet", "--tags", "origin", "main")
+}
+
+func runGitForTest(t *testing.T, dir string, args ...string) {
+	_, err := runGit(context.Background(), dir, append([]string{"-c", "user.name=tanzu", "-c", "user.email=tanzu@example.com"}, args...)...)
+	assert.Nil(t, err)
+}
+
+func TestGitDiscovery(t *testing.T) {
+	assert := assert.New(t)
+
+	defaultCacheDir := common.DefaultCacheDir
+	common.DefaultCacheDir = t.TempDir()
+	defer func() { common.DefaultCacheDir = defaultCacheDir }()
+
+	// A local bare repository stands in for the remote repository
+	bareRepo := filepath.Join(t.TempDir(), "plugins.git")
+	workDir := t.TempDir()
+	runGitForTest(t, "", "init", "--quiet", "--bare", "--initial-branch", "main", bareRepo)
+	runGitForTest(t, "", "clone", "--quiet", bareRepo, workDir)
+	runGitForTest(t, workDir, "checkout", "--quiet", "-b", "main")
+	commitGitTestPlugin(t, workDir, "login")
+	runGitForTest(t, workDir, "tag", "v1.0.0")
+	runGitForTest(t, workDir, "push", "--quiet", "origin", "v1.0.0")
+
+	gd := NewGitDiscovery("git-plugins", bareRepo, "", "plugins")
+	assert.Equal("git-plugins", gd.Name())
+	assert.Equal(common.DiscoveryTypeGit, gd.Type())
+	plugins, err := gd.List()
+	assert.Nil(err)
+	assert.Equal(1, len(plugins))
+	assert.Equal("login", plugins[0].Name)
+	assert.Equal("git-plugins", plugins[0].Source)
+	assert.Equal(common.DiscoveryTypeGit, plugins[0].DiscoveryType)
+	assert.Equal([]string{"v0.2.0"}, plugins[0].SupportedVersions)
+
+	version, err := gd.(ContentVersioner).ContentVersion(context.Background())
+	assert.Nil(err)
+	assert.NotEmpty(version)
+
+	// New commits are fetched into the cached checkout
+	commitGitTestPlugin(t, workDir, "cluster")
+	newVersion, err := gd.(ContentVersioner).ContentVersion(context.Background())
+	assert.Nil(err)
+	assert.NotEqual(version, newVersion)
+	plugins, err = gd.List()
+	assert.Nil(err)
+	assert.Equal(2, len(plugins))
+	p, err := gd.Describe("cluster")
+	assert.Nil(err)
+	assert.Equal("cluster plugin", p.Description)
+
+	// Manifests from a tag
+	plugins, err = NewGitDiscovery("git-plugins", bareRepo, "v1.0.0", "plugins").List()
+	assert.Nil(err)
+	assert.Equal(1, len(plugins))
+
+	// The cached checkout is used when the repository cannot be fetched
+	assert.Nil(os.RemoveAll(bareRepo))
+	plugins, err = gd.List()
+	assert.Nil(err)
+	assert.Equal(2, len(plugins))
+
+	_, err = NewGitDiscovery("git-plugins", bareRepo, "not-exists", "plugins").List()
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "unable to clone git repository")
+
+	_, err = NewGitDiscovery("git-plugins", bareRepo, "", "../plugins").List()
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "must be relative to the root of the git repository")
+}
+
+func TestGitDiscoveryRejectsOptionInjection(t *testing.T) {
+	assert := assert.New(t)
+
+	defaultCacheDir := common.DefaultCacheDir
+	common.DefaultCacheDir = t.TempDir()
+	defer func() { common.DefaultCacheDir = defaultCacheDir }()
+
+	_, err := NewGitDiscovery("git-plugins", "--upload-pack=touch pwned", "", "plugins").List()
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "invalid git repository URL")
+
+	_, err = NewGitDiscovery("git-plugins", "https://example.com/plugins.git", "--output=pwned", "plugins").List()
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "invalid git ref")
+
+	// The ext transport running arbitrary commands is not allowed
+	marker := filepath.Join(t.TempDir(), "pwned")
+	_, err = NewGitDiscovery("git-plugins", "ext::touch "+marker, "", "plugins").List()
+	assert.NotNil(err)
+	_, err = os.Stat(marker)
+	assert.True(os.IsNotExist(err))
+}
@@ -9,6 +9,7 @@
 package discovery
 
 import (
+	"context"
 	"errors"
 
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/plugin"
@@ -30,6 +31,31 @@ type Discovery interface {
 	Type() string
 }
 
+// ContextLister is implemented by the discoveries which stop listing the
+// plugins once the given context is done
+type ContextLister interface {
+	// ListContext lists the available plugins.
+	ListContext(ctx context.Context) ([]plugin.Discovered, error)
+}
+
+// ContentVersioner is implemented by the discoveries which can tell the version
+// of the plugins they serve without listing them, e.g. the digest of an OCI image,
+// the ETag of a REST endpoint or the commit of a git ref
+type ContentVersioner interface {
+	// ContentVersion returns the version of the content of the discovery,
+	// or an empty string if it is unknown.
+	ContentVersion(ctx context.Context) (string, error)
+}
+
+// ListContext lists the available plugins of the discovery. Listing stops once
+// the context is done if the discovery is a ContextLister.
+func ListContext(ctx context.Context, d Discovery) ([]plugin.Discovered, error) {
+	if cl, ok := d.(ContextLister); ok {
+		return cl.ListContext(ctx)
+	}
+	return d.List()
+}
+
 // CreateDiscoveryFromV1alpha1 creates discovery interface from v1alpha1 API
 func CreateDiscoveryFromV1alpha1(pd configapi.PluginDiscovery) (Discovery, error) {
 	switch {
@@ -43,6 +69,8 @@ func CreateDiscoveryFromV1alpha1(pd configapi.PluginDiscovery) (Discovery, error
 		return NewKubernetesDiscovery(pd.Kubernetes.Name, pd.Kubernetes.Path, pd.Kubernetes.Context), nil
 	case pd.REST != nil:
 		return NewRESTDiscovery(pd.REST.Name, pd.REST.Endpoint, pd.REST.BasePath), nil
+	case pd.Git != nil:
+		return NewGitDiscovery(pd.Git.Name, pd.Git.URL, pd.Git.Ref, pd.Git.Path), nil
 	}
 	return nil, errors.New("unknown plugin discovery source")
 }
@@ -65,4 +65,13 @@ func Test_CreateDiscoveryFromV1alpha1(t *testing.T) {
 	assert.Nil(err)
 	assert.Equal(common.DiscoveryTypeREST, discovery.Type())
 	assert.Equal("fake-rest", discovery.Name())
+
+	// When Git discovery is provided
+	pd = configapi.PluginDiscovery{
+		Git: &configapi.GitDiscovery{Name: "fake-git", URL: "https://github.com/fake/plugins.git"},
+	}
+	discovery, err = CreateDiscoveryFromV1alpha1(pd)
+	assert.Nil(err)
+	assert.Equal(common.DiscoveryTypeGit, discovery.Type())
+	assert.Equal("fake-git", discovery.Name())
 }
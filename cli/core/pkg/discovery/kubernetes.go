@@ -126,6 +126,8 @@ func DiscoveredFromK8sV1alpha1WithImageRepositoryOverride(p *cliv1alpha1.CLIPlug
 		RecommendedVersion: p.Spec.RecommendedVersion,
 		Optional:           p.Spec.Optional,
 		Target:             cliv1alpha1.StringToTarget(string(p.Spec.Target)),
+		Requirements:       p.Spec.Requirements,
+		Permissions:        p.Spec.Permissions,
 	}
 	dp.SupportedVersions = make([]string, 0)
 	for v := range p.Spec.Artifacts {
@@ -125,6 +125,8 @@ func DiscoveredFromK8sV1alpha1(p *cliv1alpha1.CLIPlugin) (plugin.Discovered, err
 		RecommendedVersion: p.Spec.RecommendedVersion,
 		Optional:           p.Spec.Optional,
 		Target:             cliv1alpha1.StringToTarget(string(p.Spec.Target)),
+		Requirements:       p.Spec.Requirements,
+		Permissions:        p.Spec.Permissions,
 	}
 	dp.SupportedVersions = make([]string, 0)
 	for v := range p.Spec.Artifacts {
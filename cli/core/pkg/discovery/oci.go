@@ -4,6 +4,7 @@
 package discovery
 
 import (
+	"context"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -13,6 +14,7 @@ import (
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/carvelhelpers"
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/common"
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/plugin"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/signature"
 )
 
 // OCIDiscovery is an artifact discovery endpoint utilizing OCI image
@@ -40,6 +42,21 @@ func (od *OCIDiscovery) List() (plugins []plugin.Discovered, err error) {
 	return od.Manifest()
 }
 
+// ListContext lists the available plugins. The context only applies to the
+// resolution of the discovery image, not to its download.
+func (od *OCIDiscovery) ListContext(ctx context.Context) ([]plugin.Discovered, error) {
+	return od.manifest(ctx)
+}
+
+// ContentVersion returns the digest of the discovery image
+func (od *OCIDiscovery) ContentVersion(ctx context.Context) (string, error) {
+	image, err := signature.ResolveImageDigest(ctx, od.image)
+	if err != nil {
+		return "", err
+	}
+	return image[strings.LastIndex(image, "@")+1:], nil
+}
+
 // Describe a plugin.
 func (od *OCIDiscovery) Describe(name string) (p plugin.Discovered, err error) {
 	plugins, err := od.Manifest()
@@ -69,12 +86,29 @@ func (od *OCIDiscovery) Type() string {
 
 // Manifest returns the manifest for a local repository.
 func (od *OCIDiscovery) Manifest() ([]plugin.Discovered, error) {
-	outputData, err := carvelhelpers.ProcessCarvelPackage(od.image)
+	return od.manifest(context.Background())
+}
+
+func (od *OCIDiscovery) manifest(ctx context.Context) ([]plugin.Discovered, error) {
+	// pin the discovery image, so that the signature verified at installation is the
+	// one of the manifest read here
+	image, err := signature.ResolveImageDigest(ctx, od.image)
+	if err != nil {
+		return nil, err
+	}
+	outputData, err := carvelhelpers.ProcessCarvelPackage(image)
 	if err != nil {
 		return nil, errors.Wrap(err, "error while processing package")
 	}
 
-	return processDiscoveryManifestData(outputData, od.name)
+	plugins, err := processDiscoveryManifestData(outputData, od.name)
+	if err != nil {
+		return nil, err
+	}
+	for i := range plugins {
+		plugins[i].DiscoveryImage = image
+	}
+	return plugins, nil
 }
 
 func processDiscoveryManifestData(data []byte, discoveryName string) ([]plugin.Discovered, error) {
@@ -45,6 +45,12 @@ type Plugin struct {
 
 	// Target the target of the plugin
 	Target cliv1alpha1.Target `json:"contextType"`
+
+	// Requirements contains the compatibility and dependency requirements for every supported version.
+	Requirements map[string]cliv1alpha1.PluginRequirements `json:"requirements,omitempty"`
+
+	// Permissions contains the permissions declared for every supported version.
+	Permissions map[string]cliv1alpha1.PluginPermissions `json:"permissions,omitempty"`
 }
 
 // DescribePluginResponse defines the response from Describe Plugin API.
@@ -104,7 +110,12 @@ func (d *RESTDiscovery) doRequest(req *http.Request, v interface{}) error {
 
 // List available plugins.
 func (d *RESTDiscovery) List() ([]plugin.Discovered, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	return d.ListContext(context.Background())
+}
+
+// ListContext lists the available plugins.
+func (d *RESTDiscovery) ListContext(ctx context.Context) ([]plugin.Discovered, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/%s", d.endpoint, d.basePath), http.NoBody)
@@ -131,6 +142,28 @@ func (d *RESTDiscovery) List() ([]plugin.Discovered, error) {
 	return plugins, nil
 }
 
+// ContentVersion returns the ETag of the plugins listed by the REST API, or
+// an empty string if the API does not return one
+func (d *RESTDiscovery) ContentVersion(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, fmt.Sprintf("%s/%s", d.endpoint, d.basePath), http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	res, err := d.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", nil
+	}
+	return res.Header.Get("ETag"), nil
+}
+
 // Describe a plugin.
 func (d *RESTDiscovery) Describe(name string) (p plugin.Discovered, err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
@@ -174,6 +207,8 @@ func DiscoveredFromREST(p *Plugin) (plugin.Discovered, error) {
 		RecommendedVersion: p.RecommendedVersion,
 		Optional:           p.Optional,
 		Target:             cliv1alpha1.StringToTarget(string(p.Target)),
+		Requirements:       p.Requirements,
+		Permissions:        p.Permissions,
 	}
 	dp.SupportedVersions = make([]string, 0)
 	for v := range p.Artifacts {
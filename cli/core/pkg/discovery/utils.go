@@ -18,6 +18,7 @@ func CheckDiscoveryName(ds configapi.PluginDiscovery, dn string) bool {
 		(ds.Kubernetes != nil && ds.Kubernetes.Name == dn) ||
 		(ds.Local != nil && ds.Local.Name == dn) ||
 		(ds.REST != nil && ds.REST.Name == dn) ||
+		(ds.Git != nil && ds.Git.Name == dn) ||
 		(ds.OCI != nil && ds.OCI.Name == dn)
 }
 
@@ -38,6 +39,9 @@ func CompareDiscoverySource(ds1, ds2 configapi.PluginDiscovery, dsType string) b
 
 	case common.DiscoveryTypeREST:
 		return compareRESTDiscoverySources(ds1, ds2)
+
+	case common.DiscoveryTypeGit:
+		return compareGitDiscoverySources(ds1, ds2)
 	}
 	return false
 }
@@ -75,6 +79,14 @@ func compareRESTDiscoverySources(ds1, ds2 configapi.PluginDiscovery) bool {
 		ds1.REST.Endpoint == ds2.REST.Endpoint
 }
 
+func compareGitDiscoverySources(ds1, ds2 configapi.PluginDiscovery) bool {
+	return ds1.Git != nil && ds2.Git != nil &&
+		ds1.Git.Name == ds2.Git.Name &&
+		ds1.Git.URL == ds2.Git.URL &&
+		ds1.Git.Ref == ds2.Git.Ref &&
+		ds1.Git.Path == ds2.Git.Path
+}
+
 // SortVersions sorts the supported version strings in semver 2.0 order.
 func SortVersions(vStrArr []string) error {
 	vArr := make([]*semver.Version, len(vStrArr))
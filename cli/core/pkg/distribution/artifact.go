@@ -22,6 +22,12 @@ type Artifact struct {
 	// SHA256 hash of the plugin binary.
 	Digest string
 
+	// Signature is the base64 encoded cosign signature of the plugin binary.
+	Signature string
+
+	// Certificate is the base64 encoded PEM signing certificate for keyless signatures.
+	Certificate string
+
 	// OS of the plugin binary in `GOOS` format.
 	OS string
 
@@ -119,11 +125,13 @@ func (aMap Artifacts) DescribeArtifact(version, os, arch string) (Artifact, erro
 // ArtifactFromK8sV1alpha1 returns Artifact from k8sV1alpha1
 func ArtifactFromK8sV1alpha1(a cliv1alpha1.Artifact) Artifact { //nolint:gocritic
 	return Artifact{
-		Image:  a.Image,
-		URI:    a.URI,
-		Digest: a.Digest,
-		OS:     a.OS,
-		Arch:   a.Arch,
+		Image:       a.Image,
+		URI:         a.URI,
+		Digest:      a.Digest,
+		Signature:   a.Signature,
+		Certificate: a.Certificate,
+		OS:          a.OS,
+		Arch:        a.Arch,
 	}
 }
 
@@ -57,11 +57,22 @@ type Discovered struct {
 	// oci, local or kubernetes
 	DiscoveryType string
 
+	// DiscoveryImage is the OCI image from where the plugin was discovered.
+	// Only set for plugins discovered from oci discovery.
+	DiscoveryImage string
+
 	// Target defines the target to which this plugin is applicable to
 	Target cliv1alpha1.Target
 
 	// Status is the installed/uninstalled status of the plugin.
 	Status string
+
+	// Requirements contains the compatibility and dependency requirements
+	// of the plugin for every supported version.
+	Requirements map[string]cliv1alpha1.PluginRequirements
+
+	// Permissions contains the permissions declared by the plugin for every supported version.
+	Permissions map[string]cliv1alpha1.PluginPermissions
 }
 
 // DiscoveredSorter sorts discovered objects.
@@ -0,0 +1,642 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/aunum/log"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8syaml "sigs.k8s.io/yaml"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/artifact"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/common"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/config"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/plugin"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/signature"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/utils"
+)
+
+const (
+	// BundleManifestFileName is the file name of the manifest of a plugin bundle
+	BundleManifestFileName = "bundle.yaml"
+	// bundleDiscoveryName is the name of the local discovery directory of a plugin bundle
+	bundleDiscoveryName = "bundle"
+	// bundleImagesDirName is the directory of the OCI image layout containing the images the
+	// plugin binaries of a plugin bundle were exported from, and their signatures
+	bundleImagesDirName = "images"
+	// bundleAPIVersion is the version of the plugin bundle format
+	bundleAPIVersion = "cli.tanzu.vmware.com/v1alpha1"
+	// bundleKind is the kind of the plugin bundle manifest
+	bundleKind = "PluginBundle"
+)
+
+// BundledPlugin is a plugin binary contained in a plugin bundle
+type BundledPlugin struct {
+	// Name is the name of the plugin.
+	Name string `yaml:"name"`
+	// Target is the target of the plugin.
+	Target cliv1alpha1.Target `yaml:"target,omitempty"`
+	// Version is the version of the plugin.
+	Version string `yaml:"version"`
+	// OS of the plugin binary in `GOOS` format.
+	OS string `yaml:"os"`
+	// Arch of the plugin binary in `GOARCH` format.
+	Arch string `yaml:"arch"`
+	// Path is the path of the plugin binary relative to the distribution directory of the bundle.
+	Path string `yaml:"path"`
+	// Digest is the SHA256 hash of the plugin binary.
+	Digest string `yaml:"digest"`
+	// ImageDigest is the digest of the image the plugin binary was exported from, if any.
+	// The image and its cosign signature are part of the images directory of the bundle,
+	// so that the plugin binary can be verified with the image signature on import.
+	ImageDigest string `yaml:"imageDigest,omitempty"`
+}
+
+// PluginBundleManifest describes the content of a plugin bundle
+type PluginBundleManifest struct {
+	APIVersion string          `yaml:"apiVersion"`
+	Kind       string          `yaml:"kind"`
+	Plugins    []BundledPlugin `yaml:"plugins"`
+}
+
+// ExportPluginBundleOptions are the options to export a plugin bundle
+type ExportPluginBundleOptions struct {
+	// Plugins are the names of the plugins to export. All available plugins are exported if empty.
+	Plugins []string
+	// AllVersions exports all supported versions instead of only the recommended version.
+	AllVersions bool
+	// Platforms are the platforms to export in `os/arch` format.
+	// Defaults to the platform of the running CLI.
+	Platforms []string
+	// OutputFile is the path of the plugin bundle tarball.
+	OutputFile string
+}
+
+// ImportPluginBundleOptions are the options to import a plugin bundle
+type ImportPluginBundleOptions struct {
+	// File is the path of the plugin bundle tarball.
+	File string
+	// StageDir is the directory where the plugin bundle is extracted without installing
+	// the plugins. The plugins are installed if empty.
+	StageDir string
+	// AllowUnsigned installs plugins without a signature although a signature trust root is configured.
+	AllowUnsigned bool
+}
+
+// ExportPluginBundle resolves the plugins from the configured discovery sources and writes
+// them together with a manifest and their digests into a single tarball. The content of the
+// tarball is a local discovery and distribution source which can be installed on a
+// disconnected machine with `ImportPluginBundle` or `InstallPluginsFromLocalSource`.
+func ExportPluginBundle(o *ExportPluginBundleOptions) error {
+	if o.OutputFile == "" {
+		return errors.New("output file for the plugin bundle cannot be empty")
+	}
+	platforms, err := parsePlatforms(o.Platforms)
+	if err != nil {
+		return err
+	}
+
+	availablePlugins, err := AvailablePlugins()
+	if err != nil {
+		return err
+	}
+	plugins, err := filterPluginsForBundle(availablePlugins, o.Plugins)
+	if err != nil {
+		return err
+	}
+
+	bundleDir, err := os.MkdirTemp("", "plugin_bundle")
+	if err != nil {
+		return errors.Wrap(err, "error creating temporary directory")
+	}
+	defer os.RemoveAll(bundleDir)
+
+	e := &bundleExporter{dir: bundleDir, platforms: platforms}
+	manifest := PluginBundleManifest{APIVersion: bundleAPIVersion, Kind: bundleKind, Plugins: []BundledPlugin{}}
+	for i := range plugins {
+		bundled, err := e.exportPlugin(&plugins[i], o.AllVersions)
+		if err != nil {
+			return err
+		}
+		manifest.Plugins = append(manifest.Plugins, bundled...)
+	}
+	if len(manifest.Plugins) == 0 {
+		return errors.New("no plugin binaries available for the requested platforms")
+	}
+
+	b, err := yaml.Marshal(&manifest)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal plugin bundle manifest")
+	}
+	if err := os.WriteFile(filepath.Join(bundleDir, BundleManifestFileName), b, 0644); err != nil {
+		return err
+	}
+
+	if err := writeTarGz(bundleDir, o.OutputFile); err != nil {
+		return errors.Wrap(err, "unable to write plugin bundle")
+	}
+	log.Infof("Exported %d plugin binaries to %q", len(manifest.Plugins), o.OutputFile)
+	return nil
+}
+
+// ImportPluginBundle extracts a plugin bundle created by `ExportPluginBundle` and verifies
+// the digests of all plugin binaries. The plugins are installed unless a staging directory
+// is provided, in which case the bundle is only extracted to the staging directory.
+func ImportPluginBundle(o *ImportPluginBundleOptions) error {
+	dir := o.StageDir
+	if dir == "" {
+		tmpDir, err := os.MkdirTemp("", "plugin_bundle")
+		if err != nil {
+			return errors.Wrap(err, "error creating temporary directory")
+		}
+		defer os.RemoveAll(tmpDir)
+		dir = tmpDir
+	}
+
+	if err := extractTarGz(o.File, dir); err != nil {
+		return errors.Wrapf(err, "unable to extract plugin bundle %q", o.File)
+	}
+	manifest, err := verifyPluginBundle(dir)
+	if err != nil {
+		return err
+	}
+	trustedDigests, err := verifyBundledImages(dir, manifest, o.AllowUnsigned)
+	if err != nil {
+		return err
+	}
+
+	if o.StageDir != "" {
+		log.Infof("Staged %d plugin binaries to %q. Install them with `tanzu plugin install all --local %s`", len(manifest.Plugins), o.StageDir, o.StageDir)
+		return nil
+	}
+	// Installing from a local source changes the default local distribution directory
+	// which must not point to the removed temporary directory afterwards
+	localPluginDistroDir := common.DefaultLocalPluginDistroDir
+	defer func() { common.DefaultLocalPluginDistroDir = localPluginDistroDir }()
+	return installPluginsFromLocalSource(cli.AllPlugins, cli.VersionLatest, cliv1alpha1.TargetNone, dir, false,
+		InstallOptions{AllowUnsigned: o.AllowUnsigned, trustedDigests: trustedDigests})
+}
+
+// bundleExporter downloads plugin binaries into the plugin bundle directory
+type bundleExporter struct {
+	dir       string
+	platforms [][2]string
+}
+
+func (e *bundleExporter) exportPlugin(p *plugin.Discovered, allVersions bool) ([]BundledPlugin, error) {
+	artifacts, ok := p.Distribution.(distribution.Artifacts)
+	if !ok {
+		return nil, errors.Errorf("unsupported distribution for plugin %q", p.Name)
+	}
+
+	versions := []string{p.RecommendedVersion}
+	if allVersions {
+		versions = p.SupportedVersions
+	}
+
+	cliPlugin := cliv1alpha1.CLIPlugin{
+		TypeMeta:   metav1.TypeMeta{APIVersion: cliv1alpha1.GroupVersion.String(), Kind: "CLIPlugin"},
+		ObjectMeta: metav1.ObjectMeta{Name: p.Name},
+		Spec: cliv1alpha1.CLIPluginSpec{
+			Description:        p.Description,
+			RecommendedVersion: p.RecommendedVersion,
+			Optional:           p.Optional,
+			Target:             p.Target,
+			Artifacts:          map[string]cliv1alpha1.ArtifactList{},
+		},
+	}
+
+	var bundled []BundledPlugin
+	for _, version := range versions {
+		for _, platform := range e.platforms {
+			a, err := artifacts.GetArtifact(version, platform[0], platform[1])
+			if err != nil {
+				log.Warningf("skipping plugin '%v:%v' as it is not available for %v/%v", p.Name, version, platform[0], platform[1])
+				continue
+			}
+			bp, err := e.exportArtifact(p, version, &a)
+			if err != nil {
+				return nil, err
+			}
+			bundled = append(bundled, *bp)
+			cliPlugin.Spec.Artifacts[version] = append(cliPlugin.Spec.Artifacts[version], cliv1alpha1.Artifact{
+				URI:         bp.Path,
+				Type:        common.DistributionTypeLocal,
+				Digest:      bp.Digest,
+				Signature:   a.Signature,
+				Certificate: a.Certificate,
+				OS:          a.OS,
+				Arch:        a.Arch,
+			})
+		}
+		if requirements, ok := p.Requirements[version]; ok {
+			if cliPlugin.Spec.Requirements == nil {
+				cliPlugin.Spec.Requirements = map[string]cliv1alpha1.PluginRequirements{}
+			}
+			cliPlugin.Spec.Requirements[version] = requirements
+		}
+		if permissions, ok := p.Permissions[version]; ok {
+			if cliPlugin.Spec.Permissions == nil {
+				cliPlugin.Spec.Permissions = map[string]cliv1alpha1.PluginPermissions{}
+			}
+			cliPlugin.Spec.Permissions[version] = permissions
+		}
+	}
+	if len(bundled) == 0 {
+		return nil, nil
+	}
+
+	// Recommend the highest exported version if the recommended version is not part of the bundle
+	if _, ok := cliPlugin.Spec.Artifacts[p.RecommendedVersion]; !ok {
+		for i := len(versions) - 1; i >= 0; i-- {
+			if _, ok := cliPlugin.Spec.Artifacts[versions[i]]; ok {
+				cliPlugin.Spec.RecommendedVersion = versions[i]
+				break
+			}
+		}
+	}
+
+	b, err := k8syaml.Marshal(&cliPlugin)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to marshal discovery resource for plugin %q", p.Name)
+	}
+	discoveryFile := filepath.Join(e.dir, "discovery", bundleDiscoveryName, bundlePluginDirName(p.Name, p.Target)+".yaml")
+	if err := os.MkdirAll(filepath.Dir(discoveryFile), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(discoveryFile, b, 0644); err != nil {
+		return nil, err
+	}
+	return bundled, nil
+}
+
+func (e *bundleExporter) exportArtifact(p *plugin.Discovered, version string, a *distribution.Artifact) (*BundledPlugin, error) {
+	log.Infof("Exporting plugin '%v:%v' for %v/%v", p.Name, version, a.OS, a.Arch)
+
+	b, imageDigest, err := e.fetchArtifact(p, version, a)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to fetch plugin '%v:%v' for %v/%v", p.Name, version, a.OS, a.Arch)
+	}
+	if err := verifyPluginPostDownload(p, a.Digest, b); err != nil {
+		return nil, err
+	}
+
+	binaryName := "tanzu-" + p.Name
+	if a.OS == "windows" {
+		binaryName += exe
+	}
+	// Paths are always slash separated so that bundles can be shared across platforms
+	relPath := path.Join(bundlePluginDirName(p.Name, p.Target), version, fmt.Sprintf("%s_%s", a.OS, a.Arch), binaryName)
+	binaryPath := filepath.Join(e.dir, "distribution", filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(binaryPath), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(binaryPath, b, 0755); err != nil {
+		return nil, err
+	}
+
+	return &BundledPlugin{
+		Name:        p.Name,
+		Target:      p.Target,
+		Version:     version,
+		OS:          a.OS,
+		Arch:        a.Arch,
+		Path:        relPath,
+		Digest:      fmt.Sprintf("%x", sha256.Sum256(b)),
+		ImageDigest: imageDigest,
+	}, nil
+}
+
+// fetchArtifact downloads the plugin binary. Images are exported with their cosign
+// signature into the images directory of the bundle and the plugin binary is read
+// from the exported image, whose digest is returned.
+func (e *bundleExporter) fetchArtifact(p *plugin.Discovered, version string, a *distribution.Artifact) ([]byte, string, error) {
+	if a.Image != "" {
+		imagesDir := filepath.Join(e.dir, bundleImagesDirName)
+		digest, signed, err := signature.ExportImage(a.Image, imagesDir)
+		if err != nil {
+			return nil, "", err
+		}
+		if !signed {
+			log.Warningf("exporting plugin '%v:%v' for %v/%v without signature as image %q is not signed", p.Name, version, a.OS, a.Arch, a.Image)
+		}
+		img, err := signature.LayoutImage(imagesDir, digest)
+		if err != nil {
+			return nil, "", err
+		}
+		b, err := readImageBinary(img)
+		if err != nil {
+			return nil, "", err
+		}
+		return b, digest, nil
+	}
+	if a.URI != "" {
+		art, err := artifact.NewURIArtifact(a.URI)
+		if err != nil {
+			return nil, "", err
+		}
+		b, err := art.Fetch()
+		return b, "", err
+	}
+	return nil, "", errors.Errorf("invalid artifact for os:%s, arch:%s", a.OS, a.Arch)
+}
+
+// readImageBinary returns the plugin binary contained in the image after checking the
+// layers of the image against their digests. Like for the OCI artifacts, the image must
+// contain a single file outside of test directories.
+func readImageBinary(img v1.Image) ([]byte, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	var binary []byte
+	fileCount := 0
+	for _, layer := range layers {
+		digest, err := layer.Digest()
+		if err != nil {
+			return nil, err
+		}
+		rc, err := layer.Compressed()
+		if err != nil {
+			return nil, err
+		}
+		compressed, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		if actual, _, err := v1.SHA256(bytes.NewReader(compressed)); err != nil || actual != digest {
+			return nil, errors.Errorf("layer %s of the plugin image has been altered", digest)
+		}
+
+		gr, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, err
+		}
+		tr := tar.NewReader(gr)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if hdr.Typeflag != tar.TypeReg || utils.ContainsString(strings.Split(hdr.Name, "/"), "test") {
+				continue
+			}
+			if binary, err = io.ReadAll(tr); err != nil { //nolint:gosec
+				return nil, err
+			}
+			fileCount++
+		}
+	}
+	if fileCount != 1 {
+		return nil, errors.Errorf("oci artifact image for plugin is required to have only 1 file, but found %v", fileCount)
+	}
+	return binary, nil
+}
+
+// verifyPluginBundle verifies that all plugin binaries listed in the manifest of
+// the extracted plugin bundle exist and match their digests
+func verifyPluginBundle(dir string) (*PluginBundleManifest, error) {
+	b, err := os.ReadFile(filepath.Join(dir, BundleManifestFileName))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read plugin bundle manifest")
+	}
+	var manifest PluginBundleManifest
+	if err := yaml.Unmarshal(b, &manifest); err != nil {
+		return nil, errors.Wrap(err, "unable to parse plugin bundle manifest")
+	}
+	if manifest.APIVersion != bundleAPIVersion || manifest.Kind != bundleKind {
+		return nil, errors.Errorf("unsupported plugin bundle: expected apiVersion %q and kind %q", bundleAPIVersion, bundleKind)
+	}
+
+	for i := range manifest.Plugins {
+		bp := &manifest.Plugins[i]
+		binary, err := os.ReadFile(filepath.Join(dir, "distribution", filepath.FromSlash(bp.Path)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read plugin '%v:%v' for %v/%v from bundle", bp.Name, bp.Version, bp.OS, bp.Arch)
+		}
+		if digest := fmt.Sprintf("%x", sha256.Sum256(binary)); digest != bp.Digest {
+			return nil, errors.Errorf("plugin '%v:%v' for %v/%v has been corrupted in the bundle. bundle digest: %s, actual digest: %s", bp.Name, bp.Version, bp.OS, bp.Arch, bp.Digest, digest)
+		}
+	}
+	return &manifest, nil
+}
+
+// verifyBundledImages verifies the signatures of the images the plugin binaries of the
+// extracted plugin bundle were exported from against the configured trust root, and
+// that the plugin binaries match their images. It returns the digests of the verified
+// plugin binaries. Images without a trusted signature fail the verification unless
+// unsigned plugins are allowed.
+func verifyBundledImages(dir string, manifest *PluginBundleManifest, allowUnsigned bool) (map[string]bool, error) {
+	verifier, err := config.GetPluginSignatureVerifier()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to configure plugin signature verifier")
+	}
+	if verifier == nil {
+		return nil, nil
+	}
+
+	trustedDigests := map[string]bool{}
+	for i := range manifest.Plugins {
+		bp := &manifest.Plugins[i]
+		if bp.ImageDigest == "" {
+			continue
+		}
+		img, err := signature.VerifyLayoutImage(filepath.Join(dir, bundleImagesDirName), bp.ImageDigest, verifier)
+		if err != nil {
+			if allowUnsigned || config.AllowUnsignedPlugins() {
+				log.Warningf("unable to verify the image of plugin '%v:%v' for %v/%v: %v", bp.Name, bp.Version, bp.OS, bp.Arch, err)
+				continue
+			}
+			return nil, errors.Wrapf(err, "unable to verify the image of plugin '%v:%v' for %v/%v", bp.Name, bp.Version, bp.OS, bp.Arch)
+		}
+		binary, err := readImageBinary(img)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read the image of plugin '%v:%v' for %v/%v", bp.Name, bp.Version, bp.OS, bp.Arch)
+		}
+		if digest := fmt.Sprintf("%x", sha256.Sum256(binary)); digest != bp.Digest {
+			return nil, errors.Errorf("plugin '%v:%v' for %v/%v does not match the image it was exported from. bundle digest: %s, image digest: %s", bp.Name, bp.Version, bp.OS, bp.Arch, bp.Digest, digest)
+		}
+		trustedDigests[bp.Digest] = true
+	}
+	return trustedDigests, nil
+}
+
+func filterPluginsForBundle(availablePlugins []plugin.Discovered, names []string) ([]plugin.Discovered, error) {
+	var plugins []plugin.Discovered
+	for i := range availablePlugins {
+		// Plugins which are installed but no longer discovered cannot be exported
+		if availablePlugins[i].Distribution == nil {
+			continue
+		}
+		if len(names) == 0 || utils.ContainsString(names, availablePlugins[i].Name) {
+			plugins = append(plugins, availablePlugins[i])
+		}
+	}
+	for _, name := range names {
+		if !containsPlugin(plugins, name) {
+			return nil, errors.Errorf("unable to find plugin '%v'", name)
+		}
+	}
+	return plugins, nil
+}
+
+func containsPlugin(plugins []plugin.Discovered, name string) bool {
+	for i := range plugins {
+		if plugins[i].Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePlatforms parses platforms in `os/arch` format
+func parsePlatforms(platforms []string) ([][2]string, error) {
+	if len(platforms) == 0 {
+		return [][2]string{{runtime.GOOS, runtime.GOARCH}}, nil
+	}
+	parsed := make([][2]string, 0, len(platforms))
+	for _, p := range platforms {
+		parts := strings.Split(p, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("invalid platform %q, expected format os/arch", p)
+		}
+		parsed = append(parsed, [2]string{parts[0], parts[1]})
+	}
+	return parsed, nil
+}
+
+func bundlePluginDirName(name string, target cliv1alpha1.Target) string {
+	if target == cliv1alpha1.TargetNone {
+		return name
+	}
+	return fmt.Sprintf("%s_%s", name, target)
+}
+
+// writeTarGz writes the content of the directory into a gzip compressed tarball
+func writeTarGz(dir, file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	err = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || p == dir {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		src, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// extractTarGz extracts a gzip compressed tarball into the directory
+func extractTarGz(file, dir string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Reject entries which would be extracted outside of the directory
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if rel, err := filepath.Rel(dir, target); err != nil || strings.HasPrefix(rel, "..") {
+			return errors.Errorf("invalid file path %q in bundle", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := writeFileFromReader(target, tr, os.FileMode(hdr.Mode).Perm()); err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("unsupported file type of %q in bundle", hdr.Name)
+		}
+	}
+}
+
+func writeFileFromReader(file string, r io.Reader, mode os.FileMode) error {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, r); err != nil { //nolint:gosec
+		return err
+	}
+	return f.Close()
+}
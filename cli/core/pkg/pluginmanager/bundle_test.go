@@ -0,0 +1,164 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/common"
+)
+
+func Test_ExportImportPluginBundle(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupLocalDistoForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	bundleFile := filepath.Join(t.TempDir(), "plugins.tar.gz")
+
+	// Export all versions of a plugin for multiple platforms
+	err := ExportPluginBundle(&ExportPluginBundleOptions{
+		Plugins:     []string{"login"},
+		AllVersions: true,
+		Platforms:   []string{"linux/amd64", "darwin/amd64"},
+		OutputFile:  bundleFile,
+	})
+	assertions.Nil(err)
+
+	stageDir := t.TempDir()
+	assertions.Nil(ImportPluginBundle(&ImportPluginBundleOptions{File: bundleFile, StageDir: stageDir}))
+	manifest := readBundleManifestForTest(t, stageDir)
+	assertions.Equal(2, len(manifest.Plugins))
+	for _, bp := range manifest.Plugins {
+		assertions.Equal("login", bp.Name)
+		assertions.Equal("v0.2.0", bp.Version)
+		assertions.FileExists(filepath.Join(stageDir, "distribution", filepath.FromSlash(bp.Path)))
+	}
+	// Staging does not install the plugins
+	installedServerPlugins, installedStandalonePlugins, err := InstalledPlugins()
+	assertions.Nil(err)
+	assertions.Equal(0, len(installedServerPlugins)+len(installedStandalonePlugins))
+
+	// The staged bundle is a local discovery and distribution source
+	localPluginDistroDir := common.DefaultLocalPluginDistroDir
+	plugins, err := DiscoverPluginsFromLocalSource(stageDir)
+	common.DefaultLocalPluginDistroDir = localPluginDistroDir
+	assertions.Nil(err)
+	assertions.Equal(1, len(plugins))
+	assertions.Equal("login", plugins[0].Name)
+
+	// Export all available plugins for the current platform and install them
+	err = ExportPluginBundle(&ExportPluginBundleOptions{OutputFile: bundleFile})
+	assertions.Nil(err)
+	assertions.Nil(ImportPluginBundle(&ImportPluginBundleOptions{File: bundleFile}))
+	installedServerPlugins, installedStandalonePlugins, err = InstalledPlugins()
+	assertions.Nil(err)
+	expectedDiscoveredPlugins := append(expectedDiscoveredContextPlugins, expectedDiscoveredStandalonePlugins...)
+	assertions.Equal(len(expectedDiscoveredPlugins), len(installedServerPlugins)+len(installedStandalonePlugins))
+
+	// Unknown plugin
+	err = ExportPluginBundle(&ExportPluginBundleOptions{Plugins: []string{"not-exists"}, OutputFile: bundleFile})
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to find plugin 'not-exists'")
+
+	// Invalid platform
+	err = ExportPluginBundle(&ExportPluginBundleOptions{Platforms: []string{"linux"}, OutputFile: bundleFile})
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), `invalid platform "linux"`)
+}
+
+func Test_ImportPluginBundle_Corrupted(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupLocalDistoForTesting()()
+
+	bundleFile := filepath.Join(t.TempDir(), "plugins.tar.gz")
+	err := ExportPluginBundle(&ExportPluginBundleOptions{Plugins: []string{"login"}, Platforms: []string{"linux/amd64"}, OutputFile: bundleFile})
+	assertions.Nil(err)
+
+	// Tamper with the plugin binary in the bundle
+	stageDir := t.TempDir()
+	assertions.Nil(ImportPluginBundle(&ImportPluginBundleOptions{File: bundleFile, StageDir: stageDir}))
+	manifest := readBundleManifestForTest(t, stageDir)
+	assertions.Nil(os.WriteFile(filepath.Join(stageDir, "distribution", filepath.FromSlash(manifest.Plugins[0].Path)), []byte("tampered"), 0755))
+	assertions.Nil(writeTarGz(stageDir, bundleFile))
+
+	err = ImportPluginBundle(&ImportPluginBundleOptions{File: bundleFile, StageDir: t.TempDir()})
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "has been corrupted in the bundle")
+
+	// Entries outside of the extraction directory are rejected
+	f, err := os.Create(bundleFile)
+	assertions.Nil(err)
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	assertions.Nil(tw.WriteHeader(&tar.Header{Name: "../escape", Mode: 0644, Size: 1, Typeflag: tar.TypeReg}))
+	_, err = tw.Write([]byte("x"))
+	assertions.Nil(err)
+	assertions.Nil(tw.Close())
+	assertions.Nil(gw.Close())
+	assertions.Nil(f.Close())
+
+	stageDir = t.TempDir()
+	err = ImportPluginBundle(&ImportPluginBundleOptions{File: bundleFile, StageDir: stageDir})
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), `invalid file path "../escape" in bundle`)
+	_, err = os.Stat(filepath.Join(filepath.Dir(stageDir), "escape"))
+	assertions.True(os.IsNotExist(err))
+}
+
+func Test_readImageBinary(t *testing.T) {
+	assertions := assert.New(t)
+
+	newImage := func(files map[string]string) *bytes.Buffer {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for name, content := range files {
+			assertions.Nil(tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+			_, err := tw.Write([]byte(content))
+			assertions.Nil(err)
+		}
+		assertions.Nil(tw.Close())
+		return &buf
+	}
+
+	// The test files of the image are ignored
+	layer, err := tarball.LayerFromReader(newImage(map[string]string{"tanzu-login": "binary", "test/tanzu-login-test": "test binary"}))
+	assertions.Nil(err)
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	assertions.Nil(err)
+	b, err := readImageBinary(img)
+	assertions.Nil(err)
+	assertions.Equal("binary", string(b))
+
+	// Images must contain a single plugin binary
+	layer, err = tarball.LayerFromReader(newImage(map[string]string{"tanzu-login": "binary", "tanzu-cluster": "binary"}))
+	assertions.Nil(err)
+	img, err = mutate.AppendLayers(empty.Image, layer)
+	assertions.Nil(err)
+	_, err = readImageBinary(img)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "required to have only 1 file, but found 2")
+}
+
+func readBundleManifestForTest(t *testing.T, dir string) *PluginBundleManifest {
+	b, err := os.ReadFile(filepath.Join(dir, BundleManifestFileName))
+	assert.Nil(t, err)
+	var manifest PluginBundleManifest
+	assert.Nil(t, yaml.Unmarshal(b, &manifest))
+	return &manifest
+}
@@ -0,0 +1,60 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/catalog"
+	configlib "github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+)
+
+// ActivateContextPlugins installs the plugins recommended by the discovery sources of the
+// given context which are not yet installed for it.
+//
+// The plugins of every context are recorded in their own catalog and their binaries are
+// installed side by side, so several versions of a plugin can be installed for different
+// contexts. The plugins of the current contexts are the ones invoked by the CLI, therefore
+// switching to a context whose plugins are already installed activates them without
+// downloading anything.
+func ActivateContextPlugins(contextName string) error {
+	context, err := configlib.GetContext(contextName)
+	if err != nil {
+		return err
+	}
+	discoveredPlugins, err := discoverServerPluginsForContext(context)
+	if err != nil {
+		return errors.Wrapf(err, "unable to discover the plugins of context %q", contextName)
+	}
+	c, err := catalog.NewContextCatalog(contextName)
+	if err != nil {
+		return err
+	}
+
+	// Record the installed versions so that dependencies which are already installed
+	// for the context are not installed again
+	var missingPlugins []pluginInstallation
+	for i := range discoveredPlugins {
+		pd, exists := c.Get(catalog.PluginNameTarget(discoveredPlugins[i].Name, discoveredPlugins[i].Target))
+		if exists {
+			discoveredPlugins[i].InstalledVersion = pd.Version
+		}
+		if !exists || pd.DiscoveredRecommendedVersion != discoveredPlugins[i].RecommendedVersion {
+			missingPlugins = append(missingPlugins, pluginInstallation{plugin: &discoveredPlugins[i], version: discoveredPlugins[i].RecommendedVersion})
+		}
+	}
+
+	installations, err := resolvePluginsRequirements(missingPlugins, discoveredPlugins)
+	if err != nil {
+		return errors.Wrapf(err, "unable to resolve the requirements of the plugins of context %q", contextName)
+	}
+	var errList []error
+	for i := range installations {
+		if err := installOrUpgradePlugin(installations[i].plugin, installations[i].version, false, InstallOptions{}); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	return kerrors.NewAggregate(errList)
+}
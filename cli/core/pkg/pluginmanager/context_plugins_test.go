@@ -0,0 +1,61 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/common"
+	configlib "github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+)
+
+func Test_ActivateContextPlugins(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupLocalDistoForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	err := ActivateContextPlugins("not-exists")
+	assertions.NotNil(err)
+
+	// Install the plugins of the current k8s context
+	assertions.Nil(ActivateContextPlugins("mgmt"))
+	installedServerPlugins, err := InstalledServerPlugins()
+	assertions.Nil(err)
+	pd := findPluginDescriptors(installedServerPlugins, "cluster", cliv1alpha1.TargetK8s)
+	assertions.NotNil(pd)
+	assertions.Equal("v1.6.0", pd.Version)
+	assertions.Equal(common.PluginScopeContext, pd.Scope)
+	newerInstallationPath := pd.InstallationPath
+
+	// Switch to the context using an older version of the cluster plugin
+	assertions.Nil(configlib.SetCurrentContext("mgmt-old"))
+	installedServerPlugins, err = InstalledServerPlugins()
+	assertions.Nil(err)
+	assertions.Nil(findPluginDescriptors(installedServerPlugins, "cluster", cliv1alpha1.TargetK8s))
+
+	assertions.Nil(ActivateContextPlugins("mgmt-old"))
+	installedServerPlugins, err = InstalledServerPlugins()
+	assertions.Nil(err)
+	pd = findPluginDescriptors(installedServerPlugins, "cluster", cliv1alpha1.TargetK8s)
+	assertions.NotNil(pd)
+	assertions.Equal("v0.2.0", pd.Version)
+	assertions.NotEqual(newerInstallationPath, pd.InstallationPath)
+
+	// Switching back activates the installed version without installing it again
+	assertions.Nil(configlib.SetCurrentContext("mgmt"))
+	execCommand = nil
+	assertions.Nil(ActivateContextPlugins("mgmt"))
+	installedServerPlugins, err = InstalledServerPlugins()
+	assertions.Nil(err)
+	pd = findPluginDescriptors(installedServerPlugins, "cluster", cliv1alpha1.TargetK8s)
+	assertions.NotNil(pd)
+	assertions.Equal("v1.6.0", pd.Version)
+	assertions.Equal(newerInstallationPath, pd.InstallationPath)
+}
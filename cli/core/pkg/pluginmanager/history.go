@@ -0,0 +1,42 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"github.com/pkg/errors"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/catalog"
+	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
+)
+
+// PluginHistoryOptions specifies the plugin whose installation history is used
+type PluginHistoryOptions struct {
+	Target     cliv1alpha1.Target
+	PluginName string
+}
+
+// RollbackPlugin activates the previously installed version of the plugin
+// and returns the descriptor of the activated version
+func RollbackPlugin(options PluginHistoryOptions) (cliapi.PluginDescriptor, error) {
+	c, pd, err := findInstalledPlugin(options.PluginName, options.Target)
+	if err != nil {
+		return cliapi.PluginDescriptor{}, err
+	}
+
+	previous, err := c.Rollback(catalog.PluginNameTarget(pd.Name, pd.Target))
+	if err != nil {
+		return cliapi.PluginDescriptor{}, errors.Wrapf(err, "unable to rollback plugin '%v'", options.PluginName)
+	}
+	return previous, nil
+}
+
+// PluginHistory returns the installations of the plugin kept in the catalog, most recent first
+func PluginHistory(options PluginHistoryOptions) ([]catalog.PluginHistoryEntry, error) {
+	c, pd, err := findInstalledPlugin(options.PluginName, options.Target)
+	if err != nil {
+		return nil, err
+	}
+	return c.History(catalog.PluginNameTarget(pd.Name, pd.Target)), nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/catalog"
+)
+
+func Test_RollbackPlugin_PluginHistory(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupLocalDistoForTesting()()
+
+	options := PluginHistoryOptions{PluginName: "login", Target: cliv1alpha1.TargetNone}
+
+	// Try to rollback plugin when plugin is not installed
+	_, err := RollbackPlugin(options)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to find plugin 'login'")
+
+	mockInstallPlugin(assertions, "login", "v0.2.0", cliv1alpha1.TargetNone)
+
+	// Try to rollback plugin without any previous installation
+	_, err = RollbackPlugin(options)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to rollback plugin 'login'")
+
+	// Simulate the upgrade of the plugin to a newer version
+	c, err := catalog.NewContextCatalog("")
+	assertions.Nil(err)
+	pd, ok := c.Get("login")
+	assertions.True(ok)
+	pd.Version = "v0.3.0"
+	pd.InstallationPath = filepath.Join(t.TempDir(), "tanzu-login")
+	assertions.Nil(os.WriteFile(pd.InstallationPath, []byte("login"), 0755))
+	assertions.Nil(c.Upsert(&pd))
+
+	history, err := PluginHistory(options)
+	assertions.Nil(err)
+	assertions.Equal(2, len(history))
+	assertions.Equal("v0.3.0", history[0].Descriptor.Version)
+	assertions.True(history[0].Active)
+	assertions.Equal("v0.2.0", history[1].Descriptor.Version)
+
+	previous, err := RollbackPlugin(options)
+	assertions.Nil(err)
+	assertions.Equal("v0.2.0", previous.Version)
+
+	_, installedStandalonePlugins, err := InstalledPlugins()
+	assertions.Nil(err)
+	assertions.Equal(1, len(installedStandalonePlugins))
+	assertions.Equal("v0.2.0", installedStandalonePlugins[0].Version)
+}
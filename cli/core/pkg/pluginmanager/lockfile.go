@@ -0,0 +1,252 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+
+	"github.com/aunum/log"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/plugin"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/utils"
+	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
+)
+
+const (
+	// DefaultLockfileName is the file name of the plugin lockfile if none is specified
+	DefaultLockfileName = "tanzu-plugins.lock.yaml"
+	// lockfileAPIVersion is the version of the plugin lockfile format
+	lockfileAPIVersion = "cli.tanzu.vmware.com/v1alpha1"
+	// lockfileKind is the kind of the plugin lockfile
+	lockfileKind = "PluginLock"
+)
+
+// LockedPlugin is a plugin version recorded in a plugin lockfile
+type LockedPlugin struct {
+	// Name is the name of the plugin.
+	Name string `yaml:"name"`
+	// Target is the target of the plugin.
+	Target cliv1alpha1.Target `yaml:"target,omitempty"`
+	// Version is the installed version of the plugin.
+	Version string `yaml:"version"`
+	// Source is the name of the discovery source from where the plugin was installed.
+	Source string `yaml:"source,omitempty"`
+	// Digests are the SHA256 hashes of the plugin binaries by platform in
+	// "os/arch" format, e.g. "linux/amd64".
+	Digests map[string]string `yaml:"digests,omitempty"`
+}
+
+// PluginLock is a set of plugin versions which can be installed reproducibly
+type PluginLock struct {
+	APIVersion string         `yaml:"apiVersion"`
+	Kind       string         `yaml:"kind"`
+	Plugins    []LockedPlugin `yaml:"plugins"`
+}
+
+// WritePluginLockfile records the name, target, version, source and binary digests
+// of every installed plugin in the given lockfile. Besides the digest of the installed
+// binary, the digests published by the discovery sources for the other platforms are
+// recorded so that the lockfile can be used on any platform.
+func WritePluginLockfile(path string) error {
+	serverPlugins, standalonePlugins, err := InstalledPlugins()
+	if err != nil {
+		return err
+	}
+	availablePlugins, err := AvailablePlugins()
+	if err != nil {
+		log.Warningf("only the digests of the installed plugin binaries are recorded: %v", err)
+	}
+
+	lock := PluginLock{APIVersion: lockfileAPIVersion, Kind: lockfileKind, Plugins: []LockedPlugin{}}
+	installedPlugins := append(serverPlugins, standalonePlugins...)
+	for i := range installedPlugins {
+		pd := &installedPlugins[i]
+		digest, err := installedPluginDigest(pd)
+		if err != nil {
+			return errors.Wrapf(err, "unable to compute digest of plugin %q", pd.Name)
+		}
+		lp := LockedPlugin{
+			Name:    pd.Name,
+			Target:  pd.Target,
+			Version: pd.Version,
+			Source:  pd.Discovery,
+		}
+		lp.Digests = publishedDigests(findLockedPlugin(&lp, availablePlugins), pd.Version)
+		lp.Digests[platformKey(runtime.GOOS, runtime.GOARCH)] = digest
+		lock.Plugins = append(lock.Plugins, lp)
+	}
+	sort.Slice(lock.Plugins, func(i, j int) bool {
+		if lock.Plugins[i].Name != lock.Plugins[j].Name {
+			return lock.Plugins[i].Name < lock.Plugins[j].Name
+		}
+		return lock.Plugins[i].Target < lock.Plugins[j].Target
+	})
+
+	b, err := yaml.Marshal(&lock)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal plugin lockfile")
+	}
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		return errors.Wrap(err, "unable to write plugin lockfile")
+	}
+	log.Infof("Wrote %d plugins to lockfile %q", len(lock.Plugins), path)
+	return nil
+}
+
+// ReadPluginLockfile reads and validates a plugin lockfile
+func ReadPluginLockfile(path string) (*PluginLock, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read plugin lockfile")
+	}
+	var lock PluginLock
+	if err := yaml.UnmarshalStrict(b, &lock); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse plugin lockfile %q", path)
+	}
+	if lock.APIVersion != lockfileAPIVersion || lock.Kind != lockfileKind {
+		return nil, errors.Errorf("unsupported plugin lockfile %q: expected apiVersion %q and kind %q", path, lockfileAPIVersion, lockfileKind)
+	}
+	for i := range lock.Plugins {
+		lp := &lock.Plugins[i]
+		if lp.Name == "" || lp.Version == "" || len(lp.Digests) == 0 {
+			return nil, errors.Errorf("invalid plugin lockfile %q: name, version and digest are required for every plugin", path)
+		}
+	}
+	return &lock, nil
+}
+
+// SyncPluginsFromLockfile installs exactly the plugin versions recorded in the lockfile.
+// The downloaded plugin binaries must match the digests recorded in the lockfile.
+func SyncPluginsFromLockfile(path string, options InstallOptions) error {
+	lock, err := ReadPluginLockfile(path)
+	if err != nil {
+		return err
+	}
+	plugins, err := AvailablePlugins()
+	if err != nil {
+		return err
+	}
+	serverPlugins, standalonePlugins, err := InstalledPlugins()
+	if err != nil {
+		return err
+	}
+	installedPlugins := append(serverPlugins, standalonePlugins...)
+
+	installed := false
+	errList := make([]error, 0)
+	for i := range lock.Plugins {
+		lp := &lock.Plugins[i]
+		if isLockedPluginInstalled(lp, installedPlugins) {
+			continue
+		}
+		p := findLockedPlugin(lp, plugins)
+		if p == nil {
+			errList = append(errList, errors.Errorf("unable to find plugin '%v' from lockfile in the discovery sources", lockedPluginName(lp)))
+			continue
+		}
+		installed = true
+		if err := installLockedPlugin(p, lp, options); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	if err := kerrors.NewAggregate(errList); err != nil {
+		return err
+	}
+
+	if !installed {
+		log.Info("All plugins from the lockfile are already installed")
+	} else {
+		log.Info("Successfully installed all plugins from the lockfile")
+	}
+	return nil
+}
+
+func installLockedPlugin(p *plugin.Discovered, lp *LockedPlugin, options InstallOptions) error {
+	if !utils.ContainsString(p.SupportedVersions, lp.Version) {
+		return errors.Errorf("version %v of plugin '%v' from lockfile is not available from discovery %q", lp.Version, pluginKey(lp.Name, lp.Target), p.Source)
+	}
+	if err := checkCoreVersionRequirement(p.Name, lp.Version, p.Requirements[lp.Version].CoreVersion); err != nil {
+		return err
+	}
+	digest := lp.Digests[platformKey(runtime.GOOS, runtime.GOARCH)]
+	if digest == "" {
+		return errors.Errorf("no digest of plugin '%v' for platform %v recorded in lockfile", lockedPluginName(lp), platformKey(runtime.GOOS, runtime.GOARCH))
+	}
+	return installPluginWithDigest(p, lp.Version, digest, false, options)
+}
+
+// publishedDigests returns the digests of the binaries of the plugin version by
+// platform as published by its discovery source
+func publishedDigests(p *plugin.Discovered, version string) map[string]string {
+	digests := map[string]string{}
+	if p == nil {
+		return digests
+	}
+	artifacts, ok := p.Distribution.(distribution.Artifacts)
+	if !ok {
+		return digests
+	}
+	for _, a := range artifacts[version] {
+		if a.Digest != "" {
+			digests[platformKey(a.OS, a.Arch)] = a.Digest
+		}
+	}
+	return digests
+}
+
+// findLockedPlugin returns the available plugin matching the locked plugin, preferring
+// the plugin from the discovery source recorded in the lockfile
+func findLockedPlugin(lp *LockedPlugin, plugins []plugin.Discovered) *plugin.Discovered {
+	var match *plugin.Discovered
+	for i := range plugins {
+		if plugins[i].Name != lp.Name || plugins[i].Target != lp.Target {
+			continue
+		}
+		if plugins[i].Source == lp.Source {
+			return &plugins[i]
+		}
+		if match == nil {
+			match = &plugins[i]
+		}
+	}
+	return match
+}
+
+func isLockedPluginInstalled(lp *LockedPlugin, installedPlugins []cliapi.PluginDescriptor) bool {
+	for i := range installedPlugins {
+		if installedPlugins[i].Name != lp.Name || installedPlugins[i].Target != lp.Target || installedPlugins[i].Version != lp.Version {
+			continue
+		}
+		digest, err := installedPluginDigest(&installedPlugins[i])
+		return err == nil && digest == lp.Digests[platformKey(runtime.GOOS, runtime.GOARCH)]
+	}
+	return false
+}
+
+// installedPluginDigest returns the SHA256 hash of the installed plugin binary
+// in the same format as the digests published by the discovery sources
+func installedPluginDigest(pd *cliapi.PluginDescriptor) (string, error) {
+	b, err := os.ReadFile(pd.InstallationPath)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
+
+func lockedPluginName(lp *LockedPlugin) string {
+	return fmt.Sprintf("%s:%s", pluginKey(lp.Name, lp.Target), lp.Version)
+}
+
+func platformKey(os, arch string) string {
+	return fmt.Sprintf("%s/%s", os, arch)
+}
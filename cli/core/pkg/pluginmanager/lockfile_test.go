@@ -0,0 +1,137 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/plugin"
+)
+
+func Test_PluginLockfile(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupLocalDistoForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	assertions.Nil(SyncPlugins(InstallOptions{}))
+
+	lockfile := filepath.Join(t.TempDir(), DefaultLockfileName)
+	assertions.Nil(WritePluginLockfile(lockfile))
+
+	lock, err := ReadPluginLockfile(lockfile)
+	assertions.Nil(err)
+	expectedDiscoveredPlugins := append(expectedDiscoveredContextPlugins, expectedDiscoveredStandalonePlugins...)
+	assertions.Equal(len(expectedDiscoveredPlugins), len(lock.Plugins))
+	for _, edp := range expectedDiscoveredPlugins {
+		lp := findLockedPluginForTest(lock.Plugins, edp.Name, edp.Target)
+		assertions.NotNil(lp)
+		assertions.Equal(edp.RecommendedVersion, lp.Version)
+		assertions.NotEmpty(lp.Source)
+		assertions.Len(lp.Digests[platformKey(runtime.GOOS, runtime.GOARCH)], 64)
+	}
+
+	// Plugins matching the lockfile are not installed again
+	assertions.Nil(SyncPluginsFromLockfile(lockfile, InstallOptions{}))
+
+	// Install the plugins from the lockfile after cleaning all installed plugins
+	assertions.Nil(Clean())
+	assertions.Nil(SyncPluginsFromLockfile(lockfile, InstallOptions{}))
+	installedServerPlugins, installedStandalonePlugins, err := InstalledPlugins()
+	assertions.Nil(err)
+	assertions.Equal(len(lock.Plugins), len(installedServerPlugins)+len(installedStandalonePlugins))
+
+	// Plugin binary not matching the digest from the lockfile
+	assertions.Nil(Clean())
+	lp := findLockedPluginForTest(lock.Plugins, "login", cliv1alpha1.TargetNone)
+	digest := lp.Digests[platformKey(runtime.GOOS, runtime.GOARCH)]
+	lp.Digests[platformKey(runtime.GOOS, runtime.GOARCH)] = "0000000000000000000000000000000000000000000000000000000000000000"
+	writeLockfileForTest(t, lockfile, lock)
+	err = SyncPluginsFromLockfile(lockfile, InstallOptions{})
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), `"login" plugin post-download verification failed`)
+
+	// Lockfile without a digest for the platform
+	lp.Digests = map[string]string{"other/arch": digest}
+	writeLockfileForTest(t, lockfile, lock)
+	err = SyncPluginsFromLockfile(lockfile, InstallOptions{})
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), fmt.Sprintf("no digest of plugin 'login:%s' for platform %s/%s", lp.Version, runtime.GOOS, runtime.GOARCH))
+
+	// Plugin version not available from the discovery sources
+	lp.Version = "v9.9.9"
+	writeLockfileForTest(t, lockfile, lock)
+	err = SyncPluginsFromLockfile(lockfile, InstallOptions{})
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "version v9.9.9 of plugin 'login' from lockfile is not available")
+
+	// Plugin not available from the discovery sources
+	lp.Name = "not-exists"
+	writeLockfileForTest(t, lockfile, lock)
+	err = SyncPluginsFromLockfile(lockfile, InstallOptions{})
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to find plugin 'not-exists:v9.9.9' from lockfile")
+}
+
+func Test_ReadPluginLockfile(t *testing.T) {
+	assertions := assert.New(t)
+
+	lockfile := filepath.Join(t.TempDir(), DefaultLockfileName)
+	_, err := ReadPluginLockfile(lockfile)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to read plugin lockfile")
+
+	assertions.Nil(os.WriteFile(lockfile, []byte("apiVersion: v1\nkind: ConfigMap\n"), 0644))
+	_, err = ReadPluginLockfile(lockfile)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unsupported plugin lockfile")
+
+	writeLockfileForTest(t, lockfile, &PluginLock{Plugins: []LockedPlugin{{Name: "login", Version: "v0.2.0"}}})
+	_, err = ReadPluginLockfile(lockfile)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "name, version and digest are required for every plugin")
+}
+
+func writeLockfileForTest(t *testing.T, path string, lock *PluginLock) {
+	lock.APIVersion = lockfileAPIVersion
+	lock.Kind = lockfileKind
+	b, err := yaml.Marshal(lock)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(path, b, 0644))
+}
+
+func findLockedPluginForTest(plugins []LockedPlugin, name string, target cliv1alpha1.Target) *LockedPlugin {
+	for i := range plugins {
+		if plugins[i].Name == name && plugins[i].Target == target {
+			return &plugins[i]
+		}
+	}
+	return nil
+}
+
+func Test_publishedDigests(t *testing.T) {
+	assertions := assert.New(t)
+
+	p := &plugin.Discovered{Distribution: distribution.Artifacts{
+		"v1.0.0": distribution.ArtifactList{
+			{OS: "linux", Arch: "amd64", Digest: "linux-digest"},
+			{OS: "darwin", Arch: "arm64", Digest: "darwin-digest"},
+			{OS: "windows", Arch: "amd64"},
+		},
+	}}
+	assertions.Equal(map[string]string{"linux/amd64": "linux-digest", "darwin/arm64": "darwin-digest"}, publishedDigests(p, "v1.0.0"))
+	assertions.Empty(publishedDigests(p, "v2.0.0"))
+	assertions.Empty(publishedDigests(nil, "v1.0.0"))
+}
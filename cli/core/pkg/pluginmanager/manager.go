@@ -5,7 +5,9 @@
 package pluginmanager
 
 import (
+	"context"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -13,6 +15,8 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aunum/log"
 	"github.com/pkg/errors"
@@ -30,6 +34,7 @@ import (
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/config"
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/discovery"
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/plugin"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/signature"
 	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
 	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
 	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/component"
@@ -53,6 +58,15 @@ type DeletePluginOptions struct {
 	ForceDelete bool
 }
 
+// InstallOptions are the options to verify the plugins to install
+type InstallOptions struct {
+	// AllowUnsigned installs plugins without a signature although a signature trust root is configured.
+	AllowUnsigned bool
+	// trustedDigests are the digests of the plugin binaries of a plugin bundle which were
+	// exported from an image whose signature is verified.
+	trustedDigests map[string]bool
+}
+
 // ValidatePlugin validates the plugin descriptor.
 func ValidatePlugin(p *cliapi.PluginDescriptor) (err error) {
 	// skip builder plugin for bootstrapping
@@ -77,25 +91,94 @@ func ValidatePlugin(p *cliapi.PluginDescriptor) (err error) {
 	return
 }
 
+// discoverPlugins lists the plugins from all the discovery sources concurrently.
+// A discovery source that fails or does not respond within the configured timeout
+// is reported as a warning and skipped.
 func discoverPlugins(pd []configapi.PluginDiscovery) ([]plugin.Discovered, error) {
-	allPlugins := make([]plugin.Discovered, 0)
+	discoveries := make([]discovery.Discovery, 0, len(pd))
 	for _, d := range pd {
-		discObject, err := discovery.CreateDiscoveryFromV1alpha1(d)
+		discObject, err := createDiscovery(d)
 		if err != nil {
 			return nil, errors.Wrapf(err, "unable to create discovery")
 		}
+		discoveries = append(discoveries, discObject)
+	}
 
-		plugins, err := discObject.List()
-		if err != nil {
-			log.Warningf("unable to list plugin from discovery '%v': %v", discObject.Name(), err.Error())
-			continue
-		}
+	// Keep the results in the order of the discovery sources as the
+	// sequence of sources determines the priority of duplicate plugins
+	results := make([][]plugin.Discovered, len(discoveries))
+	timeout := config.GetDiscoveryTimeout()
 
-		allPlugins = append(allPlugins, plugins...)
+	var wg sync.WaitGroup
+	for i := range discoveries {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			plugins, err := listPluginsWithTimeout(discoveries[i], timeout)
+			if err != nil {
+				log.Warningf("unable to list plugin from discovery '%v': %v", discoveries[i].Name(), err.Error())
+				return
+			}
+			results[i] = plugins
+		}(i)
+	}
+	wg.Wait()
+
+	allPlugins := make([]plugin.Discovered, 0)
+	for i := range results {
+		allPlugins = append(allPlugins, results[i]...)
 	}
 	return allPlugins, nil
 }
 
+// createDiscovery creates the discovery for the discovery source. Remote discovery
+// sources are cached for the configured duration. Local and kubernetes discovery
+// sources are not cached as their content can change without any change to the
+// discovery source configuration.
+func createDiscovery(pd configapi.PluginDiscovery) (discovery.Discovery, error) {
+	discObject, err := discovery.CreateDiscoveryFromV1alpha1(pd)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := config.GetDiscoveryCacheTTL()
+	if ttl <= 0 || discObject.Type() == common.DiscoveryTypeLocal || discObject.Type() == common.DiscoveryTypeKubernetes {
+		return discObject, nil
+	}
+	return discovery.NewCachedDiscovery(discObject, pd, ttl)
+}
+
+// listPluginsWithTimeout lists the plugins from the discovery and returns
+// error if the discovery does not respond within the given duration. The
+// discovery is then cancelled if it supports it.
+func listPluginsWithTimeout(d discovery.Discovery, timeout time.Duration) ([]plugin.Discovered, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	type listResult struct {
+		plugins []plugin.Discovered
+		err     error
+	}
+	ch := make(chan listResult, 1)
+	go func() {
+		plugins, err := discovery.ListContext(ctx, d)
+		ch <- listResult{plugins: plugins, err: err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.plugins, r.err
+	case <-ctx.Done():
+		return nil, errors.Errorf("timed out after %v", timeout)
+	}
+}
+
+// CleanDiscoveryCache removes the cached plugins of all discovery sources so that
+// the plugins get discovered again from the discovery sources
+func CleanDiscoveryCache() error {
+	return discovery.CleanDiscoveryCache()
+}
+
 // DiscoverStandalonePlugins returns the available standalone plugins
 func DiscoverStandalonePlugins() (plugins []plugin.Discovered, err error) {
 	cfg, e := configlib.GetClientConfig()
@@ -104,6 +187,12 @@ func DiscoverStandalonePlugins() (plugins []plugin.Discovered, err error) {
 		return
 	}
 
+	// The discovery sources are overlaid by the profile in use
+	if cfg != nil {
+		if e := configlib.ApplyCurrentProfile(cfg); e != nil {
+			log.Warningf("ignoring the profile in use: %v", e)
+		}
+	}
 	if cfg == nil || cfg.ClientOptions == nil || cfg.ClientOptions.CLI == nil {
 		plugins = []plugin.Discovered{}
 		return
@@ -132,44 +221,68 @@ func DiscoverServerPlugins() ([]plugin.Discovered, error) {
 }
 
 func discoverServerPluginsBasedOnAllCurrentContexts() ([]plugin.Discovered, error) {
-	var plugins []plugin.Discovered
-	var errList []error
-
 	currentContextMap, err := configlib.GetAllCurrentContextsMap()
 	if err != nil {
 		return nil, err
 	}
 	if len(currentContextMap) == 0 {
-		return plugins, nil
+		return nil, nil
 	}
 
+	contexts := make([]*configapi.Context, 0, len(currentContextMap))
 	for _, context := range currentContextMap {
-		var discoverySources []configapi.PluginDiscovery
-		discoverySources = append(discoverySources, context.DiscoverySources...)
-		discoverySources = append(discoverySources, defaultDiscoverySourceBasedOnContext(context)...)
-		discoveredPlugins, err := discoverPlugins(discoverySources)
-		if err != nil {
-			errList = append(errList, err)
+		contexts = append(contexts, context)
+	}
+
+	// Discover the plugins of all contexts concurrently
+	results := make([][]plugin.Discovered, len(contexts))
+	errs := make([]error, len(contexts))
+	var wg sync.WaitGroup
+	for i := range contexts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = discoverServerPluginsForContext(contexts[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var plugins []plugin.Discovered
+	var errList []error
+	for i := range contexts {
+		if errs[i] != nil {
+			errList = append(errList, errs[i])
 			continue
 		}
-		for i := range discoveredPlugins {
-			discoveredPlugins[i].Scope = common.PluginScopeContext
-			discoveredPlugins[i].Status = common.PluginStatusNotInstalled
-			discoveredPlugins[i].ContextName = context.Name
-
-			// Associate Target of the plugin based on the Context Type of the Context
-			switch context.Type {
-			case configapi.CtxTypeTMC:
-				discoveredPlugins[i].Target = cliv1alpha1.TargetTMC
-			case configapi.CtxTypeK8s:
-				discoveredPlugins[i].Target = cliv1alpha1.TargetK8s
-			}
-		}
-		plugins = append(plugins, discoveredPlugins...)
+		plugins = append(plugins, results[i]...)
 	}
 	return plugins, kerrors.NewAggregate(errList)
 }
 
+func discoverServerPluginsForContext(context *configapi.Context) ([]plugin.Discovered, error) {
+	var discoverySources []configapi.PluginDiscovery
+	discoverySources = append(discoverySources, context.DiscoverySources...)
+	discoverySources = append(discoverySources, defaultDiscoverySourceBasedOnContext(context)...)
+	discoveredPlugins, err := discoverPlugins(discoverySources)
+	if err != nil {
+		return nil, err
+	}
+	for i := range discoveredPlugins {
+		discoveredPlugins[i].Scope = common.PluginScopeContext
+		discoveredPlugins[i].Status = common.PluginStatusNotInstalled
+		discoveredPlugins[i].ContextName = context.Name
+
+		// Associate Target of the plugin based on the Context Type of the Context
+		switch context.Type {
+		case configapi.CtxTypeTMC:
+			discoveredPlugins[i].Target = cliv1alpha1.TargetTMC
+		case configapi.CtxTypeK8s:
+			discoveredPlugins[i].Target = cliv1alpha1.TargetK8s
+		}
+	}
+	return discoveredPlugins, nil
+}
+
 // discoverServerPluginsBasedOnCurrentServer returns the available plugins associated with the given server
 func discoverServerPluginsBasedOnCurrentServer() ([]plugin.Discovered, error) {
 	var plugins []plugin.Discovered
@@ -191,6 +304,7 @@ func discoverServerPluginsBasedOnCurrentServer() ([]plugin.Discovered, error) {
 	for i := range plugins {
 		plugins[i].Scope = common.PluginScopeContext
 		plugins[i].Status = common.PluginStatusNotInstalled
+		plugins[i].ContextName = server.Name
 	}
 	return plugins, nil
 }
@@ -198,14 +312,26 @@ func discoverServerPluginsBasedOnCurrentServer() ([]plugin.Discovered, error) {
 // DiscoverPlugins returns all the discovered plugins including standalone and context-scoped plugins
 // Context scoped plugin discovery happens for all active contexts
 func DiscoverPlugins() ([]plugin.Discovered, []plugin.Discovered) {
-	serverPlugins, err := DiscoverServerPlugins()
-	if err != nil {
-		log.Warningf("unable to discover server plugins, %v", err.Error())
-	}
+	var serverPlugins, standalonePlugins []plugin.Discovered
+	var serverErr, standaloneErr error
 
-	standalonePlugins, err := DiscoverStandalonePlugins()
-	if err != nil {
-		log.Warningf("unable to discover standalone plugins, %v", err.Error())
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		serverPlugins, serverErr = DiscoverServerPlugins()
+	}()
+	go func() {
+		defer wg.Done()
+		standalonePlugins, standaloneErr = DiscoverStandalonePlugins()
+	}()
+	wg.Wait()
+
+	if serverErr != nil {
+		log.Warningf("unable to discover server plugins, %v", serverErr.Error())
+	}
+	if standaloneErr != nil {
+		log.Warningf("unable to discover standalone plugins, %v", standaloneErr.Error())
 	}
 
 	// TODO(anuj): Remove duplicate plugins with server plugins getting higher priority
@@ -411,12 +537,7 @@ func InstalledPlugins() (serverPlugins, standalonePlugins []cliapi.PluginDescrip
 
 // InstalledStandalonePlugins returns the installed standalone plugins.
 func InstalledStandalonePlugins() ([]cliapi.PluginDescriptor, error) {
-	standAloneCatalog, err := catalog.NewContextCatalog("")
-	if err != nil {
-		return nil, err
-	}
-	standalonePlugins := standAloneCatalog.List()
-	return standalonePlugins, nil
+	return queryInstalledPluginDescriptors(&catalog.PluginQuery{Contexts: []string{""}})
 }
 
 // InstalledServerPlugins returns the installed server plugins.
@@ -426,34 +547,50 @@ func InstalledServerPlugins() ([]cliapi.PluginDescriptor, error) {
 		return nil, err
 	}
 
-	var serverPlugins []cliapi.PluginDescriptor
+	contexts := make([]string, 0, len(serverNames))
 	for _, serverName := range serverNames {
 		if serverName != "" {
-			serverCatalog, err := catalog.NewContextCatalog(serverName)
-			if err != nil {
-				return nil, err
-			}
-			serverPlugins = append(serverPlugins, serverCatalog.List()...)
+			contexts = append(contexts, serverName)
 		}
 	}
+	if len(contexts) == 0 {
+		return nil, nil
+	}
+	return queryInstalledPluginDescriptors(&catalog.PluginQuery{Contexts: contexts})
+}
 
-	return serverPlugins, nil
+func queryInstalledPluginDescriptors(q *catalog.PluginQuery) ([]cliapi.PluginDescriptor, error) {
+	plugins, err := catalog.QueryInstalledPlugins(q)
+	if err != nil {
+		return nil, err
+	}
+	pds := make([]cliapi.PluginDescriptor, 0, len(plugins))
+	for i := range plugins {
+		// The scope of the plugin is determined by the catalog it is installed in and
+		// not by the descriptor reported by the plugin binary
+		pd := plugins[i].Descriptor
+		if plugins[i].Context == "" {
+			pd.Scope = common.PluginScopeStandalone
+		} else {
+			pd.Scope = common.PluginScopeContext
+		}
+		pds = append(pds, pd)
+	}
+	return pds, nil
 }
 
 // DescribePlugin describes a plugin.
 func DescribePlugin(pluginName string, target cliv1alpha1.Target) (desc *cliapi.PluginDescriptor, err error) {
-	serverPlugins, standalonePlugins, err := InstalledPlugins()
+	serverNames, err := configlib.GetAllCurrentContextsList()
 	if err != nil {
 		return nil, err
 	}
-	var matchedPlugins []cliapi.PluginDescriptor
+	// Add empty serverName for standalone plugins
+	serverNames = append(serverNames, "")
 
-	pds := serverPlugins
-	pds = append(pds, standalonePlugins...)
-	for i := range pds {
-		if pds[i].Name == pluginName {
-			matchedPlugins = append(matchedPlugins, pds[i])
-		}
+	matchedPlugins, err := queryInstalledPluginDescriptors(&catalog.PluginQuery{Name: pluginName, Contexts: serverNames})
+	if err != nil {
+		return nil, err
 	}
 
 	if len(matchedPlugins) == 0 {
@@ -491,7 +628,7 @@ func InitializePlugin(descriptor *cliapi.PluginDescriptor) error {
 }
 
 // InstallPlugin installs a plugin from the given repository.
-func InstallPlugin(pluginName, version string, target cliv1alpha1.Target) error {
+func InstallPlugin(pluginName, version string, target cliv1alpha1.Target, options InstallOptions) error {
 	availablePlugins, err := AvailablePlugins()
 	if err != nil {
 		return err
@@ -508,21 +645,36 @@ func InstallPlugin(pluginName, version string, target cliv1alpha1.Target) error
 	}
 
 	if len(matchedPlugins) == 1 {
-		return installOrUpgradePlugin(&matchedPlugins[0], version, false)
+		return installPluginWithDependencies(&matchedPlugins[0], version, availablePlugins, options)
 	}
 
 	for i := range matchedPlugins {
 		if matchedPlugins[i].Target == target {
-			return installOrUpgradePlugin(&matchedPlugins[i], version, false)
+			return installPluginWithDependencies(&matchedPlugins[i], version, availablePlugins, options)
 		}
 	}
 
 	return errors.Errorf("unable to uniquely identify plugin '%v'. Please specify correct Target(kubernetes[k8s]/mission-control[tmc]) of the plugin with `--target` flag", pluginName)
 }
 
+// installPluginWithDependencies resolves the requirements of the plugin version against
+// the available plugins and installs the missing dependencies before the plugin itself
+func installPluginWithDependencies(p *plugin.Discovered, version string, availablePlugins []plugin.Discovered, options InstallOptions) error {
+	installations, err := resolvePluginRequirements(p, version, availablePlugins)
+	if err != nil {
+		return errors.Wrapf(err, "unable to install plugin '%v:%v'", p.Name, version)
+	}
+	for i := range installations {
+		if err := installOrUpgradePlugin(installations[i].plugin, installations[i].version, false, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // UpgradePlugin upgrades a plugin from the given repository.
-func UpgradePlugin(pluginName, version string, target cliv1alpha1.Target) error {
-	return InstallPlugin(pluginName, version, target)
+func UpgradePlugin(pluginName, version string, target cliv1alpha1.Target, options InstallOptions) error {
+	return InstallPlugin(pluginName, version, target, options)
 }
 
 // GetRecommendedVersionOfPlugin returns recommended version of the plugin
@@ -554,14 +706,21 @@ func GetRecommendedVersionOfPlugin(pluginName string, target cliv1alpha1.Target)
 	return "", errors.Errorf("unable to uniquely identify plugin '%v'. Please specify correct Target(kubernetes[k8s]/mission-control[tmc]) of the plugin with `--target` flag", pluginName)
 }
 
-func installOrUpgradePlugin(p *plugin.Discovered, version string, installTestPlugin bool) error {
+func installOrUpgradePlugin(p *plugin.Discovered, version string, installTestPlugin bool, options InstallOptions) error {
+	return installPluginWithDigest(p, version, "", installTestPlugin, options)
+}
+
+// installPluginWithDigest installs the plugin version after verifying that the
+// downloaded binary matches the given digest. If the digest is empty, the digest
+// published by the distribution of the plugin is used.
+func installPluginWithDigest(p *plugin.Discovered, version, digest string, installTestPlugin bool, options InstallOptions) error {
 	if p.Target == cliv1alpha1.TargetNone {
 		log.Infof("Installing plugin '%v:%v'", p.Name, version)
 	} else {
 		log.Infof("Installing plugin '%v:%v' with target '%v'", p.Name, version, p.Target)
 	}
 
-	binary, err := fetchAndVerifyPlugin(p, version)
+	binary, err := fetchAndVerifyPlugin(p, version, digest, options)
 	if err != nil {
 		return err
 	}
@@ -580,27 +739,47 @@ func installOrUpgradePlugin(p *plugin.Discovered, version string, installTestPlu
 	return updateDescriptorAndInitializePlugin(p, descriptor)
 }
 
-func fetchAndVerifyPlugin(p *plugin.Discovered, version string) ([]byte, error) {
+func fetchAndVerifyPlugin(p *plugin.Discovered, version, digest string, options InstallOptions) ([]byte, error) {
 	// verify plugin before download
 	err := verifyPluginPreDownload(p)
 	if err != nil {
 		return nil, errors.Wrapf(err, "%q plugin pre-download verification failed", p.Name)
 	}
 
-	b, err := p.Distribution.Fetch(version, runtime.GOOS, runtime.GOARCH)
+	// verify the signature of the plugin image before download, the image is then
+	// fetched by the verified digest
+	pinnedImage, err := verifyPluginImageSignature(p, version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%q plugin signature verification failed", p.Name)
+	}
+
+	var b []byte
+	if pinnedImage != "" {
+		b, err = artifact.NewOCIArtifact(pinnedImage).Fetch()
+	} else {
+		b, err = p.Distribution.Fetch(version, runtime.GOOS, runtime.GOARCH)
+	}
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to fetch the plugin metadata for plugin %q", p.Name)
 	}
 
 	// verify plugin after download but before installation
-	d, err := p.Distribution.GetDigest(version, runtime.GOOS, runtime.GOARCH)
-	if err != nil {
-		return nil, err
+	if digest == "" {
+		digest, err = p.Distribution.GetDigest(version, runtime.GOOS, runtime.GOARCH)
+		if err != nil {
+			return nil, err
+		}
 	}
-	err = verifyPluginPostDownload(p, d, b)
+	err = verifyPluginPostDownload(p, digest, b)
 	if err != nil {
 		return nil, errors.Wrapf(err, "%q plugin post-download verification failed", p.Name)
 	}
+
+	// verify plugin signature against the configured trust root before installation
+	err = verifyPluginSignature(p, version, b, options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%q plugin signature verification failed", p.Name)
+	}
 	return b, nil
 }
 
@@ -628,6 +807,18 @@ func installAndDescribePlugin(p *plugin.Discovered, version string, binary []byt
 	if err = json.Unmarshal(bytesInfo, &descriptor); err != nil {
 		return nil, errors.Wrapf(err, "could not unmarshal plugin %q description", p.Name)
 	}
+	// The plugin binary may declare requirements which are not published by the discovery source
+	if descriptor.Requirements != nil {
+		if err := checkCoreVersionRequirement(p.Name, version, descriptor.Requirements.CoreVersion); err != nil {
+			os.Remove(pluginPath)
+			return nil, err
+		}
+	}
+	// The permissions published by the discovery source, which admins review before
+	// installing the plugin, take precedence over the ones declared by the binary
+	if permissions, ok := p.Permissions[version]; ok {
+		descriptor.Permissions = permissions.DeepCopy()
+	}
 	descriptor.InstallationPath = pluginPath
 	descriptor.Discovery = p.Source
 	descriptor.DiscoveredRecommendedVersion = p.RecommendedVersion
@@ -669,41 +860,11 @@ func updateDescriptorAndInitializePlugin(p *plugin.Discovered, descriptor *cliap
 
 // DeletePlugin deletes a plugin.
 func DeletePlugin(options DeletePluginOptions) error {
-	serverNames, err := configlib.GetAllCurrentContextsList()
+	matchedPluginCatalog, matchedPluginDescriptor, err := findInstalledPlugin(options.PluginName, options.Target)
 	if err != nil {
 		return err
 	}
 
-	var matchedPluginDescriptor cliapi.PluginDescriptor
-	var matchedPluginCatalog *catalog.ContextCatalog
-	matchedPluginCount := 0
-
-	// Add empty serverName for standalone plugins
-	serverNames = append(serverNames, "")
-
-	for _, serverName := range serverNames {
-		c, err := catalog.NewContextCatalog(serverName)
-		if err != nil {
-			continue
-		}
-
-		pds := c.List()
-		for i := range pds {
-			if pds[i].Name == options.PluginName && (options.Target == "" || options.Target == pds[i].Target) {
-				matchedPluginDescriptor = pds[i]
-				matchedPluginCatalog = c
-				matchedPluginCount++
-			}
-		}
-	}
-
-	if matchedPluginCount == 0 {
-		return errors.Errorf("unable to find plugin '%v'", options.PluginName)
-	}
-	if matchedPluginCount > 1 {
-		return errors.Errorf("unable to uniquely identify plugin '%v'. Please specify correct Target(kubernetes[k8s]/mission-control[tmc]) of the plugin with `--target` flag", options.PluginName)
-	}
-
 	if !options.ForceDelete {
 		if err := component.AskForConfirmation(fmt.Sprintf("Deleting Plugin '%s'. Are you sure?", options.PluginName)); err != nil {
 			return err
@@ -719,24 +880,62 @@ func DeletePlugin(options DeletePluginOptions) error {
 	return nil
 }
 
+// findInstalledPlugin returns the catalog and descriptor of the installed plugin with the given
+// name and target, looking up the catalogs of all current contexts and the stand-alone catalog
+func findInstalledPlugin(pluginName string, target cliv1alpha1.Target) (*catalog.ContextCatalog, cliapi.PluginDescriptor, error) {
+	serverNames, err := configlib.GetAllCurrentContextsList()
+	if err != nil {
+		return nil, cliapi.PluginDescriptor{}, err
+	}
+
+	// Add empty serverName for standalone plugins
+	serverNames = append(serverNames, "")
+
+	matchedPlugins, err := catalog.QueryInstalledPlugins(&catalog.PluginQuery{Name: pluginName, Target: target, Contexts: serverNames})
+	if err != nil {
+		return nil, cliapi.PluginDescriptor{}, err
+	}
+
+	if len(matchedPlugins) == 0 {
+		return nil, cliapi.PluginDescriptor{}, errors.Errorf("unable to find plugin '%v'", pluginName)
+	}
+	if len(matchedPlugins) > 1 {
+		return nil, cliapi.PluginDescriptor{}, errors.Errorf("unable to uniquely identify plugin '%v'. Please specify correct Target(kubernetes[k8s]/mission-control[tmc]) of the plugin with `--target` flag", pluginName)
+	}
+
+	c, err := catalog.NewContextCatalog(matchedPlugins[0].Context)
+	if err != nil {
+		return nil, cliapi.PluginDescriptor{}, err
+	}
+	return c, matchedPlugins[0].Descriptor, nil
+}
+
 // SyncPlugins automatically downloads all available plugins to users machine
-func SyncPlugins() error {
+func SyncPlugins(options InstallOptions) error {
 	log.Info("Checking for required plugins...")
 	plugins, err := AvailablePlugins()
 	if err != nil {
 		return err
 	}
 
-	installed := false
-
-	errList := make([]error, 0)
+	var missingPlugins []pluginInstallation
 	for idx := range plugins {
 		if plugins[idx].Status != common.PluginStatusInstalled {
-			installed = true
-			err = InstallPlugin(plugins[idx].Name, plugins[idx].RecommendedVersion, plugins[idx].Target)
-			if err != nil {
-				errList = append(errList, err)
-			}
+			missingPlugins = append(missingPlugins, pluginInstallation{plugin: &plugins[idx], version: plugins[idx].RecommendedVersion})
+		}
+	}
+	// The requirements of all plugins are resolved together so that the installed
+	// versions do not depend on the order in which the plugins are discovered
+	installations, err := resolvePluginsRequirements(missingPlugins, plugins)
+	if err != nil {
+		return errors.Wrap(err, "unable to resolve the requirements of the plugins to sync")
+	}
+	installed := len(installations) != 0
+
+	errList := make([]error, 0)
+	for i := range installations {
+		if err := installOrUpgradePlugin(installations[i].plugin, installations[i].version, false, options); err != nil {
+			errList = append(errList, err)
 		}
 	}
 	err = kerrors.NewAggregate(errList)
@@ -752,8 +951,23 @@ func SyncPlugins() error {
 	return nil
 }
 
-// InstallPluginsFromLocalSource installs plugin from local source directory
-func InstallPluginsFromLocalSource(pluginName, version string, target cliv1alpha1.Target, localPath string, installTestPlugin bool) error {
+// InstallPluginsFromLocalSource installs plugin from local source directory. The plugins of a
+// plugin bundle staged to the directory are verified with the images they were exported from.
+func InstallPluginsFromLocalSource(pluginName, version string, target cliv1alpha1.Target, localPath string, installTestPlugin bool, options InstallOptions) error {
+	if _, err := os.Stat(filepath.Join(localPath, BundleManifestFileName)); err == nil {
+		manifest, err := verifyPluginBundle(localPath)
+		if err != nil {
+			return err
+		}
+		options.trustedDigests, err = verifyBundledImages(localPath, manifest, options.AllowUnsigned)
+		if err != nil {
+			return err
+		}
+	}
+	return installPluginsFromLocalSource(pluginName, version, target, localPath, installTestPlugin, options)
+}
+
+func installPluginsFromLocalSource(pluginName, version string, target cliv1alpha1.Target, localPath string, installTestPlugin bool, options InstallOptions) error {
 	// Set default local plugin distro to local-path as while installing the plugin
 	// from local source we should take t
 	common.DefaultLocalPluginDistroDir = localPath
@@ -776,13 +990,13 @@ func InstallPluginsFromLocalSource(pluginName, version string, target cliv1alpha
 	}
 
 	if len(matchedPlugins) == 1 {
-		return installOrUpgradePlugin(&matchedPlugins[0], FindVersion(matchedPlugins[0].RecommendedVersion, version), installTestPlugin)
+		return installOrUpgradePlugin(&matchedPlugins[0], FindVersion(matchedPlugins[0].RecommendedVersion, version), installTestPlugin, options)
 	}
 
 	for i := range matchedPlugins {
 		// Install all plugins otherwise include all matching plugins
 		if pluginName == cli.AllPlugins || matchedPlugins[i].Target == target {
-			err = installOrUpgradePlugin(&matchedPlugins[i], FindVersion(matchedPlugins[i].RecommendedVersion, version), installTestPlugin)
+			err = installOrUpgradePlugin(&matchedPlugins[i], FindVersion(matchedPlugins[i].RecommendedVersion, version), installTestPlugin, options)
 			if err != nil {
 				errList = append(errList, err)
 			}
@@ -861,6 +1075,12 @@ func Clean() error {
 	if err := catalog.CleanCatalogCache(); err != nil {
 		return errors.Errorf("Failed to clean the catalog cache %v", err)
 	}
+	if err := CleanDiscoveryCache(); err != nil {
+		return errors.Errorf("Failed to clean the discovery cache %v", err)
+	}
+	if err := cli.CleanCompletionCache(); err != nil {
+		return errors.Errorf("Failed to clean the completion cache %v", err)
+	}
 	return os.RemoveAll(common.DefaultPluginRoot)
 }
 
@@ -1053,6 +1273,91 @@ func verifyPluginPostDownload(p *plugin.Discovered, srcDigest string, b []byte)
 	return nil
 }
 
+// verifyPluginImageSignature verifies the cosign signatures of the discovery image
+// and of the plugin image against the configured trust root. It returns the plugin
+// image pinned to its verified digest, or an empty string if the plugin is not
+// distributed as an image or no trust root is configured.
+func verifyPluginImageSignature(p *plugin.Discovered, version string) (string, error) {
+	verifier, err := config.GetPluginSignatureVerifier()
+	if err != nil {
+		return "", errors.Wrap(err, "unable to configure plugin signature verifier")
+	}
+	if verifier == nil {
+		return "", nil
+	}
+
+	if p.DiscoveryImage != "" {
+		if _, err := signature.VerifyImage(p.DiscoveryImage, verifier); err != nil {
+			return "", errors.Wrapf(err, "unable to verify discovery image %q", p.DiscoveryImage)
+		}
+	}
+
+	artifactInfo, err := p.Distribution.DescribeArtifact(version, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", err
+	}
+	if artifactInfo.Image == "" {
+		return "", nil
+	}
+	pinnedImage, err := signature.ResolveImageDigest(context.Background(), artifactInfo.Image)
+	if err != nil {
+		return "", err
+	}
+	if _, err := signature.VerifyImage(pinnedImage, verifier); err != nil {
+		return "", errors.Wrapf(err, "unable to verify plugin image %q", artifactInfo.Image)
+	}
+	return pinnedImage, nil
+}
+
+// verifyPluginSignature verifies the cosign signature of the plugin binary against
+// the configured trust root. Plugins distributed as images are verified before
+// download by verifyPluginImageSignature. Verification is skipped if no trust root
+// is configured.
+func verifyPluginSignature(p *plugin.Discovered, version string, b []byte, options InstallOptions) error {
+	verifier, err := config.GetPluginSignatureVerifier()
+	if err != nil {
+		return errors.Wrap(err, "unable to configure plugin signature verifier")
+	}
+	if verifier == nil {
+		return nil
+	}
+
+	artifactInfo, err := p.Distribution.DescribeArtifact(version, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	if artifactInfo.Image != "" {
+		return nil
+	}
+
+	if artifactInfo.Signature == "" {
+		return verifyUnsignedPlugin(p, version, b, options)
+	}
+	sig := signature.Signature{Base64Signature: artifactInfo.Signature}
+	if artifactInfo.Certificate != "" {
+		sig.Certificate, err = base64.StdEncoding.DecodeString(artifactInfo.Certificate)
+		if err != nil {
+			return errors.Wrap(err, "unable to decode signing certificate")
+		}
+	}
+	return verifier.Verify(b, sig)
+}
+
+// verifyUnsignedPlugin fails the installation of a plugin binary without a signature,
+// including binaries from local discovery sources whose digests are not signed, unless
+// it was exported into a plugin bundle from a verified image or unsigned plugins are
+// explicitly allowed
+func verifyUnsignedPlugin(p *plugin.Discovered, version string, b []byte, options InstallOptions) error {
+	if options.trustedDigests[fmt.Sprintf("%x", sha256.Sum256(b))] {
+		return nil
+	}
+	if options.AllowUnsigned || config.AllowUnsignedPlugins() {
+		log.Warningf("installing unsigned plugin \"%s:%s\" as unsigned plugins are allowed", p.Name, version)
+		return nil
+	}
+	return errors.Errorf("no signature available for artifact \"%s:%s:%s:%s\", use --allow-unsigned to install it anyway", p.Name, version, runtime.GOOS, runtime.GOARCH)
+}
+
 func FindVersion(recommendedPluginVersion, requestedVersion string) string {
 	if requestedVersion == "" || requestedVersion == cli.VersionLatest {
 		return recommendedPluginVersion
@@ -93,7 +93,7 @@ func mockInstallPlugin(assert *assert.Assertions, name, version string, target c
 	execCommand = fakeInfoExecCommand
 	defer func() { execCommand = exec.Command }()
 
-	err := InstallPlugin(name, version, target)
+	err := InstallPlugin(name, version, target, InstallOptions{})
 	assert.Nil(err)
 }
 
@@ -4,11 +4,21 @@
 package pluginmanager
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/aunum/log"
 
@@ -21,6 +31,7 @@ import (
 	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/config"
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/distribution"
 	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/plugin"
 	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
 
@@ -103,12 +114,12 @@ func Test_InstallPlugin_InstalledPlugins(t *testing.T) {
 	defer func() { execCommand = exec.Command }()
 
 	// Try installing nonexistent plugin
-	err := InstallPlugin("not-exists", "v0.2.0", cliv1alpha1.TargetNone)
+	err := InstallPlugin("not-exists", "v0.2.0", cliv1alpha1.TargetNone, InstallOptions{})
 	assertions.NotNil(err)
 	assertions.Contains(err.Error(), "unable to find plugin 'not-exists'")
 
 	// Install login (standalone) plugin
-	err = InstallPlugin("login", "v0.2.0", cliv1alpha1.TargetNone)
+	err = InstallPlugin("login", "v0.2.0", cliv1alpha1.TargetNone, InstallOptions{})
 	assertions.Nil(err)
 	// Verify installed plugin
 	installedServerPlugins, installedStandalonePlugins, err := InstalledPlugins()
@@ -118,30 +129,30 @@ func Test_InstallPlugin_InstalledPlugins(t *testing.T) {
 	assertions.Equal("login", installedStandalonePlugins[0].Name)
 
 	// Try installing cluster plugin with no context-type
-	err = InstallPlugin("cluster", "v0.2.0", cliv1alpha1.TargetNone)
+	err = InstallPlugin("cluster", "v0.2.0", cliv1alpha1.TargetNone, InstallOptions{})
 	assertions.NotNil(err)
 	assertions.Contains(err.Error(), "unable to uniquely identify plugin 'cluster'. Please specify correct Target(kubernetes[k8s]/mission-control[tmc]) of the plugin with `--target` flag")
 
 	// Try installing cluster plugin with context-type=tmc
-	err = InstallPlugin("cluster", "v0.2.0", cliv1alpha1.TargetTMC)
+	err = InstallPlugin("cluster", "v0.2.0", cliv1alpha1.TargetTMC, InstallOptions{})
 	assertions.Nil(err)
 
 	// Try installing cluster plugin through context-type=k8s with incorrect version
-	err = InstallPlugin("cluster", "v1.0.0", cliv1alpha1.TargetK8s)
+	err = InstallPlugin("cluster", "v1.0.0", cliv1alpha1.TargetK8s, InstallOptions{})
 	assertions.NotNil(err)
 	assertions.Contains(err.Error(), "unable to fetch the plugin metadata")
 
 	// Try installing cluster plugin through context-type=k8s
-	err = InstallPlugin("cluster", "v1.6.0", cliv1alpha1.TargetK8s)
+	err = InstallPlugin("cluster", "v1.6.0", cliv1alpha1.TargetK8s, InstallOptions{})
 	assertions.Nil(err)
 
 	// Try installing management-cluster plugin from standalone discovery without context-type
-	err = InstallPlugin("management-cluster", "v1.6.0", cliv1alpha1.TargetNone)
+	err = InstallPlugin("management-cluster", "v1.6.0", cliv1alpha1.TargetNone, InstallOptions{})
 	assertions.NotNil(err)
 	assertions.Contains(err.Error(), "unable to uniquely identify plugin 'management-cluster'. Please specify correct Target(kubernetes[k8s]/mission-control[tmc]) of the plugin with `--target` flag")
 
 	// Try installing management-cluster plugin from standalone discovery
-	err = InstallPlugin("management-cluster", "v1.6.0", cliv1alpha1.TargetK8s)
+	err = InstallPlugin("management-cluster", "v1.6.0", cliv1alpha1.TargetK8s, InstallOptions{})
 	assertions.Nil(err)
 
 	// Verify installed plugins
@@ -460,12 +471,12 @@ func Test_InstallPlugin_InstalledPlugins_From_LocalSource(t *testing.T) {
 	localPluginSourceDir := filepath.Join(currentDirAbsPath, "test", "local")
 
 	// Try installing nonexistent plugin
-	err := InstallPluginsFromLocalSource("not-exists", "v0.2.0", cliv1alpha1.TargetNone, localPluginSourceDir, false)
+	err := InstallPluginsFromLocalSource("not-exists", "v0.2.0", cliv1alpha1.TargetNone, localPluginSourceDir, false, InstallOptions{})
 	assertions.NotNil(err)
 	assertions.Contains(err.Error(), "unable to find plugin 'not-exists'")
 
 	// Install login from local source directory
-	err = InstallPluginsFromLocalSource("login", "v0.2.0", cliv1alpha1.TargetNone, localPluginSourceDir, false)
+	err = InstallPluginsFromLocalSource("login", "v0.2.0", cliv1alpha1.TargetNone, localPluginSourceDir, false, InstallOptions{})
 	assertions.Nil(err)
 	// Verify installed plugin
 	installedServerPlugins, installedStandalonePlugins, err := InstalledPlugins()
@@ -475,7 +486,7 @@ func Test_InstallPlugin_InstalledPlugins_From_LocalSource(t *testing.T) {
 	assertions.Equal("login", installedStandalonePlugins[0].Name)
 
 	// Try installing cluster plugin from local source directory
-	err = InstallPluginsFromLocalSource("cluster", "v0.2.0", cliv1alpha1.TargetTMC, localPluginSourceDir, false)
+	err = InstallPluginsFromLocalSource("cluster", "v0.2.0", cliv1alpha1.TargetTMC, localPluginSourceDir, false, InstallOptions{})
 	assertions.Nil(err)
 	installedServerPlugins, installedStandalonePlugins, err = InstalledPlugins()
 	assertions.Nil(err)
@@ -483,7 +494,7 @@ func Test_InstallPlugin_InstalledPlugins_From_LocalSource(t *testing.T) {
 	assertions.Equal(2, len(installedStandalonePlugins))
 
 	// Try installing a plugin from incorrect local path
-	err = InstallPluginsFromLocalSource("cluster", "v0.2.0", cliv1alpha1.TargetTMC, "fakepath", false)
+	err = InstallPluginsFromLocalSource("cluster", "v0.2.0", cliv1alpha1.TargetTMC, "fakepath", false, InstallOptions{})
 	assertions.NotNil(err)
 	assertions.Contains(err.Error(), "no such file or directory")
 }
@@ -598,7 +609,7 @@ func Test_SyncPlugins_All_Plugins(t *testing.T) {
 	}
 
 	// Sync all available plugins
-	err = SyncPlugins()
+	err = SyncPlugins(InstallOptions{})
 	assertions.Nil(err)
 
 	// Get all available plugins(standalone+context-aware) and verify the status is updated to `installed`
@@ -758,7 +769,7 @@ func Test_InstallPluginsFromLocalSourceWithLegacyDirectoryStructure(t *testing.T
 
 	// Using generic InstallPluginsFromLocalSource to test the legacy directory install
 	// When passing legacy directory structure which contains manifest.yaml file
-	err := InstallPluginsFromLocalSource("all", "", cliv1alpha1.TargetNone, filepath.Join("test", "legacy"), false)
+	err := InstallPluginsFromLocalSource("all", "", cliv1alpha1.TargetNone, filepath.Join("test", "legacy"), false, InstallOptions{})
 	assertions.Nil(err)
 
 	// Verify installed plugin
@@ -920,6 +931,178 @@ func TestVerifyPluginPostDownload(t *testing.T) {
 	}
 }
 
+func TestVerifyPluginSignature(t *testing.T) {
+	b, err := os.ReadFile("test/local/distribution/v0.2.0/tanzu-login")
+	assert.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	assert.NoError(t, err)
+	publicKeyFile := filepath.Join(t.TempDir(), "cosign.pub")
+	assert.NoError(t, os.WriteFile(publicKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	sign := func(payload []byte) string {
+		digest := sha256.Sum256(payload)
+		sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		assert.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(sig)
+	}
+	discoveredWithArtifact := func(a distribution.Artifact) *plugin.Discovered {
+		a.OS = runtime.GOOS
+		a.Arch = runtime.GOARCH
+		return &plugin.Discovered{
+			Name:         "login",
+			Distribution: distribution.Artifacts{"v0.2.0": distribution.ArtifactList{a}},
+		}
+	}
+
+	tcs := []struct {
+		name             string
+		publicKey        string
+		allowUnsignedEnv bool
+		options          InstallOptions
+		p                *plugin.Discovered
+		err              string
+	}{
+		{
+			name: "success - no trust root configured",
+			p:    discoveredWithArtifact(distribution.Artifact{URI: "https://storage.googleapis.com/tanzu-cli-advanced-plugins/tanzu-login"}),
+		},
+		{
+			name:      "success - valid signature",
+			publicKey: publicKeyFile,
+			p:         discoveredWithArtifact(distribution.Artifact{URI: "https://storage.googleapis.com/tanzu-cli-advanced-plugins/tanzu-login", Signature: sign(b)}),
+		},
+		{
+			name:      "success - bundled artifact of a verified image",
+			publicKey: publicKeyFile,
+			options:   InstallOptions{trustedDigests: map[string]bool{fmt.Sprintf("%x", sha256.Sum256(b)): true}},
+			p:         discoveredWithArtifact(distribution.Artifact{URI: "test/local/distribution/v0.2.0/tanzu-login", Digest: fmt.Sprintf("%x", sha256.Sum256(b))}),
+		},
+		{
+			name:      "success - unsigned plugins allowed",
+			publicKey: publicKeyFile,
+			options:   InstallOptions{AllowUnsigned: true},
+			p:         discoveredWithArtifact(distribution.Artifact{URI: "https://storage.googleapis.com/tanzu-cli-advanced-plugins/tanzu-login"}),
+		},
+		{
+			name:             "success - unsigned plugins allowed by the environment",
+			publicKey:        publicKeyFile,
+			allowUnsignedEnv: true,
+			p:                discoveredWithArtifact(distribution.Artifact{URI: "https://storage.googleapis.com/tanzu-cli-advanced-plugins/tanzu-login"}),
+		},
+		{
+			name:      "failure - local artifact with unsigned digest",
+			publicKey: publicKeyFile,
+			p:         discoveredWithArtifact(distribution.Artifact{URI: "test/local/distribution/v0.2.0/tanzu-login", Digest: fmt.Sprintf("%x", sha256.Sum256(b))}),
+			err:       fmt.Sprintf("no signature available for artifact \"login:v0.2.0:%s:%s\"", runtime.GOOS, runtime.GOARCH),
+		},
+		{
+			name:      "failure - local artifact of another verified image",
+			publicKey: publicKeyFile,
+			options:   InstallOptions{trustedDigests: map[string]bool{"0000": true}},
+			p:         discoveredWithArtifact(distribution.Artifact{URI: "test/local/distribution/v0.2.0/tanzu-login", Digest: "0000"}),
+			err:       fmt.Sprintf("no signature available for artifact \"login:v0.2.0:%s:%s\"", runtime.GOOS, runtime.GOARCH),
+		},
+		{
+			name:      "failure - missing signature",
+			publicKey: publicKeyFile,
+			p:         discoveredWithArtifact(distribution.Artifact{URI: "https://storage.googleapis.com/tanzu-cli-advanced-plugins/tanzu-login"}),
+			err:       fmt.Sprintf("no signature available for artifact \"login:v0.2.0:%s:%s\"", runtime.GOOS, runtime.GOARCH),
+		},
+		{
+			name:      "failure - invalid signature",
+			publicKey: publicKeyFile,
+			p:         discoveredWithArtifact(distribution.Artifact{URI: "https://storage.googleapis.com/tanzu-cli-advanced-plugins/tanzu-login", Signature: sign([]byte("other binary"))}),
+			err:       "invalid signature",
+		},
+		{
+			name:      "failure - public key does not exist",
+			publicKey: filepath.Join(t.TempDir(), "does-not-exist.pub"),
+			p:         discoveredWithArtifact(distribution.Artifact{URI: "https://storage.googleapis.com/tanzu-cli-advanced-plugins/tanzu-login"}),
+			err:       "unable to configure plugin signature verifier",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			os.Setenv(constants.PluginSignaturePublicKey, tc.publicKey)
+			defer os.Unsetenv(constants.PluginSignaturePublicKey)
+			if tc.allowUnsignedEnv {
+				os.Setenv(constants.PluginSignatureAllowUnsigned, "true")
+				defer os.Unsetenv(constants.PluginSignatureAllowUnsigned)
+			}
+
+			err := verifyPluginSignature(tc.p, "v0.2.0", b, tc.options)
+			if tc.err != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// fakeDiscovery is a discovery which returns the plugins after the given delay
+type fakeDiscovery struct {
+	name    string
+	delay   time.Duration
+	plugins []plugin.Discovered
+}
+
+func (d *fakeDiscovery) Name() string { return d.name }
+func (d *fakeDiscovery) Type() string { return "fake" }
+func (d *fakeDiscovery) List() ([]plugin.Discovered, error) {
+	time.Sleep(d.delay)
+	return d.plugins, nil
+}
+func (d *fakeDiscovery) Describe(name string) (plugin.Discovered, error) {
+	return plugin.Discovered{}, nil
+}
+
+// cancellableDiscovery is a discovery which lists the plugins until its context is done
+type cancellableDiscovery struct {
+	fakeDiscovery
+	cancelled chan struct{}
+}
+
+func (d *cancellableDiscovery) ListContext(ctx context.Context) ([]plugin.Discovered, error) {
+	select {
+	case <-time.After(d.delay):
+		return d.plugins, nil
+	case <-ctx.Done():
+		close(d.cancelled)
+		return nil, ctx.Err()
+	}
+}
+
+func Test_listPluginsWithTimeout(t *testing.T) {
+	assertions := assert.New(t)
+
+	d := &fakeDiscovery{name: "fast", plugins: []plugin.Discovered{{Name: "foo"}}}
+	plugins, err := listPluginsWithTimeout(d, time.Second)
+	assertions.Nil(err)
+	assertions.Equal(d.plugins, plugins)
+
+	d = &fakeDiscovery{name: "slow", delay: time.Second, plugins: []plugin.Discovered{{Name: "foo"}}}
+	plugins, err = listPluginsWithTimeout(d, 10*time.Millisecond)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "timed out after 10ms")
+	assertions.Nil(plugins)
+
+	// The discovery is cancelled after the timeout
+	cd := &cancellableDiscovery{fakeDiscovery: fakeDiscovery{name: "slow", delay: time.Minute}, cancelled: make(chan struct{})}
+	_, err = listPluginsWithTimeout(cd, 10*time.Millisecond)
+	assertions.NotNil(err)
+	select {
+	case <-cd.cancelled:
+	case <-time.After(time.Second):
+		assertions.Fail("discovery was not cancelled after the timeout")
+	}
+}
+
 func Test_removeDuplicates(t *testing.T) {
 	assertions := assert.New(t)
 
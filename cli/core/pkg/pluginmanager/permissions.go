@@ -0,0 +1,70 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"github.com/pkg/errors"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/plugin"
+)
+
+// GetPluginPermissions returns the permissions of the plugin version and the version. If
+// the version is empty, the permissions the installed plugin is run with are returned, or
+// the ones of the recommended version if the plugin is not installed. Other versions are
+// looked up in the discovery sources, so that the permissions can be reviewed before
+// installing the plugin. Nil permissions are returned if the plugin does not declare any.
+func GetPluginPermissions(pluginName, version string, target cliv1alpha1.Target) (*cliv1alpha1.PluginPermissions, string, error) {
+	if version == "" {
+		if pd, err := DescribePlugin(pluginName, target); err == nil {
+			return pd.Permissions, pd.Version, nil
+		}
+	}
+
+	availablePlugins, err := AvailablePlugins()
+	if err != nil {
+		return nil, "", err
+	}
+	var matchedPlugins []plugin.Discovered
+	for i := range availablePlugins {
+		if availablePlugins[i].Name == pluginName {
+			matchedPlugins = append(matchedPlugins, availablePlugins[i])
+		}
+	}
+	if len(matchedPlugins) == 0 {
+		return nil, "", errors.Errorf("unable to find plugin '%v'", pluginName)
+	}
+	if len(matchedPlugins) == 1 {
+		return discoveredPluginPermissions(&matchedPlugins[0], version)
+	}
+	for i := range matchedPlugins {
+		if matchedPlugins[i].Target == target {
+			return discoveredPluginPermissions(&matchedPlugins[i], version)
+		}
+	}
+	return nil, "", errors.Errorf("unable to uniquely identify plugin '%v'. Please specify correct Target(kubernetes[k8s]/mission-control[tmc]) of the plugin with `--target` flag", pluginName)
+}
+
+// discoveredPluginPermissions returns the permissions published by the discovery source for
+// the plugin version, the recommended version if the version is empty
+func discoveredPluginPermissions(p *plugin.Discovered, version string) (*cliv1alpha1.PluginPermissions, string, error) {
+	if version == "" {
+		version = p.RecommendedVersion
+	}
+	supported := false
+	for _, v := range p.SupportedVersions {
+		if v == version {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return nil, "", errors.Errorf("plugin '%v' does not have version '%v'", p.Name, version)
+	}
+	permissions, ok := p.Permissions[version]
+	if !ok {
+		return nil, version, nil
+	}
+	return permissions.DeepCopy(), version, nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/plugin"
+)
+
+func TestDiscoveredPluginPermissions(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &plugin.Discovered{
+		Name:               "cluster",
+		RecommendedVersion: "v1.1.0",
+		SupportedVersions:  []string{"v1.0.0", "v1.1.0"},
+		Permissions: map[string]cliv1alpha1.PluginPermissions{
+			"v1.1.0": {Network: []string{"*.example.com:443"}, ConfigKeys: []string{"contexts"}, Credentials: true},
+		},
+	}
+
+	permissions, version, err := discoveredPluginPermissions(p, "")
+	assert.NoError(err)
+	assert.Equal("v1.1.0", version)
+	assert.Equal([]string{"*.example.com:443"}, permissions.Network)
+	assert.True(permissions.Credentials)
+
+	// Versions which do not declare permissions are run with full access
+	permissions, version, err = discoveredPluginPermissions(p, "v1.0.0")
+	assert.NoError(err)
+	assert.Equal("v1.0.0", version)
+	assert.Nil(permissions)
+
+	_, _, err = discoveredPluginPermissions(p, "v2.0.0")
+	assert.ErrorContains(err, "does not have version 'v2.0.0'")
+}
@@ -0,0 +1,209 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/buildinfo"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/plugin"
+)
+
+// pluginInstallation is a plugin version which needs to be installed
+type pluginInstallation struct {
+	plugin  *plugin.Discovered
+	version string
+}
+
+// requirementsResolver resolves the dependencies of plugins against the available plugins
+type requirementsResolver struct {
+	availablePlugins []plugin.Discovered
+	// selected is the version selected for every resolved plugin
+	selected map[string]string
+	// requiredBy is the plugin which caused the selection of a plugin version
+	requiredBy map[string]string
+	// roots are the plugins whose requirements are resolved, by plugin key
+	roots map[string]pluginInstallation
+	// resolved are the plugins whose dependencies have been resolved
+	resolved map[string]bool
+	// installations are the plugins to install in installation order
+	installations []pluginInstallation
+}
+
+// resolvePluginRequirements validates the requirements of the given plugin version and resolves
+// its dependencies against the available plugins. It returns the plugins to install in
+// installation order, i.e. dependencies come before the plugins depending on them and the
+// given plugin is always the last one. Dependencies which are already installed with a
+// version satisfying the constraint are not returned.
+func resolvePluginRequirements(p *plugin.Discovered, version string, availablePlugins []plugin.Discovered) ([]pluginInstallation, error) {
+	return resolvePluginsRequirements([]pluginInstallation{{plugin: p, version: version}}, availablePlugins)
+}
+
+// resolvePluginsRequirements resolves the requirements of the given plugin versions jointly,
+// so that the versions of the given plugins are selected before any of their dependencies and
+// the result does not depend on the order of the plugins. It returns the combined set of
+// plugins to install in installation order, or an error if the requirements conflict.
+func resolvePluginsRequirements(plugins []pluginInstallation, availablePlugins []plugin.Discovered) ([]pluginInstallation, error) {
+	r := &requirementsResolver{
+		availablePlugins: availablePlugins,
+		selected:         map[string]string{},
+		requiredBy:       map[string]string{},
+		roots:            map[string]pluginInstallation{},
+		resolved:         map[string]bool{},
+	}
+	for _, pi := range plugins {
+		key := pluginKey(pi.plugin.Name, pi.plugin.Target)
+		r.selected[key] = pi.version
+		r.roots[key] = pi
+	}
+	for _, pi := range plugins {
+		if err := r.resolve(pi.plugin, pi.version); err != nil {
+			return nil, err
+		}
+	}
+	return r.installations, nil
+}
+
+func (r *requirementsResolver) resolve(p *plugin.Discovered, version string) error {
+	key := pluginKey(p.Name, p.Target)
+	if r.resolved[key] {
+		return nil
+	}
+	r.resolved[key] = true
+
+	requirements := p.Requirements[version]
+	if err := checkCoreVersionRequirement(p.Name, version, requirements.CoreVersion); err != nil {
+		return err
+	}
+
+	for _, dep := range requirements.Dependencies {
+		target := dep.Target
+		if target == "" {
+			target = p.Target
+		}
+		key := pluginKey(dep.Name, target)
+		dependent := fmt.Sprintf("%s:%s", p.Name, version)
+
+		constraint, err := parseVersionConstraint(dep.Version)
+		if err != nil {
+			return errors.Wrapf(err, "plugin %q has an invalid version constraint %q for dependency %q", dependent, dep.Version, dep.Name)
+		}
+
+		// The dependency has already been resolved, either by another plugin
+		// or as part of a dependency cycle, or is one of the plugins to resolve
+		if selectedVersion, ok := r.selected[key]; ok {
+			if !versionSatisfies(selectedVersion, constraint) {
+				return errors.Errorf("conflicting requirements for plugin %q: version %v selected for %q does not satisfy %q required by %q",
+					dep.Name, selectedVersion, r.requiredBy[key], dep.Version, dependent)
+			}
+			if root, ok := r.roots[key]; ok {
+				if err := r.resolve(root.plugin, root.version); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		depPlugin := r.findPlugin(dep.Name, target)
+		if depPlugin == nil {
+			return errors.Errorf("plugin %q requires plugin %q which is not available", dependent, pluginKey(dep.Name, target))
+		}
+
+		r.requiredBy[key] = dependent
+		if depPlugin.InstalledVersion != "" && versionSatisfies(depPlugin.InstalledVersion, constraint) {
+			r.selected[key] = depPlugin.InstalledVersion
+			continue
+		}
+
+		depVersion := selectVersion(depPlugin, constraint)
+		if depVersion == "" {
+			return errors.Errorf("plugin %q requires plugin %q with version %q but no such version is available", dependent, dep.Name, dep.Version)
+		}
+		r.selected[key] = depVersion
+		if err := r.resolve(depPlugin, depVersion); err != nil {
+			return err
+		}
+	}
+
+	r.installations = append(r.installations, pluginInstallation{plugin: p, version: version})
+	return nil
+}
+
+func (r *requirementsResolver) findPlugin(name string, target cliv1alpha1.Target) *plugin.Discovered {
+	for i := range r.availablePlugins {
+		if r.availablePlugins[i].Name == name && r.availablePlugins[i].Target == target {
+			return &r.availablePlugins[i]
+		}
+	}
+	return nil
+}
+
+// checkCoreVersionRequirement verifies that the running CLI core satisfies the core version
+// constraint of a plugin version. Development builds without a valid semantic version are
+// considered compatible with all plugins.
+func checkCoreVersionRequirement(pluginName, version, coreVersion string) error {
+	if coreVersion == "" {
+		return nil
+	}
+	constraint, err := parseVersionConstraint(coreVersion)
+	if err != nil {
+		return errors.Wrapf(err, "plugin %q has an invalid core version constraint %q", pluginName, coreVersion)
+	}
+	v, err := semver.NewVersion(buildinfo.Version)
+	if err != nil {
+		return nil
+	}
+	// Ignore the pre-release so that pre-release builds of the CLI satisfy
+	// constraints on the version being released
+	core, err := v.SetPrerelease("")
+	if err != nil {
+		return nil
+	}
+	if !constraint.Check(&core) {
+		return errors.Errorf("plugin '%v:%v' requires Tanzu CLI core version %q but the installed version is %v", pluginName, version, coreVersion, buildinfo.Version)
+	}
+	return nil
+}
+
+// parseVersionConstraint parses a semantic version constraint.
+// An empty constraint is satisfied by any version.
+func parseVersionConstraint(constraint string) (*semver.Constraints, error) {
+	if constraint == "" {
+		constraint = "*"
+	}
+	return semver.NewConstraint(constraint)
+}
+
+func versionSatisfies(version string, constraint *semver.Constraints) bool {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	return constraint.Check(v)
+}
+
+// selectVersion returns the recommended version of the plugin if it satisfies the constraint,
+// otherwise the highest supported version satisfying it
+func selectVersion(p *plugin.Discovered, constraint *semver.Constraints) string {
+	if versionSatisfies(p.RecommendedVersion, constraint) {
+		return p.RecommendedVersion
+	}
+	for i := len(p.SupportedVersions) - 1; i >= 0; i-- {
+		if versionSatisfies(p.SupportedVersions[i], constraint) {
+			return p.SupportedVersions[i]
+		}
+	}
+	return ""
+}
+
+func pluginKey(name string, target cliv1alpha1.Target) string {
+	if target == cliv1alpha1.TargetNone {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, target)
+}
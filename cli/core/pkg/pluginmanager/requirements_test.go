@@ -0,0 +1,231 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/buildinfo"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/plugin"
+)
+
+func installationVersions(installations []pluginInstallation) []string {
+	versions := make([]string, 0, len(installations))
+	for i := range installations {
+		versions = append(versions, installations[i].plugin.Name+":"+installations[i].version)
+	}
+	return versions
+}
+
+func TestResolvePluginRequirements(t *testing.T) {
+	tcs := []struct {
+		name      string
+		plugins   []plugin.Discovered
+		expected  []string
+		errString string
+	}{
+		{
+			name: "no requirements",
+			plugins: []plugin.Discovered{
+				{Name: "cluster", RecommendedVersion: "v1.0.0", SupportedVersions: []string{"v1.0.0"}},
+			},
+			expected: []string{"cluster:v1.0.0"},
+		},
+		{
+			name: "dependencies are installed first",
+			plugins: []plugin.Discovered{
+				{Name: "cluster", RecommendedVersion: "v1.0.0", SupportedVersions: []string{"v1.0.0"},
+					Requirements: map[string]cliv1alpha1.PluginRequirements{
+						"v1.0.0": {Dependencies: []cliv1alpha1.PluginDependency{{Name: "secret", Version: ">= v0.2.0"}}},
+					}},
+				{Name: "secret", RecommendedVersion: "v0.3.0", SupportedVersions: []string{"v0.1.0", "v0.2.0", "v0.3.0"},
+					Requirements: map[string]cliv1alpha1.PluginRequirements{
+						"v0.3.0": {Dependencies: []cliv1alpha1.PluginDependency{{Name: "login"}}},
+					}},
+				{Name: "login", RecommendedVersion: "v0.1.0", SupportedVersions: []string{"v0.1.0"}},
+			},
+			expected: []string{"login:v0.1.0", "secret:v0.3.0", "cluster:v1.0.0"},
+		},
+		{
+			name: "highest supported version is selected if recommended version does not satisfy the constraint",
+			plugins: []plugin.Discovered{
+				{Name: "cluster", RecommendedVersion: "v1.0.0", SupportedVersions: []string{"v1.0.0"},
+					Requirements: map[string]cliv1alpha1.PluginRequirements{
+						"v1.0.0": {Dependencies: []cliv1alpha1.PluginDependency{{Name: "secret", Version: "< v0.3.0"}}},
+					}},
+				{Name: "secret", RecommendedVersion: "v0.3.0", SupportedVersions: []string{"v0.1.0", "v0.2.0", "v0.3.0"}},
+			},
+			expected: []string{"secret:v0.2.0", "cluster:v1.0.0"},
+		},
+		{
+			name: "installed dependency satisfying the constraint is not installed again",
+			plugins: []plugin.Discovered{
+				{Name: "cluster", RecommendedVersion: "v1.0.0", SupportedVersions: []string{"v1.0.0"},
+					Requirements: map[string]cliv1alpha1.PluginRequirements{
+						"v1.0.0": {Dependencies: []cliv1alpha1.PluginDependency{{Name: "secret", Version: ">= v0.1.0"}}},
+					}},
+				{Name: "secret", RecommendedVersion: "v0.3.0", InstalledVersion: "v0.1.0", SupportedVersions: []string{"v0.1.0", "v0.3.0"}},
+			},
+			expected: []string{"cluster:v1.0.0"},
+		},
+		{
+			name: "dependency cycle",
+			plugins: []plugin.Discovered{
+				{Name: "cluster", RecommendedVersion: "v1.0.0", SupportedVersions: []string{"v1.0.0"},
+					Requirements: map[string]cliv1alpha1.PluginRequirements{
+						"v1.0.0": {Dependencies: []cliv1alpha1.PluginDependency{{Name: "secret"}}},
+					}},
+				{Name: "secret", RecommendedVersion: "v0.3.0", SupportedVersions: []string{"v0.3.0"},
+					Requirements: map[string]cliv1alpha1.PluginRequirements{
+						"v0.3.0": {Dependencies: []cliv1alpha1.PluginDependency{{Name: "cluster", Version: "v1.x"}}},
+					}},
+			},
+			expected: []string{"secret:v0.3.0", "cluster:v1.0.0"},
+		},
+		{
+			name: "dependency with a different target",
+			plugins: []plugin.Discovered{
+				{Name: "cluster", RecommendedVersion: "v1.0.0", SupportedVersions: []string{"v1.0.0"}, Target: cliv1alpha1.TargetK8s,
+					Requirements: map[string]cliv1alpha1.PluginRequirements{
+						"v1.0.0": {Dependencies: []cliv1alpha1.PluginDependency{{Name: "cluster", Target: cliv1alpha1.TargetTMC}}},
+					}},
+				{Name: "cluster", RecommendedVersion: "v0.5.0", SupportedVersions: []string{"v0.5.0"}, Target: cliv1alpha1.TargetTMC},
+			},
+			expected: []string{"cluster:v0.5.0", "cluster:v1.0.0"},
+		},
+		{
+			name: "missing dependency",
+			plugins: []plugin.Discovered{
+				{Name: "cluster", RecommendedVersion: "v1.0.0", SupportedVersions: []string{"v1.0.0"},
+					Requirements: map[string]cliv1alpha1.PluginRequirements{
+						"v1.0.0": {Dependencies: []cliv1alpha1.PluginDependency{{Name: "secret"}}},
+					}},
+			},
+			errString: `plugin "cluster:v1.0.0" requires plugin "secret" which is not available`,
+		},
+		{
+			name: "no version satisfies the constraint",
+			plugins: []plugin.Discovered{
+				{Name: "cluster", RecommendedVersion: "v1.0.0", SupportedVersions: []string{"v1.0.0"},
+					Requirements: map[string]cliv1alpha1.PluginRequirements{
+						"v1.0.0": {Dependencies: []cliv1alpha1.PluginDependency{{Name: "secret", Version: ">= v1.0.0"}}},
+					}},
+				{Name: "secret", RecommendedVersion: "v0.3.0", SupportedVersions: []string{"v0.3.0"}},
+			},
+			errString: `requires plugin "secret" with version ">= v1.0.0" but no such version is available`,
+		},
+		{
+			name: "conflicting constraints",
+			plugins: []plugin.Discovered{
+				{Name: "cluster", RecommendedVersion: "v1.0.0", SupportedVersions: []string{"v1.0.0"},
+					Requirements: map[string]cliv1alpha1.PluginRequirements{
+						"v1.0.0": {Dependencies: []cliv1alpha1.PluginDependency{{Name: "secret", Version: "< v0.3.0"}, {Name: "login"}}},
+					}},
+				{Name: "secret", RecommendedVersion: "v0.3.0", SupportedVersions: []string{"v0.2.0", "v0.3.0"}},
+				{Name: "login", RecommendedVersion: "v0.1.0", SupportedVersions: []string{"v0.1.0"},
+					Requirements: map[string]cliv1alpha1.PluginRequirements{
+						"v0.1.0": {Dependencies: []cliv1alpha1.PluginDependency{{Name: "secret", Version: ">= v0.3.0"}}},
+					}},
+			},
+			errString: `conflicting requirements for plugin "secret": version v0.2.0 selected for "cluster:v1.0.0" does not satisfy ">= v0.3.0" required by "login:v0.1.0"`,
+		},
+		{
+			name: "invalid constraint",
+			plugins: []plugin.Discovered{
+				{Name: "cluster", RecommendedVersion: "v1.0.0", SupportedVersions: []string{"v1.0.0"},
+					Requirements: map[string]cliv1alpha1.PluginRequirements{
+						"v1.0.0": {Dependencies: []cliv1alpha1.PluginDependency{{Name: "secret", Version: "not-a-constraint"}}},
+					}},
+			},
+			errString: "has an invalid version constraint",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			installations, err := resolvePluginRequirements(&tc.plugins[0], tc.plugins[0].RecommendedVersion, tc.plugins)
+			if tc.errString != "" {
+				assert.NotNil(t, err)
+				assert.Contains(t, err.Error(), tc.errString)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, installationVersions(installations))
+		})
+	}
+}
+
+func TestResolvePluginsRequirements(t *testing.T) {
+	assert := assert.New(t)
+
+	resolve := func(plugins []plugin.Discovered) ([]string, error) {
+		var roots []pluginInstallation
+		for i := range plugins {
+			roots = append(roots, pluginInstallation{plugin: &plugins[i], version: plugins[i].RecommendedVersion})
+		}
+		installations, err := resolvePluginsRequirements(roots, plugins)
+		return installationVersions(installations), err
+	}
+	cluster := plugin.Discovered{Name: "cluster", RecommendedVersion: "v1.0.0", SupportedVersions: []string{"v1.0.0"},
+		Requirements: map[string]cliv1alpha1.PluginRequirements{
+			"v1.0.0": {Dependencies: []cliv1alpha1.PluginDependency{{Name: "login"}}},
+		}}
+	login := plugin.Discovered{Name: "login", RecommendedVersion: "v0.2.0", SupportedVersions: []string{"v0.1.0", "v0.2.0"}}
+
+	// Plugins required by other plugins are installed first and only once, in any order
+	installations, err := resolve([]plugin.Discovered{cluster, login})
+	assert.Nil(err)
+	assert.Equal([]string{"login:v0.2.0", "cluster:v1.0.0"}, installations)
+	installations, err = resolve([]plugin.Discovered{login, cluster})
+	assert.Nil(err)
+	assert.Equal([]string{"login:v0.2.0", "cluster:v1.0.0"}, installations)
+
+	// The version of a plugin to install is not replaced by a version selected for a dependency
+	cluster.Requirements = map[string]cliv1alpha1.PluginRequirements{
+		"v1.0.0": {Dependencies: []cliv1alpha1.PluginDependency{{Name: "login", Version: "< v0.2.0"}}},
+	}
+	for _, plugins := range [][]plugin.Discovered{{cluster, login}, {login, cluster}} {
+		_, err = resolve(plugins)
+		assert.NotNil(err)
+		assert.Contains(err.Error(), `conflicting requirements for plugin "login": version v0.2.0`)
+	}
+}
+
+func TestCheckCoreVersionRequirement(t *testing.T) {
+	assert := assert.New(t)
+
+	version := buildinfo.Version
+	defer func() { buildinfo.Version = version }()
+
+	buildinfo.Version = "v0.28.0"
+	assert.Nil(checkCoreVersionRequirement("cluster", "v1.0.0", ""))
+	assert.Nil(checkCoreVersionRequirement("cluster", "v1.0.0", ">= v0.28.0"))
+
+	err := checkCoreVersionRequirement("cluster", "v1.0.0", ">= v0.29.0")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), `plugin 'cluster:v1.0.0' requires Tanzu CLI core version ">= v0.29.0" but the installed version is v0.28.0`)
+
+	err = checkCoreVersionRequirement("cluster", "v1.0.0", "invalid")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "invalid core version constraint")
+
+	// Pre-release builds satisfy constraints on the version being released
+	buildinfo.Version = "v0.29.0-dev"
+	assert.Nil(checkCoreVersionRequirement("cluster", "v1.0.0", ">= v0.29.0"))
+
+	// Development builds are compatible with all plugins
+	buildinfo.Version = "dev"
+	assert.Nil(checkCoreVersionRequirement("cluster", "v1.0.0", ">= v0.29.0"))
+
+	// Plugin versions not satisfied by the core version are rejected when resolving requirements
+	buildinfo.Version = "v0.28.0"
+	p := plugin.Discovered{Name: "cluster", RecommendedVersion: "v1.0.0", SupportedVersions: []string{"v1.0.0"},
+		Requirements: map[string]cliv1alpha1.PluginRequirements{"v1.0.0": {CoreVersion: ">= v0.29.0"}}}
+	_, err = resolvePluginRequirements(&p, "v1.0.0", []plugin.Discovered{p})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "requires Tanzu CLI core version")
+}
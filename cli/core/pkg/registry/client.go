@@ -11,6 +11,7 @@ import (
 	"github.com/cppforlife/go-cli-ui/ui"
 	regname "github.com/google/go-containerregistry/pkg/name"
 	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	regremote "github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/k14s/imgpkg/pkg/imgpkg/cmd"
 	ctlimg "github.com/k14s/imgpkg/pkg/imgpkg/registry"
 	"github.com/pkg/errors"
@@ -21,9 +22,14 @@ type registry struct {
 	registry ctlimg.Registry
 }
 
-// New instantiates a new Registry
+// New instantiates a new Registry. Unless anonymous access is requested,
+// the registry credentials are resolved with NewKeychain.
 func New(opts *ctlimg.Opts) (Registry, error) {
-	reg, err := ctlimg.NewRegistry(*opts)
+	var regOpts []regremote.Option
+	if !opts.Anon && opts.Username == "" && opts.Token == "" {
+		regOpts = append(regOpts, regremote.WithAuthFromKeychain(NewKeychain()))
+	}
+	reg, err := ctlimg.NewRegistry(*opts, regOpts...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to initialze registry client")
 	}
@@ -0,0 +1,67 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	regauthn "github.com/google/go-containerregistry/pkg/authn"
+	ctlimg "github.com/k14s/imgpkg/pkg/imgpkg/registry"
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/constants"
+)
+
+// NewKeychain returns the keychain used to authenticate with image registries.
+// Credentials are resolved from the IMGPKG_REGISTRY_* environment variables first,
+// then from the docker config.json file ($DOCKER_CONFIG or ~/.docker) including the
+// credential helpers and credential store configured in it. Registries without
+// credentials are accessed anonymously.
+func NewKeychain() regauthn.Keychain {
+	return ctlimg.Keychain(ctlimg.KeychainOpts{}, os.Environ)
+}
+
+// GetCACertPaths returns the CA bundle files configured with the
+// TANZU_CLI_REGISTRY_CA_CERT_PATHS environment variable
+func GetCACertPaths() []string {
+	paths := make([]string, 0)
+	for _, path := range filepath.SplitList(os.Getenv(constants.ConfigVariableRegistryCACertPaths)) {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// NewTransport returns the HTTP transport to access the registries with the CA
+// certificates and TLS verification of the given options, in the same way as the
+// imgpkg registry client
+func NewTransport(opts *ctlimg.Opts) (*http.Transport, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	for _, path := range opts.CACertPaths {
+		certs, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read CA certificates from %q", path)
+		}
+		if ok := pool.AppendCertsFromPEM(certs); !ok {
+			return nil, errors.Errorf("unable to add CA certificates from %q", path)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = false
+	transport.ResponseHeaderTimeout = opts.ResponseHeaderTimeout
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:            pool,
+		InsecureSkipVerify: !opts.VerifyCerts, //nolint:gosec
+	}
+	return transport, nil
+}
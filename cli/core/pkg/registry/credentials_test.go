@@ -0,0 +1,185 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	ggcrregistry "github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	ctlimg "github.com/k14s/imgpkg/pkg/imgpkg/registry"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/constants"
+)
+
+const (
+	testUsername = "tanzu"
+	testPassword = "s3cr3t"
+)
+
+// newAuthenticatedRegistry starts an in-process registry which requires basic authentication
+// and returns its host
+func newAuthenticatedRegistry(t *testing.T) string {
+	handler := ggcrregistry.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != testUsername || password != testPassword {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+	return u.Host
+}
+
+// pushFileImage pushes an image with a single file to the registry
+func pushFileImage(t *testing.T, image, filename string, content []byte) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	assert.Nil(t, tw.WriteHeader(&tar.Header{Name: filename, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write(content)
+	assert.Nil(t, err)
+	assert.Nil(t, tw.Close())
+
+	layer, err := tarball.LayerFromReader(&buf)
+	assert.Nil(t, err)
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	assert.Nil(t, err)
+	ref, err := name.ParseReference(image)
+	assert.Nil(t, err)
+	auth := &authn.Basic{Username: testUsername, Password: testPassword}
+	assert.Nil(t, remote.Write(ref, img, remote.WithAuth(auth)))
+}
+
+func writeDockerConfig(t *testing.T, config string) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0600))
+	t.Setenv("DOCKER_CONFIG", dir)
+}
+
+func getFilesFromRegistry(image string) (map[string][]byte, error) {
+	reg, err := New(&ctlimg.Opts{VerifyCerts: true})
+	if err != nil {
+		return nil, err
+	}
+	return reg.GetFiles(image)
+}
+
+func TestRegistryCredentials(t *testing.T) {
+	assert := assert.New(t)
+
+	host := newAuthenticatedRegistry(t)
+	image := fmt.Sprintf("%s/tanzu-cli-plugins/login:v0.2.0", host)
+	pushFileImage(t, image, "tanzu-login", []byte("login"))
+
+	// No credentials configured for the registry
+	writeDockerConfig(t, `{}`)
+	_, err := getFilesFromRegistry(image)
+	assert.NotNil(err)
+
+	// Credentials from the docker config file
+	auth := base64.StdEncoding.EncodeToString([]byte(testUsername + ":" + testPassword))
+	writeDockerConfig(t, fmt.Sprintf(`{"auths": {%q: {"auth": %q}}}`, host, auth))
+	files, err := getFilesFromRegistry(image)
+	assert.Nil(err)
+	assert.Equal([]byte("login"), files["tanzu-login"])
+
+	// Credentials from the environment take precedence over the docker config file
+	t.Setenv("IMGPKG_REGISTRY_HOSTNAME", host)
+	t.Setenv("IMGPKG_REGISTRY_USERNAME", testUsername)
+	t.Setenv("IMGPKG_REGISTRY_PASSWORD", "invalid")
+	_, err = getFilesFromRegistry(image)
+	assert.NotNil(err)
+	t.Setenv("IMGPKG_REGISTRY_PASSWORD", testPassword)
+	writeDockerConfig(t, `{}`)
+	files, err = getFilesFromRegistry(image)
+	assert.Nil(err)
+	assert.Equal([]byte("login"), files["tanzu-login"])
+}
+
+func TestRegistryCredentialHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper script requires a POSIX shell")
+	}
+	assert := assert.New(t)
+
+	host := newAuthenticatedRegistry(t)
+	image := fmt.Sprintf("%s/tanzu-cli-plugins/login:v0.2.0", host)
+	pushFileImage(t, image, "tanzu-login", []byte("login"))
+
+	// Credential helper which returns the credentials of the registry
+	helperDir := t.TempDir()
+	helper := fmt.Sprintf("#!/bin/sh\necho '{\"ServerURL\":%q,\"Username\":%q,\"Secret\":%q}'\n", host, testUsername, testPassword)
+	assert.Nil(os.WriteFile(filepath.Join(helperDir, "docker-credential-tanzu-test"), []byte(helper), 0700)) //nolint:gosec
+	t.Setenv("PATH", strings.Join([]string{helperDir, os.Getenv("PATH")}, string(os.PathListSeparator)))
+
+	writeDockerConfig(t, fmt.Sprintf(`{"credHelpers": {%q: "tanzu-test"}}`, host))
+	files, err := getFilesFromRegistry(image)
+	assert.Nil(err)
+	assert.Equal([]byte("login"), files["tanzu-login"])
+}
+
+func TestGetCACertPaths(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Setenv(constants.ConfigVariableRegistryCACertPaths, "")
+	assert.Empty(GetCACertPaths())
+
+	paths := []string{filepath.Join("certs", "ca.crt"), filepath.Join("certs", "proxy.crt")}
+	t.Setenv(constants.ConfigVariableRegistryCACertPaths, strings.Join(paths, string(os.PathListSeparator))+string(os.PathListSeparator))
+	assert.Equal(paths, GetCACertPaths())
+}
+
+func TestNewTransport(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	assert.Nil(os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+
+	get := func(opts *ctlimg.Opts) error {
+		transport, err := NewTransport(opts)
+		if err != nil {
+			return err
+		}
+		res, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	}
+
+	// Untrusted certificate
+	assert.NotNil(get(&ctlimg.Opts{VerifyCerts: true}))
+	// Certificate trusted with the configured CA certificates
+	assert.Nil(get(&ctlimg.Opts{VerifyCerts: true, CACertPaths: []string{caFile}}))
+	// Certificate verification disabled
+	assert.Nil(get(&ctlimg.Opts{VerifyCerts: false}))
+
+	_, err := NewTransport(&ctlimg.Opts{CACertPaths: []string{filepath.Join(t.TempDir(), "does-not-exist.crt")}})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "unable to read CA certificates")
+}
@@ -0,0 +1,140 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// oidIssuer is the deprecated fulcio extension carrying the OIDC issuer as raw string
+	oidIssuer = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	// oidIssuerV2 is the fulcio extension carrying the OIDC issuer as DER encoded UTF8String
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// CertificateVerifier verifies keyless signatures. The signing certificate must
+// chain up to one of the configured roots and must be issued to the configured
+// identity (and OIDC issuer, if configured).
+//
+// Note: The transparency log is not consulted. Short-lived signing certificates
+// are therefore validated at the time they were issued.
+type CertificateVerifier struct {
+	roots      *x509.CertPool
+	identity   string
+	oidcIssuer string
+}
+
+// NewCertificateVerifier creates a Verifier for keyless signatures from the PEM encoded
+// root certificates and the expected certificate identity and OIDC issuer
+func NewCertificateVerifier(rootsPEM []byte, identity, oidcIssuer string) (Verifier, error) {
+	if identity == "" {
+		return nil, errors.New("certificate identity cannot be empty")
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(rootsPEM) {
+		return nil, errors.New("unable to parse root certificates")
+	}
+	return &CertificateVerifier{
+		roots:      roots,
+		identity:   identity,
+		oidcIssuer: oidcIssuer,
+	}, nil
+}
+
+// NewCertificateVerifierFromFile creates a Verifier for keyless signatures from the
+// PEM encoded root certificates file and the expected certificate identity and OIDC issuer
+func NewCertificateVerifierFromFile(rootsPath, identity, oidcIssuer string) (Verifier, error) {
+	b, err := os.ReadFile(rootsPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read root certificates file %q", rootsPath)
+	}
+	return NewCertificateVerifier(b, identity, oidcIssuer)
+}
+
+// Verify verifies the signing certificate against the configured trust root and identity
+// and the signature of the payload with the public key of the signing certificate
+func (v *CertificateVerifier) Verify(payload []byte, sig Signature) error {
+	if len(sig.Certificate) == 0 {
+		return errors.New("signing certificate is missing")
+	}
+	cert, err := parseCertificate(sig.Certificate)
+	if err != nil {
+		return err
+	}
+
+	intermediates := x509.NewCertPool()
+	if len(sig.Chain) != 0 {
+		intermediates.AppendCertsFromPEM(sig.Chain)
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		CurrentTime:   cert.NotBefore,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+	if err != nil {
+		return errors.Wrap(err, "signing certificate is not trusted")
+	}
+
+	if err := v.verifyIdentity(cert); err != nil {
+		return err
+	}
+
+	return verifyWithPublicKey(cert.PublicKey, payload, sig.Base64Signature)
+}
+
+func (v *CertificateVerifier) verifyIdentity(cert *x509.Certificate) error {
+	found := false
+	for _, email := range cert.EmailAddresses {
+		found = found || email == v.identity
+	}
+	for _, uri := range cert.URIs {
+		found = found || uri.String() == v.identity
+	}
+	if !found {
+		return errors.Errorf("signing certificate is not issued to the identity %q", v.identity)
+	}
+
+	if v.oidcIssuer == "" {
+		return nil
+	}
+	issuer := certificateOIDCIssuer(cert)
+	if issuer != v.oidcIssuer {
+		return errors.Errorf("signing certificate is issued by OIDC issuer %q instead of %q", issuer, v.oidcIssuer)
+	}
+	return nil
+}
+
+func certificateOIDCIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidIssuerV2):
+			var issuer string
+			if _, err := asn1.UnmarshalWithParams(ext.Value, &issuer, "utf8"); err == nil {
+				return issuer
+			}
+		case ext.Id.Equal(oidIssuer):
+			return string(ext.Value)
+		}
+	}
+	return ""
+}
+
+func parseCertificate(pemBytes []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("unable to decode PEM encoded signing certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse signing certificate")
+	}
+	return cert, nil
+}
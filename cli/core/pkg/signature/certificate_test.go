@@ -0,0 +1,135 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testIdentity   = "release@example.com"
+	testOIDCIssuer = "https://accounts.example.com"
+)
+
+type testCA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	rootPEM []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	return &testCA{
+		cert:    cert,
+		key:     key,
+		rootPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue returns a short-lived signing key and certificate similar to the ones issued by fulcio
+func (ca *testCA) issue(t *testing.T, email, issuer string) (*ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	issuerValue, err := asn1.MarshalWithParams(issuer, "utf8")
+	assert.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		NotBefore:       time.Now().Add(-30 * time.Minute),
+		NotAfter:        time.Now().Add(-20 * time.Minute),
+		EmailAddresses:  []string{email},
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		ExtraExtensions: []pkix.Extension{{Id: oidIssuerV2, Value: issuerValue}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, key.Public(), ca.key)
+	assert.Nil(t, err)
+	return key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCertificateVerifier(t *testing.T) {
+	assert := assert.New(t)
+
+	ca := newTestCA(t)
+	v, err := NewCertificateVerifier(ca.rootPEM, testIdentity, testOIDCIssuer)
+	assert.Nil(err)
+
+	payload := []byte("plugin binary")
+	key, cert := ca.issue(t, testIdentity, testOIDCIssuer)
+
+	// Valid signature with an expired (short-lived) certificate
+	assert.Nil(v.Verify(payload, Signature{Base64Signature: signECDSA(t, key, payload), Certificate: cert}))
+
+	// Tampered payload
+	err = v.Verify([]byte("tampered"), Signature{Base64Signature: signECDSA(t, key, payload), Certificate: cert})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "invalid signature")
+
+	// Missing certificate
+	err = v.Verify(payload, Signature{Base64Signature: signECDSA(t, key, payload)})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "signing certificate is missing")
+
+	// Wrong identity
+	key, cert = ca.issue(t, "someone@example.com", testOIDCIssuer)
+	err = v.Verify(payload, Signature{Base64Signature: signECDSA(t, key, payload), Certificate: cert})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "is not issued to the identity")
+
+	// Wrong OIDC issuer
+	key, cert = ca.issue(t, testIdentity, "https://other.example.com")
+	err = v.Verify(payload, Signature{Base64Signature: signECDSA(t, key, payload), Certificate: cert})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "is issued by OIDC issuer")
+
+	// Certificate issued by an untrusted CA
+	key, cert = newTestCA(t).issue(t, testIdentity, testOIDCIssuer)
+	err = v.Verify(payload, Signature{Base64Signature: signECDSA(t, key, payload), Certificate: cert})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "signing certificate is not trusted")
+}
+
+func TestNewCertificateVerifier(t *testing.T) {
+	assert := assert.New(t)
+
+	ca := newTestCA(t)
+	_, err := NewCertificateVerifier(ca.rootPEM, "", "")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "certificate identity cannot be empty")
+
+	_, err = NewCertificateVerifier([]byte("invalid"), testIdentity, "")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "unable to parse root certificates")
+
+	// OIDC issuer is optional
+	v, err := NewCertificateVerifier(ca.rootPEM, testIdentity, "")
+	assert.Nil(err)
+	payload := []byte("plugin binary")
+	key, cert := ca.issue(t, testIdentity, "https://other.example.com")
+	assert.Nil(v.Verify(payload, Signature{Base64Signature: signECDSA(t, key, payload), Certificate: cert}))
+}
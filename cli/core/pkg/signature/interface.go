@@ -0,0 +1,29 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package signature implements verification of cosign signatures for
+// plugin binaries and OCI images against a configured trust root.
+package signature
+
+// Signature is a detached signature over a payload as produced by
+// `cosign sign-blob` or attached to an OCI image by `cosign sign`.
+type Signature struct {
+	// Base64Signature is the base64 encoded signature of the payload.
+	Base64Signature string
+
+	// Certificate is the PEM encoded signing certificate.
+	// Only set for keyless signatures.
+	Certificate []byte
+
+	// Chain is the PEM encoded certificate chain of the signing certificate.
+	// Only set for keyless signatures.
+	Chain []byte
+}
+
+// Verifier is the interface to verify the signature of a payload
+// against a trust root (public key or keyless certificate identity).
+type Verifier interface {
+	// Verify verifies the signature of the payload and returns error
+	// if the signature is invalid or not trusted.
+	Verify(payload []byte, sig Signature) error
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// PublicKeyVerifier verifies signatures created with the private key
+// matching the configured public key, e.g. `cosign sign-blob --key cosign.key`
+type PublicKeyVerifier struct {
+	publicKey crypto.PublicKey
+}
+
+// NewPublicKeyVerifier creates a Verifier from the PEM encoded public key
+func NewPublicKeyVerifier(pemBytes []byte) (Verifier, error) {
+	publicKey, err := parsePublicKey(pemBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &PublicKeyVerifier{publicKey: publicKey}, nil
+}
+
+// NewPublicKeyVerifierFromFile creates a Verifier from the PEM encoded public key file
+func NewPublicKeyVerifierFromFile(path string) (Verifier, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read public key file %q", path)
+	}
+	return NewPublicKeyVerifier(b)
+}
+
+// Verify verifies the signature of the payload with the configured public key
+func (v *PublicKeyVerifier) Verify(payload []byte, sig Signature) error {
+	return verifyWithPublicKey(v.publicKey, payload, sig.Base64Signature)
+}
+
+func parsePublicKey(pemBytes []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("unable to decode PEM encoded public key")
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse public key")
+	}
+	return publicKey, nil
+}
+
+func verifyWithPublicKey(publicKey crypto.PublicKey, payload []byte, b64Sig string) error {
+	if b64Sig == "" {
+		return errors.New("signature is missing")
+	}
+	sig, err := base64.StdEncoding.DecodeString(b64Sig)
+	if err != nil {
+		return errors.Wrap(err, "unable to decode signature")
+	}
+	digest := sha256.Sum256(payload)
+
+	switch pk := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pk, digest[:], sig) {
+			return errors.New("invalid signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pk, crypto.SHA256, digest[:], sig); err != nil {
+			return errors.Wrap(err, "invalid signature")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pk, payload, sig) {
+			return errors.New("invalid signature")
+		}
+	default:
+		return errors.Errorf("unsupported public key type %T", publicKey)
+	}
+	return nil
+}
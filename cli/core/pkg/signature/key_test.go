@@ -0,0 +1,103 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateECDSAKey(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	return key, encodePublicKey(t, key.Public())
+}
+
+func encodePublicKey(t *testing.T, publicKey crypto.PublicKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	assert.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func signECDSA(t *testing.T, key *ecdsa.PrivateKey, payload []byte) string {
+	digest := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	assert.Nil(t, err)
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func TestPublicKeyVerifier(t *testing.T) {
+	assert := assert.New(t)
+
+	key, publicKeyPEM := generateECDSAKey(t)
+	v, err := NewPublicKeyVerifier(publicKeyPEM)
+	assert.Nil(err)
+
+	payload := []byte("plugin binary")
+	sig := signECDSA(t, key, payload)
+
+	assert.Nil(v.Verify(payload, Signature{Base64Signature: sig}))
+
+	err = v.Verify([]byte("tampered plugin binary"), Signature{Base64Signature: sig})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "invalid signature")
+
+	err = v.Verify(payload, Signature{})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "signature is missing")
+
+	err = v.Verify(payload, Signature{Base64Signature: "not-base64!"})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "unable to decode signature")
+
+	otherKey, _ := generateECDSAKey(t)
+	err = v.Verify(payload, Signature{Base64Signature: signECDSA(t, otherKey, payload)})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "invalid signature")
+}
+
+func TestPublicKeyVerifierED25519(t *testing.T) {
+	assert := assert.New(t)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(err)
+	v, err := NewPublicKeyVerifier(encodePublicKey(t, publicKey))
+	assert.Nil(err)
+
+	payload := []byte("plugin binary")
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, payload))
+	assert.Nil(v.Verify(payload, Signature{Base64Signature: sig}))
+	assert.NotNil(v.Verify([]byte("tampered plugin binary"), Signature{Base64Signature: sig}))
+}
+
+func TestNewPublicKeyVerifierFromFile(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewPublicKeyVerifier([]byte("invalid"))
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "unable to decode PEM encoded public key")
+
+	_, err = NewPublicKeyVerifierFromFile(filepath.Join(t.TempDir(), "does-not-exist.pub"))
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "unable to read public key file")
+
+	_, publicKeyPEM := generateECDSAKey(t)
+	keyFile := filepath.Join(t.TempDir(), "cosign.pub")
+	assert.Nil(os.WriteFile(keyFile, publicKeyPEM, 0600))
+	v, err := NewPublicKeyVerifierFromFile(keyFile)
+	assert.Nil(err)
+	assert.NotNil(v)
+}
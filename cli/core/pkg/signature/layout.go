@@ -0,0 +1,149 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	regname "github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+)
+
+// refNameAnnotationKey is the annotation of the OCI image layout index naming its images
+const refNameAnnotationKey = "org.opencontainers.image.ref.name"
+
+// ExportImage writes the given OCI image, pinned to its current digest, and its
+// cosign signature image to the OCI image layout at the given path, so that the
+// image can be verified with VerifyLayoutImage without access to the registry.
+// It returns the digest of the exported image and whether a signature was found.
+func ExportImage(image, path string) (string, bool, error) {
+	pinned, err := ResolveImageDigest(context.Background(), image)
+	if err != nil {
+		return "", false, err
+	}
+	ref, err := regname.NewDigest(pinned)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "unable to parse image reference %q", pinned)
+	}
+	options, err := remoteOptions(context.Background())
+	if err != nil {
+		return "", false, err
+	}
+	img, err := remote.Image(ref, options...)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "unable to fetch image %q", image)
+	}
+
+	lp, err := openLayout(path)
+	if err != nil {
+		return "", false, err
+	}
+	if err := lp.AppendImage(img); err != nil {
+		return "", false, errors.Wrapf(err, "unable to export image %q", image)
+	}
+
+	sigTag, err := signatureTag(ref)
+	if err != nil {
+		return "", false, err
+	}
+	sigImage, err := remote.Image(sigTag, options...)
+	if err != nil {
+		// the image is exported unsigned, the error is reported on verification
+		return ref.DigestStr(), false, nil //nolint:nilerr
+	}
+	if err := lp.AppendImage(sigImage, layout.WithAnnotations(map[string]string{refNameAnnotationKey: sigTag.TagStr()})); err != nil {
+		return "", false, errors.Wrapf(err, "unable to export signature of image %q", image)
+	}
+	return ref.DigestStr(), true, nil
+}
+
+// VerifyLayoutImage verifies that the image with the given digest of the OCI image
+// layout at the given path has at least one cosign signature exported with it by
+// ExportImage which is trusted by the verifier, and returns the verified image.
+func VerifyLayoutImage(path, digest string, v Verifier) (v1.Image, error) {
+	img, err := LayoutImage(path, digest)
+	if err != nil {
+		return nil, err
+	}
+	h, err := v1.NewHash(digest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid image digest %q", digest)
+	}
+	index, err := layout.ImageIndexFromPath(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read OCI image layout %q", path)
+	}
+	indexManifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read OCI image layout %q", path)
+	}
+	sigTag := fmt.Sprintf("%s-%s.%s", h.Algorithm, h.Hex, signatureTagSuffix)
+	for i := range indexManifest.Manifests {
+		if indexManifest.Manifests[i].Annotations[refNameAnnotationKey] != sigTag {
+			continue
+		}
+		sigImage, err := index.Image(indexManifest.Manifests[i].Digest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read signature of image %q", digest)
+		}
+		if err := verifySignatureImage(digest, sigImage, digest, v); err != nil {
+			return nil, err
+		}
+		return img, nil
+	}
+	return nil, errors.Errorf("unable to find signature for image %q", digest)
+}
+
+// LayoutImage returns the image with the given digest of the OCI image layout at
+// the given path after checking that its manifest matches the digest. The layers
+// of the image are not checked against their digests.
+func LayoutImage(path, digest string) (v1.Image, error) {
+	h, err := v1.NewHash(digest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid image digest %q", digest)
+	}
+	index, err := layout.ImageIndexFromPath(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read OCI image layout %q", path)
+	}
+	img, err := index.Image(h)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to find image %q in OCI image layout %q", digest, path)
+	}
+	manifest, err := img.RawManifest()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read manifest of image %q", digest)
+	}
+	actual, _, err := v1.SHA256(bytes.NewReader(manifest))
+	if err != nil {
+		return nil, err
+	}
+	if actual != h {
+		return nil, errors.Errorf("manifest of image %q has been altered, actual digest: %s", digest, actual)
+	}
+	return img, nil
+}
+
+func openLayout(path string) (layout.Path, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return layout.Write(path, empty.Index)
+	}
+	return layout.FromPath(path)
+}
+
+// signatureTag returns the tag where cosign stores the signatures of the image
+func signatureTag(ref regname.Digest) (regname.Tag, error) {
+	h, err := v1.NewHash(ref.DigestStr())
+	if err != nil {
+		return regname.Tag{}, errors.Wrapf(err, "invalid digest for image %q", ref.String())
+	}
+	return ref.Context().Tag(fmt.Sprintf("%s-%s.%s", h.Algorithm, h.Hex, signatureTagSuffix)), nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportAndVerifyLayoutImage(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	assert.Nil(err)
+	host := u.Host
+
+	key, publicKeyPEM := generateECDSAKey(t)
+	v, err := NewPublicKeyVerifier(publicKeyPEM)
+	assert.Nil(err)
+	path := filepath.Join(t.TempDir(), "images")
+
+	// Signed image is verified from the layout
+	image, digest := pushImage(t, host, "plugins/signed")
+	pushSignature(t, host, "plugins/signed", digest, digest, func(payload []byte) string { return signECDSA(t, key, payload) })
+	exportedDigest, signed, err := ExportImage(image, path)
+	assert.Nil(err)
+	assert.True(signed)
+	assert.Equal(digest, exportedDigest)
+	img, err := VerifyLayoutImage(path, digest, v)
+	assert.Nil(err)
+	imgDigest, err := img.Digest()
+	assert.Nil(err)
+	assert.Equal(digest, imgDigest.String())
+
+	// Image signed with a different key
+	otherKey, _ := generateECDSAKey(t)
+	image, digest = pushImage(t, host, "plugins/other-key")
+	pushSignature(t, host, "plugins/other-key", digest, digest, func(payload []byte) string { return signECDSA(t, otherKey, payload) })
+	_, signed, err = ExportImage(image, path)
+	assert.Nil(err)
+	assert.True(signed)
+	_, err = VerifyLayoutImage(path, digest, v)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "no valid signature found for image")
+
+	// Unsigned image is exported without signature
+	image, digest = pushImage(t, host, "plugins/unsigned")
+	_, signed, err = ExportImage(image, path)
+	assert.Nil(err)
+	assert.False(signed)
+	_, err = VerifyLayoutImage(path, digest, v)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "unable to find signature for image")
+
+	// Altered manifest of the image
+	h := digest[len("sha256:"):]
+	assert.Nil(os.WriteFile(filepath.Join(path, "blobs", "sha256", h), []byte("{}"), 0644))
+	_, err = LayoutImage(path, digest)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "has been altered")
+}
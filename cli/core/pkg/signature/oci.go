@@ -0,0 +1,180 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	regname "github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-framework/cli/core/pkg/registry"
+)
+
+// Annotations used by cosign to attach the signature and the signing
+// certificate to the layers of the signature image
+const (
+	SignatureAnnotationKey   = "dev.cosignproject.cosign/signature"
+	CertificateAnnotationKey = "dev.sigstore.cosign/certificate"
+	ChainAnnotationKey       = "dev.sigstore.cosign/chain"
+)
+
+// signatureTagSuffix is the suffix of the tag where cosign stores
+// the signatures of an image
+const signatureTagSuffix = "sig"
+
+// simpleSigningPayload is the payload signed by cosign for OCI images
+type simpleSigningPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// remoteOptions returns the options used to access the registry with the same
+// credentials, CA certificates and TLS verification as the plugin downloads
+func remoteOptions(ctx context.Context) ([]remote.Option, error) {
+	opts, err := carvelhelpers.NewRegistryOpts()
+	if err != nil {
+		return nil, err
+	}
+	transport, err := registry.NewTransport(opts)
+	if err != nil {
+		return nil, err
+	}
+	return []remote.Option{
+		remote.WithAuthFromKeychain(registry.NewKeychain()),
+		remote.WithTransport(transport),
+		remote.WithContext(ctx),
+	}, nil
+}
+
+// ResolveImageDigest returns the reference of the given OCI image pinned to the
+// digest its tag currently resolves to. Images which are fetched after their
+// verification must be fetched by the pinned reference, so that the fetched and
+// the verified image are the same even if the tag is moved in the meantime.
+func ResolveImageDigest(ctx context.Context, image string) (string, error) {
+	ref, err := regname.ParseReference(image, regname.WeakValidation)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to parse image reference %q", image)
+	}
+	if d, ok := ref.(regname.Digest); ok {
+		return d.String(), nil
+	}
+	options, err := remoteOptions(ctx)
+	if err != nil {
+		return "", err
+	}
+	desc, err := remote.Head(ref, options...)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to resolve image %q", image)
+	}
+	return ref.Context().Digest(desc.Digest.String()).String(), nil
+}
+
+// VerifyImage verifies that the given OCI image has at least one cosign signature
+// which is trusted by the verifier and returns the digest of the verified image.
+// The image should be pinned with ResolveImageDigest beforehand, otherwise it is
+// up to the caller to fetch the image by the returned digest.
+func VerifyImage(image string, v Verifier) (string, error) {
+	pinned, err := ResolveImageDigest(context.Background(), image)
+	if err != nil {
+		return "", err
+	}
+	ref, err := regname.NewDigest(pinned)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to parse image reference %q", pinned)
+	}
+	digest := ref.DigestStr()
+	sigTag, err := signatureTag(ref)
+	if err != nil {
+		return "", err
+	}
+	options, err := remoteOptions(context.Background())
+	if err != nil {
+		return "", err
+	}
+	sigImage, err := remote.Image(sigTag, options...)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to find signature for image %q", image)
+	}
+	if err := verifySignatureImage(image, sigImage, digest, v); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// verifySignatureImage verifies that the cosign signature image has at least one
+// signature of the image digest which is trusted by the verifier
+func verifySignatureImage(image string, sigImage v1.Image, digest string, v Verifier) error {
+	manifest, err := sigImage.Manifest()
+	if err != nil {
+		return errors.Wrapf(err, "unable to read signature manifest for image %q", image)
+	}
+
+	var errList []error
+	for _, layer := range manifest.Layers {
+		b64Sig, ok := layer.Annotations[SignatureAnnotationKey]
+		if !ok {
+			continue
+		}
+		l, err := sigImage.LayerByDigest(layer.Digest)
+		if err != nil {
+			errList = append(errList, err)
+			continue
+		}
+		payload, err := readLayer(l.Compressed)
+		if err != nil {
+			errList = append(errList, err)
+			continue
+		}
+
+		sig := Signature{
+			Base64Signature: b64Sig,
+			Certificate:     []byte(layer.Annotations[CertificateAnnotationKey]),
+			Chain:           []byte(layer.Annotations[ChainAnnotationKey]),
+		}
+		if err := v.Verify(payload, sig); err != nil {
+			errList = append(errList, err)
+			continue
+		}
+		if err := verifyPayloadDigest(payload, digest); err != nil {
+			errList = append(errList, err)
+			continue
+		}
+		return nil
+	}
+
+	if len(errList) == 0 {
+		return errors.Errorf("no signature found for image %q", image)
+	}
+	return errors.Wrapf(kerrors.NewAggregate(errList), "no valid signature found for image %q", image)
+}
+
+func readLayer(open func() (io.ReadCloser, error)) ([]byte, error) {
+	rc, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func verifyPayloadDigest(payload []byte, digest string) error {
+	var ssp simpleSigningPayload
+	if err := json.Unmarshal(payload, &ssp); err != nil {
+		return errors.Wrap(err, "unable to unmarshal signature payload")
+	}
+	if ssp.Critical.Image.DockerManifestDigest != digest {
+		return errors.Errorf("signature is for image digest %q instead of %q", ssp.Critical.Image.DockerManifestDigest, digest)
+	}
+	return nil
+}
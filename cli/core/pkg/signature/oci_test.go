@@ -0,0 +1,108 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const simpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+
+// pushImage pushes a random image to the registry and returns the image reference and digest
+func pushImage(t *testing.T, host, repo string) (string, string) {
+	img, err := random.Image(64, 1)
+	assert.Nil(t, err)
+	ref, err := name.ParseReference(fmt.Sprintf("%s/%s:v1.0.0", host, repo))
+	assert.Nil(t, err)
+	assert.Nil(t, remote.Write(ref, img))
+	digest, err := img.Digest()
+	assert.Nil(t, err)
+	return ref.String(), digest.String()
+}
+
+// pushSignature attaches a cosign signature for the payload digest to the image with the given digest
+// in the same way as `cosign sign`
+func pushSignature(t *testing.T, host, repo, digest, payloadDigest string, sign func(payload []byte) string) {
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"%s/%s"},"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"},"optional":null}`, host, repo, payloadDigest))
+	layer := static.NewLayer(payload, simpleSigningMediaType)
+	sigImage, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       layer,
+		Annotations: map[string]string{SignatureAnnotationKey: sign(payload)},
+		MediaType:   types.MediaType(simpleSigningMediaType),
+	})
+	assert.Nil(t, err)
+	h, err := name.NewDigest(fmt.Sprintf("%s/%s@%s", host, repo, digest))
+	assert.Nil(t, err)
+	sigTag := h.Context().Tag(fmt.Sprintf("sha256-%s.sig", h.DigestStr()[len("sha256:"):]))
+	assert.Nil(t, remote.Write(sigTag, sigImage))
+}
+
+func TestVerifyImage(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	assert.Nil(err)
+	host := u.Host
+
+	key, publicKeyPEM := generateECDSAKey(t)
+	v, err := NewPublicKeyVerifier(publicKeyPEM)
+	assert.Nil(err)
+	signWithKey := func(payload []byte) string { return signECDSA(t, key, payload) }
+
+	// Signed image
+	image, digest := pushImage(t, host, "plugins/signed")
+	pushSignature(t, host, "plugins/signed", digest, digest, signWithKey)
+	verifiedDigest, err := VerifyImage(image, v)
+	assert.Nil(err)
+	assert.Equal(digest, verifiedDigest)
+
+	// Image pinned to its digest keeps being verified after its tag is moved
+	pinned, err := ResolveImageDigest(context.Background(), image)
+	assert.Nil(err)
+	assert.Equal(fmt.Sprintf("%s/plugins/signed@%s", host, digest), pinned)
+	pushImage(t, host, "plugins/signed")
+	verifiedDigest, err = VerifyImage(pinned, v)
+	assert.Nil(err)
+	assert.Equal(digest, verifiedDigest)
+	_, err = VerifyImage(image, v)
+	assert.NotNil(err)
+
+	// Unsigned image
+	image, _ = pushImage(t, host, "plugins/unsigned")
+	_, err = VerifyImage(image, v)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "unable to find signature for image")
+
+	// Image signed with a different key
+	otherKey, _ := generateECDSAKey(t)
+	image, digest = pushImage(t, host, "plugins/other-key")
+	pushSignature(t, host, "plugins/other-key", digest, digest, func(payload []byte) string { return signECDSA(t, otherKey, payload) })
+	_, err = VerifyImage(image, v)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "no valid signature found for image")
+
+	// Signature of another image copied to the image
+	image, digest = pushImage(t, host, "plugins/copied")
+	_, otherDigest := pushImage(t, host, "plugins/source")
+	pushSignature(t, host, "plugins/copied", digest, otherDigest, signWithKey)
+	_, err = VerifyImage(image, v)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "signature is for image digest")
+}
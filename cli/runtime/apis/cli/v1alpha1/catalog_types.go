@@ -45,6 +45,17 @@ func (pa PluginAssociation) Map() map[string]string {
 	return pa
 }
 
+// PluginInstallation is an installation of a plugin which has been activated in a catalog.
+type PluginInstallation struct {
+	// InstallationPath is the installation path of the plugin binary.
+	InstallationPath string `json:"installationPath"`
+	// InstalledAt is the time at which the installation was activated.
+	InstalledAt metav1.Time `json:"installedAt,omitempty"`
+}
+
+// PluginHistory is the list of activated installations by plugin name, most recent first.
+type PluginHistory map[string][]PluginInstallation
+
 // +kubebuilder:object:generate=false
 
 // Hook is the mechanism used to define function for plugin hooks
@@ -162,6 +173,13 @@ type PluginDescriptor struct {
 
 	// DefaultFeatureFlags is default featureflags to be configured if missing when invoking plugin
 	DefaultFeatureFlags map[string]bool `json:"defaultFeatureFlags"`
+
+	// Requirements are the CLI core compatibility and plugin dependency requirements of the plugin.
+	Requirements *cliv1alpha1.PluginRequirements `json:"requirements,omitempty" yaml:"requirements,omitempty"`
+
+	// Permissions are the network endpoints, config keys, environment variables and credentials
+	// the plugin needs. Plugins which do not declare permissions are run with full access.
+	Permissions *cliv1alpha1.PluginPermissions `json:"permissions,omitempty" yaml:"permissions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -183,6 +201,10 @@ type Catalog struct {
 	StandAlonePlugins PluginAssociation `json:"standAlonePlugins,omitempty"`
 	// ServerPlugins links a server and a set of associated plugin installations.
 	ServerPlugins map[string]PluginAssociation `json:"serverPlugins,omitempty"`
+	// StandAlonePluginHistory is the installation history of the stand-alone plugins.
+	StandAlonePluginHistory PluginHistory `json:"standAlonePluginHistory,omitempty"`
+	// ServerPluginHistory links a server and the installation history of its plugins.
+	ServerPluginHistory map[string]PluginHistory `json:"serverPluginHistory,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -9,6 +9,7 @@
 package v1alpha1
 
 import (
+	clitanzuvmwarecomv1alpha1 "github.com/vmware-tanzu/tanzu-framework/apis/cli/v1alpha1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -74,6 +75,50 @@ func (in *Catalog) DeepCopyInto(out *Catalog) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.StandAlonePluginHistory != nil {
+		in, out := &in.StandAlonePluginHistory, &out.StandAlonePluginHistory
+		*out = make(PluginHistory, len(*in))
+		for key, val := range *in {
+			var outVal []PluginInstallation
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]PluginInstallation, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ServerPluginHistory != nil {
+		in, out := &in.ServerPluginHistory, &out.ServerPluginHistory
+		*out = make(map[string]PluginHistory, len(*in))
+		for key, val := range *in {
+			var outVal map[string][]PluginInstallation
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(PluginHistory, len(*in))
+				for key, val := range *in {
+					var outVal []PluginInstallation
+					if val == nil {
+						(*out)[key] = nil
+					} else {
+						in, out := &val, &outVal
+						*out = make([]PluginInstallation, len(*in))
+						for i := range *in {
+							(*in)[i].DeepCopyInto(&(*out)[i])
+						}
+					}
+					(*out)[key] = outVal
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Catalog.
@@ -166,6 +211,53 @@ func (in PluginAssociation) DeepCopy() PluginAssociation {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in PluginHistory) DeepCopyInto(out *PluginHistory) {
+	{
+		in := &in
+		*out = make(PluginHistory, len(*in))
+		for key, val := range *in {
+			var outVal []PluginInstallation
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]PluginInstallation, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginHistory.
+func (in PluginHistory) DeepCopy() PluginHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginHistory)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginInstallation) DeepCopyInto(out *PluginInstallation) {
+	*out = *in
+	in.InstalledAt.DeepCopyInto(&out.InstalledAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginInstallation.
+func (in *PluginInstallation) DeepCopy() *PluginInstallation {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginInstallation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PluginDescriptor) DeepCopyInto(out *PluginDescriptor) {
 	*out = *in
@@ -187,6 +279,16 @@ func (in *PluginDescriptor) DeepCopyInto(out *PluginDescriptor) {
 			(*out)[key] = val
 		}
 	}
+	if in.Requirements != nil {
+		in, out := &in.Requirements, &out.Requirements
+		*out = new(clitanzuvmwarecomv1alpha1.PluginRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = new(clitanzuvmwarecomv1alpha1.PluginPermissions)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginDescriptor.
@@ -84,6 +84,22 @@ func (c *ClientConfig) HasContext(name string) bool {
 	return err == nil
 }
 
+// GetProfile by name.
+func (c *ClientConfig) GetProfile(name string) (*Profile, error) {
+	for _, p := range c.Profiles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("could not find profile %q", name)
+}
+
+// HasProfile tells whether the Profile by the given name exists.
+func (c *ClientConfig) HasProfile(name string) bool {
+	_, err := c.GetProfile(name)
+	return err == nil
+}
+
 // GetCurrentContext returns the current context for the given type.
 func (c *ClientConfig) GetCurrentContext(ctxType ContextType) (*Context, error) {
 	ctxName := c.CurrentContext[ctxType]
@@ -142,6 +142,10 @@ type GlobalServerAuth struct {
 
 	// Type of the token (user or client).
 	Type string `json:"type" yaml:"type,omitempty"`
+
+	// CredentialRef is the reference of the tokens in the credential store. The tokens
+	// are not kept in the config file when they are stored in the credential store.
+	CredentialRef string `json:"credentialRef,omitempty" yaml:"credentialRef,omitempty"`
 }
 
 // ClientOptions are the client specific options.
@@ -192,6 +196,8 @@ type PluginDiscovery struct {
 	Kubernetes *KubernetesDiscovery `json:"k8s,omitempty" yaml:"k8s,omitempty"`
 	// LocalDiscovery is set if the plugins are to be discovered via Local Manifest fast.
 	Local *LocalDiscovery `json:"local,omitempty" yaml:"local,omitempty"`
+	// GitDiscovery is set if the plugins are to be discovered via a git repository.
+	Git *GitDiscovery `json:"git,omitempty" yaml:"git,omitempty"`
 }
 
 // GCPDiscovery provides a plugin discovery mechanism via a Google Cloud Storage
@@ -257,6 +263,22 @@ type LocalDiscovery struct {
 	Path string `json:"path,omitempty" yaml:"path,omitempty"`
 }
 
+// GitDiscovery provides a plugin discovery mechanism via a git repository
+// containing YAML files, each of which contains single CLIPlugin API resource.
+type GitDiscovery struct {
+	// Name is a name of the discovery
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// URL is the URL of the git repository.
+	// E.g., https://github.com/my-org/tanzu-plugins.git
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+	// Ref is the branch, tag or commit to read the plugin manifests from.
+	// Defaults to the default branch of the repository.
+	Ref string `json:"ref,omitempty" yaml:"ref,omitempty"`
+	// Path is the directory in the repository containing the plugin manifests.
+	// Defaults to the root of the repository.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
 // PluginRepository is a CLI plugin repository
 type PluginRepository struct {
 	// GCPPluginRepository is a plugin repository that utilizes GCP cloud storage.
@@ -297,6 +319,29 @@ type ClientConfig struct {
 
 	// ClientOptions are client specific options like feature flags, env variables, repositories , discoverySources etc.
 	ClientOptions *ClientOptions `json:"clientOptions,omitempty" yaml:"clientOptions,omitempty"`
+
+	// Profiles are named bundles of client options overlaying the ClientOptions.
+	Profiles []*Profile `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+
+	// CurrentProfile is the name of the profile in use, overridden by the TANZU_PROFILE env variable.
+	CurrentProfile string `json:"currentProfile,omitempty" yaml:"currentProfile,omitempty"`
+}
+
+// Profile is a named bundle of env variables, feature flags, discovery sources, repositories
+// and edition overlaying the ClientOptions when the profile is in use.
+type Profile struct {
+	// Name of the profile.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Env variables overriding the env variables of the same name.
+	Env map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	// Features overriding the feature flags of the same plugin and name.
+	Features map[string]FeatureMap `json:"features,omitempty" yaml:"features,omitempty"`
+	// DiscoverySources replacing the discovery sources of the same name, the other sources are added.
+	DiscoverySources []PluginDiscovery `json:"discoverySources,omitempty" yaml:"discoverySources,omitempty"`
+	// Repositories replacing the repositories of the same name, the other repositories are added.
+	Repositories []PluginRepository `json:"repositories,omitempty" yaml:"repositories,omitempty"`
+	// Edition overriding the edition.
+	Edition EditionSelector `json:"edition,omitempty" yaml:"edition,omitempty"`
 }
 
 // +kubebuilder:object:root=true
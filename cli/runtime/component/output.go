@@ -4,14 +4,17 @@
 package component
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/olekukonko/tablewriter"
 	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/util/jsonpath"
 )
 
 const (
@@ -38,6 +41,16 @@ const (
 	JSONOutputType OutputType = "json"
 	// ListTableOutputType specified output should be in a list table format.
 	ListTableOutputType OutputType = "listtable"
+	// WideOutputType specifies output should be in table format including the wide columns.
+	WideOutputType OutputType = "wide"
+	// CSVOutputType specifies output should be in csv format.
+	CSVOutputType OutputType = "csv"
+	// JSONPathOutputType specifies output should be rendered with a jsonpath
+	// expression, given as "jsonpath=<expression>".
+	JSONPathOutputType OutputType = "jsonpath"
+	// TemplateOutputType specifies output should be rendered with a go template,
+	// given as "go-template=<template>" or "template=<template>".
+	TemplateOutputType OutputType = "go-template"
 )
 
 // outputwriter is our internal implementation.
@@ -46,19 +59,56 @@ type outputwriter struct {
 	keys         []string
 	values       [][]string
 	outputFormat OutputType
+	template     string
+	columns      map[string]Column
+	sortBy       string
+	descending   bool
+	filters      []string
+	noHeaders    bool
 }
 
 // NewOutputWriter gets a new instance of our output writer.
 func NewOutputWriter(output io.Writer, outputFormat string, headers ...string) OutputWriter {
+	return NewOutputWriterWithOptions(output, outputFormat, headers)
+}
+
+// NewOutputWriterWithOptions gets a new instance of our output writer configured
+// with the options, e.g. the types of the columns and the default sorting.
+func NewOutputWriterWithOptions(output io.Writer, outputFormat string, headers []string, opts ...OutputWriterOption) OutputWriter {
 	// Initialize the output writer that we use under the covers
 	ow := &outputwriter{}
 	ow.out = output
-	ow.outputFormat = OutputType(outputFormat)
+	ow.outputFormat, ow.template = parseOutputFormat(outputFormat)
 	ow.keys = headers
+	for _, opt := range opts {
+		opt(ow)
+	}
 
 	return ow
 }
 
+// parseOutputFormat splits the output format into the output type and the
+// jsonpath expression or go template of the jsonpath and go-template formats.
+func parseOutputFormat(outputFormat string) (OutputType, string) {
+	format, tmpl, _ := strings.Cut(outputFormat, "=")
+	switch OutputType(format) {
+	case JSONPathOutputType:
+		return JSONPathOutputType, tmpl
+	case TemplateOutputType, "template":
+		return TemplateOutputType, tmpl
+	}
+	return OutputType(outputFormat), ""
+}
+
+// isMachineReadable returns true if the output type is meant to be consumed by scripts
+func isMachineReadable(outputFormat OutputType) bool {
+	switch outputFormat {
+	case JSONOutputType, YAMLOutputType, CSVOutputType, JSONPathOutputType, TemplateOutputType:
+		return true
+	}
+	return false
+}
+
 // SetKeys sets the values to use as the keys for the output values.
 func (ow *outputwriter) SetKeys(headerKeys ...string) {
 	// Overwrite whatever was used in initialization
@@ -78,32 +128,46 @@ func (ow *outputwriter) AddRow(items ...interface{}) {
 
 // Render emits the generated table to the output once ready
 func (ow *outputwriter) Render() {
+	if err := ow.applyFilters(); err != nil {
+		fmt.Fprint(ow.out, err)
+		return
+	}
+	ow.sortRows()
+
 	switch ow.outputFormat {
 	case JSONOutputType:
 		renderJSON(ow.out, ow.dataStruct())
 	case YAMLOutputType:
 		renderYAML(ow.out, ow.dataStruct())
+	case JSONPathOutputType:
+		renderJSONPath(ow.out, ow.template, ow.dataStruct())
+	case TemplateOutputType:
+		renderTemplate(ow.out, ow.template, ow.dataStruct())
+	case CSVOutputType:
+		renderCSV(ow)
 	case ListTableOutputType:
 		renderListTable(ow)
-	default:
+	case WideOutputType:
 		renderTable(ow)
+	default:
+		renderTable(ow.withoutWideColumns())
 	}
 }
 
-func (ow *outputwriter) dataStruct() []map[string]string {
-	data := []map[string]string{}
-	keys := ow.keys
-	for i, k := range keys {
+func (ow *outputwriter) dataStruct() []map[string]interface{} {
+	data := []map[string]interface{}{}
+	keys := make([]string, len(ow.keys))
+	for i, k := range ow.keys {
 		keys[i] = strings.ToLower(strings.ReplaceAll(k, " ", "_"))
 	}
 
 	for _, itemValues := range ow.values {
-		item := map[string]string{}
+		item := map[string]interface{}{}
 		for i, value := range itemValues {
 			if i == len(keys) {
 				continue
 			}
-			item[keys[i]] = value
+			item[keys[i]] = ow.column(i).typedValue(value)
 		}
 		data = append(data, item)
 	}
@@ -143,11 +207,16 @@ func (obw *objectwriter) AddRow(items ...interface{}) {
 
 // Render emits the generated table to the output once ready
 func (obw *objectwriter) Render() {
-	switch obw.outputFormat {
+	outputFormat, tmpl := parseOutputFormat(string(obw.outputFormat))
+	switch outputFormat {
 	case JSONOutputType:
 		renderJSON(obw.out, obw.data)
 	case YAMLOutputType:
 		renderYAML(obw.out, obw.data)
+	case JSONPathOutputType:
+		renderJSONPath(obw.out, tmpl, obw.data)
+	case TemplateOutputType:
+		renderTemplate(obw.out, tmpl, obw.data)
 	default:
 		fmt.Fprintf(obw.out, "Invalid output format: %v\n", obw.outputFormat)
 	}
@@ -175,6 +244,71 @@ func renderYAML(out io.Writer, data interface{}) {
 	fmt.Fprintf(out, "%s", yamlInBytes)
 }
 
+// toJSONObject converts the data into its generic json representation, so that
+// jsonpath expressions and templates refer to the json field names of the data
+func toJSONObject(data interface{}) (interface{}, error) {
+	bytesJSON, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var obj interface{}
+	if err := json.Unmarshal(bytesJSON, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// renderJSONPath prints output with a jsonpath expression, e.g. "{.items[*].name}"
+func renderJSONPath(out io.Writer, expression string, data interface{}) {
+	obj, err := toJSONObject(data)
+	if err != nil {
+		fmt.Fprint(out, err)
+		return
+	}
+	jp := jsonpath.New("output")
+	if err := jp.Parse(expression); err != nil {
+		fmt.Fprintf(out, "error parsing jsonpath %q: %v", expression, err)
+		return
+	}
+	if err := jp.Execute(out, obj); err != nil {
+		fmt.Fprint(out, err)
+	}
+}
+
+// renderTemplate prints output with a go template, e.g. "{{range .}}{{.name}}{{end}}"
+func renderTemplate(out io.Writer, text string, data interface{}) {
+	obj, err := toJSONObject(data)
+	if err != nil {
+		fmt.Fprint(out, err)
+		return
+	}
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		fmt.Fprintf(out, "error parsing template %q: %v", text, err)
+		return
+	}
+	if err := tmpl.Execute(out, obj); err != nil {
+		fmt.Fprint(out, err)
+	}
+}
+
+// renderCSV prints output as comma separated values
+func renderCSV(ow *outputwriter) {
+	w := csv.NewWriter(ow.out)
+	if !ow.noHeaders {
+		_ = w.Write(ow.keys)
+	}
+	for _, values := range ow.values {
+		row := make([]string, len(ow.keys))
+		copy(row, values)
+		_ = w.Write(row)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		fmt.Fprint(ow.out, err)
+	}
+}
+
 // renderListTable prints output as a list table.
 func renderListTable(ow *outputwriter) {
 	headerLength := 10
@@ -221,7 +355,9 @@ func renderTable(ow *outputwriter) {
 	table.SetHeaderLine(false)
 	table.SetColWidth(colWidth)
 	table.SetTablePadding("\t\t")
-	table.SetHeader(ow.keys)
+	if !ow.noHeaders {
+		table.SetHeader(ow.keys)
+	}
 	table.AppendBulk(ow.values)
 	table.Render()
 }
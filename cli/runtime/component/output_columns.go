@@ -0,0 +1,260 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package component
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ColumnType defines the type of the values of a column.
+type ColumnType string
+
+const (
+	// StringColumnType specifies the values of the column are strings.
+	StringColumnType ColumnType = "string"
+	// NumberColumnType specifies the values of the column are numbers.
+	NumberColumnType ColumnType = "number"
+	// BoolColumnType specifies the values of the column are booleans.
+	BoolColumnType ColumnType = "bool"
+	// TimeColumnType specifies the values of the column are RFC 3339 timestamps.
+	TimeColumnType ColumnType = "time"
+)
+
+// Column describes a column of the output.
+type Column struct {
+	// Name is the header of the column
+	Name string
+	// Type is the type of the values of the column, StringColumnType by default.
+	// Values of number and bool columns are rendered as numbers and booleans
+	// in the json, yaml, jsonpath and go-template outputs, and the values of
+	// the columns are sorted and compared according to their type.
+	Type ColumnType
+	// Wide columns are only rendered by the wide output type
+	// and hidden in the table output type.
+	Wide bool
+}
+
+// OutputWriterOption is an option of the output writer.
+type OutputWriterOption func(*outputwriter)
+
+// WithColumns declares the type of the columns of the output writer.
+func WithColumns(columns ...Column) OutputWriterOption {
+	return func(ow *outputwriter) {
+		if ow.columns == nil {
+			ow.columns = make(map[string]Column)
+		}
+		for _, c := range columns {
+			ow.columns[columnKey(c.Name)] = c
+		}
+	}
+}
+
+// WithSortBy sorts the rows by the values of the column.
+func WithSortBy(column string, descending bool) OutputWriterOption {
+	return func(ow *outputwriter) {
+		ow.sortBy = column
+		ow.descending = descending
+	}
+}
+
+// WithFilters only renders the rows matching all the filters. A filter is an
+// expression "<column>=<value>", "<column>!=<value>", "<column><<value>" or
+// "<column>><value>", where the values are compared according to the column type.
+func WithFilters(filters ...string) OutputWriterOption {
+	return func(ow *outputwriter) {
+		ow.filters = append(ow.filters, filters...)
+	}
+}
+
+// WithNoHeaders omits the headers from the table, wide and csv outputs.
+func WithNoHeaders() OutputWriterOption {
+	return func(ow *outputwriter) {
+		ow.noHeaders = true
+	}
+}
+
+func columnKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, " ", "_"))
+}
+
+// column returns the column declared for the i-th key
+func (ow *outputwriter) column(i int) Column {
+	if i >= len(ow.keys) {
+		return Column{Type: StringColumnType}
+	}
+	if c, ok := ow.columns[columnKey(ow.keys[i])]; ok {
+		return c
+	}
+	return Column{Name: ow.keys[i], Type: StringColumnType}
+}
+
+// keyIndex returns the index of the key of the column, or -1
+func (ow *outputwriter) keyIndex(name string) int {
+	for i, key := range ow.keys {
+		if columnKey(key) == columnKey(name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// withoutWideColumns returns a copy of the output writer without the wide columns
+func (ow *outputwriter) withoutWideColumns() *outputwriter {
+	var wide []bool
+	hasWide := false
+	for i := range ow.keys {
+		wide = append(wide, ow.column(i).Wide)
+		hasWide = hasWide || ow.column(i).Wide
+	}
+	if !hasWide {
+		return ow
+	}
+
+	narrow := *ow
+	narrow.keys = nil
+	for i, key := range ow.keys {
+		if !wide[i] {
+			narrow.keys = append(narrow.keys, key)
+		}
+	}
+	narrow.values = nil
+	for _, values := range ow.values {
+		row := []string{}
+		for i, value := range values {
+			if i >= len(wide) || !wide[i] {
+				row = append(row, value)
+			}
+		}
+		narrow.values = append(narrow.values, row)
+	}
+	return &narrow
+}
+
+// applyFilters drops the rows which do not match the filters
+func (ow *outputwriter) applyFilters() error {
+	for _, filter := range ow.filters {
+		name, op, value, err := parseFilter(filter)
+		if err != nil {
+			return err
+		}
+		index := ow.keyIndex(name)
+		if index == -1 {
+			return fmt.Errorf("invalid filter %q: unknown column %q", filter, name)
+		}
+		column := ow.column(index)
+
+		values := [][]string{}
+		for _, row := range ow.values {
+			cell := ""
+			if index < len(row) {
+				cell = row[index]
+			}
+			cmp := column.compare(cell, value)
+			if (op == "=" && cmp == 0) || (op == "!=" && cmp != 0) || (op == "<" && cmp < 0) || (op == ">" && cmp > 0) {
+				values = append(values, row)
+			}
+		}
+		ow.values = values
+	}
+	return nil
+}
+
+func parseFilter(filter string) (name, op, value string, err error) {
+	for _, op := range []string{"!=", "=", "<", ">"} {
+		if name, value, found := strings.Cut(filter, op); found && name != "" {
+			return strings.TrimSpace(name), op, strings.TrimSpace(value), nil
+		}
+	}
+	return "", "", "", fmt.Errorf("invalid filter %q: expected <column>=<value>, <column>!=<value>, <column><<value> or <column>><value>", filter)
+}
+
+// sortRows sorts the rows by the values of the sort column
+func (ow *outputwriter) sortRows() {
+	if ow.sortBy == "" {
+		return
+	}
+	index := ow.keyIndex(ow.sortBy)
+	if index == -1 {
+		return
+	}
+	column := ow.column(index)
+	cell := func(row []string) string {
+		if index < len(row) {
+			return row[index]
+		}
+		return ""
+	}
+	sort.SliceStable(ow.values, func(i, j int) bool {
+		cmp := column.compare(cell(ow.values[i]), cell(ow.values[j]))
+		if ow.descending {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+}
+
+// typedValue converts the value to the type of the column. Values which
+// cannot be converted are kept as strings.
+func (c Column) typedValue(value string) interface{} {
+	switch c.Type {
+	case NumberColumnType:
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case BoolColumnType:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// compare compares the values according to the type of the column. Values which
+// cannot be converted to the type of the column are compared as strings.
+func (c Column) compare(a, b string) int {
+	switch c.Type {
+	case NumberColumnType:
+		x, errA := strconv.ParseFloat(a, 64)
+		y, errB := strconv.ParseFloat(b, 64)
+		if errA == nil && errB == nil {
+			return compareFloat(x, y)
+		}
+	case BoolColumnType:
+		x, errA := strconv.ParseBool(a)
+		y, errB := strconv.ParseBool(b)
+		if errA == nil && errB == nil {
+			return strings.Compare(strconv.FormatBool(x), strconv.FormatBool(y))
+		}
+	case TimeColumnType:
+		x, errA := time.Parse(time.RFC3339, a)
+		y, errB := time.Parse(time.RFC3339, b)
+		if errA == nil && errB == nil {
+			switch {
+			case x.Before(y):
+				return -1
+			case x.After(y):
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -31,9 +31,9 @@ type outputwriterspinner struct {
 func NewOutputWriterWithSpinner(output io.Writer, outputFormat, spinnerText string, startSpinner bool, headers ...string) (OutputWriterSpinner, error) {
 	ows := &outputwriterspinner{}
 	ows.out = output
-	ows.outputFormat = OutputType(outputFormat)
+	ows.outputFormat, ows.template = parseOutputFormat(outputFormat)
 	ows.keys = headers
-	if ows.outputFormat != JSONOutputType && ows.outputFormat != YAMLOutputType {
+	if !isMachineReadable(ows.outputFormat) {
 		ows.spinnerText = spinnerText
 		ows.spinner = spinner.New(spinner.CharSets[9], 100*time.Millisecond)
 		if err := ows.spinner.Color("bgBlack", "bold", "fgWhite"); err != nil {
@@ -317,3 +317,128 @@ type testStruct struct {
 	Name      string `json:"name,omitempty" yaml:"name,omitempty"`
 	Namespace string `json:"spacename,omitempty" yaml:"spacename,omitempty"`
 }
+
+func TestOutputWriterJSONPath(t *testing.T) {
+	var b bytes.Buffer
+	tab := NewOutputWriter(&b, `jsonpath={range [*]}{.name}{"\t"}{.status}{"\n"}{end}`, "Name", "Status")
+	require.NotNil(t, tab)
+	tab.AddRow("cluster-1", "running")
+	tab.AddRow("cluster-2", "creating")
+	tab.Render()
+
+	require.Equal(t, "cluster-1\trunning\ncluster-2\tcreating\n", b.String())
+}
+
+func TestOutputWriterTemplate(t *testing.T) {
+	var b bytes.Buffer
+	tab := NewOutputWriterWithOptions(&b, `go-template={{range .}}{{.name}}={{.workers}},{{end}}`, []string{"Name", "Workers"},
+		WithColumns(Column{Name: "Workers", Type: NumberColumnType}))
+	require.NotNil(t, tab)
+	tab.AddRow("cluster-1", 3)
+	tab.AddRow("cluster-2", 1)
+	tab.Render()
+
+	require.Equal(t, "cluster-1=3,cluster-2=1,", b.String())
+}
+
+func TestOutputWriterInvalidJSONPath(t *testing.T) {
+	var b bytes.Buffer
+	tab := NewOutputWriter(&b, `jsonpath={.name`, "Name")
+	tab.AddRow("cluster-1")
+	tab.Render()
+
+	require.Contains(t, b.String(), "error parsing jsonpath")
+}
+
+func TestOutputWriterCSV(t *testing.T) {
+	var b bytes.Buffer
+	tab := NewOutputWriter(&b, string(CSVOutputType), "Name", "Roles")
+	require.NotNil(t, tab)
+	tab.AddRow("cluster-1", "control-plane,worker")
+	tab.AddRow("cluster-2")
+	tab.Render()
+
+	require.Equal(t, "Name,Roles\ncluster-1,\"control-plane,worker\"\ncluster-2,\n", b.String())
+
+	b.Reset()
+	tab = NewOutputWriterWithOptions(&b, string(CSVOutputType), []string{"Name"}, WithNoHeaders())
+	tab.AddRow("cluster-1")
+	tab.Render()
+	require.Equal(t, "cluster-1\n", b.String())
+}
+
+func TestOutputWriterWideColumns(t *testing.T) {
+	opts := []OutputWriterOption{WithColumns(Column{Name: "Endpoint", Wide: true})}
+
+	var b bytes.Buffer
+	tab := NewOutputWriterWithOptions(&b, string(TableOutputType), []string{"Name", "Endpoint", "Status"}, opts...)
+	tab.AddRow("cluster-1", "https://cluster-1:6443", "running")
+	tab.Render()
+	lines := strings.Split(b.String(), "\n")
+	require.Equal(t, 3, len(lines), "%v", lines)
+	require.NotContains(t, lines[0], "ENDPOINT")
+	require.Contains(t, lines[1], "running")
+	require.NotContains(t, lines[1], "https://cluster-1:6443")
+
+	b.Reset()
+	tab = NewOutputWriterWithOptions(&b, string(WideOutputType), []string{"Name", "Endpoint", "Status"}, append(opts, WithNoHeaders())...)
+	tab.AddRow("cluster-1", "https://cluster-1:6443", "running")
+	tab.Render()
+	lines = strings.Split(b.String(), "\n")
+	require.Equal(t, 2, len(lines), "%v", lines)
+	require.Contains(t, lines[0], "https://cluster-1:6443")
+	require.Contains(t, lines[0], "running")
+}
+
+func TestOutputWriterTypedColumns(t *testing.T) {
+	var b bytes.Buffer
+	tab := NewOutputWriterWithOptions(&b, string(JSONOutputType), []string{"Name", "Workers", "Ready", "Created"},
+		WithColumns(
+			Column{Name: "Workers", Type: NumberColumnType},
+			Column{Name: "Ready", Type: BoolColumnType},
+			Column{Name: "Created", Type: TimeColumnType},
+		),
+		WithSortBy("workers", true),
+		WithFilters("ready=true", "created>2022-01-01T00:00:00Z"))
+	tab.AddRow("cluster-1", 2, true, "2022-03-01T00:00:00Z")
+	tab.AddRow("cluster-2", 10, true, "2022-02-01T00:00:00Z")
+	tab.AddRow("cluster-3", 5, false, "2022-02-01T00:00:00Z")
+	tab.AddRow("cluster-4", 7, true, "2021-12-01T00:00:00Z")
+	tab.Render()
+
+	require.JSONEq(t, `[
+		{"name": "cluster-2", "workers": 10, "ready": true, "created": "2022-02-01T00:00:00Z"},
+		{"name": "cluster-1", "workers": 2, "ready": true, "created": "2022-03-01T00:00:00Z"}
+	]`, b.String())
+}
+
+func TestOutputWriterInvalidFilter(t *testing.T) {
+	var b bytes.Buffer
+	tab := NewOutputWriterWithOptions(&b, string(TableOutputType), []string{"Name"}, WithFilters("status=running"))
+	tab.AddRow("cluster-1")
+	tab.Render()
+	require.Equal(t, `invalid filter "status=running": unknown column "status"`, b.String())
+
+	b.Reset()
+	tab = NewOutputWriterWithOptions(&b, string(TableOutputType), []string{"Name"}, WithFilters("name"))
+	tab.AddRow("cluster-1")
+	tab.Render()
+	require.Contains(t, b.String(), `invalid filter "name"`)
+}
+
+func TestObjectWriterJSONPath(t *testing.T) {
+	var b bytes.Buffer
+	data := struct {
+		Name     string   `json:"name"`
+		Versions []string `json:"versions"`
+	}{Name: "cluster", Versions: []string{"v0.1.0", "v0.2.0"}}
+
+	obw := NewObjectWriter(&b, "jsonpath={.versions[-1:]}", data)
+	obw.Render()
+	require.Equal(t, "v0.2.0", b.String())
+
+	b.Reset()
+	obw = NewObjectWriter(&b, "template={{.name}}:{{len .versions}}", data)
+	obw.Render()
+	require.Equal(t, "cluster:2", b.String())
+}
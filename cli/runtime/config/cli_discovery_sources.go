@@ -16,7 +16,7 @@ import (
 // GetCLIDiscoverySources retrieves cli discovery sources
 func GetCLIDiscoverySources() ([]configapi.PluginDiscovery, error) {
 	// Retrieve client config node
-	node, err := getClientConfigNode()
+	node, err := getClientConfigNodeWithProfile()
 	if err != nil {
 		return nil, err
 	}
@@ -27,7 +27,7 @@ func GetCLIDiscoverySources() ([]configapi.PluginDiscovery, error) {
 // GetCLIDiscoverySource retrieves cli discovery source by name assuming that there should only be one source with the name, returns the first match
 func GetCLIDiscoverySource(name string) (*configapi.PluginDiscovery, error) {
 	// Retrieve client config node
-	node, err := getClientConfigNode()
+	node, err := getClientConfigNodeWithProfile()
 	if err != nil {
 		return nil, err
 	}
@@ -44,6 +44,12 @@ func SetCLIDiscoverySources(discoverySources []configapi.PluginDiscovery) (err e
 	if err != nil {
 		return err
 	}
+	for _, discoverySource := range discoverySources {
+		_, name := getDiscoverySourceTypeAndName(discoverySource)
+		if err := checkNotProvidedByProfile(node, fmt.Sprintf("discovery source %q", name), profileProvidesDiscoverySource(name)); err != nil {
+			return err
+		}
+	}
 
 	// Loop through each discovery source and add or update existing node
 	for _, discoverySource := range discoverySources {
@@ -71,6 +77,10 @@ func SetCLIDiscoverySource(discoverySource configapi.PluginDiscovery) (err error
 	if err != nil {
 		return err
 	}
+	_, name := getDiscoverySourceTypeAndName(discoverySource)
+	if err := checkNotProvidedByProfile(node, fmt.Sprintf("discovery source %q", name), profileProvidesDiscoverySource(name)); err != nil {
+		return err
+	}
 
 	// Add/Update cli discovery source in the yaml node
 	persist, err := setCLIDiscoverySource(node, discoverySource)
@@ -95,6 +105,9 @@ func DeleteCLIDiscoverySource(name string) error {
 	if err != nil {
 		return err
 	}
+	if err := checkNotProvidedByProfile(node, fmt.Sprintf("discovery source %q", name), profileProvidesDiscoverySource(name)); err != nil {
+		return err
+	}
 
 	// Delete the matching cli discovery source from the yaml node
 	err = deleteCLIDiscoverySource(node, name)
@@ -168,6 +168,7 @@ contexts:
           contextType: tmc
 currentContext:
     k8s: test-mc
+schemaVersion: 2
 `
 	//nolint:goconst
 	expectedCFG2 := `contexts:
@@ -200,6 +201,7 @@ currentContext:
           contextType: tmc
 currentContext:
     k8s: test-mc
+schemaVersion: 2
 `
 
 	return CFG, expectedCFG, CFG2, expectedCFG2
@@ -296,9 +298,10 @@ func setupDataWithPatchStrategy() (string, string, string, string) {
             - oci:
                 name: new-default
                 image: new-default-image
+schemaVersion: 2
 `
 
-	expectedCfg2 := `{}
+	expectedCfg2 := `schemaVersion: 2
 `
 
 	return cfg, expectedCfg, "", expectedCfg2
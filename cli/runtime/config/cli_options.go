@@ -7,13 +7,14 @@ import (
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
 
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
 	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config/nodeutils"
 )
 
 // GetEdition retrieves ClientOptions Edition
 func GetEdition() (string, error) {
 	// Retrieve client config node
-	node, err := getClientConfigNode()
+	node, err := getClientConfigNodeWithProfile()
 	if err != nil {
 		return "", err
 	}
@@ -29,6 +30,9 @@ func SetEdition(val string) (err error) {
 	if err != nil {
 		return err
 	}
+	if err := checkNotProvidedByProfile(node, "edition", func(p *configapi.Profile) bool { return p.Edition != "" }); err != nil {
+		return err
+	}
 
 	// Add or Update edition in the yaml node
 	persist := setEdition(node, val)
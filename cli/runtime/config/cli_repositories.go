@@ -16,7 +16,7 @@ import (
 // GetCLIRepositories retrieves cli repositories
 func GetCLIRepositories() ([]configapi.PluginRepository, error) {
 	// Retrieve client config node
-	node, err := getClientConfigNode()
+	node, err := getClientConfigNodeWithProfile()
 	if err != nil {
 		return nil, err
 	}
@@ -27,7 +27,7 @@ func GetCLIRepositories() ([]configapi.PluginRepository, error) {
 // GetCLIRepository retrieves cli repository by name
 func GetCLIRepository(name string) (*configapi.PluginRepository, error) {
 	// Retrieve client config node
-	node, err := getClientConfigNode()
+	node, err := getClientConfigNodeWithProfile()
 	if err != nil {
 		return nil, err
 	}
@@ -44,6 +44,10 @@ func SetCLIRepository(repository configapi.PluginRepository) (err error) {
 	if err != nil {
 		return err
 	}
+	_, name := getRepositoryTypeAndName(repository)
+	if err := checkNotProvidedByProfile(node, fmt.Sprintf("repository %q", name), profileProvidesRepository(name)); err != nil {
+		return err
+	}
 
 	// Add or update cli repository in the yaml node
 	persist, err := setCLIRepository(node, repository)
@@ -71,6 +75,9 @@ func DeleteCLIRepository(name string) error {
 	if err != nil {
 		return err
 	}
+	if err := checkNotProvidedByProfile(node, fmt.Sprintf("repository %q", name), profileProvidesRepository(name)); err != nil {
+		return err
+	}
 
 	// Delete the matching cli repository from the yaml node
 	err = deleteCLIRepository(node, name)
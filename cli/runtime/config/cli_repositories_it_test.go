@@ -160,6 +160,7 @@ contexts:
           contextType: tmc
 currentContext:
     k8s: test-mc
+schemaVersion: 2
 `
 
 	cfg2 := `contexts:
@@ -223,6 +224,7 @@ currentContext:
           contextType: tmc
 currentContext:
     k8s: test-mc
+schemaVersion: 2
 `
 
 	return cfg, expectedCfg, cfg2, expectedCfg2
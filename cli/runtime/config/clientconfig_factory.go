@@ -19,19 +19,39 @@ import (
 
 // getClientConfigNode retrieves the multi config from the local directory with file lock
 func getClientConfigNode() (*yaml.Node, error) {
+	// Migrate the documents written by older versions of the CLI
+	if err := migrateSchemaIfNeeded(); err != nil {
+		return nil, err
+	}
+
 	useUnifiedConfig, err := UseUnifiedConfig()
 	if err != nil {
 		useUnifiedConfig = false
 	}
 
+	var node *yaml.Node
 	if useUnifiedConfig {
-		return getClientConfigNextGenNode()
+		node, err = getClientConfigNextGenNode()
+	} else {
+		node, err = getMultiConfig()
+	}
+	if err != nil {
+		return nil, err
 	}
-	return getMultiConfig()
+	return node, nil
 }
 
 // getClientConfigNodeNoLock retrieves the multi config from the local directory without acquiring the lock
 func getClientConfigNodeNoLock() (*yaml.Node, error) {
+	// Migrate the documents written by older versions of the CLI
+	if err := migrateSchemaIfNeededNoLock(); err != nil {
+		return nil, err
+	}
+	return readClientConfigNodeNoLock()
+}
+
+// readClientConfigNodeNoLock reads the multi config from the local directory as is, without acquiring the lock
+func readClientConfigNodeNoLock() (*yaml.Node, error) {
 	// Check config migration feature flag
 	useUnifiedConfig, err := UseUnifiedConfig()
 	if err != nil {
@@ -13,6 +13,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config/nodeutils"
 )
 
 func TestClientConfigNodeUpdateInParallel(t *testing.T) {
@@ -62,7 +63,9 @@ func TestClientConfigNodeUpdateInParallel(t *testing.T) {
 			node, err := getClientConfigNode()
 			assert.Nil(t, err)
 			// Make sure all expected servers are added to the knownServers list
-			assert.Equal(t, parallelExecutionCounter, len(node.Content[0].Content[5].Content))
+			contextsNode := nodeutils.FindNode(node.Content[0], nodeutils.WithKeys([]nodeutils.Key{{Name: KeyContexts}}))
+			assert.NotNil(t, contextsNode)
+			assert.Equal(t, parallelExecutionCounter, len(contextsNode.Content))
 		}()
 	}
 }
@@ -84,6 +84,25 @@ func makeMultiFileCfg(cfgNode, cfgNextGenNode *yaml.Node) (*yaml.Node, error) {
 
 // persistConfig write the updated node data to config.yaml and config-ng.yaml based on cfgItems
 func persistConfig(node *yaml.Node) error {
+	// Documents of a newer schema version keep their version
+	version := CurrentSchemaVersion()
+	if v, err := GetSchemaVersion(); err == nil && v > version {
+		version = v
+	}
+	return persistConfigWithSchemaVersion(node, version)
+}
+
+// persistConfigWithSchemaVersion writes the updated node data to config.yaml and config-ng.yaml
+// based on cfgItems, setting the schema version of the documents
+func persistConfigWithSchemaVersion(node *yaml.Node, version int) error {
+	// Move the tokens of the contexts to the credential store, unless the documents are
+	// migrated back to a schema version without credential references
+	if version >= credentialsSchemaVersion {
+		if err := storeCredentials(node); err != nil {
+			return err
+		}
+	}
+
 	// check to persist multi file or to config-ng yaml
 	useUnifiedConfig, err := UseUnifiedConfig()
 	if err != nil {
@@ -92,6 +111,9 @@ func persistConfig(node *yaml.Node) error {
 
 	// If useUnifiedConfig is set to true write to config-ng.yaml
 	if useUnifiedConfig {
+		if err := setSchemaVersion(node, version); err != nil {
+			return err
+		}
 		return persistClientConfigNextGen(node)
 	}
 
@@ -138,6 +160,14 @@ func persistConfig(node *yaml.Node) error {
 		}
 	}
 
+	// Both documents are versioned, as older versions of the CLI may write either
+	if err := setSchemaVersion(cfgNode, version); err != nil {
+		return err
+	}
+	if err := setSchemaVersion(cfgNextGenNode, version); err != nil {
+		return err
+	}
+
 	// Store the non nextGenItem config data to config.yaml
 	err = persistClientConfig(cfgNode)
 	if err != nil {
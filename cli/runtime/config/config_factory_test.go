@@ -184,6 +184,7 @@ contexts:
           contextType: tmc
 currentContext:
     k8s: test-mc
+schemaVersion: 2
 `
 
 	cfgNextGen := `
@@ -248,6 +249,7 @@ currentContext:
           contextType: tmc
 currentContext:
     k8s: test-mc
+schemaVersion: 2
 `
 
 	return cfg, cfgNextGen, expectedCfg, expectedCfgNextGen
@@ -496,6 +498,7 @@ func TestPersistConfigWithMigrateToNewConfig(t *testing.T) {
           contextType: tmc
 currentContext:
     k8s: test-mc
+schemaVersion: 2
 `
 
 	cfgTestFiles, cleanUp := setupTestConfig(t, &CfgTestData{cfg: cfg, cfgNextGen: cfgNextGen, cfgMetadata: setupConfigMetadataWithMigrateToNewConfig()})
@@ -22,4 +22,6 @@ const (
 	KeyAPIVersion              = "apiVersion"
 	KeyBomRepo                 = "bomRepo"
 	KeyCompatibilityFilePath   = "compatibilityFilePath"
+	KeyProfiles                = "profiles"
+	KeyCurrentProfile          = "currentProfile"
 )
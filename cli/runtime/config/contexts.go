@@ -116,6 +116,7 @@ func RemoveContext(name string) error {
 	if err != nil {
 		return err
 	}
+	refs := getCredentialRefs(node, name)
 	err = removeContext(node, name)
 	if err != nil {
 		return err
@@ -128,7 +129,11 @@ func RemoveContext(name string) error {
 	if err != nil {
 		return err
 	}
-	return persistConfig(node)
+	err = persistConfig(node)
+	if err != nil {
+		return err
+	}
+	return deleteCredentials(node, refs...)
 }
 
 // ContextExists checks if context by name already exists
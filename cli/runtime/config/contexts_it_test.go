@@ -82,6 +82,7 @@ servers:
             bucket: test-bucket-updated
             manifestPath: test-manifest-path-updated
 current: test-mc2
+schemaVersion: 2
 `
 	cfg2 := `contexts:
   - name: test-mc
@@ -139,6 +140,7 @@ currentContext:
             manifestPath: test-manifest-path-updated
 currentContext:
     k8s: test-mc2
+schemaVersion: 2
 `
 
 	return cfg, expectedCfg, cfg2, expectedCfg2
@@ -161,6 +161,7 @@ func convertNodeToClientConfig(node *yaml.Node) (obj *configapi.ClientConfig, er
 	if obj == nil {
 		return &configapi.ClientConfig{}, err
 	}
+	resolveCredentials(obj)
 	return obj, err
 }
 
@@ -256,3 +257,17 @@ func convertPluginDiscoveryToNode(obj *configapi.PluginDiscovery) (*yaml.Node, e
 	}
 	return &node, nil
 }
+
+// convertProfileToNode converts profile to yaml node
+func convertProfileToNode(obj *configapi.Profile) (*yaml.Node, error) {
+	bytes, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert obj to node")
+	}
+	var node yaml.Node
+	err = yaml.Unmarshal(bytes, &node)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal bytes to node")
+	}
+	return &node, nil
+}
@@ -0,0 +1,361 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/aunum/log"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config/nodeutils"
+)
+
+const (
+	// EnvCredentialStoreKey is the environment variable that selects the credential store
+	// keeping the tokens of the contexts. It is either CredentialStoreFile (default),
+	// CredentialStoreConfig or the name of a credential helper.
+	EnvCredentialStoreKey = "TANZU_CLI_CREDENTIAL_STORE"
+
+	// CredentialStoreFile keeps the tokens in a file encrypted with AES-256-GCM
+	CredentialStoreFile = "file"
+
+	// CredentialStoreConfig keeps the tokens in plain text in the config file
+	CredentialStoreConfig = "config"
+
+	// EnvCredentialStoreLegacyTokensKey is the environment variable which, set to true,
+	// keeps the tokens of the contexts in the config file besides their credential reference
+	// once they are stored in the credential store, for the plugins built with a runtime
+	// older than the minimum runtime resolving the credential references. The tokens are
+	// removed from the config file by default.
+	EnvCredentialStoreLegacyTokensKey = "TANZU_CLI_CREDENTIAL_STORE_LEGACY_TOKENS"
+
+	// credentialsSchemaVersion is the config schema version from which the tokens of the
+	// contexts are kept in the credential store
+	credentialsSchemaVersion = 2
+)
+
+// Keys of the auth stanza of the config file holding the tokens
+const (
+	keyGlobalOpts    = "globalOpts"
+	keyAuth          = "auth"
+	keyAccessToken   = "accessToken"
+	keyIDToken       = "IDToken"
+	keyRefreshToken  = "refresh_token"
+	keyCredentialRef = "credentialRef"
+)
+
+// ErrCredentialsNotFound is returned by credential stores if there are no credentials for a reference
+var ErrCredentialsNotFound = errors.New("credentials not found")
+
+// Credentials are the tokens of a context kept in the credential store
+type Credentials struct {
+	AccessToken  string `json:"accessToken,omitempty"`
+	IDToken      string `json:"idToken,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+}
+
+// CredentialStore is a backend storing the credentials of contexts by an opaque reference
+type CredentialStore interface {
+	// Get returns the credentials stored for the reference or ErrCredentialsNotFound
+	Get(ref string) (*Credentials, error)
+
+	// Store stores the credentials for the reference
+	Store(ref string, creds *Credentials) error
+
+	// Delete deletes the credentials stored for the reference
+	Delete(ref string) error
+}
+
+// credentialsCache caches the credentials read from the credential store by reference,
+// so that the credential store is accessed once per process for each context
+var (
+	credentialsCache      = map[string]*Credentials{}
+	credentialsCacheMutex sync.Mutex
+)
+
+// unreadableCredentialRefs are the references whose credentials could not be read from
+// the credential store in this process
+var unreadableCredentialRefs sync.Map
+
+// GetCredentialStore returns the credential store selected with EnvCredentialStoreKey.
+// It returns nil if the tokens are kept in the config file.
+func GetCredentialStore() (CredentialStore, error) {
+	switch name := os.Getenv(EnvCredentialStoreKey); name {
+	case "", CredentialStoreFile:
+		path, err := ClientConfigPath()
+		if err != nil {
+			return nil, err
+		}
+		return NewEncryptedFileCredentialStore(filepath.Join(filepath.Dir(path), credentialsFileName)), nil
+	case CredentialStoreConfig:
+		return nil, nil
+	default:
+		return NewHelperCredentialStore(name), nil
+	}
+}
+
+// keepLegacyTokens returns whether the tokens of the contexts are kept in the config file
+// besides their credential reference
+func keepLegacyTokens() bool {
+	keep, _ := strconv.ParseBool(os.Getenv(EnvCredentialStoreLegacyTokensKey))
+	return keep
+}
+
+// resolveCredentials fills the tokens of the contexts and servers of the config with
+// the credentials stored for their references. Credentials which cannot be read from
+// the credential store are left empty and a warning is logged. Tokens kept in the config
+// file take precedence, as they are updated by the plugins which do not resolve the
+// credential references.
+func resolveCredentials(cfg *configapi.ClientConfig) {
+	auths := make(map[*configapi.GlobalServerAuth]string)
+	for _, c := range cfg.KnownContexts {
+		if c != nil && c.GlobalOpts != nil {
+			auths[&c.GlobalOpts.Auth] = c.Name
+		}
+	}
+	for _, s := range cfg.KnownServers {
+		if s != nil && s.GlobalOpts != nil {
+			auths[&s.GlobalOpts.Auth] = s.Name
+		}
+	}
+
+	for auth, name := range auths {
+		if auth.CredentialRef == "" {
+			continue
+		}
+		if auth.AccessToken != "" || auth.IDToken != "" || auth.RefreshToken != "" {
+			auth.CredentialRef = ""
+			continue
+		}
+		creds, err := getCredentials(auth.CredentialRef)
+		if err != nil {
+			// The config is read many times per process, so the warning is logged once per reference
+			if _, warned := unreadableCredentialRefs.LoadOrStore(auth.CredentialRef, true); !warned {
+				log.Warningf("unable to read the credentials of context %q from the credential store: %v", name, err)
+			}
+			continue
+		}
+		auth.AccessToken = creds.AccessToken
+		auth.IDToken = creds.IDToken
+		auth.RefreshToken = creds.RefreshToken
+		auth.CredentialRef = ""
+	}
+}
+
+func getCredentials(ref string) (*Credentials, error) {
+	credentialsCacheMutex.Lock()
+	defer credentialsCacheMutex.Unlock()
+	if creds, ok := credentialsCache[ref]; ok {
+		return creds, nil
+	}
+	store, err := GetCredentialStore()
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return nil, ErrCredentialsNotFound
+	}
+	creds, err := store.Get(ref)
+	if err != nil {
+		return nil, err
+	}
+	credentialsCache[ref] = creds
+	return creds, nil
+}
+
+// storeCredentials moves the tokens of the contexts and servers of the config node to the
+// credential store and adds the reference of the stored credentials. The tokens are
+// kept in the config node as well if EnvCredentialStoreLegacyTokensKey is true.
+// The contexts and servers of the same name share their credentials.
+func storeCredentials(node *yaml.Node) error {
+	authNodes := getAuthNodes(node)
+	if !hasPlaintextCredentials(authNodes) {
+		return nil
+	}
+	store, err := GetCredentialStore()
+	if err != nil || store == nil {
+		return err
+	}
+
+	tokenOf := removeScalarNode
+	if keepLegacyTokens() {
+		tokenOf = getScalarNode
+	}
+	credentialsCacheMutex.Lock()
+	defer credentialsCacheMutex.Unlock()
+	for name, auths := range authNodes {
+		for _, auth := range auths {
+			creds := &Credentials{
+				AccessToken:  tokenOf(auth, keyAccessToken),
+				IDToken:      tokenOf(auth, keyIDToken),
+				RefreshToken: tokenOf(auth, keyRefreshToken),
+			}
+			if *creds == (Credentials{}) {
+				continue
+			}
+			ref := name
+			if index := nodeutils.GetNodeIndex(auth.Content, keyCredentialRef); index != -1 && auth.Content[index].Value != "" {
+				ref = auth.Content[index].Value
+			} else {
+				auth.Content = append(auth.Content, nodeutils.CreateScalarNode(keyCredentialRef, ref)...)
+			}
+			if cached, ok := credentialsCache[ref]; !ok || *cached != *creds {
+				if err := store.Store(ref, creds); err != nil {
+					return errors.Wrapf(err, "failed to store the credentials of context %q", name)
+				}
+				credentialsCache[ref] = creds
+			}
+		}
+	}
+	return nil
+}
+
+// restoreCredentials moves the credentials of the contexts and servers of the config node
+// back from the credential store to their tokens, and removes their references. The
+// credentials are left in the credential store.
+func restoreCredentials(node *yaml.Node) error {
+	for name, auths := range getAuthNodes(node) {
+		for _, auth := range auths {
+			ref := removeScalarNode(auth, keyCredentialRef)
+			if ref == "" {
+				continue
+			}
+			creds, err := getCredentials(ref)
+			if errors.Is(err, ErrCredentialsNotFound) {
+				continue
+			}
+			if err != nil {
+				return errors.Wrapf(err, "failed to read the credentials of context %q", name)
+			}
+			for key, value := range map[string]string{keyAccessToken: creds.AccessToken, keyIDToken: creds.IDToken, keyRefreshToken: creds.RefreshToken} {
+				if value != "" && getScalarNode(auth, key) == "" {
+					removeScalarNode(auth, key)
+					auth.Content = append(auth.Content, nodeutils.CreateScalarNode(key, value)...)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// deleteCredentials deletes the credentials of the references from the credential store,
+// but for the references still used by the contexts and servers of the config node
+func deleteCredentials(node *yaml.Node, refs ...string) error {
+	refs = unusedCredentialRefs(node, refs)
+	if len(refs) == 0 {
+		return nil
+	}
+	store, err := GetCredentialStore()
+	if err != nil || store == nil {
+		return err
+	}
+	credentialsCacheMutex.Lock()
+	defer credentialsCacheMutex.Unlock()
+	for _, ref := range refs {
+		delete(credentialsCache, ref)
+		if err := store.Delete(ref); err != nil && !errors.Is(err, ErrCredentialsNotFound) {
+			return errors.Wrapf(err, "failed to delete the credentials of context %q", ref)
+		}
+	}
+	return nil
+}
+
+// getCredentialRefs returns the references of the stored credentials of the context or server
+func getCredentialRefs(node *yaml.Node, name string) []string {
+	var refs []string
+	for _, auth := range getAuthNodes(node)[name] {
+		index := nodeutils.GetNodeIndex(auth.Content, keyCredentialRef)
+		if index == -1 || auth.Content[index].Value == "" {
+			continue
+		}
+		ref := auth.Content[index].Value
+		if len(refs) == 0 || refs[len(refs)-1] != ref {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// unusedCredentialRefs returns the references which are not used by any context or server
+// of the config node
+func unusedCredentialRefs(node *yaml.Node, refs []string) []string {
+	used := make(map[string]bool)
+	for _, auths := range getAuthNodes(node) {
+		for _, auth := range auths {
+			used[getScalarNode(auth, keyCredentialRef)] = true
+		}
+	}
+	var unused []string
+	for _, ref := range refs {
+		if !used[ref] {
+			unused = append(unused, ref)
+		}
+	}
+	return unused
+}
+
+// getAuthNodes returns the auth nodes of the contexts and servers of the config node by name
+func getAuthNodes(node *yaml.Node) map[string][]*yaml.Node {
+	authNodes := make(map[string][]*yaml.Node)
+	if node == nil || len(node.Content) == 0 {
+		return authNodes
+	}
+	for _, key := range []string{KeyContexts, KeyServers} {
+		itemsNode := nodeutils.FindNode(node.Content[0], nodeutils.WithKeys([]nodeutils.Key{{Name: key}}))
+		if itemsNode == nil {
+			continue
+		}
+		for _, itemNode := range itemsNode.Content {
+			index := nodeutils.GetNodeIndex(itemNode.Content, "name")
+			if index == -1 {
+				continue
+			}
+			authNode := nodeutils.FindNode(itemNode, nodeutils.WithKeys([]nodeutils.Key{{Name: keyGlobalOpts}, {Name: keyAuth}}))
+			if authNode != nil && authNode.Kind == yaml.MappingNode {
+				name := itemNode.Content[index].Value
+				authNodes[name] = append(authNodes[name], authNode)
+			}
+		}
+	}
+	return authNodes
+}
+
+func hasPlaintextCredentials(authNodes map[string][]*yaml.Node) bool {
+	for _, auths := range authNodes {
+		for _, auth := range auths {
+			for _, key := range []string{keyAccessToken, keyIDToken, keyRefreshToken} {
+				if index := nodeutils.GetNodeIndex(auth.Content, key); index != -1 && auth.Content[index].Value != "" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// getScalarNode returns the value of the key of the mapping node
+func getScalarNode(node *yaml.Node, key string) string {
+	index := nodeutils.GetNodeIndex(node.Content, key)
+	if index == -1 {
+		return ""
+	}
+	return node.Content[index].Value
+}
+
+// removeScalarNode removes the key from the mapping node and returns its value
+func removeScalarNode(node *yaml.Node, key string) string {
+	index := nodeutils.GetNodeIndex(node.Content, key)
+	if index == -1 {
+		return ""
+	}
+	value := node.Content[index].Value
+	node.Content = append(node.Content[:index-1], node.Content[index+1:]...)
+	return value
+}
@@ -0,0 +1,266 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	// EnvCredentialStoreKeyKey is the environment variable holding the base64 encoded
+	// 256-bit key of the encrypted credentials file. If it is not set, a key is generated
+	// and kept in the OS keychain. Hosts without a keychain should set it, as the key is
+	// otherwise kept unprotected in a file next to the credentials file.
+	EnvCredentialStoreKeyKey = "TANZU_CLI_CREDENTIAL_STORE_KEY"
+
+	credentialsFileName    = "credentials.enc"
+	credentialsKeyFileName = "credentials.key"
+	credentialsKeySize     = 32
+
+	// credentialsKeyringService is the service of the keychain items holding the keys
+	// of the credentials files. The items are named after the credentials files.
+	credentialsKeyringService = "tanzu-cli"
+)
+
+// getKeyringSecret and setKeyringSecret access the OS keychain, and are replaced in tests
+var (
+	getKeyringSecret = keyring.Get
+	setKeyringSecret = keyring.Set
+)
+
+// warnNoKeychainOnce warns once per process that the key of the credentials file is
+// kept in a file as no keychain is available
+var warnNoKeychainOnce sync.Once
+
+// stderr is where the warning about the key file is written, and is replaced in tests
+var stderr io.Writer = os.Stderr
+
+// encryptedFileCredentialStore keeps the credentials in a file encrypted with AES-256-GCM
+type encryptedFileCredentialStore struct {
+	path    string
+	keyPath string
+}
+
+// NewEncryptedFileCredentialStore returns a credential store keeping the credentials
+// in the given file encrypted with AES-256-GCM
+func NewEncryptedFileCredentialStore(path string) CredentialStore {
+	return &encryptedFileCredentialStore{
+		path:    path,
+		keyPath: filepath.Join(filepath.Dir(path), credentialsKeyFileName),
+	}
+}
+
+// Get returns the credentials stored for the reference
+func (s *encryptedFileCredentialStore) Get(ref string) (*Credentials, error) {
+	key, err := s.key(false)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrCredentialsNotFound
+	}
+	all, err := s.read(key)
+	if err != nil {
+		return nil, err
+	}
+	creds, ok := all[ref]
+	if !ok {
+		return nil, ErrCredentialsNotFound
+	}
+	return creds, nil
+}
+
+// Store stores the credentials for the reference
+func (s *encryptedFileCredentialStore) Store(ref string, creds *Credentials) error {
+	key, err := s.key(true)
+	if err != nil {
+		return err
+	}
+	all, err := s.read(key)
+	if err != nil {
+		return err
+	}
+	all[ref] = creds
+	return s.write(key, all)
+}
+
+// Delete deletes the credentials stored for the reference
+func (s *encryptedFileCredentialStore) Delete(ref string) error {
+	key, err := s.key(false)
+	if err != nil || key == nil {
+		return err
+	}
+	all, err := s.read(key)
+	if err != nil {
+		return err
+	}
+	if _, ok := all[ref]; !ok {
+		return ErrCredentialsNotFound
+	}
+	delete(all, ref)
+	return s.write(key, all)
+}
+
+// key returns the encryption key, generating it if requested and there is none.
+// The key is kept in the OS keychain. If no keychain is available, the key is kept
+// in the key file next to the credentials file.
+func (s *encryptedFileCredentialStore) key(generate bool) ([]byte, error) {
+	if encoded, ok := os.LookupEnv(EnvCredentialStoreKeyKey); ok {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != credentialsKeySize {
+			return nil, errors.Errorf("%s must be a base64 encoded %d-byte key", EnvCredentialStoreKeyKey, credentialsKeySize)
+		}
+		return key, nil
+	}
+
+	encoded, err := getKeyringSecret(credentialsKeyringService, s.path)
+	if err == nil {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != credentialsKeySize {
+			return nil, errors.Errorf("invalid credentials key in the OS keychain for %q", s.path)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, keyring.ErrNotFound) {
+		// The warning is written to stderr regardless of the log verbosity, as the
+		// encryption does not protect the tokens against anyone reading the key file
+		warnNoKeychainOnce.Do(func() {
+			fmt.Fprintf(stderr, "WARNING: no OS keychain available (%v). The key encrypting the tokens in %q is stored "+
+				"UNPROTECTED in %q, anyone who can read both files can decrypt the tokens. "+
+				"Set %s to provide the key, or %s to select another credential store\n",
+				err, s.path, s.keyPath, EnvCredentialStoreKeyKey, EnvCredentialStoreKey)
+		})
+		return s.keyFromFile(generate)
+	}
+
+	if !generate {
+		return nil, nil
+	}
+	key, err := newCredentialsKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := setKeyringSecret(credentialsKeyringService, s.path, base64.StdEncoding.EncodeToString(key)); err != nil {
+		return nil, errors.Wrap(err, "failed to store the credentials key in the OS keychain")
+	}
+	return key, nil
+}
+
+// keyFromFile returns the encryption key kept in the key file, generating it if
+// requested and there is none
+func (s *encryptedFileCredentialStore) keyFromFile(generate bool) ([]byte, error) {
+	key, err := os.ReadFile(s.keyPath)
+	if err == nil {
+		if len(key) != credentialsKeySize {
+			return nil, errors.Errorf("invalid credentials key file %q", s.keyPath)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "failed to read the credentials key file")
+	}
+	if !generate {
+		return nil, nil
+	}
+
+	if key, err = newCredentialsKey(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.keyPath), 0700); err != nil {
+		return nil, errors.Wrap(err, "failed to create the credentials directory")
+	}
+	if err := os.WriteFile(s.keyPath, key, 0600); err != nil {
+		return nil, errors.Wrap(err, "failed to write the credentials key file")
+	}
+	return key, nil
+}
+
+func (s *encryptedFileCredentialStore) read(key []byte) (map[string]*Credentials, error) {
+	all := make(map[string]*Credentials)
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the credentials file")
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.Errorf("invalid credentials file %q", s.path)
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt the credentials file %q", s.path)
+	}
+	if err := json.Unmarshal(plaintext, &all); err != nil {
+		return nil, errors.Wrapf(err, "invalid credentials file %q", s.path)
+	}
+	return all, nil
+}
+
+func (s *encryptedFileCredentialStore) write(key []byte, all map[string]*Credentials) error {
+	plaintext, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return errors.Wrap(err, "failed to generate the nonce")
+	}
+	data := gcm.Seal(nonce, nonce, plaintext, nil)
+
+	// Replace the credentials file atomically so that a failed write does not lose the credentials
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return errors.Wrap(err, "failed to create the credentials directory")
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(s.path), credentialsFileName)
+	if err != nil {
+		return errors.Wrap(err, "failed to write the credentials file")
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return errors.Wrap(err, "failed to write the credentials file")
+	}
+	if err := tmpFile.Close(); err != nil {
+		return errors.Wrap(err, "failed to write the credentials file")
+	}
+	return os.Rename(tmpFile.Name(), s.path)
+}
+
+func newCredentialsKey() ([]byte, error) {
+	key := make([]byte, credentialsKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, errors.Wrap(err, "failed to generate the credentials key")
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// credentialHelperPrefix is the prefix of the credential helper programs
+	credentialHelperPrefix = "tanzu-credential-"
+
+	// credentialHelperServerURLPrefix prefixes the references of the credentials
+	// to make them distinguishable from registry credentials in shared keychains
+	credentialHelperServerURLPrefix = "tanzu-cli://"
+
+	// credentialHelperUsername is the user name of all credentials stored by the CLI
+	credentialHelperUsername = "tanzu-cli"
+
+	// credentialHelperNotFound is the output of credential helpers for unknown credentials
+	credentialHelperNotFound = "credentials not found in native keychain"
+)
+
+// execCredentialHelper is overridden by tests
+var execCredentialHelper = exec.Command
+
+// credentialHelperPayload is the credentials payload of the credential helper protocol
+type credentialHelperPayload struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// helperCredentialStore stores the credentials through an external credential helper program.
+//
+// The credential helpers implement the protocol of the docker credential helpers: the program
+// tanzu-credential-<name> is invoked with the "get", "store" or "erase" action and reads the
+// reference of the credentials (or the credentials payload for "store") from its standard input.
+// The docker credential helpers for the native keychains can be used by linking them under this name.
+type helperCredentialStore struct {
+	program string
+}
+
+// NewHelperCredentialStore returns a credential store using the credential helper of the given name
+func NewHelperCredentialStore(name string) CredentialStore {
+	return &helperCredentialStore{program: credentialHelperPrefix + name}
+}
+
+// Get returns the credentials stored for the reference
+func (s *helperCredentialStore) Get(ref string) (*Credentials, error) {
+	out, err := s.run("get", credentialHelperServerURLPrefix+ref)
+	if err != nil {
+		return nil, err
+	}
+	var payload credentialHelperPayload
+	if err := json.Unmarshal(out, &payload); err != nil {
+		return nil, errors.Wrapf(err, "invalid output of credential helper %q", s.program)
+	}
+	var creds Credentials
+	if err := json.Unmarshal([]byte(payload.Secret), &creds); err != nil {
+		return nil, errors.Wrapf(err, "invalid credentials returned by credential helper %q", s.program)
+	}
+	return &creds, nil
+}
+
+// Store stores the credentials for the reference
+func (s *helperCredentialStore) Store(ref string, creds *Credentials) error {
+	secret, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(&credentialHelperPayload{
+		ServerURL: credentialHelperServerURLPrefix + ref,
+		Username:  credentialHelperUsername,
+		Secret:    string(secret),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.run("store", string(payload))
+	return err
+}
+
+// Delete deletes the credentials stored for the reference
+func (s *helperCredentialStore) Delete(ref string) error {
+	_, err := s.run("erase", credentialHelperServerURLPrefix+ref)
+	return err
+}
+
+func (s *helperCredentialStore) run(action, input string) ([]byte, error) {
+	cmd := execCredentialHelper(s.program, action)
+	cmd.Stdin = strings.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		message := strings.TrimSpace(string(out) + stderr.String())
+		if strings.Contains(message, credentialHelperNotFound) {
+			return nil, ErrCredentialsNotFound
+		}
+		return nil, errors.Errorf("credential helper %q failed to %s credentials: %v %s", s.program, action, err, message)
+	}
+	return out, nil
+}
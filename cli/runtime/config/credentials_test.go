@@ -0,0 +1,338 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zalando/go-keyring"
+
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+)
+
+func setupCredentialsTestConfig(t *testing.T, cfg, cfgNextGen string) string {
+	dir := t.TempDir()
+	for env, data := range map[string]string{
+		EnvConfigKey:         cfg,
+		EnvConfigNextGenKey:  cfgNextGen,
+		EnvConfigMetadataKey: "",
+	} {
+		path := filepath.Join(dir, env+".yaml")
+		assert.NoError(t, os.WriteFile(path, []byte(data), 0644))
+		t.Setenv(env, path)
+	}
+	keyring.MockInit()
+	credentialsCache = map[string]*Credentials{}
+	return dir
+}
+
+func newTMCContext(name string) *configapi.Context {
+	return &configapi.Context{
+		Name: name,
+		Type: configapi.CtxTypeTMC,
+		GlobalOpts: &configapi.GlobalServer{
+			Endpoint: "test-endpoint",
+			Auth: configapi.GlobalServerAuth{
+				Issuer:       "test-issuer",
+				UserName:     "test-user",
+				AccessToken:  "test-access-token",
+				IDToken:      "test-id-token",
+				RefreshToken: "test-refresh-token",
+				Type:         "api-token",
+			},
+		},
+	}
+}
+
+func assertNoPlaintextTokens(t *testing.T, paths ...string) {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "test-access-token")
+		assert.NotContains(t, string(data), "test-id-token")
+		assert.NotContains(t, string(data), "test-refresh-token")
+	}
+}
+
+func TestEncryptedFileCredentialStore(t *testing.T) {
+	dir := setupCredentialsTestConfig(t, "", "")
+
+	ctx := newTMCContext("test-tmc")
+	assert.NoError(t, SetContext(ctx, true))
+
+	// The tokens are kept in the encrypted credentials file, and its key in the keychain
+	assertNoPlaintextTokens(t, os.Getenv(EnvConfigKey), os.Getenv(EnvConfigNextGenKey), filepath.Join(dir, credentialsFileName))
+	info, err := os.Stat(filepath.Join(dir, credentialsFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.NoFileExists(t, filepath.Join(dir, credentialsKeyFileName))
+	_, err = keyring.Get(credentialsKeyringService, filepath.Join(dir, credentialsFileName))
+	assert.NoError(t, err)
+	data, err := os.ReadFile(os.Getenv(EnvConfigNextGenKey))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "credentialRef: test-tmc")
+
+	// The tokens are resolved when reading the context and the server
+	credentialsCache = map[string]*Credentials{}
+	got, err := GetContext("test-tmc")
+	assert.NoError(t, err)
+	assert.Equal(t, ctx, got)
+	server, err := GetServer("test-tmc")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-refresh-token", server.GlobalOpts.Auth.RefreshToken)
+
+	// Updating the tokens updates the stored credentials
+	ctx.GlobalOpts.Auth.AccessToken = "test-access-token-2"
+	assert.NoError(t, SetContext(ctx, false))
+	credentialsCache = map[string]*Credentials{}
+	got, err = GetCurrentContext(configapi.CtxTypeTMC)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-access-token-2", got.GlobalOpts.Auth.AccessToken)
+
+	// The credentials cannot be read with another key
+	t.Setenv(EnvCredentialStoreKeyKey, "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE=")
+	_, err = NewEncryptedFileCredentialStore(filepath.Join(dir, credentialsFileName)).Get("test-tmc")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decrypt the credentials file")
+	os.Unsetenv(EnvCredentialStoreKeyKey)
+
+	// Deleting the context deletes its credentials
+	assert.NoError(t, RemoveContext("test-tmc"))
+	_, err = NewEncryptedFileCredentialStore(filepath.Join(dir, credentialsFileName)).Get("test-tmc")
+	assert.Equal(t, ErrCredentialsNotFound, err)
+}
+
+func TestMigrateCredentials(t *testing.T) {
+	cfg := `servers:
+  - name: test-tmc
+    type: global
+    globalOpts:
+      endpoint: test-endpoint
+      auth:
+        issuer: test-issuer
+        accessToken: test-access-token
+        IDToken: test-id-token
+        refresh_token: test-refresh-token
+        type: api-token
+current: test-tmc
+`
+	cfgNextGen := `contexts:
+  - name: test-tmc
+    type: tmc
+    globalOpts:
+      endpoint: test-endpoint
+      auth:
+        issuer: test-issuer
+        accessToken: test-access-token
+        IDToken: test-id-token
+        refresh_token: test-refresh-token
+        type: api-token
+currentContext:
+  tmc: test-tmc
+`
+	dir := setupCredentialsTestConfig(t, cfg, cfgNextGen)
+
+	ctx, err := GetContext("test-tmc")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-access-token", ctx.GlobalOpts.Auth.AccessToken)
+	assertNoPlaintextTokens(t, os.Getenv(EnvConfigKey), os.Getenv(EnvConfigNextGenKey))
+
+	creds, err := NewEncryptedFileCredentialStore(filepath.Join(dir, credentialsFileName)).Get("test-tmc")
+	assert.NoError(t, err)
+	assert.Equal(t, &Credentials{AccessToken: "test-access-token", IDToken: "test-id-token", RefreshToken: "test-refresh-token"}, creds)
+
+	cfgObj, err := GetClientConfig()
+	assert.NoError(t, err)
+	server, err := cfgObj.GetCurrentServer()
+	assert.NoError(t, err)
+	assert.Equal(t, "test-id-token", server.GlobalOpts.Auth.IDToken)
+}
+
+func TestCredentialsKeyFile(t *testing.T) {
+	dir := setupCredentialsTestConfig(t, "", "")
+	path := filepath.Join(dir, credentialsFileName)
+	creds := &Credentials{AccessToken: "test-access-token"}
+
+	// The key is generated in the keychain
+	assert.NoError(t, NewEncryptedFileCredentialStore(path).Store("test-tmc", creds))
+	assert.NoFileExists(t, filepath.Join(dir, credentialsKeyFileName))
+	_, err := keyring.Get(credentialsKeyringService, path)
+	assert.NoError(t, err)
+
+	// The key is kept in the key file with a warning if no keychain is available
+	var warning bytes.Buffer
+	stderr = &warning
+	warnNoKeychainOnce = sync.Once{}
+	getKeyringSecret = func(string, string) (string, error) { return "", errors.New("no keychain") }
+	defer func() {
+		stderr = os.Stderr
+		getKeyringSecret = keyring.Get
+		warnNoKeychainOnce = sync.Once{}
+	}()
+	path = filepath.Join(dir, "other-"+credentialsFileName)
+	assert.NoError(t, NewEncryptedFileCredentialStore(path).Store("test-tmc", creds))
+	info, err := os.Stat(filepath.Join(dir, credentialsKeyFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	got, err := NewEncryptedFileCredentialStore(path).Get("test-tmc")
+	assert.NoError(t, err)
+	assert.Equal(t, creds, got)
+	assert.Contains(t, warning.String(), "WARNING: no OS keychain available (no keychain)")
+	assert.Contains(t, warning.String(), EnvCredentialStoreKeyKey)
+}
+
+func TestLegacyTokens(t *testing.T) {
+	dir := setupCredentialsTestConfig(t, "", "")
+	t.Setenv(EnvCredentialStoreLegacyTokensKey, "true")
+
+	// The tokens are kept in the config file besides their reference if requested
+	ctx := newTMCContext("test-tmc")
+	assert.NoError(t, SetContext(ctx, true))
+	data, err := os.ReadFile(os.Getenv(EnvConfigNextGenKey))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "credentialRef: test-tmc")
+	assert.Contains(t, string(data), "accessToken: test-access-token")
+	creds, err := NewEncryptedFileCredentialStore(filepath.Join(dir, credentialsFileName)).Get("test-tmc")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-access-token", creds.AccessToken)
+
+	// Tokens updated in the config file by older runtimes take precedence
+	assert.NoError(t, os.WriteFile(os.Getenv(EnvConfigNextGenKey),
+		[]byte(strings.ReplaceAll(string(data), "accessToken: test-access-token", "accessToken: test-access-token-2")), 0644))
+	got, err := GetContext("test-tmc")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-access-token-2", got.GlobalOpts.Auth.AccessToken)
+}
+
+func TestCredentialsSchemaMigrationDown(t *testing.T) {
+	setupCredentialsTestConfig(t, "", "")
+
+	ctx := newTMCContext("test-tmc")
+	assert.NoError(t, SetContext(ctx, true))
+	assertNoPlaintextTokens(t, os.Getenv(EnvConfigNextGenKey))
+
+	// The tokens are restored in the config file for the versions of the CLI without credential store
+	assert.NoError(t, MigrateSchema(credentialsSchemaVersion-1))
+	data, err := os.ReadFile(os.Getenv(EnvConfigNextGenKey))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "accessToken: test-access-token")
+	assert.Contains(t, string(data), "refresh_token: test-refresh-token")
+	assert.NotContains(t, string(data), keyCredentialRef)
+
+	// and moved to the credential store again on upgrade
+	assert.NoError(t, MigrateSchema(CurrentSchemaVersion()))
+	assertNoPlaintextTokens(t, os.Getenv(EnvConfigNextGenKey))
+	got, err := GetContext("test-tmc")
+	assert.NoError(t, err)
+	assert.Equal(t, ctx, got)
+}
+
+func TestRemoveContextKeepsSharedCredentials(t *testing.T) {
+	dir := setupCredentialsTestConfig(t, "", "")
+
+	assert.NoError(t, SetContext(newTMCContext("test-tmc"), true))
+	shared := newTMCContext("test-tmc-shared")
+	shared.GlobalOpts.Auth.CredentialRef = "test-tmc"
+	assert.NoError(t, SetContext(shared, false))
+
+	// The credentials are deleted with the last context referencing them
+	store := NewEncryptedFileCredentialStore(filepath.Join(dir, credentialsFileName))
+	assert.NoError(t, RemoveContext("test-tmc"))
+	_, err := store.Get("test-tmc")
+	assert.NoError(t, err)
+	assert.NoError(t, RemoveContext("test-tmc-shared"))
+	_, err = store.Get("test-tmc")
+	assert.Equal(t, ErrCredentialsNotFound, err)
+}
+
+func TestConfigCredentialStore(t *testing.T) {
+	dir := setupCredentialsTestConfig(t, "", "")
+	t.Setenv(EnvCredentialStoreKey, CredentialStoreConfig)
+
+	assert.NoError(t, SetContext(newTMCContext("test-tmc"), true))
+	data, err := os.ReadFile(os.Getenv(EnvConfigNextGenKey))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "test-access-token")
+	assert.NoFileExists(t, filepath.Join(dir, credentialsFileName))
+}
+
+func TestHelperCredentialStore(t *testing.T) {
+	setupCredentialsTestConfig(t, "", "")
+	t.Setenv(EnvCredentialStoreKey, "fake")
+	t.Setenv("CREDENTIAL_HELPER_DIR", t.TempDir())
+	execCredentialHelper = fakeCredentialHelperCommand
+	defer func() { execCredentialHelper = exec.Command }()
+
+	store, err := GetCredentialStore()
+	assert.NoError(t, err)
+	_, err = store.Get("test-tmc")
+	assert.Equal(t, ErrCredentialsNotFound, err)
+
+	ctx := newTMCContext("test-tmc")
+	assert.NoError(t, SetContext(ctx, true))
+	assertNoPlaintextTokens(t, os.Getenv(EnvConfigKey), os.Getenv(EnvConfigNextGenKey))
+
+	credentialsCache = map[string]*Credentials{}
+	got, err := GetContext("test-tmc")
+	assert.NoError(t, err)
+	assert.Equal(t, ctx, got)
+
+	assert.NoError(t, RemoveContext("test-tmc"))
+	_, err = store.Get("test-tmc")
+	assert.Equal(t, ErrCredentialsNotFound, err)
+}
+
+func fakeCredentialHelperCommand(command string, args ...string) *exec.Cmd {
+	cs := []string{"-test.run=TestCredentialHelperProcess", "--", command}
+	cs = append(cs, args...)
+	cmd := exec.Command(os.Args[0], cs...) //nolint:gosec
+	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1", "CREDENTIAL_HELPER_DIR=" + os.Getenv("CREDENTIAL_HELPER_DIR")}
+	return cmd
+}
+
+// TestCredentialHelperProcess is a credential helper keeping the credentials in plain files
+func TestCredentialHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	defer os.Exit(0)
+
+	input, _ := io.ReadAll(os.Stdin)
+	pathOf := func(serverURL string) string {
+		return filepath.Join(os.Getenv("CREDENTIAL_HELPER_DIR"), hex.EncodeToString([]byte(serverURL)))
+	}
+	switch os.Args[len(os.Args)-1] {
+	case "store":
+		var payload credentialHelperPayload
+		if err := json.Unmarshal(input, &payload); err != nil {
+			fmt.Fprint(os.Stderr, err)
+			os.Exit(1)
+		}
+		_ = os.WriteFile(pathOf(payload.ServerURL), input, 0600)
+	case "get":
+		data, err := os.ReadFile(pathOf(string(input)))
+		if err != nil {
+			fmt.Print(credentialHelperNotFound)
+			os.Exit(1)
+		}
+		fmt.Print(string(data))
+	case "erase":
+		if err := os.Remove(pathOf(string(input))); err != nil {
+			fmt.Print(credentialHelperNotFound)
+			os.Exit(1)
+		}
+	}
+}
@@ -23,6 +23,7 @@ const (
 	DiscoveryTypeGCP        = "gcp"
 	DiscoveryTypeKubernetes = "kubernetes"
 	DiscoveryTypeREST       = "rest"
+	DiscoveryTypeGit        = "git"
 )
 
 const (
@@ -146,13 +147,15 @@ func getDiscoverySourceTypeAndName(discoverySource configapi.PluginDiscovery) (s
 		return DiscoveryTypeKubernetes, discoverySource.Kubernetes.Name
 	} else if discoverySource.REST != nil && discoverySource.REST.Name != "" {
 		return DiscoveryTypeREST, discoverySource.REST.Name
+	} else if discoverySource.Git != nil && discoverySource.Git.Name != "" {
+		return DiscoveryTypeGit, discoverySource.Git.Name
 	}
 	return "", ""
 }
 
 // Find the matching discovery source type and index from accepted discovery sources
 func findDiscoverySourceTypeAndIndexByWeakMatch(discoverySourceContentNodes []*yaml.Node) (string, int) {
-	acceptedDiscoverySources := []string{DiscoveryTypeOCI, DiscoveryTypeLocal, DiscoveryTypeGCP, DiscoveryTypeKubernetes, DiscoveryTypeREST}
+	acceptedDiscoverySources := []string{DiscoveryTypeOCI, DiscoveryTypeLocal, DiscoveryTypeGCP, DiscoveryTypeKubernetes, DiscoveryTypeREST, DiscoveryTypeGit}
 	for _, discoverySourceType := range acceptedDiscoverySources {
 		idx := nodeutils.GetNodeIndex(discoverySourceContentNodes, discoverySourceType)
 		if idx != -1 {
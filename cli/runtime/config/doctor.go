@@ -0,0 +1,387 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config/collectionutils"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config/nodeutils"
+)
+
+// ConfigIssue is a problem of the config documents found by DiagnoseConfig.
+type ConfigIssue struct {
+	// Document is the path of the config document with the issue.
+	Document string `json:"document" yaml:"document"`
+	// Description of the issue.
+	Description string `json:"description" yaml:"description"`
+	// Repair describes how the issue is repaired by RepairConfig, empty if the
+	// issue cannot be repaired automatically.
+	Repair string `json:"repair,omitempty" yaml:"repair,omitempty"`
+}
+
+// configDiagnosis is an issue and the function repairing it
+type configDiagnosis struct {
+	issue  ConfigIssue
+	repair func() error
+}
+
+// DiagnoseConfig validates the config documents and returns the issues found.
+func DiagnoseConfig() ([]ConfigIssue, error) {
+	AcquireTanzuConfigLock()
+	defer ReleaseTanzuConfigLock()
+	diagnoses, err := diagnoseConfigNoLock()
+	if err != nil {
+		return nil, err
+	}
+	return configIssues(diagnoses), nil
+}
+
+// RepairConfig validates the config documents and repairs the issues found, backing
+// up the documents first. It returns the issues found, including the issues which
+// cannot be repaired automatically.
+func RepairConfig() ([]ConfigIssue, error) {
+	AcquireTanzuConfigLock()
+	defer ReleaseTanzuConfigLock()
+	diagnoses, err := diagnoseConfigNoLock()
+	if err != nil || len(diagnoses) == 0 {
+		return nil, err
+	}
+	version, err := GetSchemaVersion()
+	if err != nil {
+		// The documents are corrupted and their version is unknown
+		version = 0
+	}
+	if _, err := BackupConfig(version); err != nil {
+		return nil, errors.Wrap(err, "failed to back up the config before repair")
+	}
+	for _, d := range diagnoses {
+		if d.repair == nil {
+			continue
+		}
+		if err := d.repair(); err != nil {
+			return nil, errors.Wrapf(err, "failed to repair %v: %v", d.issue.Document, d.issue.Description)
+		}
+	}
+	return configIssues(diagnoses), nil
+}
+
+func configIssues(diagnoses []configDiagnosis) []ConfigIssue {
+	issues := make([]ConfigIssue, 0, len(diagnoses))
+	for _, d := range diagnoses {
+		issues = append(issues, d.issue)
+	}
+	return issues
+}
+
+// diagnoseConfigNoLock returns the issues of the config documents. The content of the
+// documents is only validated once the documents are valid YAML documents.
+func diagnoseConfigNoLock() ([]configDiagnosis, error) {
+	paths, err := configDocumentPaths()
+	if err != nil {
+		return nil, err
+	}
+	metadataPath, err := CfgMetadataFilePath()
+	if err != nil {
+		return nil, err
+	}
+	var diagnoses []configDiagnosis
+	for _, path := range append(paths, metadataPath) {
+		if d := diagnoseDocument(path); d != nil {
+			diagnoses = append(diagnoses, *d)
+		}
+	}
+	if len(diagnoses) > 0 {
+		return diagnoses, nil
+	}
+
+	version, err := GetSchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	document := paths[len(paths)-1]
+	switch {
+	case version < CurrentSchemaVersion():
+		from := version
+		// The schema version is updated when the repaired documents are persisted
+		diagnoses = append(diagnoses, configDiagnosis{
+			issue: ConfigIssue{
+				Document:    document,
+				Description: fmt.Sprintf("the schema version %v is older than the current version %v", version, CurrentSchemaVersion()),
+				Repair:      fmt.Sprintf("migrate to the schema version %v", CurrentSchemaVersion()),
+			},
+			repair: func() error { return migrateSchemaNoLock(from, CurrentSchemaVersion()) },
+		})
+	case version > CurrentSchemaVersion():
+		diagnoses = append(diagnoses, configDiagnosis{issue: ConfigIssue{
+			Document:    document,
+			Description: fmt.Sprintf("the schema version %v is newer than the supported version %v, the CLI should be upgraded", version, CurrentSchemaVersion()),
+		}})
+		return diagnoses, nil
+	}
+
+	node, err := readClientConfigNodeNoLock()
+	if err != nil {
+		return nil, err
+	}
+	var repairs []func(node *yaml.Node)
+	var nodeDiagnoses []configDiagnosis
+	for _, check := range []func(node *yaml.Node) []nodeDiagnosis{diagnoseContexts, diagnoseServers, diagnoseDiscoverySources} {
+		for _, d := range check(node) {
+			nodeDiagnoses = append(nodeDiagnoses, configDiagnosis{issue: ConfigIssue{Document: documentOfKey(paths, d.key), Description: d.description, Repair: d.repairDescription}})
+			if d.repair != nil {
+				repairs = append(repairs, d.repair)
+			}
+		}
+	}
+	if len(repairs) > 0 {
+		// All the repairs of the content are persisted at once, after the migration if any
+		nodeDiagnoses[len(nodeDiagnoses)-1].repair = func() error {
+			node, err := readClientConfigNodeNoLock()
+			if err != nil {
+				return err
+			}
+			for _, repair := range repairs {
+				repair(node)
+			}
+			return persistConfig(node)
+		}
+	}
+	return append(diagnoses, nodeDiagnoses...), nil
+}
+
+// diagnoseDocument reports a config document which is not a valid YAML document
+func diagnoseDocument(path string) *configDiagnosis {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err == nil {
+		err = validateDocument(b)
+		if err == nil {
+			return nil
+		}
+	}
+	d := &configDiagnosis{issue: ConfigIssue{Document: path, Description: fmt.Sprintf("the document is invalid: %v", err.Error())}}
+	if backup := latestValidBackup(path); backup != "" {
+		d.issue.Repair = fmt.Sprintf("restore the backup %v", backup)
+		d.repair = func() error { return copyFile(backup, path) }
+	} else {
+		d.issue.Repair = "move the document to the backups and start with an empty document"
+		d.repair = func() error { return moveAsideDocument(path) }
+	}
+	return d
+}
+
+// validateDocument returns an error if the content is neither empty nor a YAML mapping
+func validateDocument(b []byte) error {
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(b, &node); err != nil {
+		return err
+	}
+	if len(node.Content) != 1 || node.Content[0].Kind != yaml.MappingNode {
+		return errors.New("the document is not a YAML mapping")
+	}
+	return nil
+}
+
+// latestValidBackup returns the latest backup of the config document which is valid
+func latestValidBackup(path string) string {
+	backups, err := ConfigBackups(path)
+	if err != nil {
+		return ""
+	}
+	for _, backup := range backups {
+		b, err := os.ReadFile(backup)
+		if err == nil && validateDocument(b) == nil {
+			return backup
+		}
+	}
+	return ""
+}
+
+// moveAsideDocument moves the invalid config document to the backup directory so
+// that the CLI starts with an empty document
+func moveAsideDocument(path string) error {
+	backupDir := filepath.Join(filepath.Dir(path), configBackupDirName)
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return err
+	}
+	invalid := filepath.Join(backupDir, fmt.Sprintf("%s.%s.invalid", filepath.Base(path), time.Now().UTC().Format("20060102T150405.000000000")))
+	return os.Rename(path, invalid)
+}
+
+// nodeDiagnosis is an issue of the content of the client config node and the
+// function repairing the node
+type nodeDiagnosis struct {
+	key               string
+	description       string
+	repairDescription string
+	repair            func(node *yaml.Node)
+}
+
+// diagnoseContexts reports contexts without name or type, duplicate contexts and
+// current contexts which do not exist
+func diagnoseContexts(node *yaml.Node) []nodeDiagnosis {
+	diagnoses := diagnoseNamedItems(node, []string{KeyContexts}, "context", func(itemNode *yaml.Node) string {
+		if index := nodeutils.GetNodeIndex(itemNode.Content, "name"); index != -1 {
+			return itemNode.Content[index].Value
+		}
+		return ""
+	})
+
+	var cfg configapi.ClientConfig
+	if err := node.Decode(&cfg); err != nil {
+		return append(diagnoses, nodeDiagnosis{key: KeyContexts, description: fmt.Sprintf("the contexts are invalid: %v", err.Error())})
+	}
+	for _, ctx := range cfg.KnownContexts {
+		if ctx.Name != "" && !isSupportedContextType(ctx.Type) {
+			diagnoses = append(diagnoses, nodeDiagnosis{key: KeyContexts, description: fmt.Sprintf("the context %q has the unknown type %q", ctx.Name, ctx.Type)})
+		}
+	}
+	for ctxType, name := range cfg.CurrentContext {
+		if ctx, err := cfg.GetContext(name); err == nil && ctx.Type == ctxType {
+			continue
+		}
+		ctx := &configapi.Context{Name: name, Type: ctxType}
+		diagnoses = append(diagnoses, nodeDiagnosis{
+			key:               KeyCurrentContext,
+			description:       fmt.Sprintf("the current %v context %q does not exist", ctxType, name),
+			repairDescription: fmt.Sprintf("unset the current %v context", ctxType),
+			repair:            func(node *yaml.Node) { _ = removeCurrentContext(node, ctx) },
+		})
+	}
+	return diagnoses
+}
+
+// diagnoseServers reports servers without name, duplicate servers and a current
+// server which does not exist
+func diagnoseServers(node *yaml.Node) []nodeDiagnosis {
+	diagnoses := diagnoseNamedItems(node, []string{KeyServers}, "server", func(itemNode *yaml.Node) string {
+		if index := nodeutils.GetNodeIndex(itemNode.Content, "name"); index != -1 {
+			return itemNode.Content[index].Value
+		}
+		return ""
+	})
+
+	var cfg configapi.ClientConfig
+	if err := node.Decode(&cfg); err != nil {
+		return append(diagnoses, nodeDiagnosis{key: KeyServers, description: fmt.Sprintf("the servers are invalid: %v", err.Error())})
+	}
+	if cfg.CurrentServer != "" && !cfg.HasServer(cfg.CurrentServer) {
+		diagnoses = append(diagnoses, nodeDiagnosis{
+			key:               KeyCurrentServer,
+			description:       fmt.Sprintf("the current server %q does not exist", cfg.CurrentServer),
+			repairDescription: "unset the current server",
+			repair:            func(node *yaml.Node) { _, _ = setCurrentServer(node, "") },
+		})
+	}
+	return diagnoses
+}
+
+// diagnoseDiscoverySources reports standalone discovery sources without name and
+// duplicate discovery sources
+func diagnoseDiscoverySources(node *yaml.Node) []nodeDiagnosis {
+	return diagnoseNamedItems(node, []string{KeyClientOptions, KeyCLI, KeyDiscoverySources}, "discovery source", func(itemNode *yaml.Node) string {
+		var ds configapi.PluginDiscovery
+		if err := itemNode.Decode(&ds); err != nil {
+			return ""
+		}
+		_, name := getDiscoverySourceTypeAndName(ds)
+		return name
+	})
+}
+
+// diagnoseNamedItems reports the items of the sequence at the path of keys without name,
+// and the items with the name of a previous item. The repair removes the items, keeping
+// the first item of every name.
+func diagnoseNamedItems(node *yaml.Node, path []string, kind string, nameOf func(itemNode *yaml.Node) string) []nodeDiagnosis {
+	itemsNode := findNodeByPath(node, path)
+	if itemsNode == nil || itemsNode.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var diagnoses []nodeDiagnosis
+	seen := make(map[string]bool)
+	for i, itemNode := range itemsNode.Content {
+		name := nameOf(itemNode)
+		switch {
+		case name == "":
+			diagnoses = append(diagnoses, nodeDiagnosis{
+				key:               path[0],
+				description:       fmt.Sprintf("the %v at index %v has no name", kind, i),
+				repairDescription: fmt.Sprintf("remove the %v", kind),
+			})
+		case seen[name]:
+			diagnoses = append(diagnoses, nodeDiagnosis{
+				key:               path[0],
+				description:       fmt.Sprintf("the %v %q is duplicated", kind, name),
+				repairDescription: fmt.Sprintf("keep the first %v %q", kind, name),
+			})
+		default:
+			seen[name] = true
+			continue
+		}
+	}
+	if len(diagnoses) > 0 {
+		diagnoses[0].repair = func(node *yaml.Node) {
+			itemsNode := findNodeByPath(node, path)
+			if itemsNode == nil {
+				return
+			}
+			seen := make(map[string]bool)
+			var items []*yaml.Node
+			for _, itemNode := range itemsNode.Content {
+				name := nameOf(itemNode)
+				if name == "" || seen[name] {
+					continue
+				}
+				seen[name] = true
+				items = append(items, itemNode)
+			}
+			itemsNode.Content = items
+		}
+	}
+	return diagnoses
+}
+
+// documentOfKey returns the path of the config document of the top level key, i.e.
+// config.yaml for the legacy keys unless the unified config is used
+func documentOfKey(paths []string, key string) string {
+	if len(paths) > 1 && collectionutils.Contains(LegacyConfigNodeKeys, key) {
+		return paths[0]
+	}
+	return paths[len(paths)-1]
+}
+
+// findNodeByPath returns the node at the path of keys
+func findNodeByPath(node *yaml.Node, path []string) *yaml.Node {
+	if node == nil || len(node.Content) == 0 {
+		return nil
+	}
+	var keys []nodeutils.Key
+	for _, key := range path {
+		keys = append(keys, nodeutils.Key{Name: key})
+	}
+	return nodeutils.FindNode(node.Content[0], nodeutils.WithKeys(keys))
+}
+
+func isSupportedContextType(ctxType configapi.ContextType) bool {
+	for _, t := range configapi.SupportedCtxTypes {
+		if t == ctxType {
+			return true
+		}
+	}
+	return false
+}
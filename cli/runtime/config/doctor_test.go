@@ -0,0 +1,145 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+)
+
+func TestRepairConfigContent(t *testing.T) {
+	assert := assert.New(t)
+
+	cfgPath, cfgNextGenPath := setupSchemaTestConfig(t, `servers:
+  - name: test-mc
+    type: managementcluster
+  - name: test-mc
+    type: global
+current: missing-mc
+clientOptions:
+  cli:
+    discoverySources:
+      - local:
+          name: default-local
+          path: standalone
+      - local:
+          name: default-local
+          path: other
+schemaVersion: 2
+`, `contexts:
+  - name: test-mc
+    type: k8s
+  - name: test-mc
+    type: tmc
+  - type: k8s
+currentContext:
+  k8s: test-mc
+  tmc: test-mc
+schemaVersion: 2
+`)
+
+	issues, err := DiagnoseConfig()
+	assert.NoError(err)
+	descriptions := make(map[string]string)
+	for _, issue := range issues {
+		descriptions[issue.Description] = issue.Document
+	}
+	assert.Equal(map[string]string{
+		`the context "test-mc" is duplicated`:                cfgNextGenPath,
+		"the context at index 2 has no name":                 cfgNextGenPath,
+		`the current tmc context "test-mc" does not exist`:   cfgNextGenPath,
+		`the server "test-mc" is duplicated`:                 cfgPath,
+		`the current server "missing-mc" does not exist`:     cfgPath,
+		`the discovery source "default-local" is duplicated`: cfgPath,
+	}, descriptions)
+
+	repaired, err := RepairConfig()
+	assert.NoError(err)
+	assert.Equal(issues, repaired)
+
+	issues, err = DiagnoseConfig()
+	assert.NoError(err)
+	assert.Empty(issues)
+
+	cfg, err := GetClientConfig()
+	assert.NoError(err)
+	assert.Len(cfg.KnownContexts, 1)
+	assert.Equal(configapi.CtxTypeK8s, cfg.KnownContexts[0].Type)
+	assert.Equal(map[configapi.ContextType]string{configapi.CtxTypeK8s: "test-mc"}, cfg.CurrentContext)
+	assert.Len(cfg.KnownServers, 1)
+	assert.Empty(cfg.CurrentServer)
+	assert.Len(cfg.ClientOptions.CLI.DiscoverySources, 1)
+	assert.Equal("standalone", cfg.ClientOptions.CLI.DiscoverySources[0].Local.Path)
+
+	backups, err := ConfigBackups(cfgNextGenPath)
+	assert.NoError(err)
+	assert.Len(backups, 1)
+}
+
+func TestRepairConfigInvalidDocument(t *testing.T) {
+	assert := assert.New(t)
+
+	cfgPath, cfgNextGenPath := setupSchemaTestConfig(t, "current: test-mc\nschemaVersion: 2\n", "contexts: [\n")
+
+	issues, err := DiagnoseConfig()
+	assert.NoError(err)
+	assert.Len(issues, 1)
+	assert.Equal(cfgNextGenPath, issues[0].Document)
+	assert.Equal("move the document to the backups and start with an empty document", issues[0].Repair)
+
+	_, err = GetClientConfig()
+	assert.Error(err)
+
+	_, err = RepairConfig()
+	assert.NoError(err)
+	_, err = os.Stat(cfgNextGenPath)
+	assert.True(os.IsNotExist(err))
+	invalid, err := filepath.Glob(filepath.Join(filepath.Dir(cfgNextGenPath), configBackupDirName, "config-ng.yaml.*.invalid"))
+	assert.NoError(err)
+	assert.Len(invalid, 1)
+	_, err = GetClientConfig()
+	assert.NoError(err)
+
+	// Invalid documents are restored from the latest valid backup
+	_, err = BackupConfig(2)
+	assert.NoError(err)
+	assert.NoError(os.WriteFile(cfgPath, []byte("- not a mapping\n"), 0644))
+	issues, err = DiagnoseConfig()
+	assert.NoError(err)
+	assert.Len(issues, 1)
+	assert.Equal(cfgPath, issues[0].Document)
+	assert.Contains(issues[0].Repair, "restore the backup")
+
+	_, err = RepairConfig()
+	assert.NoError(err)
+	b, err := os.ReadFile(cfgPath)
+	assert.NoError(err)
+	assert.Equal("current: test-mc\nschemaVersion: 2\n", string(b))
+}
+
+func TestRepairConfigSchemaVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	cfgPath, _ := setupSchemaTestConfig(t, "current: test-mc\n", "")
+	issues, err := DiagnoseConfig()
+	assert.NoError(err)
+	assert.Equal("the schema version 0 is older than the current version 2", issues[0].Description)
+
+	_, err = RepairConfig()
+	assert.NoError(err)
+	version, err := GetSchemaVersion()
+	assert.NoError(err)
+	assert.Equal(CurrentSchemaVersion(), version)
+
+	assert.NoError(os.WriteFile(cfgPath, []byte("schemaVersion: 7\n"), 0644))
+	issues, err = DiagnoseConfig()
+	assert.NoError(err)
+	assert.Len(issues, 1)
+	assert.Empty(issues[0].Repair)
+}
@@ -4,6 +4,8 @@
 package config
 
 import (
+	"fmt"
+
 	"github.com/pkg/errors"
 
 	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config/nodeutils"
@@ -11,10 +13,10 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// GetAllEnvs retrieves all env values from config
+// GetAllEnvs retrieves all env values from config, overlaid by the profile in use
 func GetAllEnvs() (map[string]string, error) {
 	// Retrieve client config node
-	node, err := getClientConfigNode()
+	node, err := getClientConfigNodeWithProfile()
 	if err != nil {
 		return nil, err
 	}
@@ -32,10 +34,10 @@ func getAllEnvs(node *yaml.Node) (map[string]string, error) {
 	return nil, errors.New("not found")
 }
 
-// GetEnv retrieves env value by key
+// GetEnv retrieves env value by key, overlaid by the profile in use
 func GetEnv(key string) (string, error) {
 	// Retrieve client config node
-	node, err := getClientConfigNode()
+	node, err := getClientConfigNodeWithProfile()
 	if err != nil {
 		return "", err
 	}
@@ -65,6 +67,9 @@ func DeleteEnv(key string) error {
 	if err != nil {
 		return err
 	}
+	if err := checkNotProvidedByProfile(node, fmt.Sprintf("env variable %q", key), profileProvidesEnv(key)); err != nil {
+		return err
+	}
 	err = deleteEnv(node, key)
 	if err != nil {
 		return err
@@ -110,6 +115,9 @@ func SetEnv(key, value string) (err error) {
 	if err != nil {
 		return err
 	}
+	if err := checkNotProvidedByProfile(node, fmt.Sprintf("env variable %q", key), profileProvidesEnv(key)); err != nil {
+		return err
+	}
 
 	// add or update env map
 	persist, err := setEnv(node, key, value)
@@ -157,7 +165,7 @@ func setEnv(node *yaml.Node, key, value string) (persist bool, err error) {
 }
 
 // GetEnvConfigurations returns a map of configured environment variables
-// to values as part of tanzu configuration file
+// to values as part of tanzu configuration file, overlaid by the profile in use
 // it returns nil if configuration is not yet defined
 func GetEnvConfigurations() map[string]string {
 	envs, err := GetAllEnvs()
@@ -4,6 +4,7 @@
 package config
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -14,10 +15,11 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// IsFeatureEnabled checks and returns whether specific plugin and key is true
+// IsFeatureEnabled checks and returns whether specific plugin and key is true,
+// overlaid by the profile in use
 func IsFeatureEnabled(plugin, key string) (bool, error) {
 	// Retrieve client config node
-	node, err := getClientConfigNode()
+	node, err := getClientConfigNodeWithProfile()
 	if err != nil {
 		return false, err
 	}
@@ -54,6 +56,9 @@ func DeleteFeature(plugin, key string) error {
 	if err != nil {
 		return err
 	}
+	if err := checkNotProvidedByProfile(node, fmt.Sprintf("feature flag %q of %q", key, plugin), profileProvidesFeature(plugin, key)); err != nil {
+		return err
+	}
 	err = deleteFeature(node, plugin, key)
 	if err != nil {
 		return err
@@ -94,6 +99,9 @@ func SetFeature(plugin, key, value string) (err error) {
 	if err != nil {
 		return err
 	}
+	if err := checkNotProvidedByProfile(node, fmt.Sprintf("feature flag %q of %q", key, plugin), profileProvidesFeature(plugin, key)); err != nil {
+		return err
+	}
 	// Add or Update Feature plugin
 	persist, err := setFeature(node, plugin, key, value)
 	if err != nil {
@@ -165,6 +173,9 @@ func IsFeatureActivated(feature string) bool {
 	if err != nil {
 		return false
 	}
+	if err := ApplyCurrentProfile(cfg); err != nil {
+		warnIgnoredProfile(err)
+	}
 	status, err := cfg.IsConfigFeatureActivated(feature)
 	if err != nil {
 		return false
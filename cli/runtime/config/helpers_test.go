@@ -5,6 +5,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,8 +18,10 @@ type CfgTestData struct {
 }
 
 func setupTestConfig(t *testing.T, data *CfgTestData) (files []*os.File, cleanup func()) {
-	// Setup config data
-	cfgFile, err := os.CreateTemp("", "tanzu_config")
+	// Setup config data in a directory of its own, as the credentials are stored next to the config
+	dir, err := os.MkdirTemp("", "tanzu_config")
+	assert.Nil(t, err)
+	cfgFile, err := os.CreateTemp(dir, "tanzu_config")
 	assert.Nil(t, err)
 	err = os.WriteFile(cfgFile.Name(), []byte(data.cfg), 0644)
 	assert.Nil(t, err)
@@ -26,7 +29,7 @@ func setupTestConfig(t *testing.T, data *CfgTestData) (files []*os.File, cleanup
 	err = os.Setenv(EnvConfigKey, cfgFile.Name())
 	assert.NoError(t, err)
 
-	cfgNextGenFile, err := os.CreateTemp("", "tanzu_config_ng")
+	cfgNextGenFile, err := os.CreateTemp(dir, "tanzu_config_ng")
 	assert.Nil(t, err)
 	err = os.WriteFile(cfgNextGenFile.Name(), []byte(data.cfgNextGen), 0644)
 	assert.Nil(t, err)
@@ -34,7 +37,7 @@ func setupTestConfig(t *testing.T, data *CfgTestData) (files []*os.File, cleanup
 	err = os.Setenv(EnvConfigNextGenKey, cfgNextGenFile.Name())
 	assert.NoError(t, err)
 
-	cfgMetadataFile, err := os.CreateTemp("", "tanzu_config_metadata")
+	cfgMetadataFile, err := os.CreateTemp(dir, "tanzu_config_metadata")
 	assert.Nil(t, err)
 	err = os.WriteFile(cfgMetadataFile.Name(), []byte(data.cfgMetadata), 0644)
 	assert.Nil(t, err)
@@ -51,6 +54,14 @@ func setupTestConfig(t *testing.T, data *CfgTestData) (files []*os.File, cleanup
 
 		err = os.Remove(cfgMetadataFile.Name())
 		assert.NoError(t, err)
+
+		// The directory is kept for the tests that keep using the config paths
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		for _, entry := range entries {
+			err = os.RemoveAll(filepath.Join(dir, entry.Name()))
+			assert.NoError(t, err)
+		}
 	}
 
 	return []*os.File{cfgFile, cfgNextGenFile, cfgMetadataFile}, cleanup
@@ -139,6 +139,7 @@ servers:
             required: true
           contextType: tmc
 current: test-mc
+schemaVersion: 2
 `
 
 	expectedCfg2 := `contexts:
@@ -164,6 +165,7 @@ current: test-mc
             manifestPath: ctx-test-manifest-path
 currentContext:
     k8s: test-mc
+schemaVersion: 2
 `
 
 	c := &configapi.ClientConfig{
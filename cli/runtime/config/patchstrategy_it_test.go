@@ -102,6 +102,7 @@ contexts:
         context: test-context
 currentContext:
     k8s: test-mc
+schemaVersion: 2
 `
 
 	cfg2 := `contexts:
@@ -135,6 +136,7 @@ currentContext:
         context: test-context
 currentContext:
     k8s: test-mc
+schemaVersion: 2
 `
 
 	return cfg, expectedCfg, cfg2, expectedCfg2
@@ -0,0 +1,312 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"os"
+	"sync"
+
+	"github.com/aunum/log"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config/nodeutils"
+)
+
+// EnvProfileKey is the environment variable selecting the profile in use, overriding the
+// current profile of the config
+const EnvProfileKey = "TANZU_PROFILE"
+
+// warnedMissingProfile avoids warning about the same missing profile on every read
+var warnedMissingProfile sync.Map
+
+// GetProfile retrieves the profile by name
+func GetProfile(name string) (*configapi.Profile, error) {
+	cfg, err := GetClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.GetProfile(name)
+}
+
+// ProfileExists checks whether the profile exists
+func ProfileExists(name string) (bool, error) {
+	cfg, err := GetClientConfig()
+	if err != nil {
+		return false, err
+	}
+	return cfg.HasProfile(name), nil
+}
+
+// SetProfile adds the profile, or replaces the profile of the same name
+func SetProfile(p *configapi.Profile) error {
+	if p == nil || p.Name == "" {
+		return errors.New("profile name is required")
+	}
+	AcquireTanzuConfigLock()
+	defer ReleaseTanzuConfigLock()
+	node, err := getClientConfigNodeNoLock()
+	if err != nil {
+		return err
+	}
+	if err := setProfile(node, p); err != nil {
+		return err
+	}
+	return persistConfig(node)
+}
+
+// GetCurrentProfileName returns the name of the profile in use, i.e. the TANZU_PROFILE env
+// variable if set and the current profile of the config otherwise. It returns an empty name
+// if no profile is in use.
+func GetCurrentProfileName() (string, error) {
+	if name := os.Getenv(EnvProfileKey); name != "" {
+		return name, nil
+	}
+	cfg, err := GetClientConfig()
+	if err != nil {
+		return "", err
+	}
+	return cfg.CurrentProfile, nil
+}
+
+// GetCurrentProfile returns the profile in use, nil if no profile is in use.
+func GetCurrentProfile() (*configapi.Profile, error) {
+	cfg, err := GetClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return currentProfile(cfg)
+}
+
+// SetCurrentProfile sets the current profile of the config, or unsets it if the name is empty
+func SetCurrentProfile(name string) error {
+	AcquireTanzuConfigLock()
+	defer ReleaseTanzuConfigLock()
+	node, err := getClientConfigNodeNoLock()
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		cfg, err := convertNodeToClientConfig(node)
+		if err != nil {
+			return err
+		}
+		if !cfg.HasProfile(name) {
+			return errors.Errorf("profile %q not found", name)
+		}
+	}
+	persist, err := setScalarNode(node, KeyCurrentProfile, name)
+	if err != nil {
+		return err
+	}
+	if persist {
+		return persistConfig(node)
+	}
+	return nil
+}
+
+// ApplyCurrentProfile overlays the client options of the config with the profile in use.
+// The config is only meant to be read once the profile is applied, as storing it would
+// store the profile in the client options.
+func ApplyCurrentProfile(cfg *configapi.ClientConfig) error {
+	p, err := currentProfile(cfg)
+	if err != nil || p == nil {
+		return err
+	}
+	if cfg.ClientOptions == nil {
+		cfg.ClientOptions = &configapi.ClientOptions{}
+	}
+	ApplyProfile(cfg.ClientOptions, p)
+	return nil
+}
+
+// ApplyProfile overlays the client options with the profile. The env variables and feature
+// flags of the profile override the ones of the same name, the discovery sources and
+// repositories of the profile replace the ones of the same name and the others are added.
+func ApplyProfile(options *configapi.ClientOptions, p *configapi.Profile) {
+	for key, value := range p.Env {
+		if options.Env == nil {
+			options.Env = make(map[string]string)
+		}
+		options.Env[key] = value
+	}
+	for plugin, features := range p.Features {
+		if options.Features == nil {
+			options.Features = make(map[string]configapi.FeatureMap)
+		}
+		if options.Features[plugin] == nil {
+			options.Features[plugin] = make(configapi.FeatureMap)
+		}
+		for key, value := range features {
+			options.Features[plugin][key] = value
+		}
+	}
+
+	if len(p.DiscoverySources) == 0 && len(p.Repositories) == 0 && p.Edition == "" {
+		return
+	}
+	if options.CLI == nil {
+		options.CLI = &configapi.CLIOptions{}
+	}
+	for _, ds := range p.DiscoverySources {
+		_, name := getDiscoverySourceTypeAndName(ds)
+		index := -1
+		for i := range options.CLI.DiscoverySources {
+			if _, n := getDiscoverySourceTypeAndName(options.CLI.DiscoverySources[i]); n == name {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			options.CLI.DiscoverySources = append(options.CLI.DiscoverySources, *ds.DeepCopy())
+		} else {
+			options.CLI.DiscoverySources[index] = *ds.DeepCopy()
+		}
+	}
+	for _, repo := range p.Repositories {
+		_, name := getRepositoryTypeAndName(repo)
+		index := -1
+		for i := range options.CLI.Repositories {
+			if _, n := getRepositoryTypeAndName(options.CLI.Repositories[i]); n == name {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			options.CLI.Repositories = append(options.CLI.Repositories, *repo.DeepCopy())
+		} else {
+			options.CLI.Repositories[index] = *repo.DeepCopy()
+		}
+	}
+	if p.Edition != "" {
+		options.CLI.Edition = p.Edition //nolint:staticcheck
+	}
+}
+
+// currentProfile returns the profile in use of the config, nil if no profile is in use
+func currentProfile(cfg *configapi.ClientConfig) (*configapi.Profile, error) {
+	name := os.Getenv(EnvProfileKey)
+	if name == "" {
+		name = cfg.CurrentProfile
+	}
+	if name == "" {
+		return nil, nil
+	}
+	p, err := cfg.GetProfile(name)
+	if err != nil {
+		return nil, errors.Errorf("profile %q not found", name)
+	}
+	return p, nil
+}
+
+// getClientConfigNodeWithProfile returns the client config node overlaid with the profile in
+// use, to read the client options. A profile which does not exist is ignored with a warning.
+func getClientConfigNodeWithProfile() (*yaml.Node, error) {
+	node, err := getClientConfigNode()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := convertNodeToClientConfig(node)
+	if err != nil {
+		return nil, err
+	}
+	if os.Getenv(EnvProfileKey) == "" && cfg.CurrentProfile == "" {
+		return node, nil
+	}
+	if err := ApplyCurrentProfile(cfg); err != nil {
+		warnIgnoredProfile(err)
+		return node, nil
+	}
+	return convertClientConfigToNode(cfg)
+}
+
+// warnIgnoredProfile warns once that the profile in use is ignored
+func warnIgnoredProfile(err error) {
+	if _, warned := warnedMissingProfile.LoadOrStore(err.Error(), true); !warned {
+		log.Warningf("ignoring the profile in use: %v", err)
+	}
+}
+
+// checkNotProvidedByProfile returns an error if the entry of the client options is provided
+// by the profile in use. The profiles only overlay the client options on read, so writing
+// the entry to the client options would not change the entry read.
+func checkNotProvidedByProfile(node *yaml.Node, entry string, provides func(p *configapi.Profile) bool) error {
+	cfg, err := convertNodeToClientConfig(node)
+	if err != nil {
+		return err
+	}
+	p, err := currentProfile(cfg)
+	if err != nil || p == nil {
+		// A profile which does not exist is ignored
+		return nil
+	}
+	if provides(p) {
+		return errors.Errorf("%s is provided by the profile %q in use, update the profile with 'tanzu config profile' instead", entry, p.Name)
+	}
+	return nil
+}
+
+// profileProvidesDiscoverySource returns whether the profile provides the discovery source
+func profileProvidesDiscoverySource(name string) func(p *configapi.Profile) bool {
+	return func(p *configapi.Profile) bool {
+		for _, ds := range p.DiscoverySources {
+			if _, n := getDiscoverySourceTypeAndName(ds); n == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// profileProvidesRepository returns whether the profile provides the repository
+func profileProvidesRepository(name string) func(p *configapi.Profile) bool {
+	return func(p *configapi.Profile) bool {
+		for _, repo := range p.Repositories {
+			if _, n := getRepositoryTypeAndName(repo); n == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// profileProvidesEnv returns whether the profile provides the env variable
+func profileProvidesEnv(key string) func(p *configapi.Profile) bool {
+	return func(p *configapi.Profile) bool {
+		_, ok := p.Env[key]
+		return ok
+	}
+}
+
+// profileProvidesFeature returns whether the profile provides the feature flag of the plugin
+func profileProvidesFeature(plugin, key string) func(p *configapi.Profile) bool {
+	return func(p *configapi.Profile) bool {
+		_, ok := p.Features[plugin][key]
+		return ok
+	}
+}
+
+// setProfile adds the profile to the profiles node, or replaces the profile of the same name
+func setProfile(node *yaml.Node, p *configapi.Profile) error {
+	newProfileNode, err := convertProfileToNode(p)
+	if err != nil {
+		return err
+	}
+	keys := []nodeutils.Key{
+		{Name: KeyProfiles, Type: yaml.SequenceNode},
+	}
+	profilesNode := nodeutils.FindNode(node.Content[0], nodeutils.WithForceCreate(), nodeutils.WithKeys(keys))
+	if profilesNode == nil {
+		return nodeutils.ErrNodeNotFound
+	}
+	for i, profileNode := range profilesNode.Content {
+		if index := nodeutils.GetNodeIndex(profileNode.Content, "name"); index != -1 && profileNode.Content[index].Value == p.Name {
+			profilesNode.Content[i] = newProfileNode.Content[0]
+			return nil
+		}
+	}
+	profilesNode.Content = append(profilesNode.Content, newProfileNode.Content[0])
+	return nil
+}
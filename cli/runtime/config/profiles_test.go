@@ -0,0 +1,118 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+)
+
+func TestProfiles(t *testing.T) {
+	assert := assert.New(t)
+
+	setupSchemaTestConfig(t, `clientOptions:
+  cli:
+    discoverySources:
+      - oci:
+          name: default
+          image: registry.example.com/plugins:latest
+      - local:
+          name: local
+          path: standalone
+    edition: tkg
+  env:
+    FOO: base
+    BAR: base
+  features:
+    global:
+      context-target: "false"
+schemaVersion: 2
+`, "")
+	t.Setenv(EnvProfileKey, "")
+
+	assert.NoError(SetProfile(&configapi.Profile{Name: "lab", Env: map[string]string{"FOO": "first"}}))
+	assert.NoError(SetProfile(&configapi.Profile{
+		Name:     "lab",
+		Env:      map[string]string{"FOO": "lab", "BAZ": "lab"},
+		Features: map[string]configapi.FeatureMap{"global": {"context-target": "true"}},
+		DiscoverySources: []configapi.PluginDiscovery{
+			{Local: &configapi.LocalDiscovery{Name: "default", Path: "lab"}},
+			{Local: &configapi.LocalDiscovery{Name: "extra", Path: "extra"}},
+		},
+		Edition: configapi.EditionCommunity,
+	}))
+	assert.NoError(SetProfile(&configapi.Profile{Name: "prod"}))
+	assert.Error(SetProfile(&configapi.Profile{}))
+
+	cfg, err := GetClientConfig()
+	assert.NoError(err)
+	assert.Len(cfg.Profiles, 2)
+	p, err := GetProfile("lab")
+	assert.NoError(err)
+	assert.Equal(map[string]string{"FOO": "lab", "BAZ": "lab"}, p.Env)
+
+	// No profile is in use
+	assert.Equal(map[string]string{"FOO": "base", "BAR": "base"}, GetEnvConfigurations())
+	current, err := GetCurrentProfile()
+	assert.NoError(err)
+	assert.Nil(current)
+
+	assert.Error(SetCurrentProfile("missing"))
+	assert.NoError(SetCurrentProfile("lab"))
+	name, err := GetCurrentProfileName()
+	assert.NoError(err)
+	assert.Equal("lab", name)
+
+	assert.Equal(map[string]string{"FOO": "lab", "BAR": "base", "BAZ": "lab"}, GetEnvConfigurations())
+	enabled, err := IsFeatureEnabled("global", "context-target")
+	assert.NoError(err)
+	assert.True(enabled)
+	assert.True(IsFeatureActivated("features.global.context-target"))
+	edition, err := GetEdition()
+	assert.NoError(err)
+	assert.Equal(configapi.EditionCommunity, edition)
+	discoverySources, err := GetCLIDiscoverySources()
+	assert.NoError(err)
+	assert.Equal([]configapi.PluginDiscovery{
+		{Local: &configapi.LocalDiscovery{Name: "default", Path: "lab"}},
+		{Local: &configapi.LocalDiscovery{Name: "local", Path: "standalone"}},
+		{Local: &configapi.LocalDiscovery{Name: "extra", Path: "extra"}},
+	}, discoverySources)
+
+	// The profile is not stored in the client options
+	cfg, err = GetClientConfig()
+	assert.NoError(err)
+	assert.Equal("base", cfg.ClientOptions.Env["FOO"])
+	assert.NoError(SetEnv("BAR", "updated"))
+	assert.Equal(map[string]string{"FOO": "lab", "BAR": "updated", "BAZ": "lab"}, GetEnvConfigurations())
+
+	// The entries provided by the profile are not written to the client options
+	err = SetCLIDiscoverySource(configapi.PluginDiscovery{Local: &configapi.LocalDiscovery{Name: "default", Path: "updated"}})
+	assert.ErrorContains(err, `discovery source "default" is provided by the profile "lab" in use`)
+	assert.Error(DeleteCLIDiscoverySource("extra"))
+	assert.NoError(SetCLIDiscoverySource(configapi.PluginDiscovery{Local: &configapi.LocalDiscovery{Name: "local", Path: "updated"}}))
+	assert.Error(SetEnv("FOO", "updated"))
+	assert.Error(DeleteEnv("BAZ"))
+	assert.Error(SetFeature("global", "context-target", "false"))
+	assert.Error(SetEdition(configapi.EditionStandard))
+	cfg, err = GetClientConfig()
+	assert.NoError(err)
+	assert.Equal("base", cfg.ClientOptions.Env["FOO"])
+	assert.Equal("default", cfg.ClientOptions.CLI.DiscoverySources[0].OCI.Name)
+
+	// The env variable overrides the current profile
+	t.Setenv(EnvProfileKey, "prod")
+	assert.Equal(map[string]string{"FOO": "base", "BAR": "updated"}, GetEnvConfigurations())
+	t.Setenv(EnvProfileKey, "missing")
+	assert.Equal(map[string]string{"FOO": "base", "BAR": "updated"}, GetEnvConfigurations())
+	_, err = GetCurrentProfile()
+	assert.Error(err)
+
+	t.Setenv(EnvProfileKey, "")
+	assert.NoError(SetCurrentProfile(""))
+	assert.False(IsFeatureActivated("features.global.context-target"))
+}
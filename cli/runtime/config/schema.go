@@ -0,0 +1,452 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aunum/log"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config/nodeutils"
+)
+
+const (
+	// KeySchemaVersion is the key of the schema version of the config documents
+	KeySchemaVersion = "schemaVersion"
+
+	// configBackupDirName is the name of the directory, next to the config
+	// documents, where the config documents are backed up before migration
+	configBackupDirName = "backups"
+	// maxConfigBackups is the number of backups kept for every config document
+	maxConfigBackups = 5
+)
+
+// Migration migrates the config documents from the previous schema version to its
+// version (Up) and back (Down). The steps are applied to the client config node
+// combining config.yaml and config-ng.yaml, and must be idempotent as documents
+// written by older versions of the CLI are migrated again.
+type Migration struct {
+	// Version is the schema version of the documents migrated by the Up step.
+	Version int
+	// Description of the migration.
+	Description string
+	// Up migrates the documents from Version-1 to Version.
+	Up func(node *yaml.Node) error
+	// Down migrates the documents from Version back to Version-1.
+	Down func(node *yaml.Node) error
+}
+
+// configMigrations is the ordered registry of migrations, where the version of the
+// n-th migration is n. The last version is the schema version of the documents
+// written by this version of the library.
+var configMigrations = []*Migration{
+	{
+		Version:     1,
+		Description: "Add the contexts of the servers written by older versions of the CLI",
+		Up:          migrateServersToContexts,
+		Down:        migrateContextsToServers,
+	},
+	{
+		Version:     credentialsSchemaVersion,
+		Description: "Move the tokens of the contexts and servers to the credential store",
+		Up:          storeCredentials,
+		Down:        restoreCredentials,
+	},
+}
+
+// warnedNewerSchemaVersion avoids warning about the same newer schema version on every read
+var warnedNewerSchemaVersion sync.Map
+
+// upToDateSchema caches the last check of the schema version of the config documents
+// which found them up to date, so that the documents are only parsed again once changed
+var upToDateSchema struct {
+	sync.Mutex
+	stamp   string
+	version int
+}
+
+// Migrations returns the ordered registry of the config schema migrations.
+func Migrations() []Migration {
+	migrations := make([]Migration, 0, len(configMigrations))
+	for _, m := range configMigrations {
+		migrations = append(migrations, *m)
+	}
+	return migrations
+}
+
+// CurrentSchemaVersion returns the schema version of the config documents written by
+// this version of the library.
+func CurrentSchemaVersion() int {
+	return len(configMigrations)
+}
+
+// GetSchemaVersion returns the schema version of the config documents, i.e. the newest
+// version of the documents if a document is newer than the current version, and the
+// oldest version otherwise. Documents without version were written before the versioning
+// of the schema, and have the version 0.
+func GetSchemaVersion() (int, error) {
+	paths, err := configDocumentPaths()
+	if err != nil {
+		return 0, err
+	}
+	oldest, newest := CurrentSchemaVersion(), CurrentSchemaVersion()
+	for _, path := range paths {
+		v, exists, err := documentSchemaVersion(path)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			continue
+		}
+		if v < oldest {
+			oldest = v
+		}
+		if v > newest {
+			newest = v
+		}
+	}
+	if newest > CurrentSchemaVersion() {
+		return newest, nil
+	}
+	return oldest, nil
+}
+
+// MigrateSchema migrates the config documents up or down to the schema version,
+// backing up the documents first. The documents are migrated down before switching
+// to an older version of the CLI, as the documents are otherwise migrated up again.
+func MigrateSchema(version int) error {
+	if version < 0 || version > CurrentSchemaVersion() {
+		return errors.Errorf("unknown config schema version %v, the supported versions are 0 to %v", version, CurrentSchemaVersion())
+	}
+	AcquireTanzuConfigLock()
+	defer ReleaseTanzuConfigLock()
+	from, err := GetSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if from > CurrentSchemaVersion() {
+		return errors.Errorf("the config schema version %v is newer than the supported version %v", from, CurrentSchemaVersion())
+	}
+	return migrateSchemaNoLock(from, version)
+}
+
+// migrateSchemaIfNeeded migrates the config documents written by older versions of
+// the CLI to the current schema version, acquiring the lock only if needed
+func migrateSchemaIfNeeded() error {
+	// The documents may be written concurrently, so they are read again with the lock
+	// unless they are already up to date
+	if stamp, err := configDocumentsStamp(); err == nil {
+		if isSchemaUpToDate(stamp) {
+			return nil
+		}
+		if version, err := GetSchemaVersion(); err == nil && version == CurrentSchemaVersion() {
+			setSchemaUpToDate(stamp)
+			return nil
+		}
+	}
+	AcquireTanzuConfigLock()
+	defer ReleaseTanzuConfigLock()
+	return migrateSchemaIfNeededNoLock()
+}
+
+// migrateSchemaIfNeededNoLock migrates the config documents written by older versions
+// of the CLI to the current schema version without acquiring the lock
+func migrateSchemaIfNeededNoLock() error {
+	stamp, err := configDocumentsStamp()
+	if err != nil {
+		return err
+	}
+	if isSchemaUpToDate(stamp) {
+		return nil
+	}
+	version, err := GetSchemaVersion()
+	if err != nil {
+		return err
+	}
+	switch {
+	case version == CurrentSchemaVersion():
+		setSchemaUpToDate(stamp)
+		return nil
+	case version > CurrentSchemaVersion():
+		if _, warned := warnedNewerSchemaVersion.LoadOrStore(version, true); !warned {
+			log.Warningf("the config schema version %v is newer than the supported version %v, please upgrade the CLI", version, CurrentSchemaVersion())
+		}
+		return nil
+	}
+	if err := migrateSchemaNoLock(version, CurrentSchemaVersion()); err != nil {
+		return err
+	}
+	if stamp, err := configDocumentsStamp(); err == nil {
+		setSchemaUpToDate(stamp)
+	}
+	return nil
+}
+
+// configDocumentsStamp returns the paths, sizes and modification times of the config
+// documents and of the config metadata, which selects the documents in use
+func configDocumentsStamp() (string, error) {
+	var paths []string
+	for _, pathFunc := range []func() (string, error){ClientConfigPath, ClientConfigNextGenPath, CfgMetadataFilePath} {
+		path, err := pathFunc()
+		if err != nil {
+			return "", err
+		}
+		paths = append(paths, path)
+	}
+	var stamp strings.Builder
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		switch {
+		case os.IsNotExist(err):
+			fmt.Fprintf(&stamp, "%s:-;", path)
+		case err != nil:
+			return "", err
+		default:
+			fmt.Fprintf(&stamp, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return stamp.String(), nil
+}
+
+// isSchemaUpToDate returns whether the config documents with the stamp were found up
+// to date by the last check
+func isSchemaUpToDate(stamp string) bool {
+	upToDateSchema.Lock()
+	defer upToDateSchema.Unlock()
+	return upToDateSchema.stamp == stamp && upToDateSchema.version == CurrentSchemaVersion()
+}
+
+// setSchemaUpToDate records that the config documents with the stamp are up to date
+func setSchemaUpToDate(stamp string) {
+	upToDateSchema.Lock()
+	defer upToDateSchema.Unlock()
+	upToDateSchema.stamp = stamp
+	upToDateSchema.version = CurrentSchemaVersion()
+}
+
+// migrateSchemaNoLock applies the migration steps between the versions to the config
+// documents and persists them with the target version. The documents are backed up
+// if the steps change them.
+func migrateSchemaNoLock(from, to int) error {
+	node, err := readClientConfigNodeNoLock()
+	if err != nil {
+		return err
+	}
+	before, err := yaml.Marshal(node)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the config")
+	}
+
+	for v := from; v < to; v++ {
+		if err := configMigrations[v].Up(node); err != nil {
+			return errors.Wrapf(err, "failed to migrate the config to schema version %v", v+1)
+		}
+	}
+	for v := from; v > to; v-- {
+		if err := configMigrations[v-1].Down(node); err != nil {
+			return errors.Wrapf(err, "failed to migrate the config back to schema version %v", v-1)
+		}
+	}
+
+	after, err := yaml.Marshal(node)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the config")
+	}
+	if !bytes.Equal(before, after) {
+		if _, err := BackupConfig(from); err != nil {
+			return errors.Wrap(err, "failed to back up the config before migration")
+		}
+	}
+	return persistConfigWithSchemaVersion(node, to)
+}
+
+// BackupConfig copies the existing config documents to the backup directory, naming the
+// copies after the schema version and the time of the backup, and returns their paths.
+// Only the latest backups of every document are kept.
+func BackupConfig(version int) ([]string, error) {
+	paths, err := configDocumentPaths()
+	if err != nil {
+		return nil, err
+	}
+	metadataPath, err := CfgMetadataFilePath()
+	if err != nil {
+		return nil, err
+	}
+	paths = append(paths, metadataPath)
+	var backups []string
+	timestamp := time.Now().UTC().Format("20060102T150405.000000000")
+	for _, path := range paths {
+		exists, err := fileExists(path)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		backupDir := filepath.Join(filepath.Dir(path), configBackupDirName)
+		if err := os.MkdirAll(backupDir, 0755); err != nil {
+			return nil, err
+		}
+		backup := filepath.Join(backupDir, fmt.Sprintf("%s.%s.v%d", filepath.Base(path), timestamp, version))
+		if err := copyFile(path, backup); err != nil {
+			return nil, err
+		}
+		backups = append(backups, backup)
+		if err := pruneConfigBackups(path); err != nil {
+			log.Infof("unable to remove the old backups of %v: %v", path, err.Error())
+		}
+	}
+	return backups, nil
+}
+
+// ConfigBackups returns the backups of the config document, the latest first.
+func ConfigBackups(path string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(path), configBackupDirName, filepath.Base(path)+".*.v*"))
+	if err != nil {
+		return nil, err
+	}
+	// The timestamps of the backup names sort chronologically
+	sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+	return matches, nil
+}
+
+func pruneConfigBackups(path string) error {
+	backups, err := ConfigBackups(path)
+	if err != nil || len(backups) <= maxConfigBackups {
+		return err
+	}
+	for _, backup := range backups[maxConfigBackups:] {
+		if err := os.Remove(backup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configDocumentPaths returns the paths of the versioned config documents, i.e.
+// config-ng.yaml and, unless the unified config is used, config.yaml
+func configDocumentPaths() ([]string, error) {
+	nextGenPath, err := ClientConfigNextGenPath()
+	if err != nil {
+		return nil, err
+	}
+	if useUnifiedConfig, err := UseUnifiedConfig(); err == nil && useUnifiedConfig {
+		return []string{nextGenPath}, nil
+	}
+	path, err := ClientConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return []string{path, nextGenPath}, nil
+}
+
+// documentSchemaVersion returns the schema version of the config document and
+// whether the document exists. Empty documents are considered missing.
+func documentSchemaVersion(path string) (version int, exists bool, err error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) || (err == nil && len(bytes.TrimSpace(b)) == 0) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return 0, true, errors.Wrapf(err, "failed to parse the config %v, run 'tanzu config doctor' to repair it", path)
+	}
+	value, ok := doc[KeySchemaVersion]
+	if !ok {
+		return 0, true, nil
+	}
+	version, err = strconv.Atoi(strings.TrimSpace(fmt.Sprint(value)))
+	if err != nil {
+		return 0, true, errors.Errorf("invalid config schema version %q in %v", value, path)
+	}
+	return version, true, nil
+}
+
+// setSchemaVersion sets the schema version of the config document node
+func setSchemaVersion(node *yaml.Node, version int) error {
+	keys := []nodeutils.Key{
+		{Name: KeySchemaVersion, Type: yaml.ScalarNode, Value: ""},
+	}
+	versionNode := nodeutils.FindNode(node.Content[0], nodeutils.WithForceCreate(), nodeutils.WithKeys(keys))
+	if versionNode == nil {
+		return nodeutils.ErrNodeNotFound
+	}
+	versionNode.Tag = "!!int"
+	versionNode.Style = 0
+	versionNode.Value = strconv.Itoa(version)
+	return nil
+}
+
+// migrateServersToContexts adds the contexts of the servers missing in the contexts
+func migrateServersToContexts(node *yaml.Node) error {
+	var cfg configapi.ClientConfig
+	if err := node.Decode(&cfg); err != nil {
+		return errors.Wrap(err, "failed to convert node to ClientConfig")
+	}
+	known := make(map[string]bool)
+	for _, c := range cfg.KnownContexts {
+		known[c.Name] = true
+	}
+	if !PopulateContexts(&cfg) {
+		return nil
+	}
+	for _, c := range cfg.KnownContexts {
+		if known[c.Name] {
+			continue
+		}
+		if _, err := setContext(node, c); err != nil {
+			return err
+		}
+		if cfg.CurrentContext[c.Type] == c.Name {
+			if _, err := setCurrentContext(node, c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// migrateContextsToServers adds the servers of the contexts missing in the servers, so
+// that the older versions of the CLI, which only know the servers, find them. The
+// contexts are kept as the servers cannot hold all their settings.
+func migrateContextsToServers(node *yaml.Node) error {
+	var cfg configapi.ClientConfig
+	if err := node.Decode(&cfg); err != nil {
+		return errors.Wrap(err, "failed to convert node to ClientConfig")
+	}
+	known := make(map[string]bool)
+	for _, s := range cfg.KnownServers {
+		known[s.Name] = true
+	}
+	currentServer := cfg.CurrentServer
+	populateServers(&cfg)
+	for _, s := range cfg.KnownServers {
+		if known[s.Name] {
+			continue
+		}
+		if _, err := setServer(node, s); err != nil {
+			return err
+		}
+	}
+	if cfg.CurrentServer != currentServer {
+		if _, err := setCurrentServer(node, cfg.CurrentServer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config/nodeutils"
+)
+
+// setupSchemaTestConfig writes the config documents in a temporary directory and
+// returns the paths of config.yaml and config-ng.yaml
+func setupSchemaTestConfig(t *testing.T, cfg, cfgNextGen string) (string, string) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	cfgNextGenPath := filepath.Join(dir, "config-ng.yaml")
+	t.Setenv(EnvConfigKey, cfgPath)
+	t.Setenv(EnvConfigNextGenKey, cfgNextGenPath)
+	t.Setenv(EnvConfigMetadataKey, filepath.Join(dir, "config-metadata.yaml"))
+	t.Setenv(EnvCredentialStoreKey, CredentialStoreConfig)
+	if cfg != "" {
+		assert.NoError(t, os.WriteFile(cfgPath, []byte(cfg), 0644))
+	}
+	if cfgNextGen != "" {
+		assert.NoError(t, os.WriteFile(cfgNextGenPath, []byte(cfgNextGen), 0644))
+	}
+	return cfgPath, cfgNextGenPath
+}
+
+func TestSchemaMigrationOfOlderConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	// config.yaml written by an older CLI which only knows the servers
+	cfgPath, cfgNextGenPath := setupSchemaTestConfig(t, `servers:
+  - name: test-mc
+    type: managementcluster
+    managementClusterOpts:
+      endpoint: test-endpoint
+      path: test-path
+      context: test-context
+current: test-mc
+`, "")
+
+	version, err := GetSchemaVersion()
+	assert.NoError(err)
+	assert.Equal(0, version)
+
+	ctx, err := GetCurrentContext("k8s")
+	assert.NoError(err)
+	assert.Equal("test-mc", ctx.Name)
+	assert.Equal("test-path", ctx.ClusterOpts.Path)
+
+	version, err = GetSchemaVersion()
+	assert.NoError(err)
+	assert.Equal(CurrentSchemaVersion(), version)
+	for _, path := range []string{cfgPath, cfgNextGenPath} {
+		b, err := os.ReadFile(path)
+		assert.NoError(err)
+		assert.Contains(string(b), "schemaVersion: 2\n")
+	}
+
+	// The original document is backed up
+	backups, err := ConfigBackups(cfgPath)
+	assert.NoError(err)
+	assert.Len(backups, 1)
+	assert.Regexp(`config\.yaml\.\d{8}T\d{6}\.\d{9}\.v0$`, backups[0])
+	b, err := os.ReadFile(backups[0])
+	assert.NoError(err)
+	assert.NotContains(string(b), "schemaVersion")
+
+	// Up to date documents are not migrated again
+	_, err = GetClientConfig()
+	assert.NoError(err)
+	backups, err = ConfigBackups(cfgPath)
+	assert.NoError(err)
+	assert.Len(backups, 1)
+}
+
+func TestSchemaMigrationUpAndDown(t *testing.T) {
+	assert := assert.New(t)
+
+	migrations := configMigrations
+	t.Cleanup(func() { configMigrations = migrations })
+	setKey := func(key, value string) func(node *yaml.Node) error {
+		return func(node *yaml.Node) error {
+			_, err := setScalarNode(node, key, value)
+			return err
+		}
+	}
+	current := CurrentSchemaVersion()
+	configMigrations = append(append([]*Migration{}, migrations...),
+		&Migration{Version: current + 1, Description: "rename", Up: setKey("edition", "tkg"), Down: setKey("edition", "tce")},
+	)
+
+	cfgPath, _ := setupSchemaTestConfig(t, "", fmt.Sprintf("edition: tce\nschemaVersion: %d\n", current))
+	assert.Equal(current+1, CurrentSchemaVersion())
+
+	edition := func() string {
+		node, err := readClientConfigNodeNoLock()
+		assert.NoError(err)
+		editionNode := nodeutils.FindNode(node.Content[0], nodeutils.WithKeys([]nodeutils.Key{{Name: "edition"}}))
+		assert.NotNil(editionNode)
+		return editionNode.Value
+	}
+
+	_, err := GetClientConfig()
+	assert.NoError(err)
+	assert.Equal("tkg", edition())
+
+	assert.NoError(MigrateSchema(current))
+	assert.Equal("tce", edition())
+	version, err := GetSchemaVersion()
+	assert.NoError(err)
+	assert.Equal(current, version)
+
+	assert.Error(MigrateSchema(current + 2))
+
+	// Documents of a newer version are neither migrated nor downgraded
+	assert.NoError(os.WriteFile(cfgPath, []byte("schemaVersion: 9\n"), 0644))
+	_, err = GetClientConfig()
+	assert.NoError(err)
+	assert.Equal("tce", edition())
+	version, err = GetSchemaVersion()
+	assert.NoError(err)
+	assert.Equal(9, version)
+	assert.Error(MigrateSchema(current + 1))
+}
+
+func TestBackupConfigKeepsLatestBackups(t *testing.T) {
+	assert := assert.New(t)
+
+	cfgPath, _ := setupSchemaTestConfig(t, "current: test-mc\n", "")
+	for i := 0; i < maxConfigBackups+2; i++ {
+		backups, err := BackupConfig(i)
+		assert.NoError(err)
+		assert.Len(backups, 1)
+	}
+	backups, err := ConfigBackups(cfgPath)
+	assert.NoError(err)
+	assert.Len(backups, maxConfigBackups)
+	assert.Regexp(`\.v6$`, backups[0])
+}
+
+func TestSchemaMigrationDownAddsServers(t *testing.T) {
+	assert := assert.New(t)
+
+	// config-ng.yaml written by this version of the CLI which only knows the contexts
+	cfgPath, _ := setupSchemaTestConfig(t, "", `contexts:
+  - name: test-mc
+    type: k8s
+    clusterOpts:
+      endpoint: test-endpoint
+      path: test-path
+      context: test-context
+      isManagementCluster: true
+currentContext:
+  k8s: test-mc
+schemaVersion: 2
+`)
+
+	assert.NoError(MigrateSchema(0))
+	version, err := GetSchemaVersion()
+	assert.NoError(err)
+	assert.Equal(0, version)
+
+	server, err := GetServer("test-mc")
+	assert.NoError(err)
+	assert.Equal("test-path", server.ManagementClusterOpts.Path)
+	current, err := GetCurrentServer()
+	assert.NoError(err)
+	assert.Equal("test-mc", current.Name)
+	ctx, err := GetContext("test-mc")
+	assert.NoError(err)
+	assert.Equal("test-context", ctx.ClusterOpts.Context)
+
+	// The older versions of the CLI find the server in config.yaml
+	b, err := os.ReadFile(cfgPath)
+	assert.NoError(err)
+	assert.Contains(string(b), "current: test-mc\n")
+}
+
+func TestSchemaVersionCheckIsCached(t *testing.T) {
+	assert := assert.New(t)
+
+	cfgPath, _ := setupSchemaTestConfig(t, "current: test-mc\n", "")
+	_, err := GetClientConfig()
+	assert.NoError(err)
+	stamp, err := configDocumentsStamp()
+	assert.NoError(err)
+	assert.True(isSchemaUpToDate(stamp))
+
+	// Changed documents are checked again
+	assert.NoError(os.WriteFile(cfgPath, []byte("current: test-mc\nschemaVersion: 0\n"), 0644))
+	stamp, err = configDocumentsStamp()
+	assert.NoError(err)
+	assert.False(isSchemaUpToDate(stamp))
+	_, err = GetClientConfig()
+	assert.NoError(err)
+	version, err := GetSchemaVersion()
+	assert.NoError(err)
+	assert.Equal(CurrentSchemaVersion(), version)
+}
@@ -205,6 +205,7 @@ func RemoveServer(name string) error {
 	if err != nil {
 		return err
 	}
+	refs := getCredentialRefs(node, name)
 	err = removeServer(node, name)
 	if err != nil {
 		return err
@@ -222,7 +223,11 @@ func RemoveServer(name string) error {
 	if err != nil {
 		return err
 	}
-	return persistConfig(node)
+	err = persistConfig(node)
+	if err != nil {
+		return err
+	}
+	return deleteCredentials(node, refs...)
 }
 
 func setCurrentServer(node *yaml.Node, name string) (persist bool, err error) {
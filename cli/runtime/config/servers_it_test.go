@@ -88,6 +88,7 @@ servers:
             bucket: test-bucket-updated
             manifestPath: test-manifest-path
 current: test-mc2
+schemaVersion: 2
 `
 
 	cfg2 := `contexts:
@@ -148,6 +149,7 @@ currentContext:
             manifestPath: test-manifest-path
 currentContext:
     k8s: test-mc2
+schemaVersion: 2
 `
 
 	return cfg, expectedCfg, cfg2, expectedCfg2
@@ -284,6 +286,7 @@ func TestServersIntegrationAndMigratedToNewConfig(t *testing.T) {
             manifestPath: test-manifest-path
 currentContext:
     k8s: test-mc2
+schemaVersion: 2
 servers:
     - name: test-mc2
       type: k8s
@@ -0,0 +1,91 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+)
+
+// CompletionFunc returns the completion results of a positional argument or flag value
+// being typed, given the positional arguments already typed. A result is either a value
+// or a value and its description separated by a tab, e.g. "my-cluster\tworkload cluster".
+//
+// The Tanzu CLI forwards the shell completion requests to the plugin with the cobra
+// __complete command, so the completion functions are used for all the shells.
+type CompletionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// RegisterCompletion sets the dynamic completion of the positional arguments of the
+// command, one completion function per position. The positional arguments after the
+// last completion function are not completed.
+func RegisterCompletion(cmd *cobra.Command, completions ...CompletionFunc) {
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= len(completions) || completions[len(args)] == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completions[len(args)](cmd, args, toComplete)
+	}
+}
+
+// RegisterFlagCompletion sets the dynamic completion of the values of the flag of the command.
+func RegisterFlagCompletion(cmd *cobra.Command, flagName string, completion CompletionFunc) error {
+	return cmd.RegisterFlagCompletionFunc(flagName, completion)
+}
+
+// CompleteValues returns a completion function completing the values starting with the
+// value being typed. Values can be described, separating the value and its description with a tab.
+func CompleteValues(values ...string) CompletionFunc {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterCompletions(values, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// CompleteValuesFunc returns a completion function completing the values returned by
+// the function starting with the value being typed, e.g. the names of the resources
+// listed from the current context. Errors are reported to the shell as completion errors.
+func CompleteValuesFunc(f func(cmd *cobra.Command, args []string) ([]string, error)) CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		values, err := f(cmd, args)
+		if err != nil {
+			cobra.CompErrorln(err.Error())
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return filterCompletions(values, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// CompleteContexts returns a completion function completing the names of the contexts
+// of the type, or of all the contexts if the type is empty.
+func CompleteContexts(ctxType configapi.ContextType) CompletionFunc {
+	return CompleteValuesFunc(func(_ *cobra.Command, _ []string) ([]string, error) {
+		cfg, err := config.GetClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, ctx := range cfg.KnownContexts {
+			if ctxType == "" || ctx.Type == ctxType {
+				names = append(names, fmt.Sprintf("%s\t%s", ctx.Name, ctx.Type))
+			}
+		}
+		return names, nil
+	})
+}
+
+// filterCompletions returns the completion results whose value starts with the prefix
+func filterCompletions(values []string, prefix string) []string {
+	var results []string
+	for _, v := range values {
+		value := strings.SplitN(v, "\t", 2)[0]
+		if strings.HasPrefix(value, prefix) {
+			results = append(results, v)
+		}
+	}
+	return results
+}
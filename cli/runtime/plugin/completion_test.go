@@ -0,0 +1,75 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+)
+
+// complete runs the cobra __complete command of the plugin like the Tanzu CLI does
+func complete(t *testing.T, p *Plugin, args ...string) string {
+	var out bytes.Buffer
+	p.Cmd.SetOut(&out)
+	p.Cmd.SetErr(&bytes.Buffer{})
+	p.Cmd.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+	assert.NoError(t, p.Execute())
+	return out.String()
+}
+
+func TestCompletion(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	t.Setenv("TANZU_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("TANZU_CONFIG_NEXT_GEN", filepath.Join(dir, "config-ng.yaml"))
+	t.Setenv("TANZU_CONFIG_METADATA", filepath.Join(dir, "config-metadata.yaml"))
+	assert.NoError(config.AddContext(&configapi.Context{Name: "mgmt", Type: configapi.CtxTypeK8s}, true))
+	assert.NoError(config.AddContext(&configapi.Context{Name: "tmc", Type: configapi.CtxTypeTMC}, true))
+
+	p, err := NewPlugin(&cliapi.PluginDescriptor{
+		Name:        "cluster",
+		Description: "Cluster operations",
+		Version:     "v1.2.3",
+		Group:       cliapi.RunCmdGroup,
+	})
+	assert.NoError(err)
+
+	var ctxName string
+	getCmd := &cobra.Command{
+		Use:  "get CLUSTER_NAME NODE_NAME",
+		RunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+	getCmd.Flags().StringVar(&ctxName, "context", "", "context of the cluster")
+	RegisterCompletion(getCmd,
+		CompleteValuesFunc(func(_ *cobra.Command, _ []string) ([]string, error) {
+			return []string{"cluster-1\tworkload cluster", "cluster-2", "mgmt"}, nil
+		}),
+		CompleteValues("node-a", "node-b"),
+	)
+	assert.NoError(RegisterFlagCompletion(getCmd, "context", CompleteContexts(configapi.CtxTypeK8s)))
+	deleteCmd := &cobra.Command{
+		Use:  "delete CLUSTER_NAME",
+		RunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+	RegisterCompletion(deleteCmd, CompleteValuesFunc(func(_ *cobra.Command, _ []string) ([]string, error) {
+		return nil, errors.New("unable to list the clusters")
+	}))
+	p.AddCommands(getCmd, deleteCmd)
+
+	assert.Equal("cluster-1\tworkload cluster\ncluster-2\n:4\n", complete(t, p, "get", "cl"))
+	assert.Equal("node-b\n:4\n", complete(t, p, "get", "cluster-1", "node-b"))
+	assert.Equal(":4\n", complete(t, p, "get", "cluster-1", "node-a", ""))
+	assert.Equal("mgmt\tk8s\n:4\n", complete(t, p, "get", "--context", ""))
+	assert.Equal(":1\n", complete(t, p, "delete", ""))
+}
@@ -8,28 +8,56 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/component"
 	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/plugin/lint"
 )
 
+var (
+	lintDisabledRules []string
+	lintOutputFormat  string
+	lintListRules     bool
+)
+
 // LintCmd inspects the Cobra command tree to ensure all commands meet the linting standards
-// todo: checks for duplicate commands and duplicate aliases
 var lintCmd = &cobra.Command{
-	Use:          "lint",
-	Short:        "Lint on cobra command structure",
-	Long:         "Lint this command's full flag and cmd tree. The cmd or flag will be skipped when annotated with 'no-lint'",
+	Use:   "lint",
+	Short: "Lint on cobra command structure",
+	Long: "Lint this command's full flag and cmd tree. The cmd or flag will be skipped when annotated with 'no-lint'. " +
+		"The linting fails when a rule reports an error, and rules can be disabled with --disable.",
 	Hidden:       true,
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		linter, err := lint.NewCobraLinter(cmd)
+		linter, err := lint.NewCobraLinter(cmd, lint.WithDisabledRules(lintDisabledRules...))
 		if err != nil {
 			return err
 		}
 
-		if !linter.Run() {
-			linter.Output()
+		if lintListRules {
+			output := component.NewOutputWriter(cmd.OutOrStdout(), lintOutputFormat, "ID", "Level", "Enabled", "Description")
+			for _, rule := range linter.Rules() {
+				output.AddRow(rule.ID, rule.Level, rule.Enabled, rule.Description)
+			}
+			output.Render()
+			return nil
+		}
+
+		success := linter.Run()
+		if lintOutputFormat != lint.JSONOutputFormat && lintOutputFormat != lint.SARIFOutputFormat && len(linter.Findings()) == 0 {
+			return nil
+		}
+		if err := linter.Report(cmd.OutOrStdout(), lintOutputFormat); err != nil {
+			return err
+		}
+		if !success {
 			return errors.New("cobra command linting failed")
 		}
 
 		return nil
 	},
 }
+
+func init() {
+	lintCmd.Flags().StringSliceVar(&lintDisabledRules, "disable", []string{}, "IDs of the lint rules to disable")
+	lintCmd.Flags().StringVarP(&lintOutputFormat, "output", "o", lint.TableOutputFormat, "Output format of the lint report (table|json|sarif)")
+	lintCmd.Flags().BoolVar(&lintListRules, "list-rules", false, "List the lint rules")
+}
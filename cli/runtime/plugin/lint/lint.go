@@ -4,20 +4,73 @@
 package lint
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
 
 	"github.com/spf13/cobra"
 
 	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/component"
 )
 
-var cobraLints = []cobraLint{
-	&TKGTerms{},
-	&TKGFlags{},
+// Level is the severity of the findings of a lint rule.
+type Level string
+
+const (
+	// LevelError findings fail the linting.
+	LevelError Level = "error"
+	// LevelWarning findings are reported without failing the linting.
+	LevelWarning Level = "warning"
+)
+
+// Output formats of the lint report.
+const (
+	TableOutputFormat = "table"
+	JSONOutputFormat  = "json"
+	SARIFOutputFormat = "sarif"
+)
+
+// cobraLintRule is a lint rule, identified by its ID to be configured
+type cobraLintRule struct {
+	id          string
+	description string
+	level       Level
+	lint        cobraLint
+}
+
+var cobraLints = []*cobraLintRule{
+	{id: "command-terms", description: "Commands use the standard nouns and verbs", level: LevelError, lint: &TKGTerms{}},
+	{id: "flag-terms", description: "Flags use the standard flag names", level: LevelError, lint: &TKGFlags{}},
+	{id: "duplicate-commands", description: "Sibling commands have unique names and aliases", level: LevelError, lint: &DuplicateCommands{}},
+	{id: "flag-shorthands", description: "Flag shorthands do not collide and have the same meaning across the command tree", level: LevelError, lint: &FlagShorthands{}},
+	{id: "required-flag-help", description: "Required flags have a help text", level: LevelError, lint: &RequiredFlagHelp{}},
+	{id: "output-flag", description: "The --output flag is a string flag with the -o shorthand", level: LevelError, lint: &OutputFlag{}},
+	{id: "examples", description: "Runnable commands have examples", level: LevelWarning, lint: &Examples{}},
+	{id: "persistent-flag-shadowing", description: "Flags do not shadow the persistent flags of the parent commands", level: LevelWarning, lint: &PersistentFlagShadowing{}},
+}
+
+// LinterOption configures the rules of the linter.
+type LinterOption func(*CobraLintRunner)
+
+// WithDisabledRules disables the rules with the IDs.
+func WithDisabledRules(ids ...string) LinterOption {
+	return func(c *CobraLintRunner) {
+		for _, id := range ids {
+			c.disabled[id] = true
+		}
+	}
+}
+
+// WithRuleLevel overrides the level of the rule with the ID.
+func WithRuleLevel(id string, level Level) LinterOption {
+	return func(c *CobraLintRunner) {
+		c.levels[id] = level
+	}
 }
 
 // NewCobraLinter returns an instance of CobraLintRunner.
-func NewCobraLinter(cmd *cobra.Command) (*CobraLintRunner, error) {
+func NewCobraLinter(cmd *cobra.Command, opts ...LinterOption) (*CobraLintRunner, error) {
 	terms, err := loadPluginWords(cmd)
 	if err != nil {
 		return nil, err
@@ -29,15 +82,64 @@ func NewCobraLinter(cmd *cobra.Command) (*CobraLintRunner, error) {
 		cliTerms: terms,
 		cmd:      cmd,
 	}
-	return &CobraLintRunner{
-		results: &r,
-		config:  cfg,
-	}, nil
+	c := &CobraLintRunner{
+		results:  &r,
+		config:   cfg,
+		disabled: make(map[string]bool),
+		levels:   make(map[string]Level),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	for id := range c.disabled {
+		if findRule(id) == nil {
+			return nil, fmt.Errorf("unknown lint rule %q", id)
+		}
+	}
+	for id := range c.levels {
+		if findRule(id) == nil {
+			return nil, fmt.Errorf("unknown lint rule %q", id)
+		}
+	}
+	return c, nil
+}
+
+func findRule(id string) *cobraLintRule {
+	for _, rule := range cobraLints {
+		if rule.id == id {
+			return rule
+		}
+	}
+	return nil
 }
 
 // Results is a map of commands and lint errors associated with them.
 type Results map[string][]string
 
+// Finding is a lint error of a command reported by a rule.
+type Finding struct {
+	// Rule is the ID of the rule.
+	Rule string `json:"rule"`
+	// Level of the finding.
+	Level Level `json:"level"`
+	// Command is the command the finding is reported for.
+	Command string `json:"command"`
+	// Message describes the finding.
+	Message string `json:"message"`
+}
+
+// RuleInfo describes a lint rule.
+type RuleInfo struct {
+	// ID of the rule, used to configure it.
+	ID string `json:"id"`
+	// Description of the rule.
+	Description string `json:"description"`
+	// Level of the findings of the rule.
+	Level Level `json:"level"`
+	// Enabled tells if the rule is run.
+	Enabled bool `json:"enabled"`
+}
+
 type cobraLint interface {
 	Init(*cobraLintConfig)
 	Execute() *Results
@@ -45,8 +147,11 @@ type cobraLint interface {
 
 // CobraLintRunner lints cobra commands and reports results.
 type CobraLintRunner struct {
-	results *Results
-	config  *cobraLintConfig
+	results  *Results
+	findings []Finding
+	config   *cobraLintConfig
+	disabled map[string]bool
+	levels   map[string]Level
 }
 
 type cobraLintConfig struct {
@@ -54,29 +159,106 @@ type cobraLintConfig struct {
 	cmd      *cobra.Command
 }
 
-// Run runs the linter and reports success or failure.
+// Rules returns the rules of the linter.
+func (c *CobraLintRunner) Rules() []RuleInfo {
+	rules := make([]RuleInfo, 0, len(cobraLints))
+	for _, rule := range cobraLints {
+		rules = append(rules, RuleInfo{
+			ID:          rule.id,
+			Description: rule.description,
+			Level:       c.level(rule),
+			Enabled:     !c.disabled[rule.id],
+		})
+	}
+	return rules
+}
+
+func (c *CobraLintRunner) level(rule *cobraLintRule) Level {
+	if level, ok := c.levels[rule.id]; ok {
+		return level
+	}
+	return rule.level
+}
+
+// Run runs the enabled rules and reports success, i.e. no error level findings.
 func (c *CobraLintRunner) Run() bool {
 	success := true
-	for _, l := range cobraLints {
-		l.Init(c.config)
-		for key, value := range *l.Execute() {
-			if success {
+	for _, rule := range cobraLints {
+		if c.disabled[rule.id] {
+			continue
+		}
+		rule.lint.Init(c.config)
+		results := rule.lint.Execute()
+		if results == nil {
+			continue
+		}
+		level := c.level(rule)
+		for key, value := range *results {
+			if level == LevelError {
 				success = false
 			}
 			(*c.results)[key] = append((*c.results)[key], value...)
+			for _, message := range dedupe(value) {
+				c.findings = append(c.findings, Finding{Rule: rule.id, Level: level, Command: key, Message: message})
+			}
 		}
 	}
+	sort.SliceStable(c.findings, func(i, j int) bool {
+		return c.findings[i].Command < c.findings[j].Command
+	})
 	return success
 }
 
+// Findings returns the findings of the last run.
+func (c *CobraLintRunner) Findings() []Finding {
+	return c.findings
+}
+
 // Output writes the results of linting in a table form.
 func (c *CobraLintRunner) Output() {
-	t := component.NewOutputWriter(c.config.cmd.OutOrStdout(), "table", "command", "lint")
-	for k, vs := range *c.results {
-		for _, v := range vs {
-			t.AddRow(k, v)
-		}
+	t := component.NewOutputWriter(c.config.cmd.OutOrStdout(), "table", "command", "rule", "level", "lint")
+	for _, f := range c.findings {
+		t.AddRow(f.Command, f.Rule, f.Level, f.Message)
 	}
 	t.Render()
 	fmt.Println("---")
 }
+
+// Report writes the findings of the last run in the format, either
+// TableOutputFormat, JSONOutputFormat or SARIFOutputFormat.
+func (c *CobraLintRunner) Report(w io.Writer, format string) error {
+	var report interface{}
+	switch format {
+	case "", TableOutputFormat:
+		c.Output()
+		return nil
+	case JSONOutputFormat:
+		findings := c.findings
+		if findings == nil {
+			findings = []Finding{}
+		}
+		report = findings
+	case SARIFOutputFormat:
+		report = c.sarifReport()
+	default:
+		return fmt.Errorf("unknown lint output format %q, can be %s|%s|%s", format, TableOutputFormat, JSONOutputFormat, SARIFOutputFormat)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,269 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+)
+
+const (
+	outputFlag          = "output"
+	outputFlagShorthand = "o"
+	completion          = "completion"
+)
+
+// walk calls the function for the command and its sub-commands, skipping the lint
+// and help commands and the commands annotated with 'no-lint'
+func walk(cmd *cobra.Command, f func(cmd *cobra.Command)) {
+	if cmd == nil {
+		return
+	}
+	if _, ok := cmd.Annotations[noLint]; ok {
+		return
+	}
+	if name := cmd.Name(); name == lintName || name == help {
+		return
+	}
+	f(cmd)
+	for _, subCmd := range cmd.Commands() {
+		walk(subCmd, f)
+	}
+}
+
+// ownFlags returns the flags defined by the command, i.e. its local and
+// persistent flags but not the persistent flags inherited from its parents
+func ownFlags(cmd *cobra.Command) []*flag.Flag {
+	var flags []*flag.Flag
+	cmd.PersistentFlags().VisitAll(func(f *flag.Flag) {
+		flags = append(flags, f)
+	})
+	cmd.Flags().VisitAll(func(f *flag.Flag) {
+		if cmd.PersistentFlags().Lookup(f.Name) != nil {
+			return
+		}
+		if p, _ := inheritedFlag(cmd, f.Name); p == f {
+			return
+		}
+		flags = append(flags, f)
+	})
+	return flags
+}
+
+// inheritedFlag returns the persistent flag of the closest parent command with the name
+func inheritedFlag(cmd *cobra.Command, name string) (*flag.Flag, *cobra.Command) {
+	for p := cmd.Parent(); p != nil; p = p.Parent() {
+		if f := p.PersistentFlags().Lookup(name); f != nil {
+			return f, p
+		}
+	}
+	return nil, nil
+}
+
+// inheritedShorthand returns the persistent flag of the closest parent command with the shorthand
+func inheritedShorthand(cmd *cobra.Command, shorthand string) (*flag.Flag, *cobra.Command) {
+	for p := cmd.Parent(); p != nil; p = p.Parent() {
+		if f := p.PersistentFlags().ShorthandLookup(shorthand); f != nil {
+			return f, p
+		}
+	}
+	return nil, nil
+}
+
+// DuplicateCommands reports sibling commands with the same name or alias.
+type DuplicateCommands struct {
+	cmd *cobra.Command
+}
+
+// Init initializes DuplicateCommands using a config.
+func (l *DuplicateCommands) Init(c *cobraLintConfig) {
+	l.cmd = c.cmd.Parent()
+}
+
+// Execute runs the analysis and reports results.
+func (l *DuplicateCommands) Execute() *Results {
+	results := make(Results)
+	walk(l.cmd, func(cmd *cobra.Command) {
+		names := make(map[string]string)
+		for _, subCmd := range cmd.Commands() {
+			for i, name := range append([]string{subCmd.Name()}, subCmd.Aliases...) {
+				term := fmt.Sprintf("command %q", name)
+				if i > 0 {
+					term = fmt.Sprintf("alias %q of command %q", name, subCmd.Name())
+				}
+				if other, ok := names[name]; ok {
+					results[cmd.CommandPath()] = append(results[cmd.CommandPath()],
+						fmt.Sprintf("%s conflicts with %s", term, other))
+					continue
+				}
+				names[name] = term
+			}
+		}
+	})
+	return &results
+}
+
+// FlagShorthands reports flag shorthands colliding with the shorthands of the persistent
+// flags of the parent commands, and shorthands used for different flags across the tree.
+type FlagShorthands struct {
+	cmd *cobra.Command
+}
+
+// Init initializes FlagShorthands using a config.
+func (l *FlagShorthands) Init(c *cobraLintConfig) {
+	l.cmd = c.cmd.Parent()
+}
+
+// Execute runs the analysis and reports results.
+func (l *FlagShorthands) Execute() *Results {
+	results := make(Results)
+	shorthands := make(map[string]map[string]bool)
+	walk(l.cmd, func(cmd *cobra.Command) {
+		for _, f := range ownFlags(cmd) {
+			if f.Shorthand == "" || f.Name == help {
+				continue
+			}
+			if shorthands[f.Shorthand] == nil {
+				shorthands[f.Shorthand] = make(map[string]bool)
+			}
+			shorthands[f.Shorthand][f.Name] = true
+			if p, parent := inheritedShorthand(cmd, f.Shorthand); p != nil && p.Name != f.Name {
+				results[cmd.CommandPath()] = append(results[cmd.CommandPath()],
+					fmt.Sprintf("shorthand -%s of flag --%s collides with the persistent flag --%s of command %q",
+						f.Shorthand, f.Name, p.Name, parent.CommandPath()))
+			}
+		}
+	})
+
+	for shorthand, names := range shorthands {
+		if len(names) < 2 {
+			continue
+		}
+		var flags []string
+		for name := range names {
+			flags = append(flags, "--"+name)
+		}
+		sort.Strings(flags)
+		results[l.cmd.CommandPath()] = append(results[l.cmd.CommandPath()],
+			fmt.Sprintf("shorthand -%s is used for different flags: %s", shorthand, strings.Join(flags, ", ")))
+	}
+	sort.Strings(results[l.cmd.CommandPath()])
+	return &results
+}
+
+// RequiredFlagHelp reports required flags without help text.
+type RequiredFlagHelp struct {
+	cmd *cobra.Command
+}
+
+// Init initializes RequiredFlagHelp using a config.
+func (l *RequiredFlagHelp) Init(c *cobraLintConfig) {
+	l.cmd = c.cmd.Parent()
+}
+
+// Execute runs the analysis and reports results.
+func (l *RequiredFlagHelp) Execute() *Results {
+	results := make(Results)
+	walk(l.cmd, func(cmd *cobra.Command) {
+		for _, f := range ownFlags(cmd) {
+			required := f.Annotations[cobra.BashCompOneRequiredFlag]
+			if len(required) == 0 || required[0] != "true" {
+				continue
+			}
+			if strings.TrimSpace(f.Usage) == "" {
+				results[cmd.CommandPath()] = append(results[cmd.CommandPath()],
+					fmt.Sprintf("required flag --%s has no help text", f.Name))
+			}
+		}
+	})
+	return &results
+}
+
+// OutputFlag reports --output flags which are not string flags with the -o
+// shorthand, and other flags using the -o shorthand.
+type OutputFlag struct {
+	cmd *cobra.Command
+}
+
+// Init initializes OutputFlag using a config.
+func (l *OutputFlag) Init(c *cobraLintConfig) {
+	l.cmd = c.cmd.Parent()
+}
+
+// Execute runs the analysis and reports results.
+func (l *OutputFlag) Execute() *Results {
+	results := make(Results)
+	walk(l.cmd, func(cmd *cobra.Command) {
+		for _, f := range ownFlags(cmd) {
+			var message string
+			switch {
+			case f.Name == outputFlag && f.Shorthand != outputFlagShorthand:
+				message = fmt.Sprintf("flag --%s should have the shorthand -%s", outputFlag, outputFlagShorthand)
+			case f.Name == outputFlag && f.Value.Type() != "string":
+				message = fmt.Sprintf("flag --%s should be a string flag, not %s", outputFlag, f.Value.Type())
+			case f.Name != outputFlag && f.Shorthand == outputFlagShorthand:
+				message = fmt.Sprintf("shorthand -%s of flag --%s is reserved for the --%s flag", outputFlagShorthand, f.Name, outputFlag)
+			default:
+				continue
+			}
+			results[cmd.CommandPath()] = append(results[cmd.CommandPath()], message)
+		}
+	})
+	return &results
+}
+
+// Examples reports runnable commands without examples.
+type Examples struct {
+	cmd *cobra.Command
+}
+
+// Init initializes Examples using a config.
+func (l *Examples) Init(c *cobraLintConfig) {
+	l.cmd = c.cmd.Parent()
+}
+
+// Execute runs the analysis and reports results.
+func (l *Examples) Execute() *Results {
+	results := make(Results)
+	walk(l.cmd, func(cmd *cobra.Command) {
+		if !cmd.Runnable() || cmd.Hidden || cmd.Deprecated != "" || cmd == l.cmd || cmd.Name() == completion {
+			return
+		}
+		if strings.TrimSpace(cmd.Example) == "" {
+			results[cmd.CommandPath()] = append(results[cmd.CommandPath()], "command has no examples")
+		}
+	})
+	return &results
+}
+
+// PersistentFlagShadowing reports flags with the name of a persistent flag of a parent command.
+type PersistentFlagShadowing struct {
+	cmd *cobra.Command
+}
+
+// Init initializes PersistentFlagShadowing using a config.
+func (l *PersistentFlagShadowing) Init(c *cobraLintConfig) {
+	l.cmd = c.cmd.Parent()
+}
+
+// Execute runs the analysis and reports results.
+func (l *PersistentFlagShadowing) Execute() *Results {
+	results := make(Results)
+	walk(l.cmd, func(cmd *cobra.Command) {
+		for _, f := range ownFlags(cmd) {
+			if f.Name == help {
+				continue
+			}
+			if p, parent := inheritedFlag(cmd, f.Name); p != nil && p != f {
+				results[cmd.CommandPath()] = append(results[cmd.CommandPath()],
+					fmt.Sprintf("flag --%s shadows the persistent flag of command %q", f.Name, parent.CommandPath()))
+			}
+		}
+	})
+	return &results
+}
@@ -0,0 +1,138 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func noop(cmd *cobra.Command, args []string) {}
+
+// newLintTestTree returns the lint command of a plugin command tree with lint issues
+func newLintTestTree() *cobra.Command {
+	var s string
+	var b bool
+	root := &cobra.Command{Use: "cluster"}
+	root.PersistentFlags().StringVarP(&s, "kubeconfig", "k", "", "kubeconfig")
+	lintCmd := &cobra.Command{Use: "lint", Run: noop}
+
+	createCmd := &cobra.Command{Use: "create CLUSTER_NAME", Aliases: []string{"new"}, Run: noop, Example: "tanzu cluster create my-cluster"}
+	createCmd.Flags().StringVarP(&s, "plan", "p", "", "")
+	_ = createCmd.MarkFlagRequired("plan")
+	createCmd.Flags().BoolVarP(&b, "kube", "k", false, "kube")
+	createCmd.Flags().StringVar(&s, "kubeconfig", "", "kubeconfig")
+
+	listCmd := &cobra.Command{Use: "list", Aliases: []string{"ls", "new"}, Run: noop}
+	listCmd.Flags().StringVarP(&s, "output", "o", "", "Output format")
+	listCmd.Flags().StringVarP(&s, "namespace", "n", "", "namespace")
+
+	getCmd := &cobra.Command{Use: "get", Run: noop, Example: "tanzu cluster get my-cluster"}
+	getCmd.Flags().StringVarP(&s, "output", "x", "", "Output format")
+	getCmd.Flags().BoolVarP(&b, "overwrite", "o", false, "overwrite")
+	getCmd.Flags().StringVarP(&s, "name", "n", "", "name")
+
+	lsCmd := &cobra.Command{Use: "ls", Run: noop, Hidden: true}
+	skippedCmd := &cobra.Command{Use: "skipped", Run: noop, Annotations: map[string]string{noLint: ""}}
+	skippedCmd.Flags().StringVarP(&s, "output", "", "", "")
+
+	root.AddCommand(lintCmd, createCmd, listCmd, getCmd, lsCmd, skippedCmd)
+	return lintCmd
+}
+
+func TestLintRules(t *testing.T) {
+	lintCmd := newLintTestTree()
+	cfg := &cobraLintConfig{cmd: lintCmd}
+
+	for _, test := range []struct {
+		lint     cobraLint
+		expected Results
+	}{
+		{
+			lint: &DuplicateCommands{},
+			expected: Results{"cluster": {
+				`alias "new" of command "list" conflicts with alias "new" of command "create"`,
+				`command "ls" conflicts with alias "ls" of command "list"`,
+			}},
+		},
+		{
+			lint: &FlagShorthands{},
+			expected: Results{
+				"cluster create": {`shorthand -k of flag --kube collides with the persistent flag --kubeconfig of command "cluster"`},
+				"cluster": {
+					"shorthand -k is used for different flags: --kube, --kubeconfig",
+					"shorthand -n is used for different flags: --name, --namespace",
+					"shorthand -o is used for different flags: --output, --overwrite",
+				},
+			},
+		},
+		{
+			lint:     &RequiredFlagHelp{},
+			expected: Results{"cluster create": {"required flag --plan has no help text"}},
+		},
+		{
+			lint: &OutputFlag{},
+			expected: Results{"cluster get": {
+				"flag --output should have the shorthand -o",
+				"shorthand -o of flag --overwrite is reserved for the --output flag",
+			}},
+		},
+		{
+			lint:     &Examples{},
+			expected: Results{"cluster list": {"command has no examples"}},
+		},
+		{
+			lint:     &PersistentFlagShadowing{},
+			expected: Results{"cluster create": {`flag --kubeconfig shadows the persistent flag of command "cluster"`}},
+		},
+	} {
+		test.lint.Init(cfg)
+		require.Equal(t, test.expected, *test.lint.Execute())
+	}
+}
+
+func TestCobraLintRunner(t *testing.T) {
+	lintCmd := newLintTestTree()
+
+	_, err := NewCobraLinter(lintCmd, WithDisabledRules("unknown"))
+	require.EqualError(t, err, `unknown lint rule "unknown"`)
+
+	// Only warnings do not fail the linting
+	linter, err := NewCobraLinter(lintCmd,
+		WithDisabledRules("command-terms", "flag-terms", "duplicate-commands", "flag-shorthands", "required-flag-help"),
+		WithRuleLevel("output-flag", LevelWarning))
+	require.NoError(t, err)
+	require.True(t, linter.Run())
+	require.Equal(t, 4, len(linter.Findings()))
+
+	var b bytes.Buffer
+	require.NoError(t, linter.Report(&b, JSONOutputFormat))
+	var findings []Finding
+	require.NoError(t, json.Unmarshal(b.Bytes(), &findings))
+	require.Equal(t, Finding{Rule: "examples", Level: LevelWarning, Command: "cluster list", Message: "command has no examples"}, findings[3])
+
+	linter, err = NewCobraLinter(lintCmd, WithDisabledRules("command-terms", "flag-terms"))
+	require.NoError(t, err)
+	require.False(t, linter.Run())
+
+	b.Reset()
+	require.NoError(t, linter.Report(&b, SARIFOutputFormat))
+	var sarif sarifLog
+	require.NoError(t, json.Unmarshal(b.Bytes(), &sarif))
+	require.Equal(t, "2.1.0", sarif.Version)
+	run := sarif.Runs[0]
+	require.Equal(t, len(cobraLints), len(run.Tool.Driver.Rules))
+	require.False(t, run.Tool.Driver.Rules[0].DefaultConfiguration.Enabled)
+	require.Equal(t, len(linter.Findings()), len(run.Results))
+	for _, result := range run.Results {
+		require.Equal(t, result.RuleID, run.Tool.Driver.Rules[result.RuleIndex].ID)
+		require.NotEmpty(t, result.Locations[0].LogicalLocations[0].FullyQualifiedName)
+	}
+
+	require.EqualError(t, linter.Report(&b, "xml"), `unknown lint output format "xml", can be table|json|sarif`)
+}
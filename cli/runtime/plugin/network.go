@@ -0,0 +1,87 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// EnvNetworkKey is the environment variable the CLI core sets to the comma separated list of
+// network endpoints a plugin declaring its permissions is permitted to connect to.
+const EnvNetworkKey = "TANZU_PLUGIN_NETWORK"
+
+// proxyEnvKeys are the environment variables of the proxies the connections may go through
+var proxyEnvKeys = []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"}
+
+// restrictNetwork restricts the connections dialed by http.DefaultTransport to the network
+// endpoints set by the CLI core, if any. This is a safeguard for the plugins which honour
+// their declared permissions, it does not restrict clients using their own transport.
+func restrictNetwork() {
+	value, ok := os.LookupEnv(EnvNetworkKey)
+	if !ok {
+		return
+	}
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return
+	}
+	endpoints := splitEndpoints(value)
+	for _, key := range proxyEnvKeys {
+		if proxy, err := url.Parse(os.Getenv(key)); err == nil && proxy.Host != "" {
+			endpoints = append(endpoints, proxy.Host)
+		}
+	}
+
+	dial := transport.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if !endpointPermitted(endpoints, addr) {
+			return nil, errors.Errorf("connection to %q is not permitted, the plugin does not declare it in its network permissions", addr)
+		}
+		return dial(ctx, network, addr)
+	}
+}
+
+// splitEndpoints splits the comma separated list of network endpoints
+func splitEndpoints(value string) []string {
+	var endpoints []string
+	for _, endpoint := range strings.Split(value, ",") {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+// endpointPermitted checks whether the address matches one of the endpoints, which are
+// host or host:port patterns whose host may contain "*" wildcards, e.g. "*.example.com:443"
+func endpointPermitted(endpoints []string, addr string) bool {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = addr, ""
+	}
+	for _, endpoint := range endpoints {
+		endpointHost, endpointPort, err := net.SplitHostPort(endpoint)
+		if err != nil {
+			endpointHost, endpointPort = endpoint, ""
+		}
+		if endpointPort != "" && endpointPort != port {
+			continue
+		}
+		if matched, _ := path.Match(strings.ToLower(endpointHost), strings.ToLower(host)); matched {
+			return true
+		}
+	}
+	return false
+}
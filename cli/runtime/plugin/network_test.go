@@ -0,0 +1,31 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointPermitted(t *testing.T) {
+	endpoints := splitEndpoints(" api.example.com:443, *.tmc.example.com,,localhost")
+	assert.Equal(t, []string{"api.example.com:443", "*.tmc.example.com", "localhost"}, endpoints)
+
+	tcs := []struct {
+		addr      string
+		permitted bool
+	}{
+		{addr: "api.example.com:443", permitted: true},
+		{addr: "API.example.com:443", permitted: true},
+		{addr: "api.example.com:80", permitted: false},
+		{addr: "org.tmc.example.com:443", permitted: true},
+		{addr: "tmc.example.com:443", permitted: false},
+		{addr: "localhost:8080", permitted: true},
+		{addr: "example.com:443", permitted: false},
+	}
+	for _, tc := range tcs {
+		assert.Equal(t, tc.permitted, endpointPermitted(endpoints, tc.addr), tc.addr)
+	}
+}
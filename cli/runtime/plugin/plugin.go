@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -19,17 +20,22 @@ import (
 // Plugin is a Tanzu CLI plugin.
 type Plugin struct {
 	Cmd *cobra.Command
+
+	descriptor *cliapi.PluginDescriptor
+	exporter   EventExporter
 }
 
 // NewPlugin creates an instance of Plugin.
 func NewPlugin(descriptor *cliapi.PluginDescriptor) (*Plugin, error) {
 	ApplyDefaultConfig(descriptor)
+	restrictNetwork()
 	err := ValidatePlugin(descriptor)
 	if err != nil {
 		return nil, err
 	}
 	p := &Plugin{
-		Cmd: newRootCmd(descriptor),
+		Cmd:        newRootCmd(descriptor),
+		descriptor: descriptor,
 	}
 	p.Cmd.AddCommand(lintCmd)
 	p.Cmd.AddCommand(genDocsCmd)
@@ -55,9 +61,33 @@ func (p *Plugin) AddCommands(commands ...*cobra.Command) {
 	p.Cmd.AddCommand(commands...)
 }
 
-// Execute executes the plugin.
+// SetEventExporter sets the exporter of the command events of the plugin, which
+// is used instead of the OTLP/HTTP exporter configured with FeatureTelemetryOTLPEndpoint.
+func (p *Plugin) SetEventExporter(exporter EventExporter) {
+	p.exporter = exporter
+}
+
+// Execute executes the plugin. The event of the command invocation is recorded
+// if the telemetry is enabled with the FeatureTelemetry feature, and exported by
+// the next invocation while it runs. Plugins exit with ExitCode of the error.
 func (p *Plugin) Execute() error {
-	return p.Cmd.Execute()
+	start := time.Now()
+	tc := getTelemetryConfig()
+	if tc == nil {
+		_, err := p.Cmd.ExecuteC()
+		return err
+	}
+
+	exported := p.exportCommandEvents(tc)
+	cmd, err := p.Cmd.ExecuteC()
+	p.recordCommandEvent(tc, cmd, start, err)
+	if exported != nil {
+		select {
+		case <-exported:
+		case <-time.After(telemetryFlushWait):
+		}
+	}
+	return err
 }
 
 // parsePluginDescriptor parses a plugin descriptor in yaml.
@@ -0,0 +1,301 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+)
+
+// Features of ClientOptions.Features configuring the telemetry of the plugin commands.
+const (
+	// FeatureTelemetry opts in to the telemetry of the plugin commands when "true".
+	FeatureTelemetry = "telemetry"
+	// FeatureTelemetryRedactFlags is a comma separated list of glob patterns of the
+	// names of the flags omitted from the events.
+	FeatureTelemetryRedactFlags = "telemetry-redact-flags"
+	// FeatureTelemetryRedactCommands is a comma separated list of command paths, e.g.
+	// "cluster create", whose events only record the plugin name as command path.
+	FeatureTelemetryRedactCommands = "telemetry-redact-commands"
+	// FeatureTelemetryOTLPEndpoint is the endpoint of the OTLP/HTTP collector the
+	// events are exported to, e.g. "https://collector.example.com:4318".
+	FeatureTelemetryOTLPEndpoint = "telemetry-otlp-endpoint"
+
+	// telemetryFeaturesPlugin is the plugin key of ClientOptions.Features holding the telemetry features
+	telemetryFeaturesPlugin = "global"
+	// telemetrySpoolFileName is the file of the telemetry directory spooling the events
+	telemetrySpoolFileName = "events.jsonl"
+	// telemetrySpoolMaxSize is the size of the spool file from which it is rotated
+	telemetrySpoolMaxSize = 5 * 1024 * 1024
+	// redactedCommand is the command path recorded for the redacted commands
+	redactedCommand = "<redacted>"
+	// telemetryFlushWait is how long a command waits, once it completed, for the export of
+	// the events spooled by the previous commands. The export is abandoned past this delay
+	// and the events are exported again by the next command.
+	telemetryFlushWait = 200 * time.Millisecond
+)
+
+// CommandEvent is the event of the invocation of a plugin command.
+type CommandEvent struct {
+	// Timestamp at which the command started.
+	Timestamp time.Time `json:"timestamp"`
+	// PluginName is the name of the plugin.
+	PluginName string `json:"pluginName"`
+	// PluginVersion is the version of the plugin.
+	PluginVersion string `json:"pluginVersion"`
+	// CommandPath is the path of the command, without the plugin name, e.g. "create".
+	CommandPath string `json:"commandPath"`
+	// Flags are the names of the flags set by the user. Their values are never recorded.
+	Flags []string `json:"flags,omitempty"`
+	// Duration of the command in milliseconds.
+	DurationMs int64 `json:"durationMs"`
+	// ExitCode of the command.
+	ExitCode int `json:"exitCode"`
+	// OS the command ran on.
+	OS string `json:"os"`
+	// Arch the command ran on.
+	Arch string `json:"arch"`
+}
+
+// EventExporter exports the spooled command events to a telemetry backend.
+type EventExporter interface {
+	// Export exports the events. The events are kept in the spool
+	// file and exported again with the next event on error.
+	Export(events []*CommandEvent) error
+}
+
+// telemetryConfig is the telemetry configuration read from ClientOptions.Features
+type telemetryConfig struct {
+	redactFlags    []string
+	redactCommands []string
+	otlpEndpoint   string
+}
+
+// getTelemetryConfig returns the telemetry configuration, or nil if the telemetry is not enabled
+func getTelemetryConfig() *telemetryConfig {
+	cfg, err := config.GetClientConfig()
+	if err != nil || cfg.ClientOptions == nil || cfg.ClientOptions.Features == nil {
+		return nil
+	}
+	features := cfg.ClientOptions.Features[telemetryFeaturesPlugin]
+	if !strings.EqualFold(features[FeatureTelemetry], "true") {
+		return nil
+	}
+	return &telemetryConfig{
+		redactFlags:    splitFeatureList(features[FeatureTelemetryRedactFlags]),
+		redactCommands: splitFeatureList(features[FeatureTelemetryRedactCommands]),
+		otlpEndpoint:   strings.TrimSpace(features[FeatureTelemetryOTLPEndpoint]),
+	}
+}
+
+func splitFeatureList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// newCommandEvent creates the event of the command which ran from start and returned the error
+func newCommandEvent(descriptor *cliapi.PluginDescriptor, cmd *cobra.Command, start time.Time, err error, tc *telemetryConfig) *CommandEvent {
+	event := &CommandEvent{
+		Timestamp:  start.UTC(),
+		DurationMs: time.Since(start).Milliseconds(),
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+	}
+	if descriptor != nil {
+		event.PluginName = descriptor.Name
+		event.PluginVersion = descriptor.Version
+	}
+	event.ExitCode = ExitCode(err)
+	if cmd == nil {
+		return event
+	}
+
+	// The command path without the root command, which is the plugin itself
+	event.CommandPath = strings.TrimSpace(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()))
+	for _, redacted := range tc.redactCommands {
+		if event.CommandPath == redacted || strings.HasPrefix(event.CommandPath, redacted+" ") {
+			event.CommandPath = redactedCommand
+			return event
+		}
+	}
+
+	flags := map[string]bool{}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if !tc.isRedactedFlag(f.Name) {
+			flags[f.Name] = true
+		}
+	})
+	for name := range flags {
+		event.Flags = append(event.Flags, name)
+	}
+	sort.Strings(event.Flags)
+	return event
+}
+
+func (tc *telemetryConfig) isRedactedFlag(name string) bool {
+	for _, pattern := range tc.redactFlags {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// getTelemetryDir returns the directory of the telemetry spool, next to the tanzu config file
+func getTelemetryDir() (string, error) {
+	path, err := config.ClientConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "telemetry"), nil
+}
+
+// ExitCode returns the exit code of a plugin for the error returned by Execute: 0 without
+// error, the exit code of the errors implementing ExitCode() int, e.g. *exec.ExitError of
+// the commands run by the plugin, and 1 otherwise. It is the exit code recorded in the
+// command events.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
+
+// getTelemetrySpool returns the spool file of the command events
+func getTelemetrySpool() (string, error) {
+	dir, err := getTelemetryDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, telemetrySpoolFileName), nil
+}
+
+// exportCommandEvents exports the events spooled by the previous commands in the background,
+// so that the export does not delay the command. The returned channel is closed once the
+// events are exported, and is nil if there is no exporter.
+func (p *Plugin) exportCommandEvents(tc *telemetryConfig) <-chan struct{} {
+	exporter := p.exporter
+	if exporter == nil && tc.otlpEndpoint != "" {
+		exporter = NewOTLPHTTPExporter(tc.otlpEndpoint)
+	}
+	spool, err := getTelemetrySpool()
+	if exporter == nil || err != nil {
+		return nil
+	}
+	// The events of the command itself are spooled for the next command
+	pending, err := moveSpoolAside(spool)
+	if err != nil {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = exportPending(pending, exporter)
+	}()
+	return done
+}
+
+// recordCommandEvent spools the event of the command, which is exported by the next command.
+// Telemetry never fails the command, so errors are ignored.
+func (p *Plugin) recordCommandEvent(tc *telemetryConfig, cmd *cobra.Command, start time.Time, cmdErr error) {
+	// Shell completion requests are not user commands
+	if cmd != nil && (cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd) {
+		return
+	}
+	spool, err := getTelemetrySpool()
+	if err != nil {
+		return
+	}
+	_ = appendEvent(spool, newCommandEvent(p.descriptor, cmd, start, cmdErr, tc))
+}
+
+// appendEvent appends the event to the spool file, rotating the spool file when too large
+func appendEvent(spool string, event *CommandEvent) error {
+	if err := os.MkdirAll(filepath.Dir(spool), 0o700); err != nil {
+		return err
+	}
+	if info, err := os.Stat(spool); err == nil && info.Size() > telemetrySpoolMaxSize {
+		_ = os.Rename(spool, spool+".1")
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(spool, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// readSpool returns the events of the spool file, skipping the malformed lines
+func readSpool(spool string) ([]*CommandEvent, error) {
+	f, err := os.Open(spool)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []*CommandEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		event := &CommandEvent{}
+		if err := json.Unmarshal(scanner.Bytes(), event); err == nil {
+			events = append(events, event)
+		}
+	}
+	return events, scanner.Err()
+}
+
+// moveSpoolAside moves the events of the spool file aside for their export, so that
+// the events appended concurrently are kept for the next export, and returns the file
+// of the events to export. The events moved aside by a previous export which did not
+// complete are exported first, and the spool file with the next export.
+func moveSpoolAside(spool string) (string, error) {
+	pending := spool + ".pending"
+	if _, err := os.Stat(pending); os.IsNotExist(err) {
+		if err := os.Rename(spool, pending); err != nil {
+			return "", err
+		}
+	}
+	return pending, nil
+}
+
+// exportPending exports the events moved aside by moveSpoolAside, and removes them once exported
+func exportPending(pending string, exporter EventExporter) error {
+	events, err := readSpool(pending)
+	if err != nil {
+		return err
+	}
+	if len(events) > 0 {
+		if err := exporter.Export(events); err != nil {
+			return err
+		}
+	}
+	return os.Remove(pending)
+}
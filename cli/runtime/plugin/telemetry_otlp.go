@@ -0,0 +1,140 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// otlpLogsPath is the path of the logs service of the OTLP/HTTP protocol
+	otlpLogsPath = "/v1/logs"
+	// otlpExportTimeout is the timeout of the requests to the collector. The events are
+	// exported while the next command runs, and exported again when the timeout expires.
+	otlpExportTimeout = 1 * time.Second
+	// otlpServiceName is the service name of the resource emitting the events
+	otlpServiceName = "tanzu-cli"
+	// otlpScopeName is the name of the instrumentation scope of the events
+	otlpScopeName = "github.com/vmware-tanzu/tanzu-framework/cli/runtime/plugin"
+)
+
+// otlpHTTPExporter exports the command events as OTLP log records over HTTP, with the JSON encoding
+type otlpHTTPExporter struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewOTLPHTTPExporter returns an exporter sending the command events as log records
+// to the OTLP/HTTP collector at the endpoint, e.g. "https://collector.example.com:4318".
+func NewOTLPHTTPExporter(endpoint string) EventExporter {
+	return &otlpHTTPExporter{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   &http.Client{Timeout: otlpExportTimeout},
+	}
+}
+
+type otlpAnyValue struct {
+	StringValue *string    `json:"stringValue,omitempty"`
+	IntValue    *string    `json:"intValue,omitempty"`
+	ArrayValue  *otlpArray `json:"arrayValue,omitempty"`
+}
+
+type otlpArray struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	SeverityText string         `json:"severityText"`
+	Body         otlpAnyValue   `json:"body"`
+	Attributes   []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      map[string]string `json:"scope"`
+	LogRecords []otlpLogRecord   `json:"logRecords"`
+}
+
+type otlpResourceLogs struct {
+	Resource  map[string][]otlpKeyValue `json:"resource"`
+	ScopeLogs []otlpScopeLogs           `json:"scopeLogs"`
+}
+
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+func otlpString(key, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: &value}}
+}
+
+func otlpInt(key string, value int64) otlpKeyValue {
+	// int64 values are encoded as strings in the JSON encoding of OTLP
+	s := strconv.FormatInt(value, 10)
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{IntValue: &s}}
+}
+
+func otlpStrings(key string, values []string) otlpKeyValue {
+	array := &otlpArray{Values: []otlpAnyValue{}}
+	for i := range values {
+		array.Values = append(array.Values, otlpAnyValue{StringValue: &values[i]})
+	}
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{ArrayValue: array}}
+}
+
+// Export sends the events to the collector
+func (e *otlpHTTPExporter) Export(events []*CommandEvent) error {
+	body := "command"
+	records := make([]otlpLogRecord, 0, len(events))
+	for _, event := range events {
+		severity := "INFO"
+		if event.ExitCode != 0 {
+			severity = "ERROR"
+		}
+		records = append(records, otlpLogRecord{
+			TimeUnixNano: strconv.FormatInt(event.Timestamp.UnixNano(), 10),
+			SeverityText: severity,
+			Body:         otlpAnyValue{StringValue: &body},
+			Attributes: []otlpKeyValue{
+				otlpString("tanzu.plugin.name", event.PluginName),
+				otlpString("tanzu.plugin.version", event.PluginVersion),
+				otlpString("tanzu.command.path", event.CommandPath),
+				otlpStrings("tanzu.command.flags", event.Flags),
+				otlpInt("tanzu.command.duration_ms", event.DurationMs),
+				otlpInt("tanzu.command.exit_code", int64(event.ExitCode)),
+				otlpString("os.type", event.OS),
+				otlpString("host.arch", event.Arch),
+			},
+		})
+	}
+	request := otlpLogsRequest{ResourceLogs: []otlpResourceLogs{{
+		Resource:  map[string][]otlpKeyValue{"attributes": {otlpString("service.name", otlpServiceName)}},
+		ScopeLogs: []otlpScopeLogs{{Scope: map[string]string{"name": otlpScopeName}, LogRecords: records}},
+	}}}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Post(e.endpoint+otlpLogsPath, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to export the telemetry events to %s: %s", e.endpoint, resp.Status)
+	}
+	return nil
+}
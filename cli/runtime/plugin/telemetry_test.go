@@ -0,0 +1,217 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
+)
+
+type exitCodeError int
+
+func (e exitCodeError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+
+func (e exitCodeError) ExitCode() int { return int(e) }
+
+type fakeEventExporter struct {
+	events []*CommandEvent
+	err    error
+}
+
+func (e *fakeEventExporter) Export(events []*CommandEvent) error {
+	if e.err != nil {
+		return e.err
+	}
+	e.events = append(e.events, events...)
+	return nil
+}
+
+// blockingEventExporter blocks the export until released, as an unreachable collector
+type blockingEventExporter struct {
+	release chan struct{}
+}
+
+func (e *blockingEventExporter) Export(events []*CommandEvent) error {
+	<-e.release
+	return errors.New("collector unavailable")
+}
+
+func setupTelemetryTestConfig(t *testing.T, features string) string {
+	dir := t.TempDir()
+	cfg := "clientOptions:\n  features:\n    global:\n" + features
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(cfg), 0o600))
+	t.Setenv("TANZU_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("TANZU_CONFIG_NEXT_GEN", filepath.Join(dir, "config-ng.yaml"))
+	t.Setenv("TANZU_CONFIG_METADATA", filepath.Join(dir, "config-metadata.yaml"))
+	return filepath.Join(dir, "telemetry", telemetrySpoolFileName)
+}
+
+func newTelemetryTestPlugin(t *testing.T, args ...string) *Plugin {
+	p, err := NewPlugin(&cliapi.PluginDescriptor{
+		Name:        "cluster",
+		Description: "cluster operations",
+		Version:     "v1.2.3",
+		Group:       cliapi.RunCmdGroup,
+	})
+	assert.NoError(t, err)
+
+	var kubeconfig, apiToken string
+	var fail bool
+	createCmd := &cobra.Command{
+		Use: "create",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if fail {
+				return fmt.Errorf("failed to create the cluster: %w", exitCodeError(3))
+			}
+			return nil
+		},
+	}
+	createCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "kubeconfig")
+	createCmd.Flags().StringVar(&apiToken, "api-token", "", "api token")
+	createCmd.Flags().BoolVar(&fail, "fail", false, "fail")
+	loginCmd := &cobra.Command{Use: "login", Run: func(cmd *cobra.Command, args []string) {}}
+	loginCmd.Flags().StringVar(&apiToken, "api-token", "", "api token")
+	p.AddCommands(createCmd, loginCmd)
+	p.Cmd.SetArgs(args)
+	p.Cmd.SetOut(io.Discard)
+	p.Cmd.SetErr(io.Discard)
+	return p
+}
+
+func TestTelemetryDisabled(t *testing.T) {
+	spool := setupTelemetryTestConfig(t, "      context-target: \"true\"\n")
+	exporter := &fakeEventExporter{}
+	p := newTelemetryTestPlugin(t, "create", "my-cluster", "--kubeconfig", "/secret/path")
+	p.SetEventExporter(exporter)
+
+	assert.NoError(t, p.Execute())
+	assert.NoFileExists(t, spool)
+	assert.Empty(t, exporter.events)
+}
+
+func TestTelemetryEvents(t *testing.T) {
+	spool := setupTelemetryTestConfig(t, "      telemetry: \"true\"\n      telemetry-redact-flags: \"api-*\"\n      telemetry-redact-commands: login\n")
+
+	// Events are spooled while they cannot be exported
+	exporter := &fakeEventExporter{err: errors.New("collector unavailable")}
+	p := newTelemetryTestPlugin(t, "create", "my-cluster", "--kubeconfig", "/secret/path", "--api-token", "secret-token")
+	p.SetEventExporter(exporter)
+	assert.NoError(t, p.Execute())
+
+	p = newTelemetryTestPlugin(t, "login", "--api-token", "secret-token")
+	p.SetEventExporter(exporter)
+	assert.NoError(t, p.Execute())
+
+	data, err := os.ReadFile(spool + ".pending")
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "my-cluster")
+	assert.NotContains(t, string(data), "secret")
+	events, err := readSpool(spool + ".pending")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, "cluster", events[0].PluginName)
+	assert.Equal(t, "v1.2.3", events[0].PluginVersion)
+	assert.Equal(t, "create", events[0].CommandPath)
+	assert.Equal(t, []string{"kubeconfig"}, events[0].Flags)
+	assert.Equal(t, 0, events[0].ExitCode)
+	events, err = readSpool(spool)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, redactedCommand, events[0].CommandPath)
+	assert.Empty(t, events[0].Flags)
+
+	// The spooled events are exported by the next commands
+	exporter.err = nil
+	p = newTelemetryTestPlugin(t, "create", "--fail")
+	p.SetEventExporter(exporter)
+	err = p.Execute()
+	assert.Error(t, err)
+	assert.Equal(t, 3, ExitCode(err))
+	assert.Equal(t, 1, len(exporter.events))
+	assert.NoFileExists(t, spool+".pending")
+
+	p = newTelemetryTestPlugin(t, "create")
+	p.SetEventExporter(exporter)
+	assert.NoError(t, p.Execute())
+	assert.Equal(t, 3, len(exporter.events))
+	assert.Equal(t, []string{"fail"}, exporter.events[2].Flags)
+	assert.Equal(t, 3, exporter.events[2].ExitCode)
+	assert.NoFileExists(t, spool+".pending")
+	events, err = readSpool(spool)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, 0, events[0].ExitCode)
+}
+
+func TestTelemetryExportDoesNotDelayCommands(t *testing.T) {
+	spool := setupTelemetryTestConfig(t, "      telemetry: \"true\"\n")
+	assert.NoError(t, appendEvent(spool, &CommandEvent{CommandPath: "create"}))
+
+	exporter := &blockingEventExporter{release: make(chan struct{})}
+	defer close(exporter.release)
+	p := newTelemetryTestPlugin(t, "create")
+	p.SetEventExporter(exporter)
+	start := time.Now()
+	assert.NoError(t, p.Execute())
+	assert.Less(t, time.Since(start), telemetryFlushWait+time.Second)
+
+	// The events of the abandoned export are exported again by the next command
+	events, err := readSpool(spool + ".pending")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(events))
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, 0, ExitCode(nil))
+	assert.Equal(t, 1, ExitCode(errors.New("failed")))
+	assert.Equal(t, 2, ExitCode(fmt.Errorf("wrapped: %w", exitCodeError(2))))
+}
+
+func TestOTLPHTTPExporter(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/logs", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+	}))
+	defer server.Close()
+
+	spool := setupTelemetryTestConfig(t, "      telemetry: \"true\"\n      telemetry-otlp-endpoint: "+server.URL+"/\n")
+	p := newTelemetryTestPlugin(t, "create", "--kubeconfig", "path")
+	assert.NoError(t, p.Execute())
+	assert.Nil(t, request)
+	// The event is exported by the next command
+	assert.NoError(t, newTelemetryTestPlugin(t, "login").Execute())
+	assert.NoFileExists(t, spool+".pending")
+
+	records := request["resourceLogs"].([]interface{})[0].(map[string]interface{})["scopeLogs"].([]interface{})[0].(map[string]interface{})["logRecords"].([]interface{})
+	assert.Equal(t, 1, len(records))
+	attributes := map[string]interface{}{}
+	for _, attr := range records[0].(map[string]interface{})["attributes"].([]interface{}) {
+		kv := attr.(map[string]interface{})
+		attributes[kv["key"].(string)] = kv["value"]
+	}
+	assert.Equal(t, map[string]interface{}{"stringValue": "create"}, attributes["tanzu.command.path"])
+	assert.Equal(t, map[string]interface{}{"stringValue": "v1.2.3"}, attributes["tanzu.plugin.version"])
+	assert.Equal(t, map[string]interface{}{"intValue": "0"}, attributes["tanzu.command.exit_code"])
+	assert.Equal(t, map[string]interface{}{"arrayValue": map[string]interface{}{"values": []interface{}{map[string]interface{}{"stringValue": "kubeconfig"}}}}, attributes["tanzu.command.flags"])
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	assert.Error(t, NewOTLPHTTPExporter(server.URL).Export([]*CommandEvent{{CommandPath: "create"}}))
+}
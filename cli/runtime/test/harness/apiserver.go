@@ -0,0 +1,249 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// apiVerbs are the verbs of the resources served by the fake kubernetes API
+var apiVerbs = metav1.Verbs{"get", "list", "create", "update", "patch", "delete"}
+
+// fakeAPIServer serves the objects of a fake client over the kubernetes REST API, so that the
+// clients built from the kubeconfig of a context reach the fake cluster. It serves the discovery
+// of the types of the scheme of the client and the get, list, create, update, patch and delete
+// requests of their resources. Watches are not supported.
+type fakeAPIServer struct {
+	client client.Client
+	codecs serializer.CodecFactory
+}
+
+func newFakeAPIServer(c client.Client) *fakeAPIServer {
+	return &fakeAPIServer{client: c, codecs: serializer.NewCodecFactory(c.Scheme())}
+}
+
+func (s *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	var gv schema.GroupVersion
+	switch {
+	case len(parts) == 1 && parts[0] == "api":
+		s.writeJSON(w, http.StatusOK, &metav1.APIVersions{TypeMeta: metav1.TypeMeta{Kind: "APIVersions"}, Versions: []string{"v1"}})
+		return
+	case len(parts) == 1 && parts[0] == "apis":
+		s.writeJSON(w, http.StatusOK, s.apiGroups())
+		return
+	case len(parts) >= 2 && parts[0] == "api":
+		gv, parts = schema.GroupVersion{Version: parts[1]}, parts[2:]
+	case len(parts) >= 3 && parts[0] == "apis":
+		gv, parts = schema.GroupVersion{Group: parts[1], Version: parts[2]}, parts[3:]
+	default:
+		s.writeError(w, apierrors.NewNotFound(schema.GroupResource{}, r.URL.Path))
+		return
+	}
+	if len(parts) == 0 {
+		s.writeJSON(w, http.StatusOK, s.apiResources(gv))
+		return
+	}
+
+	namespace := ""
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		namespace, parts = parts[1], parts[2:]
+	}
+	gvk, err := s.client.RESTMapper().KindFor(gv.WithResource(parts[0]))
+	if err != nil {
+		s.writeError(w, apierrors.NewNotFound(gv.WithResource(parts[0]).GroupResource(), ""))
+		return
+	}
+	name := ""
+	if len(parts) >= 2 {
+		name = parts[1]
+	}
+
+	switch {
+	case r.URL.Query().Get("watch") == "true":
+		s.writeError(w, apierrors.NewMethodNotSupported(gv.WithResource(parts[0]).GroupResource(), "watch"))
+	case r.Method == http.MethodGet && name == "":
+		s.list(w, r, gvk, namespace)
+	case r.Method == http.MethodGet:
+		obj := newObject(gvk, namespace, name)
+		s.respond(w, http.StatusOK, obj, s.client.Get(r.Context(), client.ObjectKeyFromObject(obj), obj))
+	case r.Method == http.MethodPost:
+		obj, err := s.readObject(r, gvk, namespace)
+		if err == nil {
+			err = s.client.Create(r.Context(), obj)
+		}
+		s.respond(w, http.StatusCreated, obj, err)
+	case r.Method == http.MethodPut:
+		obj, err := s.readObject(r, gvk, namespace)
+		if err == nil {
+			err = s.client.Update(r.Context(), obj)
+		}
+		s.respond(w, http.StatusOK, obj, err)
+	case r.Method == http.MethodPatch:
+		obj := newObject(gvk, namespace, name)
+		patch, err := io.ReadAll(r.Body)
+		if err == nil {
+			err = s.client.Patch(r.Context(), obj, client.RawPatch(types.PatchType(r.Header.Get("Content-Type")), patch))
+		}
+		s.respond(w, http.StatusOK, obj, err)
+	case r.Method == http.MethodDelete:
+		err := s.client.Delete(r.Context(), newObject(gvk, namespace, name))
+		s.respond(w, http.StatusOK, &metav1.Status{TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}, Status: metav1.StatusSuccess}, err)
+	default:
+		s.writeError(w, apierrors.NewMethodNotSupported(gv.WithResource(parts[0]).GroupResource(), r.Method))
+	}
+}
+
+func (s *fakeAPIServer) list(w http.ResponseWriter, r *http.Request, gvk schema.GroupVersionKind, namespace string) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	opts := []client.ListOption{client.InNamespace(namespace)}
+	if selector := r.URL.Query().Get("labelSelector"); selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			s.writeError(w, apierrors.NewBadRequest(err.Error()))
+			return
+		}
+		opts = append(opts, client.MatchingLabelsSelector{Selector: parsed})
+	}
+	err := s.client.List(r.Context(), list, opts...)
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	// The kubernetes API lists the objects by namespace and name
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].GetNamespace()+"/"+list.Items[i].GetName() < list.Items[j].GetNamespace()+"/"+list.Items[j].GetName()
+	})
+	s.respond(w, http.StatusOK, list, err)
+}
+
+// apiGroups returns the discovery of the API groups of the types of the scheme
+func (s *fakeAPIServer) apiGroups() *metav1.APIGroupList {
+	versions := make(map[string][]string)
+	for gvk := range s.client.Scheme().AllKnownTypes() {
+		if gvk.Group == "" || gvk.Version == "__internal" || len(s.apiResources(gvk.GroupVersion()).APIResources) == 0 {
+			continue
+		}
+		if !contains(versions[gvk.Group], gvk.Version) {
+			versions[gvk.Group] = append(versions[gvk.Group], gvk.Version)
+		}
+	}
+	groups := &metav1.APIGroupList{TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"}}
+	for group, vs := range versions {
+		sort.Strings(vs)
+		g := metav1.APIGroup{Name: group}
+		for _, v := range vs {
+			g.Versions = append(g.Versions, metav1.GroupVersionForDiscovery{GroupVersion: group + "/" + v, Version: v})
+		}
+		g.PreferredVersion = g.Versions[0]
+		groups.Groups = append(groups.Groups, g)
+	}
+	sort.Slice(groups.Groups, func(i, j int) bool { return groups.Groups[i].Name < groups.Groups[j].Name })
+	return groups
+}
+
+// apiResources returns the discovery of the resources of the types of the scheme in the
+// group version. The types without list type, e.g. the options, are not resources.
+func (s *fakeAPIServer) apiResources(gv schema.GroupVersion) *metav1.APIResourceList {
+	resources := &metav1.APIResourceList{TypeMeta: metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"}, GroupVersion: gv.String()}
+	knownTypes := s.client.Scheme().KnownTypes(gv)
+	for kind := range knownTypes {
+		if _, ok := knownTypes[kind+"List"]; !ok {
+			continue
+		}
+		mapping, err := s.client.RESTMapper().RESTMapping(gv.WithKind(kind).GroupKind(), gv.Version)
+		if err != nil {
+			continue
+		}
+		resources.APIResources = append(resources.APIResources, metav1.APIResource{
+			Name:       mapping.Resource.Resource,
+			Namespaced: mapping.Scope.Name() == meta.RESTScopeNameNamespace,
+			Kind:       kind,
+			Verbs:      apiVerbs,
+		})
+	}
+	sort.Slice(resources.APIResources, func(i, j int) bool { return resources.APIResources[i].Name < resources.APIResources[j].Name })
+	return resources
+}
+
+func (s *fakeAPIServer) respond(w http.ResponseWriter, status int, obj interface{}, err error) {
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, status, obj)
+}
+
+func (s *fakeAPIServer) writeError(w http.ResponseWriter, err error) {
+	status, ok := err.(apierrors.APIStatus)
+	if !ok {
+		status = apierrors.NewInternalError(err)
+	}
+	result := status.Status()
+	result.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+	s.writeJSON(w, int(result.Code), &result)
+}
+
+func (s *fakeAPIServer) writeJSON(w http.ResponseWriter, status int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(obj)
+}
+
+func newObject(gvk schema.GroupVersionKind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+// readObject reads the object of the request body, encoded in JSON or, for the types of the
+// scheme, in protobuf
+func (s *fakeAPIServer) readObject(r *http.Request, gvk schema.GroupVersionKind, namespace string) (*unstructured.Unstructured, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), runtime.ContentTypeProtobuf) {
+		typed, _, err := s.codecs.UniversalDeserializer().Decode(body, &gvk, nil)
+		if err != nil {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid object: %v", err))
+		}
+		if obj.Object, err = runtime.DefaultUnstructuredConverter.ToUnstructured(typed); err != nil {
+			return nil, err
+		}
+	} else if err := obj.UnmarshalJSON(body); err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid object: %v", err))
+	}
+	obj.SetGroupVersionKind(gvk)
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+	return obj, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
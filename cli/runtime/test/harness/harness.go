@@ -0,0 +1,326 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package harness runs the commands of a plugin in-process in tests, with the tanzu
+// configuration and the kubeconfig isolated in a temporary directory and fake
+// kubernetes APIs attached to the kubernetes contexts.
+package harness
+
+import (
+	"bytes"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/plugin"
+)
+
+// EnvUpdateGoldenFiles is the environment variable which, set to "true",
+// makes AssertGolden write the golden files instead of comparing them.
+const EnvUpdateGoldenFiles = "TANZU_TEST_UPDATE_GOLDEN"
+
+// outputMutex serializes the capture of the standard output and error of the process
+var outputMutex sync.Mutex
+
+// Harness runs the commands of a plugin in-process, with the tanzu configuration
+// and the kubeconfig isolated in a temporary directory of the test.
+type Harness struct {
+	// Plugin under test.
+	Plugin *plugin.Plugin
+	// Scheme of the fake clients of the kubernetes contexts, the client-go scheme by default.
+	Scheme *runtime.Scheme
+
+	t        testing.TB
+	dir      string
+	clusters map[string]*FakeCluster
+}
+
+// FakeCluster is a fake kubernetes API attached to a kubernetes context.
+type FakeCluster struct {
+	// Name of the context.
+	Name string
+	// Client is the fake client serving the objects of the cluster.
+	Client client.Client
+	// Endpoint is the URL of the kubernetes API serving the objects of the fake client,
+	// set as the server of the cluster of the kubeconfig context.
+	Endpoint string
+}
+
+// Output is the captured output of a command run by the harness.
+type Output struct {
+	// Stdout of the command.
+	Stdout string
+	// Stderr of the command.
+	Stderr string
+	// Err returned by the command.
+	Err error
+}
+
+// NewHarness creates the plugin from the descriptor and the commands,
+// and isolates the tanzu configuration in a temporary directory.
+func NewHarness(t testing.TB, descriptor *cliapi.PluginDescriptor, commands ...*cobra.Command) *Harness {
+	p, err := plugin.NewPlugin(descriptor)
+	require.NoError(t, err)
+	p.AddCommands(commands...)
+
+	dir := t.TempDir()
+	t.Setenv(config.EnvConfigKey, filepath.Join(dir, "config.yaml"))
+	t.Setenv(config.EnvConfigNextGenKey, filepath.Join(dir, "config-ng.yaml"))
+	t.Setenv(config.EnvConfigMetadataKey, filepath.Join(dir, "config-metadata.yaml"))
+	t.Setenv(config.EnvCredentialStoreKey, config.CredentialStoreConfig)
+	t.Setenv(clientcmd.RecommendedConfigPathEnvVar, filepath.Join(dir, "kubeconfig"))
+
+	return &Harness{
+		Plugin:   p,
+		Scheme:   clientgoscheme.Scheme,
+		t:        t,
+		dir:      dir,
+		clusters: make(map[string]*FakeCluster),
+	}
+}
+
+// ConfigDir returns the temporary directory of the tanzu configuration.
+func (h *Harness) ConfigDir() string {
+	return h.dir
+}
+
+// KubeconfigPath returns the path of the kubeconfig of the kubernetes contexts.
+func (h *Harness) KubeconfigPath() string {
+	return filepath.Join(h.dir, "kubeconfig")
+}
+
+// AddContext adds the context to the tanzu configuration.
+func (h *Harness) AddContext(ctx *configapi.Context, setCurrent bool) {
+	require.NoError(h.t, config.SetContext(ctx, setCurrent))
+}
+
+// AddServer adds the server to the tanzu configuration.
+func (h *Harness) AddServer(s *configapi.Server, setCurrent bool) {
+	require.NoError(h.t, config.SetServer(s, setCurrent))
+}
+
+// AddK8sContext adds a kubernetes context, and its kubeconfig, as the current kubernetes
+// context and attaches a fake kubernetes API serving the objects to it. The fake API is
+// served over TLS on a local port for the clients built from the kubeconfig, and is also
+// available in-process with ClientForContext.
+func (h *Harness) AddK8sContext(name string, objects ...client.Object) *FakeCluster {
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(h.Scheme).
+		WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(h.Scheme)).
+		WithObjects(objects...).
+		Build()
+	server := httptest.NewTLSServer(newFakeAPIServer(fakeClient))
+	h.t.Cleanup(server.Close)
+	endpoint := server.URL
+
+	kubeconfig, err := clientcmd.LoadFromFile(h.KubeconfigPath())
+	if err != nil {
+		kubeconfig = clientcmdapi.NewConfig()
+	}
+	kubeconfig.Clusters[name] = &clientcmdapi.Cluster{
+		Server:                   endpoint,
+		CertificateAuthorityData: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}),
+	}
+	kubeconfig.AuthInfos[name] = &clientcmdapi.AuthInfo{Token: "fake-token"}
+	kubeconfig.Contexts[name] = &clientcmdapi.Context{Cluster: name, AuthInfo: name}
+	kubeconfig.CurrentContext = name
+	require.NoError(h.t, clientcmd.WriteToFile(*kubeconfig, h.KubeconfigPath()))
+
+	h.AddContext(&configapi.Context{
+		Name: name,
+		Type: configapi.CtxTypeK8s,
+		ClusterOpts: &configapi.ClusterServer{
+			Endpoint:            endpoint,
+			Path:                h.KubeconfigPath(),
+			Context:             name,
+			IsManagementCluster: true,
+		},
+	}, true)
+
+	cluster := &FakeCluster{
+		Name:     name,
+		Client:   fakeClient,
+		Endpoint: endpoint,
+	}
+	h.clusters[name] = cluster
+	return cluster
+}
+
+// AddTMCContext adds a mission-control context with fake tokens as the current mission-control context.
+func (h *Harness) AddTMCContext(name string) {
+	h.AddContext(&configapi.Context{
+		Name: name,
+		Type: configapi.CtxTypeTMC,
+		GlobalOpts: &configapi.GlobalServer{
+			Endpoint: fmt.Sprintf("%s.fake:443", name),
+			Auth: configapi.GlobalServerAuth{
+				Issuer:       fmt.Sprintf("https://%s.fake/issuer", name),
+				UserName:     "fake-user",
+				AccessToken:  "fake-access-token",
+				RefreshToken: "fake-refresh-token",
+				Type:         "api-token",
+			},
+		},
+	}, true)
+}
+
+// Cluster returns the fake kubernetes API attached to the context, or nil.
+func (h *Harness) Cluster(name string) *FakeCluster {
+	return h.clusters[name]
+}
+
+// ClientForContext returns the client of the fake kubernetes API attached to the context.
+// Plugins resolving their kubernetes client through a function variable can replace it
+// with ClientForContext in tests.
+func (h *Harness) ClientForContext(ctx *configapi.Context) (client.Client, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("no context")
+	}
+	cluster, ok := h.clusters[ctx.Name]
+	if !ok {
+		return nil, fmt.Errorf("no fake kubernetes API attached to context %q", ctx.Name)
+	}
+	return cluster.Client, nil
+}
+
+// Run runs the plugin command with the arguments and captures its output. The
+// flags of the commands are reset to their default values after the run.
+func (h *Harness) Run(args ...string) *Output {
+	defer resetFlags(h.Plugin.Cmd)
+
+	outputMutex.Lock()
+	defer outputMutex.Unlock()
+
+	var stdout, stderr bytes.Buffer
+	h.Plugin.Cmd.SetOut(&stdout)
+	h.Plugin.Cmd.SetErr(&stderr)
+	h.Plugin.Cmd.SetArgs(args)
+	defer func() {
+		h.Plugin.Cmd.SetOut(nil)
+		h.Plugin.Cmd.SetErr(nil)
+		h.Plugin.Cmd.SetArgs(nil)
+	}()
+
+	var err error
+	captureErr := captureOutput(&stdout, &stderr, func() {
+		err = h.Plugin.Execute()
+	})
+	require.NoError(h.t, captureErr)
+
+	return &Output{Stdout: stdout.String(), Stderr: stderr.String(), Err: err}
+}
+
+// RunWithOutputFormats runs the plugin command once for each output format,
+// passing the format with the --output flag, and captures the output per format.
+func (h *Harness) RunWithOutputFormats(args []string, formats ...string) map[string]*Output {
+	outputs := make(map[string]*Output)
+	for _, format := range formats {
+		outputs[format] = h.Run(append(append([]string{}, args...), "--output", format)...)
+	}
+	return outputs
+}
+
+// AssertGolden asserts the actual output equals the content of the golden file
+// testdata/<name>.golden. The golden file is written instead when the environment
+// variable EnvUpdateGoldenFiles is "true".
+func AssertGolden(t testing.TB, name, actual string) {
+	path := filepath.Join("testdata", name+".golden")
+	if os.Getenv(EnvUpdateGoldenFiles) == "true" {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(actual), 0o600))
+		return
+	}
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err, "reading golden file, set %s=true to create it", EnvUpdateGoldenFiles)
+	require.Equal(t, string(expected), actual, "output differs from golden file %s", path)
+}
+
+// captureOutput runs the function with the standard output and error of the process
+// redirected to the writers, capturing the output not written through cobra. The output
+// of the process is appended to the writers once the function returns, as the writers
+// may be written to by the function.
+func captureOutput(stdout, stderr io.Writer, f func()) error {
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	origStdout, origStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outW, errW
+
+	var wg sync.WaitGroup
+	var outBuf, errBuf bytes.Buffer
+	wg.Add(2)
+	go func() {
+		_, _ = io.Copy(&outBuf, outR)
+		wg.Done()
+	}()
+	go func() {
+		_, _ = io.Copy(&errBuf, errR)
+		wg.Done()
+	}()
+
+	defer func() {
+		os.Stdout, os.Stderr = origStdout, origStderr
+		outW.Close()
+		errW.Close()
+		wg.Wait()
+		outR.Close()
+		errR.Close()
+		_, _ = stdout.Write(outBuf.Bytes())
+		_, _ = stderr.Write(errBuf.Bytes())
+	}()
+	f()
+	return nil
+}
+
+// resetFlags resets the flags of the command and its sub-commands to their default values
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			_ = sv.Replace(splitSliceDefault(f.DefValue))
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, c := range cmd.Commands() {
+		resetFlags(c)
+	}
+}
+
+func splitSliceDefault(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, ",")
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package harness
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cliapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/cli/v1alpha1"
+	configapi "github.com/vmware-tanzu/tanzu-framework/cli/runtime/apis/config/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/component"
+	"github.com/vmware-tanzu/tanzu-framework/cli/runtime/config"
+)
+
+var newClientForContext = clientFromKubeconfig
+
+// clientFromKubeconfig returns the client of the cluster of the kubeconfig context of the context
+func clientFromKubeconfig(ctx *configapi.Context) (client.Client, error) {
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: ctx.ClusterOpts.Path},
+		&clientcmd.ConfigOverrides{CurrentContext: ctx.ClusterOpts.Context}).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(restConfig, client.Options{})
+}
+
+func newListConfigMapsCmd() *cobra.Command {
+	var outputFormat string
+	cmd := &cobra.Command{
+		Use: "list",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := config.GetCurrentContext(configapi.CtxTypeK8s)
+			if err != nil {
+				return err
+			}
+			c, err := newClientForContext(ctx)
+			if err != nil {
+				return err
+			}
+			configMaps := &corev1.ConfigMapList{}
+			if err := c.List(context.Background(), configMaps); err != nil {
+				return err
+			}
+			output := component.NewOutputWriter(cmd.OutOrStdout(), outputFormat, "Name", "Namespace")
+			for i := range configMaps.Items {
+				output.AddRow(configMaps.Items[i].Name, configMaps.Items[i].Namespace)
+			}
+			output.Render()
+			fmt.Println("listed from", ctx.Name)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format")
+	return cmd
+}
+
+func TestHarness(t *testing.T) {
+	h := NewHarness(t, &cliapi.PluginDescriptor{
+		Name:        "test",
+		Description: "test plugin",
+		Version:     "v0.0.1",
+		Group:       cliapi.RunCmdGroup,
+	}, newListConfigMapsCmd())
+
+	h.AddTMCContext("tmc")
+	h.AddK8sContext("mgmt",
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm-1", Namespace: "default"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm-2", Namespace: "kube-system"}},
+	)
+
+	ctx, err := config.GetCurrentContext(configapi.CtxTypeTMC)
+	require.NoError(t, err)
+	require.Equal(t, "fake-access-token", ctx.GlobalOpts.Auth.AccessToken)
+	server, err := config.GetCurrentServer()
+	require.NoError(t, err)
+	require.Equal(t, "mgmt", server.Name)
+	require.FileExists(t, h.KubeconfigPath())
+	k8sCtx, err := config.GetCurrentContext(configapi.CtxTypeK8s)
+	require.NoError(t, err)
+	require.Equal(t, h.Cluster("mgmt").Endpoint, k8sCtx.ClusterOpts.Endpoint)
+
+	// The commands reach the fake kubernetes API through the kubeconfig
+	outputs := h.RunWithOutputFormats([]string{"list"}, "table", "json")
+	for format, output := range outputs {
+		require.NoError(t, output.Err)
+		AssertGolden(t, "harness-list-"+format, output.Stdout)
+	}
+
+	// The flags are reset after each run
+	output := h.Run("list")
+	require.NoError(t, output.Err)
+	require.Equal(t, outputs["table"].Stdout, output.Stdout)
+
+	require.NoError(t, h.Cluster("mgmt").Client.Delete(context.Background(),
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm-1", Namespace: "default"}}))
+	output = h.Run("list", "-o", "jsonpath={[*].name}")
+	require.NoError(t, output.Err)
+	require.Equal(t, "cm-2listed from mgmt\n", output.Stdout)
+
+	// Objects created through the kubeconfig are served by the fake client
+	kubeClient, err := clientFromKubeconfig(k8sCtx)
+	require.NoError(t, err)
+	require.NoError(t, kubeClient.Create(context.Background(),
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm-3", Namespace: "default"}}))
+	require.NoError(t, h.Cluster("mgmt").Client.Get(context.Background(),
+		client.ObjectKey{Name: "cm-3", Namespace: "default"}, &corev1.ConfigMap{}))
+	require.NoError(t, kubeClient.Delete(context.Background(),
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm-3", Namespace: "default"}}))
+
+	// The commands reach the fake kubernetes API in-process with ClientForContext
+	newClientForContext = h.ClientForContext
+	defer func() { newClientForContext = clientFromKubeconfig }()
+	output = h.Run("list", "-o", "jsonpath={[*].name}")
+	require.NoError(t, output.Err)
+	require.Equal(t, "cm-2listed from mgmt\n", output.Stdout)
+
+	h.AddContext(&configapi.Context{Name: "other", Type: configapi.CtxTypeK8s, ClusterOpts: &configapi.ClusterServer{}}, true)
+	output = h.Run("list")
+	require.EqualError(t, output.Err, `no fake kubernetes API attached to context "other"`)
+	require.Contains(t, output.Stderr, `no fake kubernetes API attached to context "other"`)
+}
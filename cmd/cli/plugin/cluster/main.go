@@ -53,7 +53,7 @@ func main() {
 		clusterNodePoolCmd,
 	)
 	if err := p.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(plugin.ExitCode(err))
 	}
 }
 
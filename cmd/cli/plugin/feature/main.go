@@ -37,6 +37,6 @@ func main() {
 	)
 
 	if err := p.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(plugin.ExitCode(err))
 	}
 }
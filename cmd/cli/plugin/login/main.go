@@ -91,7 +91,7 @@ func main() {
 	`
 
 	if err := p.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(plugin.ExitCode(err))
 	}
 }
 
@@ -139,7 +139,7 @@ func login(cmd *cobra.Command, args []string) (err error) {
 
 	// Sync all required plugins if the "features.global.context-aware-cli-for-plugins" feature is enabled
 	if config.IsFeatureActivated(cliconfig.FeatureContextAwareCLIForPlugins) {
-		if err = pluginmanager.SyncPlugins(); err != nil {
+		if err = pluginmanager.SyncPlugins(pluginmanager.InstallOptions{}); err != nil {
 			log.Warning("unable to automatically sync the plugins from target server. Please run 'tanzu plugin sync' command to sync plugins manually")
 		}
 	}
@@ -198,7 +198,7 @@ func runInit() error {
 
 	// Sync plugins if management-cluster creation is successful
 	if config.IsFeatureActivated(cliconfig.FeatureContextAwareCLIForPlugins) {
-		err = pluginmanager.SyncPlugins()
+		err = pluginmanager.SyncPlugins(pluginmanager.InstallOptions{})
 		if err != nil {
 			log.Warningf("unable to sync plugins after management cluster create. Please run `tanzu plugin sync` command manually to install/update plugins")
 		}
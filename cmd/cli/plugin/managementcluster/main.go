@@ -53,6 +53,6 @@ func main() {
 	)
 
 	if err = p.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(plugin.ExitCode(err))
 	}
 }
@@ -42,7 +42,7 @@ func main() {
 	if config.IsFeatureActivated(FeatureFlagPackagePluginKctrlCommandTree) {
 		kctrl.Invoke(p)
 		if err := p.Execute(); err != nil {
-			os.Exit(1)
+			os.Exit(plugin.ExitCode(err))
 		}
 		return
 	}
@@ -57,7 +57,7 @@ func main() {
 		packageInstalledCmd,
 	)
 	if err := p.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(plugin.ExitCode(err))
 	}
 }
 
@@ -32,6 +32,6 @@ func main() {
 		loginOIDCCommand(getPinnipedCLICmd),
 	)
 	if err := p.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(plugin.ExitCode(err))
 	}
 }
@@ -44,7 +44,7 @@ func main() {
 		registrySecretCmd,
 	)
 	if err := p.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(plugin.ExitCode(err))
 	}
 }
 
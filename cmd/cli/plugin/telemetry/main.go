@@ -42,6 +42,6 @@ func main() {
 		uc.Cmd,
 	)
 	if err := p.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(plugin.ExitCode(err))
 	}
 }
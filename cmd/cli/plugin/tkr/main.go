@@ -73,7 +73,7 @@ func main() {
 		p.AddCommands(v1alpha1CmdsList...)
 	}
 	if err := p.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(plugin.ExitCode(err))
 	}
 }
 